@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
 
 	"github.com/kelseyhightower/envconfig"
 	yaml "github.com/projectcalico/go-yaml-wrapper"
@@ -74,3 +75,65 @@ func LoadClientConfigFromEnvironment() (*CalicoAPIConfig, error) {
 
 	return c, nil
 }
+
+// The service account token mounted into pods, used to detect that the client is running in
+// a Kubernetes cluster.  This is a variable so that it may be overridden in tests.
+var serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// ResolveClientConfig loads the ClientConfig and returns it along with the datastore type
+// chosen.  If a file is specified the config and datastore type are loaded from the file.
+// Otherwise the config is loaded from environment variables.  If the datastore type is not
+// set in the environment it is etcdv3 if etcd endpoints are configured, kubernetes if a
+// kubeconfig is configured, kubernetes if running in a Kubernetes pod with a service account,
+// and otherwise defaults to etcdv3.
+func ResolveClientConfig(filename string) (*CalicoAPIConfig, DatastoreType, error) {
+	if filename != "" {
+		c, err := LoadClientConfig(filename)
+		if err != nil {
+			return nil, "", err
+		}
+		return c, c.Spec.DatastoreType, nil
+	}
+
+	c, err := LoadClientConfigFromEnvironment()
+	if err != nil {
+		return nil, "", err
+	}
+	switch {
+	case envIsSet("DATASTORE_TYPE"):
+		log.Debug("Using datastore type from environment")
+	case c.Spec.EtcdEndpoints != "":
+		log.Debug("Etcd endpoints configured - using etcdv3 datastore")
+		c.Spec.DatastoreType = EtcdV3
+	case c.Spec.Kubeconfig != "":
+		log.Debug("Kubeconfig configured - using kubernetes datastore")
+		c.Spec.DatastoreType = Kubernetes
+	case inCluster():
+		log.Debug("Running in a Kubernetes cluster - using kubernetes datastore")
+		c.Spec.DatastoreType = Kubernetes
+	default:
+		log.Debug("No datastore configured - defaulting to etcdv3 datastore")
+		c.Spec.DatastoreType = EtcdV3
+	}
+	return c, c.Spec.DatastoreType, nil
+}
+
+// envIsSet returns whether the environment variable is set, either with or without the
+// CALICO_ prefix.
+func envIsSet(name string) bool {
+	if _, ok := os.LookupEnv("CALICO_" + name); ok {
+		return true
+	}
+	_, ok := os.LookupEnv(name)
+	return ok
+}
+
+// inCluster returns whether the client is running in a Kubernetes pod with a service
+// account.
+func inCluster() bool {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return false
+	}
+	_, err := os.Stat(serviceAccountTokenFile)
+	return err == nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// The environment variables that affect the resolved config.
+var resolveEnvVars = []string{
+	"DATASTORE_TYPE", "CALICO_DATASTORE_TYPE",
+	"ETCD_ENDPOINTS", "CALICO_ETCD_ENDPOINTS",
+	"KUBECONFIG", "CALICO_KUBECONFIG",
+	"KUBERNETES_SERVICE_HOST",
+}
+
+var _ = Describe("ResolveClientConfig", func() {
+	var dir string
+	var savedEnv map[string]string
+	var savedTokenFile string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "apiconfig")
+		Expect(err).NotTo(HaveOccurred())
+
+		// Start each test with a clean environment and no service account.
+		savedEnv = map[string]string{}
+		for _, k := range resolveEnvVars {
+			if v, ok := os.LookupEnv(k); ok {
+				savedEnv[k] = v
+			}
+			os.Unsetenv(k)
+		}
+		savedTokenFile = serviceAccountTokenFile
+		serviceAccountTokenFile = filepath.Join(dir, "token")
+	})
+
+	AfterEach(func() {
+		for _, k := range resolveEnvVars {
+			os.Unsetenv(k)
+		}
+		for k, v := range savedEnv {
+			os.Setenv(k, v)
+		}
+		serviceAccountTokenFile = savedTokenFile
+		os.RemoveAll(dir)
+	})
+
+	addServiceAccount := func() {
+		os.Setenv("KUBERNETES_SERVICE_HOST", "10.96.0.1")
+		Expect(ioutil.WriteFile(serviceAccountTokenFile, []byte("token"), 0600)).To(Succeed())
+	}
+
+	It("should load the config from the specified file in preference to the environment", func() {
+		filename := filepath.Join(dir, "calico.yaml")
+		Expect(ioutil.WriteFile(filename, []byte(`apiVersion: projectcalico.org/v3
+kind: CalicoAPIConfig
+spec:
+  datastoreType: kubernetes
+  kubeconfig: /path/from/file
+`), 0600)).To(Succeed())
+		os.Setenv("ETCD_ENDPOINTS", "http://10.0.0.1:2379")
+		addServiceAccount()
+
+		c, dt, err := ResolveClientConfig(filename)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dt).To(Equal(Kubernetes))
+		Expect(c.Spec.DatastoreType).To(Equal(Kubernetes))
+		Expect(c.Spec.Kubeconfig).To(Equal("/path/from/file"))
+		Expect(c.Spec.EtcdEndpoints).To(BeEmpty())
+	})
+
+	It("should return an error if the specified file does not exist", func() {
+		_, _, err := ResolveClientConfig(filepath.Join(dir, "missing.yaml"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("resolving the config from the environment",
+		func(env map[string]string, serviceAccount bool, expected DatastoreType) {
+			for k, v := range env {
+				os.Setenv(k, v)
+			}
+			if serviceAccount {
+				addServiceAccount()
+			}
+
+			c, dt, err := ResolveClientConfig("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dt).To(Equal(expected))
+			Expect(c.Spec.DatastoreType).To(Equal(expected))
+		},
+		Entry("explicit datastore type takes precedence",
+			map[string]string{"DATASTORE_TYPE": "kubernetes", "ETCD_ENDPOINTS": "http://10.0.0.1:2379"}, false, Kubernetes),
+		Entry("explicit datastore type with the CALICO_ prefix",
+			map[string]string{"CALICO_DATASTORE_TYPE": "etcdv3", "KUBECONFIG": "/kubeconfig"}, true, EtcdV3),
+		Entry("etcd endpoints take precedence over kubeconfig",
+			map[string]string{"ETCD_ENDPOINTS": "http://10.0.0.1:2379", "KUBECONFIG": "/kubeconfig"}, true, EtcdV3),
+		Entry("kubeconfig takes precedence over the service account",
+			map[string]string{"KUBECONFIG": "/kubeconfig"}, true, Kubernetes),
+		Entry("in-cluster service account",
+			map[string]string{}, true, Kubernetes),
+		Entry("service host without a service account token",
+			map[string]string{"KUBERNETES_SERVICE_HOST": "10.96.0.1"}, false, EtcdV3),
+		Entry("no configuration defaults to etcdv3",
+			map[string]string{}, false, EtcdV3),
+	)
+})
@@ -63,6 +63,11 @@ type IPPoolSpec struct {
 
 	// When disabled is true, Calico IPAM will not assign addresses from this pool.
 	Disabled bool `json:"disabled,omitempty"`
+
+	// ReservedCIDRs is a list of CIDRs within this pool that Calico IPAM should not
+	// assign to workloads, for example the addresses of a gateway or DHCP server.  Each
+	// CIDR must fall within the pool CIDR.
+	ReservedCIDRs []net.IPNet `json:"reserved-cidrs,omitempty" validate:"omitempty"`
 }
 
 type IPIPConfiguration struct {
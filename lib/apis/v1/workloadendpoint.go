@@ -72,6 +72,11 @@ type WorkloadEndpointMetadata struct {
 	// the same labels. For example, they could be used to label all “production” workloads
 	// with “deployment=prod” so that security policy can be applied to production workloads.
 	Labels map[string]string `json:"labels,omitempty" validate:"omitempty,labels"`
+
+	// Arbitrary key-value information to be used by clients.  Unlike labels, annotations
+	// are not used for selector matching.  Annotations with the projectcalico.org/ prefix
+	// are reserved for use by Calico.
+	Annotations map[string]string `json:"annotations,omitempty" validate:"omitempty,annotations"`
 }
 
 // WorkloadEndpointMetadata contains the specification for a WorkloadEndpoint resource.
@@ -49,6 +49,10 @@ type IPPoolSpec struct {
 	NATOutgoing bool `json:"natOutgoing,omitempty"`
 	// When disabled is true, Calico IPAM will not assign addresses from this pool.
 	Disabled bool `json:"disabled,omitempty"`
+	// ReservedCIDRs is a list of CIDRs within this pool that Calico IPAM will not
+	// automatically assign, for example the addresses of a gateway or DHCP server.  Each
+	// CIDR must fall within the pool CIDR.
+	ReservedCIDRs []string `json:"reservedCIDRs,omitempty" validate:"omitempty,dive,cidr"`
 
 	// Deprecated: this field is only used for APIv1 backwards compatibility.
 	// Setting this field is not allowed, this field is for internal use only.
@@ -60,6 +60,18 @@ type Rule struct {
 
 	// HTTP contains match criteria that apply to HTTP requests.
 	HTTP *HTTPMatch `json:"http,omitempty" validate:"omitempty"`
+
+	// Metadata contains additional information for this rule, for example a note for
+	// auditing.  It does not affect the traffic matched by the rule.
+	Metadata *RuleMetadata `json:"metadata,omitempty" validate:"omitempty"`
+}
+
+// RuleMetadata contains additional information about a rule that is not used when matching
+// traffic.
+type RuleMetadata struct {
+	// Annotations is a set of key value pairs that give extra information about the rule.
+	// The keys have the same format as resource annotation keys.
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // HTTPPath specifies an HTTP path to match. It may be either of the form:
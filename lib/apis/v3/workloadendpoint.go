@@ -59,9 +59,9 @@ type WorkloadEndpointSpec struct {
 	// endpoint via the configured IPNetworks.
 	IPNATs []IPNAT `json:"ipNATs,omitempty" validate:"omitempty,dive"`
 	// IPv4Gateway is the gateway IPv4 address for traffic from the workload.
-	IPv4Gateway string `json:"ipv4Gateway,omitempty" validate:"omitempty,ipv4"`
+	IPv4Gateway string `json:"ipv4Gateway,omitempty" validate:"omitempty"`
 	// IPv6Gateway is the gateway IPv6 address for traffic from the workload.
-	IPv6Gateway string `json:"ipv6Gateway,omitempty" validate:"omitempty,ipv6"`
+	IPv6Gateway string `json:"ipv6Gateway,omitempty" validate:"omitempty"`
 	// A list of security Profile resources that apply to this endpoint. Each profile is
 	// applied in the order that they appear in this list.  Profile rules are applied
 	// after the selector-based security policy.
@@ -69,7 +69,7 @@ type WorkloadEndpointSpec struct {
 	// InterfaceName the name of the Linux interface on the host: for example, tap80.
 	InterfaceName string `json:"interfaceName,omitempty" validate:"interface"`
 	// MAC is the MAC address of the endpoint interface.
-	MAC string `json:"mac,omitempty" validate:"omitempty,mac"`
+	MAC string `json:"mac,omitempty" validate:"omitempty"`
 	// Ports contains the endpoint's named ports, which may be referenced in security policy rules.
 	Ports []EndpointPort `json:"ports,omitempty" validate:"dive,omitempty"`
 }
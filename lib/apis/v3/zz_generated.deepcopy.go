@@ -1370,6 +1370,11 @@ func (in *IPPoolList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPPoolSpec) DeepCopyInto(out *IPPoolSpec) {
 	*out = *in
+	if in.ReservedCIDRs != nil {
+		in, out := &in.ReservedCIDRs, &out.ReservedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.IPIP != nil {
 		in, out := &in.IPIP, &out.IPIP
 		if *in == nil {
@@ -1805,6 +1810,15 @@ func (in *Rule) DeepCopyInto(out *Rule) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RuleMetadata)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -1818,6 +1832,29 @@ func (in *Rule) DeepCopy() *Rule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleMetadata) DeepCopyInto(out *RuleMetadata) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleMetadata.
+func (in *RuleMetadata) DeepCopy() *RuleMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAccountMatch) DeepCopyInto(out *ServiceAccountMatch) {
 	*out = *in
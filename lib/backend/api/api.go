@@ -60,7 +60,8 @@ func (s SyncStatus) String() string {
 type Client interface {
 	// Create creates the object specified in the KVPair, which must not
 	// already exist. On success, returns a KVPair for the object with
-	// revision  information filled-in.
+	// revision  information filled-in.  If the object already exists, returns
+	// an ErrorResourceAlreadyExists identifying the object's key.
 	Create(ctx context.Context, object *model.KVPair) (*model.KVPair, error)
 
 	// Update modifies the existing object specified in the KVPair.
@@ -91,11 +92,20 @@ type Client interface {
 	// revision information.
 	Get(ctx context.Context, key model.Key, revision string) (*model.KVPair, error)
 
+	// Exists returns whether the object identified by the given key exists.
+	// Where the datastore supports it this avoids fetching and parsing the
+	// object value.  A not-found is not an error; any other error is returned.
+	Exists(ctx context.Context, key model.Key, revision string) (bool, error)
+
 	// List returns a slice of KVPairs matching the input list options.
 	// list should be passed one of the model.<Type>ListOptions structs.
 	// Non-zero fields in the struct are used as filters.
 	List(ctx context.Context, list model.ListInterface, revision string) (*model.KVPairList, error)
 
+	// Count returns the number of objects matching the input list options.  Where the
+	// datastore supports it this avoids fetching and parsing the object values.
+	Count(ctx context.Context, list model.ListInterface, revision string) (int, error)
+
 	// Watch returns a WatchInterface used for watching a resources matching the
 	// input list options.
 	Watch(ctx context.Context, list model.ListInterface, revision string) (WatchInterface, error)
@@ -107,8 +117,9 @@ type Client interface {
 	// Clean removes Calico data from the backend datastore.  Used for test purposes.
 	Clean() error
 
-	// Close the client.
-	//Close()
+	// Close releases the datastore connections held by the client.  The client
+	// must not be used after it is closed.
+	Close() error
 }
 
 type Syncer interface {
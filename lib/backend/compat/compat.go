@@ -47,6 +47,10 @@ func (c *ModelAdaptor) Clean() error {
 	return c.client.Clean()
 }
 
+func (c *ModelAdaptor) Close() error {
+	return c.client.Close()
+}
+
 // Create an entry in the datastore.  This errors if the entry already exists.
 func (c *ModelAdaptor) Create(ctx context.Context, d *model.KVPair) (*model.KVPair, error) {
 	var err error
@@ -239,6 +243,39 @@ func (c *ModelAdaptor) Get(ctx context.Context, k model.Key, rev string) (*model
 	}
 }
 
+// Exists returns whether an entry exists in the datastore.  Keys that are split
+// into multiple entries by the adaptor are checked using a Get.
+func (c *ModelAdaptor) Exists(ctx context.Context, k model.Key, rev string) (bool, error) {
+	switch k.(type) {
+	case model.ProfileKey, model.NodeKey, model.BlockKey, model.GlobalBGPConfigKey:
+		_, err := c.Get(ctx, k, rev)
+		if _, ok := err.(errors.ErrorResourceDoesNotExist); ok {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		return true, nil
+	default:
+		return c.client.Exists(ctx, k, rev)
+	}
+}
+
+// Count returns the number of entries in the datastore matching the request in the
+// ListInterface.  Keys that are split into multiple entries by the adaptor are counted
+// using a List.
+func (c *ModelAdaptor) Count(ctx context.Context, l model.ListInterface, rev string) (int, error) {
+	switch l.(type) {
+	case model.NodeListOptions, model.BlockListOptions, model.GlobalBGPConfigListOptions:
+		kvps, err := c.List(ctx, l, rev)
+		if err != nil {
+			return 0, err
+		}
+		return len(kvps.KVPairs), nil
+	default:
+		return c.client.Count(ctx, l, rev)
+	}
+}
+
 // List entries in the datastore.  This may return an empty list of there are
 // no entries matching the request in the ListInterface.
 func (c *ModelAdaptor) List(ctx context.Context, l model.ListInterface, rev string) (*model.KVPairList, error) {
@@ -368,7 +405,7 @@ func (c *ModelAdaptor) listNodes(ctx context.Context, l model.NodeListOptions) (
 		results[idx] = &model.KVPair{Key: nk, Value: &nv}
 	}
 
-	return &model.KVPairList{KVPairs: results}, nil
+	return &model.KVPairList{KVPairs: results, Errors: hmr.Errors}, nil
 }
 
 // listBlock returns list of KVPairs for Block, includes making sure
@@ -391,7 +428,7 @@ func (c *ModelAdaptor) listBlock(ctx context.Context, l model.BlockListOptions)
 		results[i] = ensureBlockAffinity(bkv)
 	}
 
-	return &model.KVPairList{KVPairs: results}, nil
+	return &model.KVPairList{KVPairs: results, Errors: blockList.Errors}, nil
 }
 
 // ensureBlockAffinity ensures Affinity field has a proper value,
@@ -27,18 +27,16 @@ import (
 )
 
 // convertListResponse converts etcdv3 Kv to a model.KVPair with parsed values.
-// If the etcdv3 key or value does not represent the resource specified by the ListInterface,
-// or if value cannot be parsed, this method returns nil.
-func convertListResponse(ekv *mvccpb.KeyValue, l model.ListInterface) *model.KVPair {
+// If the etcdv3 key does not represent the resource specified by the ListInterface, this
+// method returns nil.  If the value cannot be parsed, this method returns an
+// ErrorParsingDatastoreEntry.
+func convertListResponse(ekv *mvccpb.KeyValue, l model.ListInterface) (*model.KVPair, error) {
 	log.WithField("etcdv3-etcdKey", string(ekv.Key)).Debug("Processing etcdv3 entry")
 	if k := l.KeyFromDefaultPath(string(ekv.Key)); k != nil {
 		log.WithField("model-etcdKey", k).Debug("Key is valid and converted to model-etcdKey")
-		if v, err := model.ParseValue(k, ekv.Value); err == nil {
-			log.Debug("Value is valid - return KVPair with parsed value")
-			return &model.KVPair{Key: k, Value: v, Revision: strconv.FormatInt(ekv.ModRevision, 10)}
-		}
+		return etcdToKVPair(k, ekv)
 	}
-	return nil
+	return nil, nil
 }
 
 // convertWatchEvent converts an etcdv3 watch event to an api.WatchEvent, or nil if the
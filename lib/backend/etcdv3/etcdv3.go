@@ -16,6 +16,8 @@ package etcdv3
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -23,6 +25,7 @@ import (
 	"time"
 
 	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
 	"github.com/coreos/etcd/pkg/transport"
 	log "github.com/sirupsen/logrus"
 
@@ -325,6 +328,9 @@ func (c *etcdV3Client) Get(ctx context.Context, k model.Key, revision string) (*
 	resp, err := c.etcdClient.Get(ctx, key, ops...)
 	if err != nil {
 		logCxt.WithError(err).Info("Error returned from etcdv3 client")
+		if isAuthError(err) {
+			return nil, cerrors.ErrorConnectionUnauthorized{Err: err}
+		}
 		return nil, cerrors.ErrorDatastoreError{Err: err}
 	}
 	if len(resp.Kvs) == 0 {
@@ -335,71 +341,209 @@ func (c *etcdV3Client) Get(ctx context.Context, k model.Key, revision string) (*
 	return etcdToKVPair(k, resp.Kvs[0])
 }
 
+// Exists returns whether an entry exists in the datastore.  Only the key is
+// fetched from etcd, the value is not returned or parsed.
+func (c *etcdV3Client) Exists(ctx context.Context, k model.Key, revision string) (bool, error) {
+	logCxt := log.WithFields(log.Fields{"model-etcdKey": k, "rev": revision})
+	logCxt.Debug("Processing Exists request")
+
+	key, err := model.KeyToDefaultPath(k)
+	if err != nil {
+		logCxt.Error("Unable to convert model.Key to an etcdv3 etcdKey")
+		return false, err
+	}
+	logCxt = logCxt.WithField("etcdv3-etcdKey", key)
+
+	ops := []clientv3.OpOption{clientv3.WithCountOnly()}
+	if len(revision) != 0 {
+		rev, err := parseRevision(revision)
+		if err != nil {
+			return false, err
+		}
+		ops = append(ops, clientv3.WithRev(rev))
+	}
+
+	logCxt.Debug("Calling Get on etcdv3 client")
+	resp, err := c.etcdClient.Get(ctx, key, ops...)
+	if err != nil {
+		logCxt.WithError(err).Info("Error returned from etcdv3 client")
+		return false, cerrors.ErrorDatastoreError{Err: err}
+	}
+	return resp.Count > 0, nil
+}
+
 // List entries in the datastore.  This may return an empty list of there are
 // no entries matching the request in the ListInterface.
 func (c *etcdV3Client) List(ctx context.Context, l model.ListInterface, revision string) (*model.KVPairList, error) {
 	logCxt := log.WithFields(log.Fields{"list-interface": l, "rev": revision})
 	logCxt.Debug("Processing List request")
 
-	// To list entries, we enumerate from the common root based on the supplied
-	// IDs, and then filter the results.
-	key := model.ListOptionsToDefaultPathRoot(l)
-
-	// -  If the final name segment of the name is itself a prefix, then just perform a prefix Get
-	//    using the constructed key.
-	// -  If the etcdKey is actually fully qualified, then perform an exact Get using the constructed
-	//    key.
-	// -  If the etcdKey is not fully qualified then it is a path prefix but the last segment is complete.
-	//    Append a terminating "/" and perform a prefix Get.  The terminating / for a prefix Get ensures
-	//    for a prefix of "/a" we only return "child entries" of "/a" such as "/a/x" and not siblings
-	//    such as "/ab".
-	ops := []clientv3.OpOption{}
-	if model.IsListOptionsLastSegmentPrefix(l) {
-		// The last segment is a prefix, perform a prefix Get without adding a segment
-		// delimiter.
-		logCxt.Debug("Performing a name-prefix query")
-		ops = append(ops, clientv3.WithPrefix())
-	} else if l.KeyFromDefaultPath(key) == nil {
-		// The etcdKey not a fully qualified etcdKey - it must be a prefix.
-		logCxt.Debug("Performing a parent-prefix query")
-		if !strings.HasSuffix(key, "/") {
-			key += "/"
-		}
-		ops = append(ops, clientv3.WithPrefix())
-	}
-	logCxt = logCxt.WithField("etcdv3-etcdKey", key)
+	key, isPrefix := listKey(l)
+	logCxt = logCxt.WithFields(log.Fields{"etcdv3-etcdKey": key, "isPrefix": isPrefix})
 
 	// We may also need to perform a get based on a particular revision.
+	var rev int64
 	if len(revision) != 0 {
-		rev, err := parseRevision(revision)
-		if err != nil {
+		var err error
+		if rev, err = parseRevision(revision); err != nil {
 			return nil, err
 		}
+	}
+
+	// Determine whether this is a paginated List.  Pagination is only applicable to
+	// prefix queries.  If a continue token is supplied, the List resumes from the key and
+	// revision encoded in the token so that all pages are read from the same snapshot.
+	startKey := key
+	var limit int64
+	if rlo, ok := l.(model.ResourceListOptions); ok && isPrefix && rlo.Limit > 0 {
+		limit = rlo.Limit
+		if len(rlo.Continue) != 0 {
+			token, err := decodeContinue(rlo.Continue)
+			if err != nil {
+				return nil, err
+			}
+			if !strings.HasPrefix(token.Start, key) {
+				logCxt.WithField("continue", rlo.Continue).Info("Continue token does not match List request")
+				return nil, continueValidationError(rlo.Continue)
+			}
+			startKey = token.Start
+			rev = token.Revision
+		}
+	}
+
+	ops := []clientv3.OpOption{}
+	if isPrefix {
+		ops = append(ops, clientv3.WithRange(clientv3.GetPrefixRangeEnd(key)))
+	}
+	if limit > 0 {
+		ops = append(ops, clientv3.WithLimit(limit))
+	}
+	if rev != 0 {
 		ops = append(ops, clientv3.WithRev(rev))
 	}
 
 	logCxt.Debug("Calling Get on etcdv3 client")
-	resp, err := c.etcdClient.Get(ctx, key, ops...)
+	resp, err := c.etcdClient.Get(ctx, startKey, ops...)
 	if err != nil {
 		logCxt.WithError(err).Info("Error returned from etcdv3 client")
 		return nil, cerrors.ErrorDatastoreError{Err: err}
 	}
 	logCxt.WithField("numResults", len(resp.Kvs)).Debug("Processing response from etcdv3")
 
-	// Filter/process the results.
+	// Filter/process the results.  Entries that cannot be parsed are skipped and their
+	// errors returned in the list.  Resources that do not match the filter of the list
+	// options (if any) are skipped.
+	rlo, _ := l.(model.ResourceListOptions)
 	list := []*model.KVPair{}
+	var errs []error
 	for _, p := range resp.Kvs {
-		if kv := convertListResponse(p, l); kv != nil {
+		kv, err := convertListResponse(p, l)
+		if err != nil {
+			logCxt.WithError(err).Warning("Unable to parse etcdv3 entry, skipping")
+			errs = append(errs, err)
+		} else if kv != nil && rlo.MatchesFilter(kv) {
 			list = append(list, kv)
 		}
 	}
 
+	// The List is at the requested revision if one was specified (or pinned by the continue
+	// token), otherwise it is at the current revision.  Returning the revision of the
+	// snapshot allows the caller to start a Watch from exactly that point.
+	listRev := resp.Header.Revision
+	if rev != 0 {
+		listRev = rev
+	}
+
+	// If this is a paginated List, the remaining pages are pinned to the revision of the
+	// first page.  If there are more results available, construct the continue token from
+	// the next key following the last key returned.
+	var cont string
+	if limit > 0 {
+		if resp.More && len(resp.Kvs) > 0 {
+			cont = encodeContinue(continueToken{
+				Revision: listRev,
+				Start:    string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00",
+			})
+		}
+	}
+
 	return &model.KVPairList{
 		KVPairs:  list,
-		Revision: strconv.FormatInt(resp.Header.Revision, 10),
+		Revision: strconv.FormatInt(listRev, 10),
+		Continue: cont,
+		Errors:   errs,
 	}, nil
 }
 
+// Count returns the number of entries in the datastore matching the request in the
+// ListInterface.  Only the keys are fetched from etcd, the values are not returned or
+// parsed.
+func (c *etcdV3Client) Count(ctx context.Context, l model.ListInterface, revision string) (int, error) {
+	logCxt := log.WithFields(log.Fields{"list-interface": l, "rev": revision})
+	logCxt.Debug("Processing Count request")
+
+	key, isPrefix := listKey(l)
+	logCxt = logCxt.WithFields(log.Fields{"etcdv3-etcdKey": key, "isPrefix": isPrefix})
+
+	ops := []clientv3.OpOption{clientv3.WithKeysOnly()}
+	if isPrefix {
+		ops = append(ops, clientv3.WithRange(clientv3.GetPrefixRangeEnd(key)))
+	}
+	if len(revision) != 0 {
+		rev, err := parseRevision(revision)
+		if err != nil {
+			return 0, err
+		}
+		ops = append(ops, clientv3.WithRev(rev))
+	}
+
+	logCxt.Debug("Calling Get on etcdv3 client")
+	resp, err := c.etcdClient.Get(ctx, key, ops...)
+	if err != nil {
+		logCxt.WithError(err).Info("Error returned from etcdv3 client")
+		return 0, cerrors.ErrorDatastoreError{Err: err}
+	}
+
+	// The keys under the prefix may include entries that do not match the list options,
+	// so filter on the keys as for a List.
+	count := 0
+	for _, p := range resp.Kvs {
+		if l.KeyFromDefaultPath(string(p.Key)) != nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// listKey returns the etcd key used to enumerate the entries matching the list options, and
+// whether the key is a prefix.
+func listKey(l model.ListInterface) (string, bool) {
+	// To list entries, we enumerate from the common root based on the supplied
+	// IDs, and then filter the results.
+	key := model.ListOptionsToDefaultPathRoot(l)
+
+	// -  If the final name segment of the name is itself a prefix, then just perform a prefix Get
+	//    using the constructed key.
+	// -  If the etcdKey is actually fully qualified, then perform an exact Get using the constructed
+	//    key.
+	// -  If the etcdKey is not fully qualified then it is a path prefix but the last segment is complete.
+	//    Append a terminating "/" and perform a prefix Get.  The terminating / for a prefix Get ensures
+	//    for a prefix of "/a" we only return "child entries" of "/a" such as "/a/x" and not siblings
+	//    such as "/ab".
+	if model.IsListOptionsLastSegmentPrefix(l) {
+		// The last segment is a prefix, perform a prefix Get without adding a segment
+		// delimiter.
+		return key, true
+	} else if l.KeyFromDefaultPath(key) == nil {
+		// The etcdKey not a fully qualified etcdKey - it must be a prefix.
+		if !strings.HasSuffix(key, "/") {
+			key += "/"
+		}
+		return key, true
+	}
+	return key, false
+}
+
 // EnsureInitialized makes sure that the etcd data is initialized for use by
 // Calico.
 func (c *etcdV3Client) EnsureInitialized() error {
@@ -407,6 +551,12 @@ func (c *etcdV3Client) EnsureInitialized() error {
 	return nil
 }
 
+// Close closes the etcd client, releasing its connections.  Any active watches
+// are terminated.
+func (c *etcdV3Client) Close() error {
+	return c.etcdClient.Close()
+}
+
 // Clean removes all of the Calico data from the datastore.
 func (c *etcdV3Client) Clean() error {
 	log.Warning("Cleaning etcdv3 datastore of all Calico data")
@@ -476,6 +626,45 @@ func getKeyValueStrings(d *model.KVPair) (string, string, error) {
 	return key, string(bytes), nil
 }
 
+// continueToken is the decoded form of the continue token returned from a paginated
+// List.  It contains the revision of the first page and the key to resume from.
+type continueToken struct {
+	Revision int64  `json:"rev"`
+	Start    string `json:"start"`
+}
+
+// encodeContinue encodes the continueToken as an opaque string.
+func encodeContinue(t continueToken) string {
+	b, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeContinue decodes an opaque continue token string.
+func decodeContinue(s string) (continueToken, error) {
+	t := continueToken{}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		log.WithField("continue", s).Info("Unable to decode continue token")
+		return t, continueValidationError(s)
+	}
+	if err = json.Unmarshal(b, &t); err != nil || t.Revision <= 0 || len(t.Start) == 0 {
+		log.WithField("continue", s).Info("Unable to parse continue token")
+		return t, continueValidationError(s)
+	}
+	return t, nil
+}
+
+// continueValidationError returns a validation error for an invalid continue token.
+func continueValidationError(s string) error {
+	return cerrors.ErrorValidation{
+		ErroredFields: []cerrors.ErroredField{{
+			Name:   "Continue",
+			Value:  s,
+			Reason: "continue token is not valid for this request",
+		}},
+	}
+}
+
 // parseRevision parses the model.KVPair revision string and converts to the
 // equivalent etcdv3 int64 value.
 func parseRevision(revs string) (int64, error) {
@@ -493,3 +682,13 @@ func parseRevision(revs string) (int64, error) {
 	}
 	return rev, nil
 }
+
+// isAuthError returns true if the error returned by the etcdv3 client indicates that the
+// client is not authenticated or not authorized to perform the request.
+func isAuthError(err error) bool {
+	switch err {
+	case rpctypes.ErrPermissionDenied, rpctypes.ErrAuthFailed, rpctypes.ErrInvalidAuthToken, rpctypes.ErrUserEmpty:
+		return true
+	}
+	return false
+}
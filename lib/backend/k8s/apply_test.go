@@ -0,0 +1,155 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/k8s/resources"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+// storeResourceClient implements the Create, Update and Get methods of a resource client using
+// an in-memory store.  The hooks are called before each write so that tests can simulate
+// concurrent writes by another client.  All other methods panic.
+type storeResourceClient struct {
+	resources.K8sResourceClient
+	kvps         map[string]*model.KVPair
+	revision     int
+	beforeCreate func()
+	beforeUpdate func()
+}
+
+func (c *storeResourceClient) write(kvp *model.KVPair) *model.KVPair {
+	c.revision++
+	stored := &model.KVPair{Key: kvp.Key, Value: kvp.Value, Revision: strconv.Itoa(c.revision)}
+	c.kvps[kvp.Key.String()] = stored
+	return stored
+}
+
+func (c *storeResourceClient) Create(ctx context.Context, kvp *model.KVPair) (*model.KVPair, error) {
+	if c.beforeCreate != nil {
+		c.beforeCreate()
+	}
+	if _, ok := c.kvps[kvp.Key.String()]; ok {
+		return nil, cerrors.ErrorResourceAlreadyExists{Identifier: kvp.Key}
+	}
+	return c.write(kvp), nil
+}
+
+func (c *storeResourceClient) Update(ctx context.Context, kvp *model.KVPair) (*model.KVPair, error) {
+	if c.beforeUpdate != nil {
+		c.beforeUpdate()
+	}
+	current, ok := c.kvps[kvp.Key.String()]
+	if !ok {
+		return nil, cerrors.ErrorResourceDoesNotExist{Identifier: kvp.Key}
+	}
+	if kvp.Revision != current.Revision {
+		return nil, cerrors.ErrorResourceUpdateConflict{Identifier: kvp.Key}
+	}
+	return c.write(kvp), nil
+}
+
+func (c *storeResourceClient) Get(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	kvp, ok := c.kvps[key.String()]
+	if !ok {
+		return nil, cerrors.ErrorResourceDoesNotExist{Identifier: key}
+	}
+	return kvp, nil
+}
+
+var _ = Describe("Kubernetes backend Apply", func() {
+	ctx := context.Background()
+	key := model.ResourceKey{Kind: apiv3.KindGlobalNetworkSet, Name: "networkset-1"}
+	var rc *storeResourceClient
+	var c *KubeClient
+
+	networkSet := func(net string) *apiv3.GlobalNetworkSet {
+		gns := apiv3.NewGlobalNetworkSet()
+		gns.Name = "networkset-1"
+		gns.Spec.Nets = []string{net}
+		return gns
+	}
+
+	BeforeEach(func() {
+		rc = &storeResourceClient{kvps: map[string]*model.KVPair{}}
+		c = &KubeClient{
+			clientsByResourceKind: map[string]resources.K8sResourceClient{
+				apiv3.KindGlobalNetworkSet: rc,
+			},
+		}
+	})
+
+	It("should create a resource that does not exist", func() {
+		out, err := c.Apply(ctx, &model.KVPair{Key: key, Value: networkSet("10.0.0.0/24")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Revision).To(Equal("1"))
+	})
+
+	It("should update the resource if it is created concurrently", func() {
+		rc.beforeCreate = func() {
+			rc.beforeCreate = nil
+			rc.write(&model.KVPair{Key: key, Value: networkSet("10.0.1.0/24")})
+		}
+		out, err := c.Apply(ctx, &model.KVPair{Key: key, Value: networkSet("10.0.0.0/24")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Revision).To(Equal("2"))
+		Expect(rc.kvps[key.String()].Value.(*apiv3.GlobalNetworkSet).Spec.Nets).To(Equal([]string{"10.0.0.0/24"}))
+	})
+
+	It("should update the resource at the current revision, ignoring the supplied revision", func() {
+		rc.write(&model.KVPair{Key: key, Value: networkSet("10.0.1.0/24")})
+		out, err := c.Apply(ctx, &model.KVPair{Key: key, Value: networkSet("10.0.0.0/24"), Revision: "100"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Revision).To(Equal("2"))
+	})
+
+	It("should retry if the resource is modified or deleted before the update", func() {
+		rc.write(&model.KVPair{Key: key, Value: networkSet("10.0.1.0/24")})
+		updates := 0
+		rc.beforeUpdate = func() {
+			updates++
+			switch updates {
+			case 1:
+				rc.write(&model.KVPair{Key: key, Value: networkSet("10.0.2.0/24")})
+			case 2:
+				delete(rc.kvps, key.String())
+			}
+		}
+		out, err := c.Apply(ctx, &model.KVPair{Key: key, Value: networkSet("10.0.0.0/24")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updates).To(Equal(2))
+		Expect(out.Value.(*apiv3.GlobalNetworkSet).Spec.Nets).To(Equal([]string{"10.0.0.0/24"}))
+	})
+
+	It("should return an update conflict once the retries are exhausted", func() {
+		rc.write(&model.KVPair{Key: key, Value: networkSet("10.0.1.0/24")})
+		updates := 0
+		rc.beforeUpdate = func() {
+			updates++
+			rc.write(&model.KVPair{Key: key, Value: networkSet("10.0.2.0/24")})
+		}
+		_, err := c.Apply(ctx, &model.KVPair{Key: key, Value: networkSet("10.0.0.0/24")})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceUpdateConflict{}))
+		Expect(updates).To(Equal(maxApplyRetries))
+	})
+})
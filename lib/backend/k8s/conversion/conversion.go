@@ -404,34 +404,82 @@ func (c Converter) k8sSelectorToCalico(s *metav1.LabelSelector, selectorType sel
 
 	// matchLabels is a map key => value, it means match if (label[key] ==
 	// value) for all keys.
-	keys := make([]string, 0, len(s.MatchLabels))
-	for k := range s.MatchLabels {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		v := s.MatchLabels[k]
-		selectors = append(selectors, fmt.Sprintf("%s == '%s'", k, v))
-	}
+	selectors = append(selectors, matchLabelsToCalico(s.MatchLabels)...)
 
 	// matchExpressions is a list of in/notin/exists/doesnotexist tests.
 	for _, e := range s.MatchExpressions {
-		valueList := strings.Join(e.Values, "', '")
+		selector, err := matchExpressionToCalico(e)
+		if err != nil {
+			log.WithError(err).Warning("Skipping unsupported label selector requirement")
+			continue
+		}
+		selectors = append(selectors, selector)
+	}
+
+	return strings.Join(selectors, " && ")
+}
 
-		// Each selector is formatted differently based on the operator.
+// LabelSelectorToCalico converts a k8s label selector to the equivalent Calico selector, as
+// used in the Selector fields of policy.  A nil or empty label selector matches all resources
+// and is converted to "all()".  An error is returned if a match expression uses an unsupported
+// operator, or has values that are not valid for its operator.
+func LabelSelectorToCalico(s *metav1.LabelSelector) (string, error) {
+	if s == nil || (len(s.MatchLabels) == 0 && len(s.MatchExpressions) == 0) {
+		return "all()", nil
+	}
+
+	selectors := matchLabelsToCalico(s.MatchLabels)
+	for i, e := range s.MatchExpressions {
 		switch e.Operator {
-		case metav1.LabelSelectorOpIn:
-			selectors = append(selectors, fmt.Sprintf("%s in { '%s' }", e.Key, valueList))
-		case metav1.LabelSelectorOpNotIn:
-			selectors = append(selectors, fmt.Sprintf("%s not in { '%s' }", e.Key, valueList))
-		case metav1.LabelSelectorOpExists:
-			selectors = append(selectors, fmt.Sprintf("has(%s)", e.Key))
-		case metav1.LabelSelectorOpDoesNotExist:
-			selectors = append(selectors, fmt.Sprintf("! has(%s)", e.Key))
+		case metav1.LabelSelectorOpIn, metav1.LabelSelectorOpNotIn:
+			if len(e.Values) == 0 {
+				return "", fmt.Errorf("match expression %d: operator %s requires at least one value", i, e.Operator)
+			}
+		case metav1.LabelSelectorOpExists, metav1.LabelSelectorOpDoesNotExist:
+			if len(e.Values) != 0 {
+				return "", fmt.Errorf("match expression %d: operator %s does not take values", i, e.Operator)
+			}
+		}
+		selector, err := matchExpressionToCalico(e)
+		if err != nil {
+			return "", fmt.Errorf("match expression %d: %v", i, err)
 		}
+		selectors = append(selectors, selector)
 	}
 
-	return strings.Join(selectors, " && ")
+	return strings.Join(selectors, " && "), nil
+}
+
+// matchLabelsToCalico returns a Calico selector for each of the match labels, sorted by label
+// key.
+func matchLabelsToCalico(labels map[string]string) []string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	selectors := make([]string, 0, len(keys))
+	for _, k := range keys {
+		selectors = append(selectors, fmt.Sprintf("%s == '%s'", k, labels[k]))
+	}
+	return selectors
+}
+
+// matchExpressionToCalico returns the Calico selector for a match expression.  Each selector
+// is formatted differently based on the operator.
+func matchExpressionToCalico(e metav1.LabelSelectorRequirement) (string, error) {
+	valueList := strings.Join(e.Values, "', '")
+	switch e.Operator {
+	case metav1.LabelSelectorOpIn:
+		return fmt.Sprintf("%s in { '%s' }", e.Key, valueList), nil
+	case metav1.LabelSelectorOpNotIn:
+		return fmt.Sprintf("%s not in { '%s' }", e.Key, valueList), nil
+	case metav1.LabelSelectorOpExists:
+		return fmt.Sprintf("has(%s)", e.Key), nil
+	case metav1.LabelSelectorOpDoesNotExist:
+		return fmt.Sprintf("! has(%s)", e.Key), nil
+	}
+	return "", fmt.Errorf("unsupported operator '%s', must be one of In, NotIn, Exists or DoesNotExist", e.Operator)
 }
 
 func (c Converter) k8sRuleToCalico(rPeers []networkingv1.NetworkPolicyPeer, rPorts []networkingv1.NetworkPolicyPort, ns string, ingress bool) ([]apiv3.Rule, error) {
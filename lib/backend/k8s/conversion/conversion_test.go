@@ -24,6 +24,7 @@ import (
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	"github.com/projectcalico/libcalico-go/lib/numorstring"
+	"github.com/projectcalico/libcalico-go/lib/selector"
 
 	kapiv1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -157,6 +158,93 @@ var _ = Describe("Test selector conversion", func() {
 	)
 })
 
+var _ = Describe("Test label selector conversion", func() {
+	DescribeTable("label selector conversion table",
+		func(inSelector *metav1.LabelSelector, expected string) {
+			converted, err := LabelSelectorToCalico(inSelector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(converted).To(Equal(expected))
+
+			// The converted selector should be a valid Calico selector.
+			_, err = selector.Parse(converted)
+			Expect(err).NotTo(HaveOccurred())
+		},
+
+		Entry("should match all for a nil selector", nil, "all()"),
+		Entry("should match all for an empty selector", &metav1.LabelSelector{}, "all()"),
+		Entry("should handle matchLabels",
+			&metav1.LabelSelector{
+				MatchLabels: map[string]string{"role": "db", "app": "shop"},
+			},
+			"app == 'shop' && role == 'db'",
+		),
+		Entry("should handle an OpIn expression",
+			&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "toast", Operator: metav1.LabelSelectorOpIn, Values: []string{"butter", "jam"}},
+				},
+			},
+			"toast in { 'butter', 'jam' }",
+		),
+		Entry("should handle an OpNotIn expression",
+			&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "toast", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"marmite"}},
+				},
+			},
+			"toast not in { 'marmite' }",
+		),
+		Entry("should handle an OpExists expression",
+			&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "toast", Operator: metav1.LabelSelectorOpExists},
+				},
+			},
+			"has(toast)",
+		),
+		Entry("should handle an OpDoesNotExist expression",
+			&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "toast", Operator: metav1.LabelSelectorOpDoesNotExist},
+				},
+			},
+			"! has(toast)",
+		),
+		Entry("should combine matchLabels and expressions",
+			&metav1.LabelSelector{
+				MatchLabels: map[string]string{"role": "db"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "toast", Operator: metav1.LabelSelectorOpExists},
+				},
+			},
+			"role == 'db' && has(toast)",
+		),
+	)
+
+	DescribeTable("invalid label selector table",
+		func(e metav1.LabelSelectorRequirement, expectedErr string) {
+			_, err := LabelSelectorToCalico(&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{e},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(expectedErr))
+		},
+
+		Entry("should reject an unsupported operator",
+			metav1.LabelSelectorRequirement{Key: "toast", Operator: "Gt", Values: []string{"1"}},
+			"match expression 0: unsupported operator 'Gt', must be one of In, NotIn, Exists or DoesNotExist",
+		),
+		Entry("should reject an OpIn expression without values",
+			metav1.LabelSelectorRequirement{Key: "toast", Operator: metav1.LabelSelectorOpIn},
+			"match expression 0: operator In requires at least one value",
+		),
+		Entry("should reject an OpExists expression with values",
+			metav1.LabelSelectorRequirement{Key: "toast", Operator: metav1.LabelSelectorOpExists, Values: []string{"jam"}},
+			"match expression 0: operator Exists does not take values",
+		),
+	)
+})
+
 var _ = Describe("Test Pod conversion", func() {
 
 	// Use a single instance of the Converter for these tests.
@@ -0,0 +1,183 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/k8s/resources"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+const crdResource = "customresourcedefinitions"
+
+// calicoCRDs are the CustomResourceDefinitions required by the Calico resource clients.
+var calicoCRDs = []struct {
+	name       string
+	kind       string
+	namespaced bool
+}{
+	{resources.BGPConfigCRDName, apiv3.KindBGPConfiguration, false},
+	{resources.BGPPeerCRDName, apiv3.KindBGPPeer, false},
+	{resources.ClusterInfoCRDName, apiv3.KindClusterInformation, false},
+	{resources.FelixConfigCRDName, apiv3.KindFelixConfiguration, false},
+	{resources.GlobalNetworkPolicyCRDName, apiv3.KindGlobalNetworkPolicy, false},
+	{resources.GlobalNetworkSetCRDName, apiv3.KindGlobalNetworkSet, false},
+	{resources.HostEndpointCRDName, apiv3.KindHostEndpoint, false},
+	{resources.IPPoolCRDName, apiv3.KindIPPool, false},
+	{resources.NetworkPolicyCRDName, apiv3.KindNetworkPolicy, true},
+}
+
+// crd is the subset of a CustomResourceDefinition that is managed by the client.  The fields
+// of an existing CRD that are not included are ignored when checking whether it is up to date,
+// and are left unchanged when it is updated.
+type crd struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   crdMetadata `json:"metadata"`
+	Spec       crdSpec     `json:"spec"`
+}
+
+type crdMetadata struct {
+	Name string `json:"name"`
+}
+
+type crdSpec struct {
+	Group   string   `json:"group"`
+	Version string   `json:"version"`
+	Scope   string   `json:"scope"`
+	Names   crdNames `json:"names"`
+}
+
+type crdNames struct {
+	Kind     string `json:"kind"`
+	Plural   string `json:"plural"`
+	Singular string `json:"singular"`
+}
+
+// newCRD returns the CustomResourceDefinition for the named CRD, for example
+// "ippools.crd.projectcalico.org", of the given kind.
+func newCRD(name, kind string, namespaced bool) crd {
+	parts := strings.SplitN(name, ".", 2)
+	scope := "Cluster"
+	if namespaced {
+		scope = "Namespaced"
+	}
+	return crd{
+		APIVersion: "apiextensions.k8s.io/v1beta1",
+		Kind:       "CustomResourceDefinition",
+		Metadata:   crdMetadata{Name: name},
+		Spec: crdSpec{
+			Group:   parts[1],
+			Version: "v1",
+			Scope:   scope,
+			Names: crdNames{
+				Kind:     kind,
+				Plural:   parts[0],
+				Singular: strings.ToLower(kind),
+			},
+		},
+	}
+}
+
+// buildCRDManagementClient returns a REST client for managing CustomResourceDefinitions.
+func buildCRDManagementClient(cfg rest.Config) (*rest.RESTClient, error) {
+	cfg.GroupVersion = &schema.GroupVersion{
+		Group:   "apiextensions.k8s.io",
+		Version: "v1beta1",
+	}
+	cfg.APIPath = "/apis"
+	cfg.ContentType = runtime.ContentTypeJSON
+	cfg.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
+	return rest.RESTClientFor(&cfg)
+}
+
+// ensureCRDs creates each of the Calico CustomResourceDefinitions that does not exist, and
+// updates each one whose definition differs from that required.  CRDs that are already up to
+// date are not written, so this may be called repeatedly.  If the client is not permitted to
+// manage a CRD it is assumed to be managed externally, and is skipped.
+func ensureCRDs(c rest.Interface) error {
+	for _, def := range calicoCRDs {
+		if err := ensureCRD(c, newCRD(def.name, def.kind, def.namespaced)); err != nil {
+			return resources.K8sErrorToCalico(err, def.name)
+		}
+	}
+	return nil
+}
+
+// ensureCRD creates or updates a single CustomResourceDefinition.
+func ensureCRD(c rest.Interface, required crd) error {
+	logCxt := log.WithField("CRD", required.Metadata.Name)
+	err := createOrUpdateCRD(c, required, logCxt)
+	if kerrors.IsForbidden(err) {
+		logCxt.WithError(err).Warning("Not permitted to manage CustomResourceDefinition, skipping")
+		return nil
+	}
+	return err
+}
+
+// createOrUpdateCRD creates the CustomResourceDefinition if it does not exist, or patches the
+// managed fields of the existing CustomResourceDefinition if they differ from those required.
+func createOrUpdateCRD(c rest.Interface, required crd, logCxt *log.Entry) error {
+	body, err := c.Get().Resource(crdResource).Name(required.Metadata.Name).DoRaw()
+	if kerrors.IsNotFound(err) {
+		logCxt.Info("Creating CustomResourceDefinition")
+		err = writeCRD(c.Post().Resource(crdResource), required)
+		if kerrors.IsAlreadyExists(err) {
+			logCxt.Info("CustomResourceDefinition was created by another client")
+			return nil
+		}
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	var existing crd
+	if err := json.Unmarshal(body, &existing); err != nil {
+		return fmt.Errorf("unable to parse CustomResourceDefinition %s: %v", required.Metadata.Name, err)
+	}
+	if existing.Spec == required.Spec {
+		logCxt.Debug("CustomResourceDefinition is up to date")
+		return nil
+	}
+
+	// Use a merge patch of the managed spec fields so that any other fields of the existing
+	// CRD are preserved.
+	logCxt.WithField("existing", existing.Spec).Info("Updating CustomResourceDefinition")
+	patch := map[string]interface{}{"spec": required.Spec}
+	return writeCRD(c.Patch(types.MergePatchType).Resource(crdResource).Name(required.Metadata.Name), patch)
+}
+
+// writeCRD sends the CustomResourceDefinition, or patch, as the body of the request.
+func writeCRD(req *rest.Request, def interface{}) error {
+	body, err := json.Marshal(def)
+	if err != nil {
+		return err
+	}
+	_, err = req.Body(body).DoRaw()
+	return err
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/rest"
+)
+
+// fakeCRDServer is a minimal API server that stores CustomResourceDefinitions, counting the
+// requests that write them.  If forbidden is set, all requests are rejected as Forbidden.
+type fakeCRDServer struct {
+	lock      sync.Mutex
+	crds      map[string]map[string]interface{}
+	revision  int
+	writes    []string
+	requests  int
+	forbidden bool
+}
+
+func (s *fakeCRDServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	const prefix = "/apis/apiextensions.k8s.io/v1beta1/customresourcedefinitions"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		s.writeStatus(w, http.StatusNotFound, "NotFound")
+		return
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	s.requests++
+	if s.forbidden {
+		s.writeStatus(w, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		obj, ok := s.crds[name]
+		if !ok {
+			s.writeStatus(w, http.StatusNotFound, "NotFound")
+			return
+		}
+		s.writeObject(w, http.StatusOK, obj)
+	case "POST", "PUT":
+		body, _ := ioutil.ReadAll(r.Body)
+		obj := map[string]interface{}{}
+		Expect(json.Unmarshal(body, &obj)).NotTo(HaveOccurred())
+		metadata := obj["metadata"].(map[string]interface{})
+		if r.Method == "POST" {
+			name = metadata["name"].(string)
+			if _, ok := s.crds[name]; ok {
+				s.writeStatus(w, http.StatusConflict, "AlreadyExists")
+				return
+			}
+		}
+		s.revision++
+		metadata["resourceVersion"] = strconv.Itoa(s.revision)
+		// The API server adds defaulted fields to the stored CRD.
+		obj["spec"].(map[string]interface{})["names"].(map[string]interface{})["listKind"] = "List"
+		s.crds[name] = obj
+		s.writes = append(s.writes, r.Method+" "+name)
+		s.writeObject(w, http.StatusCreated, obj)
+	case "PATCH":
+		obj, ok := s.crds[name]
+		if !ok {
+			s.writeStatus(w, http.StatusNotFound, "NotFound")
+			return
+		}
+		Expect(r.Header.Get("Content-Type")).To(Equal("application/merge-patch+json"))
+		body, _ := ioutil.ReadAll(r.Body)
+		patch := map[string]interface{}{}
+		Expect(json.Unmarshal(body, &patch)).NotTo(HaveOccurred())
+		mergePatch(obj, patch)
+		s.revision++
+		obj["metadata"].(map[string]interface{})["resourceVersion"] = strconv.Itoa(s.revision)
+		s.writes = append(s.writes, r.Method+" "+name)
+		s.writeObject(w, http.StatusOK, obj)
+	default:
+		s.writeStatus(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// mergePatch applies a JSON merge patch to the object.
+func mergePatch(obj, patch map[string]interface{}) {
+	for k, v := range patch {
+		if p, ok := v.(map[string]interface{}); ok {
+			if o, ok := obj[k].(map[string]interface{}); ok {
+				mergePatch(o, p)
+				continue
+			}
+		}
+		if v == nil {
+			delete(obj, k)
+		} else {
+			obj[k] = v
+		}
+	}
+}
+
+func (s *fakeCRDServer) writeObject(w http.ResponseWriter, code int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	Expect(json.NewEncoder(w).Encode(obj)).NotTo(HaveOccurred())
+}
+
+func (s *fakeCRDServer) writeStatus(w http.ResponseWriter, code int, reason string) {
+	s.writeObject(w, code, map[string]interface{}{
+		"kind":       "Status",
+		"apiVersion": "v1",
+		"status":     "Failure",
+		"reason":     reason,
+		"code":       code,
+	})
+}
+
+var _ = Describe("Test CustomResourceDefinition initialization", func() {
+	var fake *fakeCRDServer
+	var server *httptest.Server
+	var c *rest.RESTClient
+
+	BeforeEach(func() {
+		fake = &fakeCRDServer{crds: map[string]map[string]interface{}{}}
+		server = httptest.NewServer(fake)
+		var err error
+		c, err = buildCRDManagementClient(rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should create the CRDs once and be a no-op the second time", func() {
+		Expect(ensureCRDs(c)).NotTo(HaveOccurred())
+		Expect(fake.writes).To(HaveLen(len(calicoCRDs)))
+		Expect(fake.writes).To(ContainElement("POST ippools.crd.projectcalico.org"))
+
+		ippools := fake.crds["ippools.crd.projectcalico.org"]["spec"].(map[string]interface{})
+		Expect(ippools["group"]).To(Equal("crd.projectcalico.org"))
+		Expect(ippools["scope"]).To(Equal("Cluster"))
+		Expect(ippools["names"]).To(HaveKeyWithValue("kind", "IPPool"))
+		Expect(ippools["names"]).To(HaveKeyWithValue("plural", "ippools"))
+		policies := fake.crds["networkpolicies.crd.projectcalico.org"]["spec"].(map[string]interface{})
+		Expect(policies["scope"]).To(Equal("Namespaced"))
+
+		fake.writes = nil
+		Expect(ensureCRDs(c)).NotTo(HaveOccurred())
+		Expect(fake.writes).To(BeEmpty())
+	})
+
+	It("should patch only the managed fields of a CRD whose definition differs", func() {
+		Expect(ensureCRDs(c)).NotTo(HaveOccurred())
+		ippools := fake.crds["ippools.crd.projectcalico.org"]
+		ippools["spec"].(map[string]interface{})["scope"] = "Namespaced"
+		ippools["spec"].(map[string]interface{})["validation"] = "unmanaged"
+		ippools["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{"owner": "admin"}
+
+		fake.writes = nil
+		Expect(ensureCRDs(c)).NotTo(HaveOccurred())
+		Expect(fake.writes).To(Equal([]string{"PATCH ippools.crd.projectcalico.org"}))
+		ippools = fake.crds["ippools.crd.projectcalico.org"]
+		Expect(ippools["spec"]).To(HaveKeyWithValue("scope", "Cluster"))
+		Expect(ippools["spec"]).To(HaveKeyWithValue("validation", "unmanaged"))
+		Expect(ippools["spec"].(map[string]interface{})["names"]).To(HaveKeyWithValue("listKind", "List"))
+		Expect(ippools["metadata"]).To(HaveKeyWithValue("labels", HaveKeyWithValue("owner", "admin")))
+		Expect(ippools["metadata"]).To(HaveKeyWithValue("resourceVersion", strconv.Itoa(fake.revision)))
+	})
+
+	It("should skip the CRDs when not permitted to manage them", func() {
+		fake.forbidden = true
+		Expect(ensureCRDs(c)).NotTo(HaveOccurred())
+		Expect(fake.requests).To(Equal(len(calicoCRDs)))
+		Expect(fake.writes).To(BeEmpty())
+	})
+
+	It("should only manage the CRDs when enabled in the client", func() {
+		kc := &KubeClient{crdManagementClient: c}
+		Expect(kc.EnsureInitialized()).NotTo(HaveOccurred())
+		Expect(fake.requests).To(BeZero())
+
+		kc.manageCRDs = true
+		Expect(kc.EnsureInitialized()).NotTo(HaveOccurred())
+		Expect(fake.writes).To(HaveLen(len(calicoCRDs)))
+	})
+})
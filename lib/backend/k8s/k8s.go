@@ -48,6 +48,10 @@ var (
 	resourceListType = reflect.TypeOf(model.ResourceListOptions{})
 )
 
+// The maximum number of attempts an Apply makes to write a resource that is being created,
+// modified or deleted concurrently.
+const maxApplyRetries = 10
+
 type KubeClient struct {
 	// Main Kubernetes clients.
 	ClientSet *kubernetes.Clientset
@@ -55,6 +59,12 @@ type KubeClient struct {
 	// Client for interacting with CustomResourceDefinition.
 	crdClientV1 *rest.RESTClient
 
+	// Client for creating and updating the CustomResourceDefinitions themselves.
+	crdManagementClient rest.Interface
+
+	// Whether EnsureInitialized creates and updates the CustomResourceDefinitions.
+	manageCRDs bool
+
 	disableNodePoll bool
 
 	// Contains methods for converting Kubernetes resources to
@@ -124,64 +134,50 @@ func NewKubeClient(ca *apiconfig.CalicoAPIConfigSpec) (api.Client, error) {
 		return nil, fmt.Errorf("Failed to build V1 CRD client: %v", err)
 	}
 
+	crdManagementClient, err := buildCRDManagementClient(*config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to build CRD management client: %v", err)
+	}
+
 	kubeClient := &KubeClient{
 		ClientSet:             cs,
 		crdClientV1:           crdClientV1,
+		crdManagementClient:   crdManagementClient,
+		manageCRDs:            ca.K8sManageCRDs,
 		disableNodePoll:       ca.K8sDisableNodePoll,
 		clientsByResourceKind: make(map[string]resources.K8sResourceClient),
 		clientsByKeyType:      make(map[reflect.Type]resources.K8sResourceClient),
 		clientsByListType:     make(map[reflect.Type]resources.K8sResourceClient),
 	}
 
-	// Create the Calico sub-clients and register them.
-	kubeClient.registerResourceClient(
-		reflect.TypeOf(model.ResourceKey{}),
-		reflect.TypeOf(model.ResourceListOptions{}),
-		apiv3.KindIPPool,
-		resources.NewIPPoolClient(cs, crdClientV1),
-	)
-	kubeClient.registerResourceClient(
-		reflect.TypeOf(model.ResourceKey{}),
-		reflect.TypeOf(model.ResourceListOptions{}),
-		apiv3.KindGlobalNetworkPolicy,
-		resources.NewGlobalNetworkPolicyClient(cs, crdClientV1),
-	)
-	kubeClient.registerResourceClient(
-		reflect.TypeOf(model.ResourceKey{}),
-		reflect.TypeOf(model.ResourceListOptions{}),
-		apiv3.KindGlobalNetworkSet,
-		resources.NewGlobalNetworkSetClient(cs, crdClientV1),
-	)
-	kubeClient.registerResourceClient(
-		reflect.TypeOf(model.ResourceKey{}),
-		reflect.TypeOf(model.ResourceListOptions{}),
-		apiv3.KindNetworkPolicy,
-		resources.NewNetworkPolicyClient(cs, crdClientV1),
-	)
-	kubeClient.registerResourceClient(
-		reflect.TypeOf(model.ResourceKey{}),
-		reflect.TypeOf(model.ResourceListOptions{}),
-		apiv3.KindBGPPeer,
-		resources.NewBGPPeerClient(cs, crdClientV1),
-	)
-	kubeClient.registerResourceClient(
-		reflect.TypeOf(model.ResourceKey{}),
-		reflect.TypeOf(model.ResourceListOptions{}),
-		apiv3.KindBGPConfiguration,
-		resources.NewBGPConfigClient(cs, crdClientV1),
-	)
-	kubeClient.registerResourceClient(
-		reflect.TypeOf(model.ResourceKey{}),
-		reflect.TypeOf(model.ResourceListOptions{}),
-		apiv3.KindFelixConfiguration,
-		resources.NewFelixConfigClient(cs, crdClientV1),
-	)
-	kubeClient.registerResourceClient(
-		reflect.TypeOf(model.ResourceKey{}),
-		reflect.TypeOf(model.ResourceListOptions{}),
-		apiv3.KindClusterInformation,
-		resources.NewClusterInfoClient(cs, crdClientV1),
-	)
+	// Create the Calico custom resource sub-clients and register them.
+	for _, crd := range []struct {
+		kind      string
+		newClient func(*kubernetes.Clientset, *rest.RESTClient, ...resources.CustomResourceOption) (resources.K8sResourceClient, error)
+	}{
+		{apiv3.KindIPPool, resources.NewIPPoolClient},
+		{apiv3.KindGlobalNetworkPolicy, resources.NewGlobalNetworkPolicyClient},
+		{apiv3.KindGlobalNetworkSet, resources.NewGlobalNetworkSetClient},
+		{apiv3.KindNetworkPolicy, resources.NewNetworkPolicyClient},
+		{apiv3.KindBGPPeer, resources.NewBGPPeerClient},
+		{apiv3.KindBGPConfiguration, resources.NewBGPConfigClient},
+		{apiv3.KindFelixConfiguration, resources.NewFelixConfigClient},
+		{apiv3.KindClusterInformation, resources.NewClusterInfoClient},
+		{apiv3.KindHostEndpoint, resources.NewHostEndpointClient},
+	} {
+		client, err := crd.newClient(cs, crdClientV1)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to build %s client: %v", crd.kind, err)
+		}
+		kubeClient.registerResourceClient(
+			reflect.TypeOf(model.ResourceKey{}),
+			reflect.TypeOf(model.ResourceListOptions{}),
+			crd.kind,
+			client,
+		)
+	}
+
+	// Create the remaining Calico sub-clients and register them.
 	kubeClient.registerResourceClient(
 		reflect.TypeOf(model.ResourceKey{}),
 		reflect.TypeOf(model.ResourceListOptions{}),
@@ -194,12 +190,6 @@ func NewKubeClient(ca *apiconfig.CalicoAPIConfigSpec) (api.Client, error) {
 		apiv3.KindProfile,
 		resources.NewProfileClient(cs),
 	)
-	kubeClient.registerResourceClient(
-		reflect.TypeOf(model.ResourceKey{}),
-		reflect.TypeOf(model.ResourceListOptions{}),
-		apiv3.KindHostEndpoint,
-		resources.NewHostEndpointClient(cs, crdClientV1),
-	)
 	kubeClient.registerResourceClient(
 		reflect.TypeOf(model.ResourceKey{}),
 		reflect.TypeOf(model.ResourceListOptions{}),
@@ -253,15 +243,26 @@ func (c *KubeClient) getResourceClientFromList(list model.ListInterface) resourc
 	}
 }
 
-// EnsureInitialized checks that the necessary custom resource definitions
-// exist in the backend. This usually passes when using etcd
-// as a backend but can often fail when using KDD as it relies
-// on various custom resources existing.
-// To ensure the datastore is initialized, this function checks that a
-// known custom resource is defined: GlobalFelixConfig. It accomplishes this
-// by trying to set the ClusterType (an instance of GlobalFelixConfig).
+// EnsureInitialized ensures that the custom resource definitions required by the
+// Calico resources exist in the backend, creating or updating them as necessary.
+// This is only performed if CRD management is enabled in the client configuration,
+// otherwise the CRDs are assumed to be managed externally and this is a no-op.
+// CRDs that are already up to date are not modified, so this may be called
+// repeatedly.  Managing the CRDs requires permission to get, create and patch
+// CustomResourceDefinitions; CRDs the client is not permitted to manage are skipped.
 func (c *KubeClient) EnsureInitialized() error {
-	log.Info("EnsuringInitialized - noop")
+	if !c.manageCRDs {
+		log.Debug("CustomResourceDefinition management is not enabled")
+		return nil
+	}
+	log.Info("Ensuring Calico CustomResourceDefinitions exist")
+	return ensureCRDs(c.crdManagementClient)
+}
+
+// Close releases the connections held by the client.  The Kubernetes clients
+// do not hold open connections outside of requests and watches, so this is a
+// no-op.
+func (c *KubeClient) Close() error {
 	return nil
 }
 
@@ -388,6 +389,10 @@ func (c *KubeClient) Update(ctx context.Context, d *model.KVPair) (*model.KVPair
 // Set an existing entry in the datastore.  This ignores whether an entry already
 // exists.  This is not exposed in the main client - but we keep here for the backend
 // API.
+//
+// If the resource is created, modified or deleted concurrently the Apply is retried, so that
+// concurrent Applies of the same resource converge.  An ErrorResourceUpdateConflict is only
+// returned once the retries are exhausted.
 func (c *KubeClient) Apply(ctx context.Context, kvp *model.KVPair) (*model.KVPair, error) {
 	logContext := log.WithFields(log.Fields{
 		"Key":   kvp.Key,
@@ -395,27 +400,44 @@ func (c *KubeClient) Apply(ctx context.Context, kvp *model.KVPair) (*model.KVPai
 	})
 	logContext.Debug("Apply Kubernetes resource")
 
-	// Attempt to Create and do an Update if the resource already exists.
-	// We only log debug here since the Create and Update will also log.
-	// Can't set Revision while creating a resource.
-	updated, err := c.Create(ctx, &model.KVPair{
-		Key:   kvp.Key,
-		Value: kvp.Value,
-	})
-	if err != nil {
-		if _, ok := err.(cerrors.ErrorResourceAlreadyExists); !ok {
+	var err error
+	for i := 0; i < maxApplyRetries; i++ {
+		// Attempt to Create and do an Update if the resource already exists.
+		// We only log debug here since the Create and Update will also log.
+		// Can't set Revision while creating a resource.
+		var updated *model.KVPair
+		if updated, err = c.Create(ctx, &model.KVPair{
+			Key:   kvp.Key,
+			Value: kvp.Value,
+		}); err == nil {
+			return updated, nil
+		} else if _, ok := err.(cerrors.ErrorResourceAlreadyExists); !ok {
 			logContext.Debug("Error applying resource (using Create)")
 			return nil, err
 		}
 
-		// Try to Update if the resource already exists.
-		updated, err = c.Update(ctx, kvp)
-		if err != nil {
+		// The resource already exists, possibly because it was created concurrently, so
+		// Update it at the current revision.  If the resource is modified or deleted between
+		// the Get and the Update then retry.
+		var current *model.KVPair
+		if current, err = c.Get(ctx, kvp.Key, ""); err == nil {
+			if updated, err = c.Update(ctx, &model.KVPair{
+				Key:      kvp.Key,
+				Value:    kvp.Value,
+				Revision: current.Revision,
+			}); err == nil {
+				return updated, nil
+			}
+		}
+		switch err.(type) {
+		case cerrors.ErrorResourceDoesNotExist, cerrors.ErrorResourceUpdateConflict:
+			logContext.WithField("Retry", i).Debug("Resource modified concurrently during Apply - retry")
+		default:
 			logContext.Debug("Error applying resource (using Update)")
 			return nil, err
 		}
 	}
-	return updated, nil
+	return nil, cerrors.ErrorResourceUpdateConflict{Err: err, Identifier: kvp.Key}
 }
 
 // Delete an entry in the datastore. This is a no-op when using the k8s backend.
@@ -446,6 +468,20 @@ func (c *KubeClient) Get(ctx context.Context, k model.Key, revision string) (*mo
 	return client.Get(ctx, k, revision)
 }
 
+// Exists returns whether an entry exists in the datastore.  The Kubernetes
+// resource clients do not support a metadata-only read, so this is implemented
+// using a Get.
+func (c *KubeClient) Exists(ctx context.Context, k model.Key, revision string) (bool, error) {
+	log.Debugf("Performing 'Exists' for %+v %v", k, revision)
+	_, err := c.Get(ctx, k, revision)
+	if _, ok := err.(cerrors.ErrorResourceDoesNotExist); ok {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // List entries in the datastore.  This may return an empty list if there are
 // no entries matching the request in the ListInterface.
 func (c *KubeClient) List(ctx context.Context, l model.ListInterface, revision string) (*model.KVPairList, error) {
@@ -458,7 +494,35 @@ func (c *KubeClient) List(ctx context.Context, l model.ListInterface, revision s
 			Operation:  "List",
 		}
 	}
-	return client.List(ctx, l, revision)
+	kvps, err := client.List(ctx, l, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	// The Kubernetes API cannot filter on the spec of a resource, so apply the filter of
+	// the list options (if any) to the listed resources.
+	if rlo, ok := l.(model.ResourceListOptions); ok && rlo.Filter != nil {
+		filtered := []*model.KVPair{}
+		for _, kvp := range kvps.KVPairs {
+			if rlo.MatchesFilter(kvp) {
+				filtered = append(filtered, kvp)
+			}
+		}
+		kvps.KVPairs = filtered
+	}
+	return kvps, nil
+}
+
+// Count returns the number of entries in the datastore matching the request in the
+// ListInterface.  The Kubernetes resource clients do not support a metadata-only list,
+// so this is implemented using a List.
+func (c *KubeClient) Count(ctx context.Context, l model.ListInterface, revision string) (int, error) {
+	log.Debugf("Performing 'Count' for %+v %v", l, reflect.TypeOf(l))
+	kvps, err := c.List(ctx, l, revision)
+	if err != nil {
+		return 0, err
+	}
+	return len(kvps.KVPairs), nil
 }
 
 // List entries in the datastore.  This may return an empty list if there are
@@ -30,6 +30,7 @@ import (
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 	"github.com/projectcalico/libcalico-go/lib/backend/api"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
 	"github.com/projectcalico/libcalico-go/lib/numorstring"
 
 	k8sapi "k8s.io/api/core/v1"
@@ -591,6 +592,8 @@ var _ = Describe("Test Syncer API for Kubernetes backend", func() {
 		By("Attempting to recreate an existing Global Network Policy", func() {
 			_, err := gnpClient.Create(ctx, kvp1a)
 			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceAlreadyExists{}))
+			Expect(err.(cerrors.ErrorResourceAlreadyExists).Identifier).To(Equal(kvp1a.Key))
 		})
 
 		By("Updating an existing Global Network Policy", func() {
@@ -755,6 +758,8 @@ var _ = Describe("Test Syncer API for Kubernetes backend", func() {
 		By("Attempting to recreate an existing Host Endpoint", func() {
 			_, err := c.Create(ctx, kvp1a)
 			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceAlreadyExists{}))
+			Expect(err.(cerrors.ErrorResourceAlreadyExists).Identifier).To(Equal(kvp1a.Key))
 		})
 
 		By("Updating an existing Host Endpoint", func() {
@@ -908,6 +913,8 @@ var _ = Describe("Test Syncer API for Kubernetes backend", func() {
 		By("Attempting to recreate an existing BGP Peer", func() {
 			_, err := c.Create(ctx, kvp1a)
 			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceAlreadyExists{}))
+			Expect(err.(cerrors.ErrorResourceAlreadyExists).Identifier).To(Equal(kvp1a.Key))
 		})
 
 		By("Updating an existing BGP Peer", func() {
@@ -1086,6 +1093,8 @@ var _ = Describe("Test Syncer API for Kubernetes backend", func() {
 		By("Attempting to recreate an existing Node BGP Peer", func() {
 			_, err := c.Create(ctx, kvp1a)
 			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceAlreadyExists{}))
+			Expect(err.(cerrors.ErrorResourceAlreadyExists).Identifier).To(Equal(kvp1a.Key))
 		})
 
 		By("Updating an existing Node BGP Peer", func() {
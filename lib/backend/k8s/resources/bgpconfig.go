@@ -19,7 +19,6 @@ import (
 
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -29,19 +28,14 @@ const (
 	BGPConfigCRDName      = "bgpconfigurations.crd.projectcalico.org"
 )
 
-func NewBGPConfigClient(c *kubernetes.Clientset, r *rest.RESTClient) K8sResourceClient {
-	return &customK8sResourceClient{
-		clientSet:       c,
-		restClient:      r,
-		name:            BGPConfigCRDName,
-		resource:        BGPConfigResourceName,
-		description:     "Calico BGP Configuration",
-		k8sResourceType: reflect.TypeOf(apiv3.BGPConfiguration{}),
-		k8sResourceTypeMeta: metav1.TypeMeta{
-			Kind:       apiv3.KindBGPConfiguration,
-			APIVersion: apiv3.GroupVersionCurrent,
-		},
-		k8sListType:  reflect.TypeOf(apiv3.BGPConfigurationList{}),
-		resourceKind: apiv3.KindBGPConfiguration,
-	}
+func NewBGPConfigClient(c *kubernetes.Clientset, r *rest.RESTClient, opts ...CustomResourceOption) (K8sResourceClient, error) {
+	return NewCustomK8sResourceClient(c, r, CustomK8sResourceDefinition{
+		CRDName:      BGPConfigCRDName,
+		ResourceName: BGPConfigResourceName,
+		Description:  "Calico BGP Configuration",
+		Kind:         apiv3.KindBGPConfiguration,
+		APIVersion:   apiv3.GroupVersionCurrent,
+		ResourceType: reflect.TypeOf(apiv3.BGPConfiguration{}),
+		ListType:     reflect.TypeOf(apiv3.BGPConfigurationList{}),
+	}, opts...)
 }
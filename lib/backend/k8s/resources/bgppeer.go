@@ -19,7 +19,6 @@ import (
 
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -29,19 +28,14 @@ const (
 	BGPPeerCRDName      = "bgppeers.crd.projectcalico.org"
 )
 
-func NewBGPPeerClient(c *kubernetes.Clientset, r *rest.RESTClient) K8sResourceClient {
-	return &customK8sResourceClient{
-		clientSet:       c,
-		restClient:      r,
-		name:            BGPPeerCRDName,
-		resource:        BGPPeerResourceName,
-		description:     "Calico BGP Peers",
-		k8sResourceType: reflect.TypeOf(apiv3.BGPPeer{}),
-		k8sResourceTypeMeta: metav1.TypeMeta{
-			Kind:       apiv3.KindBGPPeer,
-			APIVersion: apiv3.GroupVersionCurrent,
-		},
-		k8sListType:  reflect.TypeOf(apiv3.BGPPeerList{}),
-		resourceKind: apiv3.KindBGPPeer,
-	}
+func NewBGPPeerClient(c *kubernetes.Clientset, r *rest.RESTClient, opts ...CustomResourceOption) (K8sResourceClient, error) {
+	return NewCustomK8sResourceClient(c, r, CustomK8sResourceDefinition{
+		CRDName:      BGPPeerCRDName,
+		ResourceName: BGPPeerResourceName,
+		Description:  "Calico BGP Peers",
+		Kind:         apiv3.KindBGPPeer,
+		APIVersion:   apiv3.GroupVersionCurrent,
+		ResourceType: reflect.TypeOf(apiv3.BGPPeer{}),
+		ListType:     reflect.TypeOf(apiv3.BGPPeerList{}),
+	}, opts...)
 }
@@ -19,7 +19,6 @@ import (
 
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -29,19 +28,14 @@ const (
 	ClusterInfoCRDName      = "clusterinformations.crd.projectcalico.org"
 )
 
-func NewClusterInfoClient(c *kubernetes.Clientset, r *rest.RESTClient) K8sResourceClient {
-	return &customK8sResourceClient{
-		clientSet:       c,
-		restClient:      r,
-		name:            ClusterInfoCRDName,
-		resource:        ClusterInfoResourceName,
-		description:     "Calico Cluster Information",
-		k8sResourceType: reflect.TypeOf(apiv3.ClusterInformation{}),
-		k8sResourceTypeMeta: metav1.TypeMeta{
-			Kind:       apiv3.KindClusterInformation,
-			APIVersion: apiv3.GroupVersionCurrent,
-		},
-		k8sListType:  reflect.TypeOf(apiv3.ClusterInformationList{}),
-		resourceKind: apiv3.KindClusterInformation,
-	}
+func NewClusterInfoClient(c *kubernetes.Clientset, r *rest.RESTClient, opts ...CustomResourceOption) (K8sResourceClient, error) {
+	return NewCustomK8sResourceClient(c, r, CustomK8sResourceDefinition{
+		CRDName:      ClusterInfoCRDName,
+		ResourceName: ClusterInfoResourceName,
+		Description:  "Calico Cluster Information",
+		Kind:         apiv3.KindClusterInformation,
+		APIVersion:   apiv3.GroupVersionCurrent,
+		ResourceType: reflect.TypeOf(apiv3.ClusterInformation{}),
+		ListType:     reflect.TypeOf(apiv3.ClusterInformationList{}),
+	}, opts...)
 }
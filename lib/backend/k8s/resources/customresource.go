@@ -16,17 +16,21 @@ package resources
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/cache"
 
 	"github.com/projectcalico/libcalico-go/lib/backend/api"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
@@ -48,6 +52,122 @@ type customK8sResourceClient struct {
 	namespaced          bool
 	resourceKind        string
 	versionconverter    VersionConverter
+
+	// The group and version of the CRD, or nil to use the group and version of the
+	// REST client.  If set, the client uses a dedicated REST client for the group and
+	// version.
+	groupVersion *schema.GroupVersion
+}
+
+// CustomResourceOption is an optional setting applied to a custom resource client on
+// construction.
+type CustomResourceOption func(*customK8sResourceClient)
+
+// WithCRDGroupVersion sets the group and version of the CustomResourceDefinition accessed by
+// the client.  This allows the Calico resources to be stored using CRDs in a group other than
+// crd.projectcalico.org.  The CRD name is updated to use the group, and requests (including the
+// apiVersion of the request bodies) use the group and version.
+func WithCRDGroupVersion(group, version string) CustomResourceOption {
+	return func(c *customK8sResourceClient) {
+		c.groupVersion = &schema.GroupVersion{Group: group, Version: version}
+	}
+}
+
+// WithCRDResource sets the (plural) resource name of the CustomResourceDefinition accessed by
+// the client, for example "IPPools".
+func WithCRDResource(resource string) CustomResourceOption {
+	return func(c *customK8sResourceClient) {
+		c.resource = resource
+	}
+}
+
+// CustomK8sResourceDefinition describes a Calico-style custom resource, allowing a client to
+// be created for a CustomResourceDefinition that is not built into this library.
+type CustomK8sResourceDefinition struct {
+	// The name of the CRD, for example "ippools.crd.projectcalico.org".
+	CRDName string
+
+	// The (plural) resource name of the CRD, for example "IPPools".
+	ResourceName string
+
+	// A description of the resource, used for logging.
+	Description string
+
+	// The kind and API version of the resource.  The kind is also used for the resource
+	// keys.
+	Kind       string
+	APIVersion string
+
+	// The Go types of the resource and the resource list, for example
+	// reflect.TypeOf(apiv3.IPPool{}) and reflect.TypeOf(apiv3.IPPoolList{}).
+	ResourceType reflect.Type
+	ListType     reflect.Type
+
+	// Whether the resource is namespaced.
+	Namespaced bool
+
+	// An optional converter applied to resources read from the Kubernetes API.
+	Converter VersionConverter
+}
+
+// NewCustomK8sResourceClient returns a K8sResourceClient for the custom resource described by
+// the definition.  Returns an error if the REST client for the group and version of the
+// options cannot be built.
+func NewCustomK8sResourceClient(c *kubernetes.Clientset, r *rest.RESTClient, def CustomK8sResourceDefinition, opts ...CustomResourceOption) (K8sResourceClient, error) {
+	return newCustomK8sResourceClient(&customK8sResourceClient{
+		clientSet:       c,
+		restClient:      r,
+		name:            def.CRDName,
+		resource:        def.ResourceName,
+		description:     def.Description,
+		k8sResourceType: def.ResourceType,
+		k8sResourceTypeMeta: metav1.TypeMeta{
+			Kind:       def.Kind,
+			APIVersion: def.APIVersion,
+		},
+		k8sListType:      def.ListType,
+		resourceKind:     def.Kind,
+		namespaced:       def.Namespaced,
+		versionconverter: def.Converter,
+	}, opts)
+}
+
+// newCustomK8sResourceClient applies the options to the custom resource client and returns it.
+func newCustomK8sResourceClient(c *customK8sResourceClient, opts []CustomResourceOption) (K8sResourceClient, error) {
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.groupVersion != nil {
+		c.name = strings.ToLower(c.resource) + "." + c.groupVersion.Group
+		if c.restClient != nil {
+			r, err := newGroupVersionRESTClient(c.restClient, *c.groupVersion,
+				reflect.New(c.k8sResourceType).Interface().(runtime.Object),
+				reflect.New(c.k8sListType).Interface().(runtime.Object),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build REST client for CRD group version %s: %v", c.groupVersion, err)
+			}
+			c.restClient = r
+		}
+	}
+	return c, nil
+}
+
+// newGroupVersionRESTClient returns a REST client for the group and version that shares the
+// server, HTTP client and rate limiter of the supplied REST client.  The resource types are
+// registered under the group and version in a scheme private to the client, so that request
+// bodies are encoded with the correct apiVersion without affecting other clients.
+func newGroupVersionRESTClient(r *rest.RESTClient, gv schema.GroupVersion, types ...runtime.Object) (*rest.RESTClient, error) {
+	s := runtime.NewScheme()
+	s.AddKnownTypes(gv, types...)
+	metav1.AddToGroupVersion(s, gv)
+	config := rest.ContentConfig{
+		GroupVersion:         &gv,
+		ContentType:          runtime.ContentTypeJSON,
+		NegotiatedSerializer: serializer.DirectCodecFactory{CodecFactory: serializer.NewCodecFactory(s)},
+	}
+	base := r.Get().AbsPath().URL()
+	return rest.NewRESTClient(base, rest.DefaultVersionedAPIPath("/apis", gv), config, 0, 0, r.GetRateLimiter(), r.Client)
 }
 
 // VersionConverter converts v1 or v3 k8s resources into v3 resources.
@@ -163,21 +283,48 @@ func (c *customK8sResourceClient) Delete(ctx context.Context, k model.Key, revis
 		return nil, err
 	}
 
-	existing, err := c.Get(ctx, k, revision)
+	namespace := k.(model.ResourceKey).Namespace
+	logContext = logContext.WithField("Name", name)
+
+	// Get the current resource, both to return it and to check the revision.
+	resOut, err := c.getResource(ctx, namespace, name)
+	if err != nil {
+		logContext.WithError(err).Info("Error getting resource to delete")
+		return nil, K8sErrorToCalico(err, k)
+	}
+
+	// The Kubernetes UID is overwritten by the Calico metadata when the resource is converted,
+	// so note it before converting the resource.
+	uid := resOut.GetObjectMeta().GetUID()
+	existing, err := c.convertResourceToKVPair(resOut)
 	if err != nil {
 		return nil, err
 	}
 
-	namespace := k.(model.ResourceKey).Namespace
+	// If a revision is supplied, only delete the resource if it has not been modified since
+	// that revision.  As with the other datastores, return the current settings along with
+	// the conflict error.
+	if len(revision) != 0 && existing.Revision != revision {
+		logContext.WithField("CurrentRevision", existing.Revision).Info("Delete failed due to resource update conflict")
+		return existing, cerrors.ErrorResourceUpdateConflict{Identifier: k}
+	}
 
-	// Delete the resource using the name.
-	logContext = logContext.WithField("Name", name)
+	// Delete the resource using the name, with a precondition on the UID of the resource that
+	// was checked so that a resource deleted and recreated in the meantime is not deleted.
+	// The Kubernetes API does not support a precondition on the resource version of a Delete,
+	// so a concurrent update of the same resource is not detected.  A failed precondition is
+	// returned as a conflict.
+	body, err := json.Marshal(&metav1.DeleteOptions{Preconditions: metav1.NewUIDPreconditions(string(uid))})
+	if err != nil {
+		return nil, err
+	}
 	logContext.Debug("Send delete request by name")
 	err = c.restClient.Delete().
 		Context(ctx).
 		NamespaceIfScoped(namespace, c.namespaced).
 		Resource(c.resource).
 		Name(name).
+		Body(body).
 		Do().
 		Error()
 	if err != nil {
@@ -206,19 +353,29 @@ func (c *customK8sResourceClient) Get(ctx context.Context, key model.Key, revisi
 	// Kubernetes.
 	logContext = logContext.WithField("Name", name)
 	logContext.Debug("Get custom Kubernetes resource by name")
+	resOut, err := c.getResource(ctx, namespace, name)
+	if err != nil {
+		logContext.WithError(err).Info("Error getting resource")
+		return nil, K8sErrorToCalico(err, key)
+	}
+
+	return c.convertResourceToKVPair(resOut)
+}
+
+// getResource gets the Kubernetes resource with the supplied namespace and name, without
+// converting it.
+func (c *customK8sResourceClient) getResource(ctx context.Context, namespace, name string) (Resource, error) {
 	resOut := reflect.New(c.k8sResourceType).Interface().(Resource)
-	err = c.restClient.Get().
+	err := c.restClient.Get().
 		Context(ctx).
 		NamespaceIfScoped(namespace, c.namespaced).
 		Resource(c.resource).
 		Name(name).
 		Do().Into(resOut)
 	if err != nil {
-		logContext.WithError(err).Info("Error getting resource")
-		return nil, K8sErrorToCalico(err, key)
+		return nil, err
 	}
-
-	return c.convertResourceToKVPair(resOut)
+	return resOut, nil
 }
 
 // List lists configured Custom K8s Resource instances in the k8s API matching the
@@ -268,12 +425,19 @@ func (c *customK8sResourceClient) List(ctx context.Context, list model.ListInter
 	// If it is a namespaced resource, then we'll need the namespace.
 	namespace := list.(model.ResourceListOptions).Namespace
 
-	// Perform the request.
-	err := c.restClient.Get().
+	// Perform the request, including the chunking parameters if this is a paginated
+	// List.
+	req := c.restClient.Get().
 		Context(ctx).
 		NamespaceIfScoped(namespace, c.namespaced).
-		Resource(c.resource).
-		Do().Into(reslOut)
+		Resource(c.resource)
+	if limit := list.(model.ResourceListOptions).Limit; limit > 0 {
+		req = req.Param("limit", strconv.FormatInt(limit, 10))
+		if cont := list.(model.ResourceListOptions).Continue; cont != "" {
+			req = req.Param("continue", cont)
+		}
+	}
+	err := req.Do().Into(reslOut)
 	if err != nil {
 		// Don't return errors for "not found".  This just
 		// means there are no matching Custom K8s Resources, and we should return
@@ -289,7 +453,9 @@ func (c *customK8sResourceClient) List(ctx context.Context, list model.ListInter
 	}
 
 	// We expect the list type to have an "Items" field that we can
-	// iterate over.
+	// iterate over.  Resources that cannot be converted are skipped and their errors
+	// returned in the list.
+	var errs []error
 	elem := reflect.ValueOf(reslOut).Elem()
 	items := reflect.ValueOf(elem.FieldByName("Items").Interface())
 	for idx := 0; idx < items.Len(); idx++ {
@@ -298,11 +464,14 @@ func (c *customK8sResourceClient) List(ctx context.Context, list model.ListInter
 			kvps = append(kvps, kvp)
 		} else {
 			logContext.WithError(err).WithField("Item", res).Warning("unable to process resource, skipping")
+			errs = append(errs, c.parseError(res, err))
 		}
 	}
 	return &model.KVPairList{
 		KVPairs:  kvps,
 		Revision: reslOut.GetListMeta().GetResourceVersion(),
+		Continue: reslOut.GetListMeta().GetContinue(),
+		Errors:   errs,
 	}, nil
 }
 
@@ -315,12 +484,14 @@ func (c *customK8sResourceClient) Watch(ctx context.Context, list model.ListInte
 		return nil, fmt.Errorf("cannot watch specific resource instance: %s", resl.Name)
 	}
 
-	k8sWatchClient := cache.NewListWatchFromClient(
-		c.restClient,
-		c.resource,
-		resl.Namespace,
-		fields.Everything())
-	k8sWatch, err := k8sWatchClient.WatchFunc(metav1.ListOptions{ResourceVersion: revision})
+	k8sWatch, err := c.restClient.Get().
+		NamespaceIfScoped(resl.Namespace, c.namespaced).
+		Resource(c.resource).
+		VersionedParams(&metav1.ListOptions{
+			ResourceVersion: revision,
+			Watch:           true,
+		}, metav1.ParameterCodec).
+		Watch()
 	if err != nil {
 		return nil, K8sErrorToCalico(err, list)
 	}
@@ -386,6 +557,16 @@ func (c *customK8sResourceClient) convertResourceToKVPair(r Resource) (*model.KV
 	return kvp, nil
 }
 
+// parseError returns the ErrorParsingDatastoreEntry for a resource that could not be converted.
+func (c *customK8sResourceClient) parseError(r Resource, err error) error {
+	key := model.ResourceKey{
+		Name:      r.GetObjectMeta().GetName(),
+		Namespace: r.GetObjectMeta().GetNamespace(),
+		Kind:      c.resourceKind,
+	}
+	return cerrors.ErrorParsingDatastoreEntry{RawKey: key.String(), Err: err}
+}
+
 func (c *customK8sResourceClient) convertKVPairToResource(kvp *model.KVPair) (Resource, error) {
 	resource := kvp.Value.(Resource)
 	resource.GetObjectMeta().SetResourceVersion(kvp.Revision)
@@ -15,11 +15,26 @@
 package resources
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
 	"github.com/projectcalico/libcalico-go/lib/net"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -27,7 +42,11 @@ import (
 
 var _ = Describe("Custom resource conversion methods (tested using BGPPeer)", func() {
 	// Create an empty client since we are only testing conversion functions.
-	client := NewBGPPeerClient(nil, nil).(*customK8sResourceClient)
+	bgpPeerClient, err := NewBGPPeerClient(nil, nil)
+	if err != nil {
+		panic(err)
+	}
+	client := bgpPeerClient.(*customK8sResourceClient)
 
 	// Define some useful test data.
 	listIncomplete := model.ResourceListOptions{}
@@ -132,3 +151,299 @@ var _ = Describe("Custom resource conversion methods (tested using BGPPeer)", fu
 		Expect(kvp.Value).To(Equal(kvp1.Value))
 	})
 })
+
+var _ = Describe("Custom resource client CRD group and version", func() {
+	// Build a REST client for the default Calico CRD group.  No requests are sent, the
+	// client is only used to construct the request URLs.
+	restClient, err := rest.RESTClientFor(&rest.Config{
+		Host:    "https://127.0.0.1:6443",
+		APIPath: "/apis",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion:         &schema.GroupVersion{Group: "crd.projectcalico.org", Version: "v1"},
+			NegotiatedSerializer: serializer.DirectCodecFactory{CodecFactory: scheme.Codecs},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	It("should use the REST client group and version by default", func() {
+		c, err := NewIPPoolClient(nil, restClient)
+		Expect(err).NotTo(HaveOccurred())
+		client := c.(*customK8sResourceClient)
+		Expect(client.name).To(Equal(IPPoolCRDName))
+		url := client.restClient.Get().Resource(client.resource).Name("pool1").URL()
+		Expect(url.Path).To(Equal("/apis/crd.projectcalico.org/v1/IPPools/pool1"))
+	})
+
+	It("should use an overridden group, version and resource", func() {
+		c, err := NewIPPoolClient(nil, restClient,
+			WithCRDGroupVersion("calico.tenant1.example.com", "v2"),
+			WithCRDResource("TenantIPPools"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		client := c.(*customK8sResourceClient)
+		Expect(client.name).To(Equal("tenantippools.calico.tenant1.example.com"))
+		url := client.restClient.Get().Resource(client.resource).Name("pool1").URL()
+		Expect(url.Path).To(Equal("/apis/calico.tenant1.example.com/v2/TenantIPPools/pool1"))
+
+		By("Checking the types are not registered in the global scheme")
+		gvk := schema.GroupVersionKind{Group: "calico.tenant1.example.com", Version: "v2", Kind: "IPPool"}
+		Expect(scheme.Scheme.Recognizes(gvk)).To(BeFalse())
+	})
+
+	It("should use an overridden group for a namespaced resource", func() {
+		c, err := NewNetworkPolicyClient(nil, restClient, WithCRDGroupVersion("calico.tenant1.example.com", "v1"))
+		Expect(err).NotTo(HaveOccurred())
+		client := c.(*networkPolicyClient).crdClient
+		url := client.restClient.Get().NamespaceIfScoped("ns1", client.namespaced).Resource(client.resource).URL()
+		Expect(url.Path).To(Equal("/apis/calico.tenant1.example.com/v1/namespaces/ns1/NetworkPolicies"))
+	})
+
+	It("should send request bodies with the overridden group and version", func() {
+		var body map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.URL.Path).To(Equal("/apis/calico.tenant1.example.com/v2/IPPools"))
+			data, err := ioutil.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.Unmarshal(data, &body)).NotTo(HaveOccurred())
+			body["metadata"].(map[string]interface{})["resourceVersion"] = "1"
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			Expect(json.NewEncoder(w).Encode(body)).NotTo(HaveOccurred())
+		}))
+		defer server.Close()
+
+		serverClient, err := rest.RESTClientFor(&rest.Config{
+			Host:    server.URL,
+			APIPath: "/apis",
+			ContentConfig: rest.ContentConfig{
+				GroupVersion:         &schema.GroupVersion{Group: "crd.projectcalico.org", Version: "v1"},
+				NegotiatedSerializer: serializer.DirectCodecFactory{CodecFactory: scheme.Codecs},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		client, err := NewIPPoolClient(nil, serverClient, WithCRDGroupVersion("calico.tenant1.example.com", "v2"))
+		Expect(err).NotTo(HaveOccurred())
+
+		pool := apiv3.NewIPPool()
+		pool.Name = "pool1"
+		pool.Spec.CIDR = "10.0.0.0/24"
+		kvp, err := client.Create(context.Background(), &model.KVPair{
+			Key:   model.ResourceKey{Kind: apiv3.KindIPPool, Name: "pool1"},
+			Value: pool,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(HaveKeyWithValue("apiVersion", "calico.tenant1.example.com/v2"))
+		Expect(body).To(HaveKeyWithValue("kind", apiv3.KindIPPool))
+		Expect(kvp.Revision).To(Equal("1"))
+		Expect(kvp.Value.(*apiv3.IPPool).Spec.CIDR).To(Equal("10.0.0.0/24"))
+	})
+})
+
+var _ = Describe("Custom resource client Delete", func() {
+	var server *httptest.Server
+	var client K8sResourceClient
+	var deleteOpts *metav1.DeleteOptions
+	var deleteStatus int
+	key := model.ResourceKey{Kind: apiv3.KindIPPool, Name: "pool1"}
+
+	BeforeEach(func() {
+		deleteOpts = nil
+		deleteStatus = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.URL.Path).To(Equal("/apis/calico.tenant1.example.com/v2/IPPools/pool1"))
+			w.Header().Set("Content-Type", "application/json")
+			switch r.Method {
+			case http.MethodGet:
+				// The Calico UID is stored in the metadata annotation and differs from the
+				// Kubernetes UID.
+				pool := apiv3.NewIPPool()
+				pool.APIVersion = "calico.tenant1.example.com/v2"
+				pool.Name = "pool1"
+				pool.ResourceVersion = "2"
+				pool.UID = "k8s-uid"
+				pool.Annotations = map[string]string{metadataAnnotation: `{"uid":"calico-uid"}`}
+				pool.Spec.CIDR = "10.0.0.0/24"
+				Expect(json.NewEncoder(w).Encode(pool)).NotTo(HaveOccurred())
+			case http.MethodDelete:
+				deleteOpts = &metav1.DeleteOptions{}
+				data, err := ioutil.ReadAll(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(json.Unmarshal(data, deleteOpts)).NotTo(HaveOccurred())
+				status := metav1.Status{
+					TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+					Status:   metav1.StatusSuccess,
+					Code:     int32(deleteStatus),
+				}
+				if deleteStatus != http.StatusOK {
+					status.Status = metav1.StatusFailure
+					status.Reason = metav1.StatusReasonConflict
+				}
+				w.WriteHeader(deleteStatus)
+				Expect(json.NewEncoder(w).Encode(status)).NotTo(HaveOccurred())
+			default:
+				Fail("unexpected request method " + r.Method)
+			}
+		}))
+
+		serverClient, err := rest.RESTClientFor(&rest.Config{
+			Host:    server.URL,
+			APIPath: "/apis",
+			ContentConfig: rest.ContentConfig{
+				GroupVersion:         &schema.GroupVersion{Group: "crd.projectcalico.org", Version: "v1"},
+				NegotiatedSerializer: serializer.DirectCodecFactory{CodecFactory: scheme.Codecs},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		client, err = NewIPPoolClient(nil, serverClient, WithCRDGroupVersion("calico.tenant1.example.com", "v2"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should delete with a precondition on the Kubernetes UID", func() {
+		kvp, err := client.Delete(context.Background(), key, "2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvp.Revision).To(Equal("2"))
+		Expect(string(kvp.Value.(*apiv3.IPPool).UID)).To(Equal("calico-uid"))
+		Expect(deleteOpts).NotTo(BeNil())
+		Expect(deleteOpts.Preconditions).NotTo(BeNil())
+		Expect(string(*deleteOpts.Preconditions.UID)).To(Equal("k8s-uid"))
+	})
+
+	It("should delete without a revision", func() {
+		_, err := client.Delete(context.Background(), key, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleteOpts).NotTo(BeNil())
+	})
+
+	It("should not delete a resource that has been modified since the revision", func() {
+		kvp, err := client.Delete(context.Background(), key, "1")
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceUpdateConflict{}))
+		Expect(kvp.Revision).To(Equal("2"))
+		Expect(deleteOpts).To(BeNil())
+	})
+
+	It("should return a conflict if the UID precondition fails", func() {
+		deleteStatus = http.StatusConflict
+		_, err := client.Delete(context.Background(), key, "2")
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceUpdateConflict{}))
+		Expect(deleteOpts).NotTo(BeNil())
+	})
+})
+
+// toyResource is a user-defined Calico-style custom resource used to test the generic custom
+// resource client.
+type toyResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              toySpec `json:"spec,omitempty"`
+}
+
+type toySpec struct {
+	Color string `json:"color,omitempty"`
+}
+
+func (t *toyResource) DeepCopyObject() runtime.Object {
+	out := &toyResource{TypeMeta: t.TypeMeta, Spec: t.Spec}
+	t.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return out
+}
+
+type toyResourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []toyResource `json:"items"`
+}
+
+func (t *toyResourceList) DeepCopyObject() runtime.Object {
+	out := &toyResourceList{TypeMeta: t.TypeMeta, ListMeta: t.ListMeta}
+	for i := range t.Items {
+		out.Items = append(out.Items, *t.Items[i].DeepCopyObject().(*toyResource))
+	}
+	return out
+}
+
+// toyConverter lowercases the color of a toy resource read from the Kubernetes API.
+type toyConverter struct{}
+
+func (c toyConverter) ConvertFromK8s(inRes Resource) (Resource, error) {
+	t, ok := inRes.(*toyResource)
+	if !ok {
+		return nil, fmt.Errorf("invalid type conversion")
+	}
+	t.Spec.Color = strings.ToLower(t.Spec.Color)
+	return t, nil
+}
+
+var _ = Describe("Custom resource client for a user-defined CRD", func() {
+	toyClient, err := NewCustomK8sResourceClient(nil, nil, CustomK8sResourceDefinition{
+		CRDName:      "toys.example.com",
+		ResourceName: "Toys",
+		Description:  "Example Toys",
+		Kind:         "Toy",
+		APIVersion:   "example.com/v1",
+		ResourceType: reflect.TypeOf(toyResource{}),
+		ListType:     reflect.TypeOf(toyResourceList{}),
+		Namespaced:   true,
+		Converter:    toyConverter{},
+	})
+	if err != nil {
+		panic(err)
+	}
+	client := toyClient.(*customK8sResourceClient)
+
+	It("should configure the client from the definition", func() {
+		Expect(client.name).To(Equal("toys.example.com"))
+		Expect(client.resource).To(Equal("Toys"))
+		Expect(client.namespaced).To(BeTrue())
+		Expect(client.k8sResourceType).To(Equal(reflect.TypeOf(toyResource{})))
+		Expect(client.k8sListType).To(Equal(reflect.TypeOf(toyResourceList{})))
+	})
+
+	It("should convert a key to and from the resource name", func() {
+		k, err := client.nameToKey("toy1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k).To(Equal(model.ResourceKey{Name: "toy1", Kind: "Toy"}))
+		n, err := client.keyToName(k)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal("toy1"))
+	})
+
+	It("should round trip a KVPair through the Kubernetes resource", func() {
+		toy := &toyResource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "toy1",
+				Namespace: "ns1",
+				Labels:    map[string]string{"a": "b"},
+			},
+			Spec: toySpec{Color: "Red"},
+		}
+		kvp := &model.KVPair{
+			Key:      model.ResourceKey{Name: "toy1", Namespace: "ns1", Kind: "Toy"},
+			Value:    toy,
+			Revision: "rv",
+		}
+
+		r, err := client.convertKVPairToResource(kvp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r).To(BeAssignableToTypeOf(&toyResource{}))
+		Expect(r.GetObjectMeta().GetResourceVersion()).To(Equal("rv"))
+
+		out, err := client.convertResourceToKVPair(r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Key).To(Equal(kvp.Key))
+		Expect(out.Revision).To(Equal("rv"))
+		Expect(out.Value).To(BeAssignableToTypeOf(&toyResource{}))
+		outToy := out.Value.(*toyResource)
+		Expect(outToy.Kind).To(Equal("Toy"))
+		Expect(outToy.APIVersion).To(Equal("example.com/v1"))
+		Expect(outToy.Labels).To(Equal(map[string]string{"a": "b"}))
+		Expect(outToy.Spec.Color).To(Equal("red"))
+	})
+})
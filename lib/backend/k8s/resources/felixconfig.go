@@ -19,7 +19,6 @@ import (
 
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -29,19 +28,14 @@ const (
 	FelixConfigCRDName      = "felixconfigurations.crd.projectcalico.org"
 )
 
-func NewFelixConfigClient(c *kubernetes.Clientset, r *rest.RESTClient) K8sResourceClient {
-	return &customK8sResourceClient{
-		clientSet:       c,
-		restClient:      r,
-		name:            FelixConfigCRDName,
-		resource:        FelixConfigResourceName,
-		description:     "Calico Felix Configuration",
-		k8sResourceType: reflect.TypeOf(apiv3.FelixConfiguration{}),
-		k8sResourceTypeMeta: metav1.TypeMeta{
-			Kind:       apiv3.KindFelixConfiguration,
-			APIVersion: apiv3.GroupVersionCurrent,
-		},
-		k8sListType:  reflect.TypeOf(apiv3.FelixConfigurationList{}),
-		resourceKind: apiv3.KindFelixConfiguration,
-	}
+func NewFelixConfigClient(c *kubernetes.Clientset, r *rest.RESTClient, opts ...CustomResourceOption) (K8sResourceClient, error) {
+	return NewCustomK8sResourceClient(c, r, CustomK8sResourceDefinition{
+		CRDName:      FelixConfigCRDName,
+		ResourceName: FelixConfigResourceName,
+		Description:  "Calico Felix Configuration",
+		Kind:         apiv3.KindFelixConfiguration,
+		APIVersion:   apiv3.GroupVersionCurrent,
+		ResourceType: reflect.TypeOf(apiv3.FelixConfiguration{}),
+		ListType:     reflect.TypeOf(apiv3.FelixConfigurationList{}),
+	}, opts...)
 }
@@ -19,7 +19,6 @@ import (
 
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -29,19 +28,14 @@ const (
 	GlobalNetworkPolicyCRDName      = "globalnetworkpolicies.crd.projectcalico.org"
 )
 
-func NewGlobalNetworkPolicyClient(c *kubernetes.Clientset, r *rest.RESTClient) K8sResourceClient {
-	return &customK8sResourceClient{
-		clientSet:       c,
-		restClient:      r,
-		name:            GlobalNetworkPolicyCRDName,
-		resource:        GlobalNetworkPolicyResourceName,
-		description:     "Calico Global Network Policies",
-		k8sResourceType: reflect.TypeOf(apiv3.GlobalNetworkPolicy{}),
-		k8sResourceTypeMeta: metav1.TypeMeta{
-			Kind:       apiv3.KindGlobalNetworkPolicy,
-			APIVersion: apiv3.GroupVersionCurrent,
-		},
-		k8sListType:  reflect.TypeOf(apiv3.GlobalNetworkPolicyList{}),
-		resourceKind: apiv3.KindGlobalNetworkPolicy,
-	}
+func NewGlobalNetworkPolicyClient(c *kubernetes.Clientset, r *rest.RESTClient, opts ...CustomResourceOption) (K8sResourceClient, error) {
+	return NewCustomK8sResourceClient(c, r, CustomK8sResourceDefinition{
+		CRDName:      GlobalNetworkPolicyCRDName,
+		ResourceName: GlobalNetworkPolicyResourceName,
+		Description:  "Calico Global Network Policies",
+		Kind:         apiv3.KindGlobalNetworkPolicy,
+		APIVersion:   apiv3.GroupVersionCurrent,
+		ResourceType: reflect.TypeOf(apiv3.GlobalNetworkPolicy{}),
+		ListType:     reflect.TypeOf(apiv3.GlobalNetworkPolicyList{}),
+	}, opts...)
 }
@@ -17,7 +17,6 @@ package resources
 import (
 	"reflect"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
@@ -29,19 +28,14 @@ const (
 	GlobalNetworkSetCRDName      = "globalnetworksets.crd.projectcalico.org"
 )
 
-func NewGlobalNetworkSetClient(c *kubernetes.Clientset, r *rest.RESTClient) K8sResourceClient {
-	return &customK8sResourceClient{
-		clientSet:       c,
-		restClient:      r,
-		name:            GlobalNetworkSetCRDName,
-		resource:        GlobalNetworkSetResourceName,
-		description:     "Calico Global Network Sets",
-		k8sResourceType: reflect.TypeOf(apiv3.GlobalNetworkSet{}),
-		k8sResourceTypeMeta: metav1.TypeMeta{
-			Kind:       apiv3.KindGlobalNetworkSet,
-			APIVersion: apiv3.GroupVersionCurrent,
-		},
-		k8sListType:  reflect.TypeOf(apiv3.GlobalNetworkSetList{}),
-		resourceKind: apiv3.KindGlobalNetworkSet,
-	}
+func NewGlobalNetworkSetClient(c *kubernetes.Clientset, r *rest.RESTClient, opts ...CustomResourceOption) (K8sResourceClient, error) {
+	return NewCustomK8sResourceClient(c, r, CustomK8sResourceDefinition{
+		CRDName:      GlobalNetworkSetCRDName,
+		ResourceName: GlobalNetworkSetResourceName,
+		Description:  "Calico Global Network Sets",
+		Kind:         apiv3.KindGlobalNetworkSet,
+		APIVersion:   apiv3.GroupVersionCurrent,
+		ResourceType: reflect.TypeOf(apiv3.GlobalNetworkSet{}),
+		ListType:     reflect.TypeOf(apiv3.GlobalNetworkSetList{}),
+	}, opts...)
 }
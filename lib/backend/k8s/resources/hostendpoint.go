@@ -19,7 +19,6 @@ import (
 
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -29,19 +28,14 @@ const (
 	HostEndpointCRDName      = "hostendpoints.crd.projectcalico.org"
 )
 
-func NewHostEndpointClient(c *kubernetes.Clientset, r *rest.RESTClient) K8sResourceClient {
-	return &customK8sResourceClient{
-		clientSet:       c,
-		restClient:      r,
-		name:            HostEndpointCRDName,
-		resource:        HostEndpointResourceName,
-		description:     "Calico HostEndpoints",
-		k8sResourceType: reflect.TypeOf(apiv3.HostEndpoint{}),
-		k8sResourceTypeMeta: metav1.TypeMeta{
-			Kind:       apiv3.KindHostEndpoint,
-			APIVersion: apiv3.GroupVersionCurrent,
-		},
-		k8sListType:  reflect.TypeOf(apiv3.HostEndpointList{}),
-		resourceKind: apiv3.KindHostEndpoint,
-	}
+func NewHostEndpointClient(c *kubernetes.Clientset, r *rest.RESTClient, opts ...CustomResourceOption) (K8sResourceClient, error) {
+	return NewCustomK8sResourceClient(c, r, CustomK8sResourceDefinition{
+		CRDName:      HostEndpointCRDName,
+		ResourceName: HostEndpointResourceName,
+		Description:  "Calico HostEndpoints",
+		Kind:         apiv3.KindHostEndpoint,
+		APIVersion:   apiv3.GroupVersionCurrent,
+		ResourceType: reflect.TypeOf(apiv3.HostEndpoint{}),
+		ListType:     reflect.TypeOf(apiv3.HostEndpointList{}),
+	}, opts...)
 }
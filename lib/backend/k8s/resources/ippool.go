@@ -18,7 +18,6 @@ import (
 	"fmt"
 	"reflect"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
@@ -31,22 +30,17 @@ const (
 	IPPoolCRDName      = "ippools.crd.projectcalico.org"
 )
 
-func NewIPPoolClient(c *kubernetes.Clientset, r *rest.RESTClient) K8sResourceClient {
-	return &customK8sResourceClient{
-		clientSet:       c,
-		restClient:      r,
-		name:            IPPoolCRDName,
-		resource:        IPPoolResourceName,
-		description:     "Calico IP Pools",
-		k8sResourceType: reflect.TypeOf(apiv3.IPPool{}),
-		k8sResourceTypeMeta: metav1.TypeMeta{
-			Kind:       apiv3.KindIPPool,
-			APIVersion: apiv3.GroupVersionCurrent,
-		},
-		k8sListType:      reflect.TypeOf(apiv3.IPPoolList{}),
-		resourceKind:     apiv3.KindIPPool,
-		versionconverter: IPPoolv1v3Converter{},
-	}
+func NewIPPoolClient(c *kubernetes.Clientset, r *rest.RESTClient, opts ...CustomResourceOption) (K8sResourceClient, error) {
+	return NewCustomK8sResourceClient(c, r, CustomK8sResourceDefinition{
+		CRDName:      IPPoolCRDName,
+		ResourceName: IPPoolResourceName,
+		Description:  "Calico IP Pools",
+		Kind:         apiv3.KindIPPool,
+		APIVersion:   apiv3.GroupVersionCurrent,
+		ResourceType: reflect.TypeOf(apiv3.IPPool{}),
+		ListType:     reflect.TypeOf(apiv3.IPPoolList{}),
+		Converter:    IPPoolv1v3Converter{},
+	}, opts...)
 }
 
 // IPPoolv1v3Converter implements VersionConverter interface.
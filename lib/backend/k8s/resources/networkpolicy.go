@@ -44,25 +44,24 @@ const (
 	NetworkPolicyCRDName      = "networkpolicies.crd.projectcalico.org"
 )
 
-func NewNetworkPolicyClient(c *kubernetes.Clientset, r *rest.RESTClient) K8sResourceClient {
-	crdClient := &customK8sResourceClient{
-		restClient:      r,
-		name:            NetworkPolicyCRDName,
-		resource:        NetworkPolicyResourceName,
-		description:     "Calico Network Policies",
-		k8sResourceType: reflect.TypeOf(apiv3.NetworkPolicy{}),
-		k8sResourceTypeMeta: metav1.TypeMeta{
-			Kind:       apiv3.KindNetworkPolicy,
-			APIVersion: apiv3.GroupVersionCurrent,
-		},
-		k8sListType:  reflect.TypeOf(apiv3.NetworkPolicyList{}),
-		resourceKind: apiv3.KindNetworkPolicy,
-		namespaced:   true,
+func NewNetworkPolicyClient(c *kubernetes.Clientset, r *rest.RESTClient, opts ...CustomResourceOption) (K8sResourceClient, error) {
+	crdClient, err := NewCustomK8sResourceClient(nil, r, CustomK8sResourceDefinition{
+		CRDName:      NetworkPolicyCRDName,
+		ResourceName: NetworkPolicyResourceName,
+		Description:  "Calico Network Policies",
+		Kind:         apiv3.KindNetworkPolicy,
+		APIVersion:   apiv3.GroupVersionCurrent,
+		ResourceType: reflect.TypeOf(apiv3.NetworkPolicy{}),
+		ListType:     reflect.TypeOf(apiv3.NetworkPolicyList{}),
+		Namespaced:   true,
+	}, opts...)
+	if err != nil {
+		return nil, err
 	}
 	return &networkPolicyClient{
 		clientSet: c,
-		crdClient: crdClient,
-	}
+		crdClient: crdClient.(*customK8sResourceClient),
+	}, nil
 }
 
 // Implements the api.Client interface for NetworkPolicys.
@@ -259,12 +258,17 @@ func (c *networkPolicyClient) List(ctx context.Context, list model.ListInterface
 		return nil, K8sErrorToCalico(err, l)
 	}
 
-	// For each policy, turn it into a Policy and generate the list.
+	// For each policy, turn it into a Policy and generate the list.  Policies that cannot
+	// be converted are skipped and their errors returned in the list.
 	for _, p := range networkPolicies.Items {
 		kvp, err := c.K8sNetworkPolicyToCalico(&p)
 		if err != nil {
-			log.WithError(err).Info("Failed to convert K8s Network Policy")
-			return nil, err
+			log.WithError(err).Warning("Failed to convert K8s Network Policy, skipping")
+			npKvps.Errors = append(npKvps.Errors, cerrors.ErrorParsingDatastoreEntry{
+				RawKey: p.Namespace + "/" + p.Name,
+				Err:    err,
+			})
+			continue
 		}
 
 		// Convert the revision to the combined CRD/k8s revision - the CRD rev will be empty.
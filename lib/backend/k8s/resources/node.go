@@ -141,10 +141,12 @@ func (c *nodeClient) List(ctx context.Context, list model.ListInterface, revisio
 		K8sErrorToCalico(err, list)
 	}
 
+	var errs []error
 	for _, node := range nodes.Items {
 		kvp, err := K8sNodeToCalico(&node)
 		if err != nil {
 			log.Errorf("Unable to convert k8s node to Calico node: node=%s: %v", node.Name, err)
+			errs = append(errs, cerrors.ErrorParsingDatastoreEntry{RawKey: node.Name, Err: err})
 			continue
 		}
 		kvps = append(kvps, kvp)
@@ -153,6 +155,7 @@ func (c *nodeClient) List(ctx context.Context, list model.ListInterface, revisio
 	return &model.KVPairList{
 		KVPairs:  kvps,
 		Revision: revision,
+		Errors:   errs,
 	}, nil
 }
 
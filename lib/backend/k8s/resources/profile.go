@@ -180,10 +180,12 @@ func (c *profileClient) List(ctx context.Context, list model.ListInterface, revi
 	}
 
 	// For each Namespace, return a profile.
+	var errs []error
 	for _, ns := range namespaces.Items {
 		kvp, err := c.getNsKv(&ns)
 		if err != nil {
 			log.Errorf("Unable to convert k8s Namespace to Calico Profile: Namespace=%s: %v", ns.Name, err)
+			errs = append(errs, cerrors.ErrorParsingDatastoreEntry{RawKey: ns.Name, Err: err})
 			continue
 		}
 		kvps = append(kvps, kvp)
@@ -201,6 +203,7 @@ func (c *profileClient) List(ctx context.Context, list model.ListInterface, revi
 		kvp, err := c.getSaKv(&sa)
 		if err != nil {
 			log.WithError(err).Errorf("Unable to convert k8s service account to Calico Profile: %s", sa.Name)
+			errs = append(errs, cerrors.ErrorParsingDatastoreEntry{RawKey: sa.Namespace + "/" + sa.Name, Err: err})
 			continue
 		}
 		log.Debug("Converted k8s sa to Calico profile ", sa.Name)
@@ -209,6 +212,7 @@ func (c *profileClient) List(ctx context.Context, list model.ListInterface, revi
 	return &model.KVPairList{
 		KVPairs:  kvps,
 		Revision: c.JoinProfileRevisions(namespaces.ResourceVersion, serviceaccounts.ResourceVersion),
+		Errors:   errs,
 	}, nil
 }
 
@@ -184,14 +184,26 @@ func (c *WorkloadEndpointClient) List(ctx context.Context, list model.ListInterf
 		}, nil
 	}
 
-	// Otherwise, enumerate all pods in a namespace.
-	pods, err := c.clientSet.CoreV1().Pods(l.Namespace).List(metav1.ListOptions{ResourceVersion: revision})
+	// Otherwise, enumerate all pods in a namespace.  If this is a paginated List then the
+	// limit applies to the pods, so a page may contain fewer endpoints than the limit.  The
+	// continue token determines the revision of subsequent pages, and the API server rejects
+	// a request that specifies both, so the revision is only sent for the first page.
+	opts := metav1.ListOptions{
+		Limit:    l.Limit,
+		Continue: l.Continue,
+	}
+	if l.Continue == "" {
+		opts.ResourceVersion = revision
+	}
+	pods, err := c.clientSet.CoreV1().Pods(l.Namespace).List(opts)
 	if err != nil {
 		return nil, K8sErrorToCalico(err, l)
 	}
 
-	// For each Pod, return a workload endpoint.
+	// For each Pod, return a workload endpoint.  Pods that cannot be converted are skipped
+	// and their errors returned in the list.
 	ret := []*model.KVPair{}
+	var errs []error
 	for _, pod := range pods.Items {
 		// Decide if this pod should be included.
 		if !c.converter.IsValidCalicoWorkloadEndpoint(&pod) {
@@ -200,13 +212,20 @@ func (c *WorkloadEndpointClient) List(ctx context.Context, list model.ListInterf
 
 		kvp, err := c.converter.PodToWorkloadEndpoint(&pod)
 		if err != nil {
-			return nil, err
+			log.WithError(err).WithField("Pod", pod.Namespace+"/"+pod.Name).Warning("Unable to convert Pod to WorkloadEndpoint, skipping")
+			errs = append(errs, cerrors.ErrorParsingDatastoreEntry{
+				RawKey: pod.Namespace + "/" + pod.Name,
+				Err:    err,
+			})
+			continue
 		}
 		ret = append(ret, kvp)
 	}
 	return &model.KVPairList{
 		KVPairs:  ret,
 		Revision: revision,
+		Continue: pods.Continue,
+		Errors:   errs,
 	}, nil
 }
 
@@ -0,0 +1,262 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/numorstring"
+)
+
+// The DeepCopy methods of the model types return copies that share no maps, slices or
+// pointers with the original, so that a copy may be modified without modifying the original.
+// This is required when modifying a value that may be shared, for example a value returned
+// from a cache.
+
+// DeepCopyInto copies the Policy into out.
+func (in *Policy) DeepCopyInto(out *Policy) {
+	*out = *in
+	if in.Order != nil {
+		order := *in.Order
+		out.Order = &order
+	}
+	out.InboundRules = deepCopyRules(in.InboundRules)
+	out.OutboundRules = deepCopyRules(in.OutboundRules)
+	out.Annotations = deepCopyStringMap(in.Annotations)
+	out.Types = deepCopyStrings(in.Types)
+}
+
+// DeepCopy returns a deep copy of the Policy.
+func (in *Policy) DeepCopy() *Policy {
+	if in == nil {
+		return nil
+	}
+	out := new(Policy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the Rule into out.
+func (in *Rule) DeepCopyInto(out *Rule) {
+	*out = *in
+	out.IPVersion = deepCopyInt(in.IPVersion)
+	out.Protocol = deepCopyProtocol(in.Protocol)
+	out.NotProtocol = deepCopyProtocol(in.NotProtocol)
+	out.ICMPType = deepCopyInt(in.ICMPType)
+	out.ICMPCode = deepCopyInt(in.ICMPCode)
+	out.NotICMPType = deepCopyInt(in.NotICMPType)
+	out.NotICMPCode = deepCopyInt(in.NotICMPCode)
+
+	out.SrcNet = in.SrcNet.DeepCopy()
+	out.SrcNets = deepCopyIPNetPtrs(in.SrcNets)
+	out.SrcPorts = deepCopyPorts(in.SrcPorts)
+	out.DstNet = in.DstNet.DeepCopy()
+	out.DstNets = deepCopyIPNetPtrs(in.DstNets)
+	out.DstPorts = deepCopyPorts(in.DstPorts)
+
+	out.NotSrcNet = in.NotSrcNet.DeepCopy()
+	out.NotSrcNets = deepCopyIPNetPtrs(in.NotSrcNets)
+	out.NotSrcPorts = deepCopyPorts(in.NotSrcPorts)
+	out.NotDstNet = in.NotDstNet.DeepCopy()
+	out.NotDstNets = deepCopyIPNetPtrs(in.NotDstNets)
+	out.NotDstPorts = deepCopyPorts(in.NotDstPorts)
+
+	out.OriginalSrcServiceAccountNames = deepCopyStrings(in.OriginalSrcServiceAccountNames)
+	out.OriginalDstServiceAccountNames = deepCopyStrings(in.OriginalDstServiceAccountNames)
+
+	out.HTTPMatch = in.HTTPMatch.DeepCopy()
+}
+
+// DeepCopy returns a deep copy of the Rule.
+func (in *Rule) DeepCopy() *Rule {
+	if in == nil {
+		return nil
+	}
+	out := new(Rule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the HTTPMatch into out.
+func (in *HTTPMatch) DeepCopyInto(out *HTTPMatch) {
+	out.Methods = deepCopyStrings(in.Methods)
+	out.Paths = nil
+	if in.Paths != nil {
+		out.Paths = make([]apiv3.HTTPPath, len(in.Paths))
+		copy(out.Paths, in.Paths)
+	}
+}
+
+// DeepCopy returns a deep copy of the HTTPMatch.
+func (in *HTTPMatch) DeepCopy() *HTTPMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the WorkloadEndpoint into out.
+func (in *WorkloadEndpoint) DeepCopyInto(out *WorkloadEndpoint) {
+	*out = *in
+	out.Mac = in.Mac.DeepCopy()
+	out.ProfileIDs = deepCopyStrings(in.ProfileIDs)
+	out.IPv4Nets = deepCopyIPNets(in.IPv4Nets)
+	out.IPv6Nets = deepCopyIPNets(in.IPv6Nets)
+	out.IPv4NAT = deepCopyIPNATs(in.IPv4NAT)
+	out.IPv6NAT = deepCopyIPNATs(in.IPv6NAT)
+	out.Labels = deepCopyStringMap(in.Labels)
+	out.Annotations = deepCopyStringMap(in.Annotations)
+	out.IPv4Gateway = in.IPv4Gateway.DeepCopy()
+	out.IPv6Gateway = in.IPv6Gateway.DeepCopy()
+	out.Ports = nil
+	if in.Ports != nil {
+		out.Ports = make([]EndpointPort, len(in.Ports))
+		copy(out.Ports, in.Ports)
+	}
+}
+
+// DeepCopy returns a deep copy of the WorkloadEndpoint.
+func (in *WorkloadEndpoint) DeepCopy() *WorkloadEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the IPNAT into out.
+func (in *IPNAT) DeepCopyInto(out *IPNAT) {
+	in.IntIP.DeepCopyInto(&out.IntIP)
+	in.ExtIP.DeepCopyInto(&out.ExtIP)
+}
+
+// DeepCopy returns a deep copy of the IPNAT.
+func (in *IPNAT) DeepCopy() *IPNAT {
+	if in == nil {
+		return nil
+	}
+	out := new(IPNAT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the IPPool into out.
+func (in *IPPool) DeepCopyInto(out *IPPool) {
+	*out = *in
+	in.CIDR.DeepCopyInto(&out.CIDR)
+	out.ReservedCIDRs = deepCopyIPNets(in.ReservedCIDRs)
+}
+
+// DeepCopy returns a deep copy of the IPPool.
+func (in *IPPool) DeepCopy() *IPPool {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func deepCopyRules(in []Rule) []Rule {
+	if in == nil {
+		return nil
+	}
+	out := make([]Rule, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+func deepCopyIPNATs(in []IPNAT) []IPNAT {
+	if in == nil {
+		return nil
+	}
+	out := make([]IPNAT, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+func deepCopyIPNets(in []net.IPNet) []net.IPNet {
+	if in == nil {
+		return nil
+	}
+	out := make([]net.IPNet, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+func deepCopyIPNetPtrs(in []*net.IPNet) []*net.IPNet {
+	if in == nil {
+		return nil
+	}
+	out := make([]*net.IPNet, len(in))
+	for i := range in {
+		out[i] = in[i].DeepCopy()
+	}
+	return out
+}
+
+func deepCopyPorts(in []numorstring.Port) []numorstring.Port {
+	if in == nil {
+		return nil
+	}
+	out := make([]numorstring.Port, len(in))
+	copy(out, in)
+	return out
+}
+
+func deepCopyProtocol(in *numorstring.Protocol) *numorstring.Protocol {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
+
+func deepCopyInt(in *int) *int {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	return &out
+}
+
+func deepCopyStrings(in []string) []string {
+	if in == nil {
+		return nil
+	}
+	out := make([]string, len(in))
+	copy(out, in)
+	return out
+}
+
+func deepCopyStringMap(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
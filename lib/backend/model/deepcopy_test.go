@@ -0,0 +1,138 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	gonet "net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/numorstring"
+)
+
+var _ = Describe("Model deep copy", func() {
+	It("should deep copy a Policy", func() {
+		order := 10.5
+		ipVersion := 4
+		protocol := numorstring.ProtocolFromString("TCP")
+		srcNet := net.MustParseCIDR("10.0.0.0/24")
+		p := &model.Policy{
+			Order: &order,
+			InboundRules: []model.Rule{{
+				Action:    "Allow",
+				IPVersion: &ipVersion,
+				Protocol:  &protocol,
+				SrcNet:    &srcNet,
+				SrcNets:   []*net.IPNet{&srcNet},
+				DstPorts:  []numorstring.Port{numorstring.SinglePort(80)},
+				HTTPMatch: &model.HTTPMatch{
+					Methods: []string{"GET"},
+					Paths:   []apiv3.HTTPPath{{Exact: "/foo"}},
+				},
+			}},
+			Selector:    "all()",
+			Annotations: map[string]string{"a": "b"},
+			Types:       []string{"Ingress"},
+		}
+		c := p.DeepCopy()
+		Expect(c).To(Equal(p))
+
+		*c.Order = 1
+		r := &c.InboundRules[0]
+		*r.IPVersion = 6
+		r.Protocol.StrVal = "UDP"
+		r.SrcNet.IP[0] = 192
+		r.SrcNets[0].Mask[3] = 255
+		r.DstPorts[0].MinPort = 8080
+		r.HTTPMatch.Methods[0] = "PUT"
+		r.HTTPMatch.Paths[0].Exact = "/bar"
+		c.Annotations["a"] = "c"
+		c.Types[0] = "Egress"
+
+		Expect(order).To(Equal(10.5))
+		Expect(ipVersion).To(Equal(4))
+		Expect(protocol.StrVal).To(Equal("TCP"))
+		Expect(srcNet.String()).To(Equal("10.0.0.0/24"))
+		Expect(p.InboundRules[0].DstPorts[0].MinPort).To(Equal(uint16(80)))
+		Expect(p.InboundRules[0].HTTPMatch.Methods).To(Equal([]string{"GET"}))
+		Expect(p.InboundRules[0].HTTPMatch.Paths[0].Exact).To(Equal("/foo"))
+		Expect(p.Annotations).To(Equal(map[string]string{"a": "b"}))
+		Expect(p.Types).To(Equal([]string{"Ingress"}))
+	})
+
+	It("should deep copy a WorkloadEndpoint", func() {
+		hw, err := gonet.ParseMAC("01:23:45:67:89:ab")
+		Expect(err).NotTo(HaveOccurred())
+		mac := &net.MAC{HardwareAddr: hw}
+		gw := net.MustParseIP("10.0.0.1")
+		wep := &model.WorkloadEndpoint{
+			Name:        "eth0",
+			Mac:         mac,
+			ProfileIDs:  []string{"default"},
+			IPv4Nets:    []net.IPNet{net.MustParseCIDR("10.0.0.2/32")},
+			IPv4NAT:     []model.IPNAT{{IntIP: net.MustParseIP("10.0.0.2"), ExtIP: net.MustParseIP("172.16.0.2")}},
+			Labels:      map[string]string{"app": "frontend"},
+			IPv4Gateway: &gw,
+			Ports:       []model.EndpointPort{{Name: "http", Protocol: numorstring.ProtocolFromString("TCP"), Port: 80}},
+		}
+		c := wep.DeepCopy()
+		Expect(c).To(Equal(wep))
+
+		c.Mac.HardwareAddr[0] = 0xff
+		c.ProfileIDs[0] = "other"
+		c.IPv4Nets[0].IP[3] = 3
+		c.IPv4NAT[0].ExtIP.IP[3] = 3
+		c.Labels["app"] = "backend"
+		c.IPv4Gateway.IP[3] = 254
+		c.Ports[0].Port = 8080
+
+		Expect(wep.Mac.String()).To(Equal("01:23:45:67:89:ab"))
+		Expect(wep.ProfileIDs).To(Equal([]string{"default"}))
+		Expect(wep.IPv4Nets[0].String()).To(Equal("10.0.0.2/32"))
+		Expect(wep.IPv4NAT[0].ExtIP.String()).To(Equal("172.16.0.2"))
+		Expect(wep.Labels).To(Equal(map[string]string{"app": "frontend"}))
+		Expect(gw.String()).To(Equal("10.0.0.1"))
+		Expect(wep.Ports[0].Port).To(Equal(uint16(80)))
+	})
+
+	It("should deep copy an IPPool", func() {
+		pool := &model.IPPool{
+			CIDR:          net.MustParseCIDR("10.0.0.0/16"),
+			IPAM:          true,
+			ReservedCIDRs: []net.IPNet{net.MustParseCIDR("10.0.1.0/24")},
+		}
+		c := pool.DeepCopy()
+		Expect(c).To(Equal(pool))
+
+		c.CIDR.IP[0] = 192
+		c.ReservedCIDRs[0].IP[2] = 2
+		c.IPAM = false
+
+		Expect(pool.CIDR.String()).To(Equal("10.0.0.0/16"))
+		Expect(pool.ReservedCIDRs[0].String()).To(Equal("10.0.1.0/24"))
+		Expect(pool.IPAM).To(BeTrue())
+	})
+
+	It("should deep copy nil values", func() {
+		var p *model.Policy
+		Expect(p.DeepCopy()).To(BeNil())
+		Expect((&model.Rule{}).DeepCopy()).To(Equal(&model.Rule{}))
+		Expect((&model.WorkloadEndpoint{}).DeepCopy()).To(Equal(&model.WorkloadEndpoint{}))
+	})
+})
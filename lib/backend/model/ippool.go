@@ -92,10 +92,17 @@ func (options IPPoolListOptions) KeyFromDefaultPath(path string) Key {
 }
 
 type IPPool struct {
-	CIDR          net.IPNet `json:"cidr"`
-	IPIPInterface string    `json:"ipip"`
-	IPIPMode      ipip.Mode `json:"ipip_mode"`
-	Masquerade    bool      `json:"masquerade"`
-	IPAM          bool      `json:"ipam"`
-	Disabled      bool      `json:"disabled"`
+	CIDR          net.IPNet   `json:"cidr"`
+	IPIPInterface string      `json:"ipip"`
+	IPIPMode      ipip.Mode   `json:"ipip_mode"`
+	Masquerade    bool        `json:"masquerade"`
+	IPAM          bool        `json:"ipam"`
+	Disabled      bool        `json:"disabled"`
+	ReservedCIDRs []net.IPNet `json:"reserved_cidrs,omitempty"`
+}
+
+// IPAMEligible returns true if IP addresses may be allocated from the pool by IPAM, that is
+// the pool has IPAM enabled and is not disabled.
+func (p IPPool) IPAMEligible() bool {
+	return p.IPAM && !p.Disabled
 }
@@ -0,0 +1,58 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+)
+
+var _ = DescribeTable("IPPool IPAM eligibility",
+	func(pool model.IPPool, expected bool) {
+		Expect(pool.IPAMEligible()).To(Equal(expected))
+	},
+	Entry("IPAM enabled", model.IPPool{IPAM: true}, true),
+	Entry("IPAM disabled", model.IPPool{IPAM: false}, false),
+	Entry("IPAM enabled but pool disabled", model.IPPool{IPAM: true, Disabled: true}, false),
+	Entry("IPAM disabled and pool disabled", model.IPPool{Disabled: true}, false),
+)
+
+var (
+	filterTrue  = true
+	filterFalse = false
+)
+
+var _ = DescribeTable("IPPool filter",
+	func(filter model.IPPoolFilter, spec apiv3.IPPoolSpec, expected bool) {
+		kvp := &model.KVPair{
+			Key:   model.ResourceKey{Kind: apiv3.KindIPPool, Name: "pool"},
+			Value: &apiv3.IPPool{Spec: spec},
+		}
+		Expect(filter.Matches(kvp)).To(Equal(expected))
+	},
+	Entry("empty filter", model.IPPoolFilter{}, apiv3.IPPoolSpec{CIDR: "10.0.0.0/24", Disabled: true}, true),
+	Entry("disabled matches", model.IPPoolFilter{Disabled: &filterTrue}, apiv3.IPPoolSpec{CIDR: "10.0.0.0/24", Disabled: true}, true),
+	Entry("disabled does not match", model.IPPoolFilter{Disabled: &filterFalse}, apiv3.IPPoolSpec{CIDR: "10.0.0.0/24", Disabled: true}, false),
+	Entry("IPIP enabled matches", model.IPPoolFilter{IPIPEnabled: &filterTrue}, apiv3.IPPoolSpec{CIDR: "10.0.0.0/24", IPIPMode: apiv3.IPIPModeCrossSubnet}, true),
+	Entry("unset IPIP mode is disabled", model.IPPoolFilter{IPIPEnabled: &filterFalse}, apiv3.IPPoolSpec{CIDR: "10.0.0.0/24"}, true),
+	Entry("IP version matches", model.IPPoolFilter{IPVersion: 6}, apiv3.IPPoolSpec{CIDR: "fd00::/120"}, true),
+	Entry("IP version does not match", model.IPPoolFilter{IPVersion: 4}, apiv3.IPPoolSpec{CIDR: "fd00::/120"}, false),
+	Entry("IPAM eligible", model.IPPoolFilter{IPAMEligible: true}, apiv3.IPPoolSpec{CIDR: "10.0.0.0/24"}, true),
+	Entry("IPAM eligible but disabled", model.IPPoolFilter{IPAMEligible: true}, apiv3.IPPoolSpec{CIDR: "10.0.0.0/24", Disabled: true}, false),
+	Entry("IPAM eligible but invalid CIDR", model.IPPoolFilter{IPAMEligible: true}, apiv3.IPPoolSpec{CIDR: "10.0.0.0/33"}, false),
+)
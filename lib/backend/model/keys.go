@@ -98,6 +98,13 @@ type KVPair struct {
 type KVPairList struct {
 	KVPairs  []*KVPair
 	Revision string
+	// Continue is set when a List was limited and further results are available.  It
+	// should be supplied in the list options of the next List to retrieve the next page.
+	Continue string
+	// Errors contains an error for each entry matching the List that could not be parsed,
+	// typically an ErrorParsingDatastoreEntry.  These entries are omitted from KVPairs so that
+	// a single malformed entry does not prevent the remaining entries from being listed.
+	Errors []error
 }
 
 // KeyToDefaultPath converts one of the Keys from this package into a unique
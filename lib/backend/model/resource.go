@@ -24,6 +24,7 @@ import (
 
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 	"github.com/projectcalico/libcalico-go/lib/namespace"
+	"github.com/projectcalico/libcalico-go/lib/net"
 )
 
 // Name/type information about a single resource.
@@ -167,6 +168,74 @@ type ResourceListOptions struct {
 	Kind string
 	// Whether the name is prefix rather than the full name.
 	Prefix bool
+	// The maximum number of results to return in a single List.  If zero, all results
+	// are returned.
+	Limit int64
+	// The continue token returned from a previous paginated List.  If blank, the List
+	// starts from the beginning.
+	Continue string
+	// An optional filter applied by the backend to the listed resources.  Only the
+	// resources that match the filter are returned.  A paginated List may therefore return
+	// fewer results than the Limit even when there are further results available.
+	Filter ResourceFilter
+}
+
+// ResourceFilter filters the resources returned by a List.
+type ResourceFilter interface {
+	// Matches returns true if the resource should be included in the List results.
+	Matches(kvp *KVPair) bool
+}
+
+// MatchesFilter returns true if the KVPair matches the Filter of the list options, or if
+// no Filter is specified.
+func (options ResourceListOptions) MatchesFilter(kvp *KVPair) bool {
+	return options.Filter == nil || options.Filter.Matches(kvp)
+}
+
+// IPPoolFilter is a ResourceFilter for v3 IPPool resources.  Each filter that is set must
+// match for a pool to be returned.
+type IPPoolFilter struct {
+	// If set, only match pools whose Disabled field matches this value.
+	Disabled *bool
+	// If set, only match pools with IPIP enabled (an IPIPMode other than Never) when
+	// true, or with IPIP disabled when false.
+	IPIPEnabled *bool
+	// If non-zero, only match pools with a valid CIDR of this IP version.
+	IPVersion int
+	// If true, only match pools that IPAM may allocate from, that is the pools that are
+	// not disabled and have a valid CIDR.
+	IPAMEligible bool
+}
+
+// Matches implements the ResourceFilter interface.
+func (f IPPoolFilter) Matches(kvp *KVPair) bool {
+	pool, ok := kvp.Value.(*apiv3.IPPool)
+	if !ok {
+		return false
+	}
+	if f.IPAMEligible && pool.Spec.Disabled {
+		return false
+	}
+	if f.Disabled != nil && pool.Spec.Disabled != *f.Disabled {
+		return false
+	}
+	if f.IPIPEnabled != nil {
+		ipip := pool.Spec.IPIPMode != "" && pool.Spec.IPIPMode != apiv3.IPIPModeNever
+		if ipip != *f.IPIPEnabled {
+			return false
+		}
+	}
+	if f.IPAMEligible || f.IPVersion != 0 {
+		_, cidr, err := net.ParseCIDR(pool.Spec.CIDR)
+		if err != nil {
+			log.WithField("Name", pool.Name).Warnf("Failed to parse the IPPool CIDR: %s. Ignoring that IPPool", pool.Spec.CIDR)
+			return false
+		}
+		if f.IPVersion != 0 && cidr.Version() != f.IPVersion {
+			return false
+		}
+	}
+	return true
 }
 
 // If the Kind, Namespace and Name are specified, but the Name is a prefix then the
@@ -0,0 +1,106 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+// ResourceVersion is the revision of a resource together with the type of the datastore that
+// assigned it, which determines how revisions may be compared.  The etcdv3 datastore assigns
+// numeric revisions that increase with each write, so the revisions may be ordered.  The
+// Kubernetes datastore assigns opaque revisions, so the revisions may only be compared for
+// equality.
+type ResourceVersion struct {
+	DatastoreType apiconfig.DatastoreType
+	Revision      string
+}
+
+// NewResourceVersion returns the ResourceVersion of a revision assigned by a datastore of the
+// given type.
+func NewResourceVersion(datastoreType apiconfig.DatastoreType, revision string) ResourceVersion {
+	return ResourceVersion{DatastoreType: datastoreType, Revision: revision}
+}
+
+// ResourceVersion returns the ResourceVersion of the KVPair, which was read from a datastore
+// of the given type.
+func (kvp *KVPair) ResourceVersion(datastoreType apiconfig.DatastoreType) ResourceVersion {
+	return NewResourceVersion(datastoreType, kvp.Revision)
+}
+
+func (v ResourceVersion) String() string {
+	return fmt.Sprintf("%s(%s)", v.DatastoreType, v.Revision)
+}
+
+// Equal returns true if the versions are the same revision of the same datastore type.
+func (v ResourceVersion) Equal(other ResourceVersion) bool {
+	return v == other
+}
+
+// Ordered returns true if the versions of the datastore type may be ordered by Compare.
+func (v ResourceVersion) Ordered() bool {
+	return v.DatastoreType == apiconfig.EtcdV3
+}
+
+// Compare returns -1, 0 or 1 if the version is older than, the same as or newer than the other
+// version.  Equal versions may always be compared.  Otherwise an ErrorOperationNotSupported is
+// returned if the versions are of different datastore types, or the datastore type does not
+// order its revisions, and an error is returned if an etcdv3 revision is not numeric.
+func (v ResourceVersion) Compare(other ResourceVersion) (int, error) {
+	if v.Equal(other) {
+		return 0, nil
+	}
+	if v.DatastoreType != other.DatastoreType {
+		return 0, cerrors.ErrorOperationNotSupported{
+			Operation:  "Compare",
+			Identifier: v,
+			Reason:     fmt.Sprintf("cannot compare with %s", other),
+		}
+	}
+	if !v.Ordered() {
+		return 0, cerrors.ErrorOperationNotSupported{
+			Operation:  "Compare",
+			Identifier: v,
+			Reason:     fmt.Sprintf("revisions of the %s datastore are not ordered", v.DatastoreType),
+		}
+	}
+
+	r1, err := strconv.ParseInt(v.Revision, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s revision '%s'", v.DatastoreType, v.Revision)
+	}
+	r2, err := strconv.ParseInt(other.Revision, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s revision '%s'", other.DatastoreType, other.Revision)
+	}
+	switch {
+	case r1 < r2:
+		return -1, nil
+	case r1 > r2:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// NewerThan returns true if the version is newer than the other version.  See Compare for the
+// errors returned if the versions cannot be ordered.
+func (v ResourceVersion) NewerThan(other ResourceVersion) (bool, error) {
+	c, err := v.Compare(other)
+	return c > 0, err
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+var _ = Describe("ResourceVersion", func() {
+	etcd := func(rev string) model.ResourceVersion {
+		return model.NewResourceVersion(apiconfig.EtcdV3, rev)
+	}
+	kdd := func(rev string) model.ResourceVersion {
+		return model.NewResourceVersion(apiconfig.Kubernetes, rev)
+	}
+
+	DescribeTable("should order etcdv3 versions numerically",
+		func(v1, v2 string, expected int) {
+			c, err := etcd(v1).Compare(etcd(v2))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c).To(Equal(expected))
+
+			newer, err := etcd(v1).NewerThan(etcd(v2))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newer).To(Equal(expected > 0))
+		},
+		Entry("older", "9", "10", -1),
+		Entry("newer", "10", "9", 1),
+		Entry("same", "10", "10", 0),
+	)
+
+	It("should return an error for a non-numeric etcdv3 version", func() {
+		_, err := etcd("abc").Compare(etcd("10"))
+		Expect(err).To(HaveOccurred())
+		_, err = etcd("10").Compare(etcd(""))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should only compare Kubernetes versions for equality", func() {
+		c, err := kdd("1234").Compare(kdd("1234"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c).To(Equal(0))
+		Expect(kdd("1234").Equal(kdd("1234"))).To(BeTrue())
+		Expect(kdd("1234").Equal(kdd("1235"))).To(BeFalse())
+
+		_, err = kdd("1235").Compare(kdd("1234"))
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorOperationNotSupported{}))
+		_, err = kdd("1/2").NewerThan(kdd("1/1"))
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorOperationNotSupported{}))
+	})
+
+	It("should not compare versions of different datastore types", func() {
+		Expect(etcd("10").Equal(kdd("10"))).To(BeFalse())
+		_, err := etcd("10").Compare(kdd("9"))
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorOperationNotSupported{}))
+	})
+
+	It("should return the version of a KVPair", func() {
+		kvp := &model.KVPair{Revision: "5"}
+		Expect(kvp.ResourceVersion(apiconfig.EtcdV3)).To(Equal(etcd("5")))
+	})
+})
@@ -158,6 +158,7 @@ type WorkloadEndpoint struct {
 	IPv4NAT          []IPNAT           `json:"ipv4_nat,omitempty"`
 	IPv6NAT          []IPNAT           `json:"ipv6_nat,omitempty"`
 	Labels           map[string]string `json:"labels,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
 	IPv4Gateway      *net.IP           `json:"ipv4_gateway,omitempty" validate:"omitempty,ipv4"`
 	IPv6Gateway      *net.IP           `json:"ipv6_gateway,omitempty" validate:"omitempty,ipv6"`
 	Ports            []EndpointPort    `json:"ports,omitempty" validate:"dive"`
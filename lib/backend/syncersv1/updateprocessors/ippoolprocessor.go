@@ -65,6 +65,15 @@ func convertIPPoolV2ToV1(kvp *model.KVPair) (*model.KVPair, error) {
 		ipipMode = ipip.Undefined
 	}
 
+	var reserved []cnet.IPNet
+	for _, r := range v3res.Spec.ReservedCIDRs {
+		_, rcidr, err := cnet.ParseCIDR(r)
+		if err != nil {
+			return nil, err
+		}
+		reserved = append(reserved, *rcidr)
+	}
+
 	return &model.KVPair{
 		Key: v1key,
 		Value: &model.IPPool{
@@ -74,6 +83,7 @@ func convertIPPoolV2ToV1(kvp *model.KVPair) (*model.KVPair, error) {
 			Masquerade:    v3res.Spec.NATOutgoing,
 			IPAM:          !v3res.Spec.Disabled,
 			Disabled:      v3res.Spec.Disabled,
+			ReservedCIDRs: reserved,
 		},
 		Revision: kvp.Revision,
 	}, nil
@@ -141,6 +141,27 @@ var _ = Describe("Test the IPPool update processor", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should convert the reserved CIDRs", func() {
+		up := updateprocessors.NewIPPoolUpdateProcessor()
+
+		res := apiv3.NewIPPool()
+		res.Name = v3PoolKey1.Name
+		res.Spec.CIDR = cidr1str
+		res.Spec.ReservedCIDRs = []string{"1.2.3.1/32", "1.2.3.16/28"}
+
+		kvps, err := up.Process(&model.KVPair{
+			Key:      v3PoolKey1,
+			Value:    res,
+			Revision: "abcde",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvps).To(HaveLen(1))
+		Expect(kvps[0].Value.(*model.IPPool).ReservedCIDRs).To(Equal([]net.IPNet{
+			net.MustParseCIDR("1.2.3.1/32"),
+			net.MustParseCIDR("1.2.3.16/28"),
+		}))
+	})
+
 	It("should fail to convert an invalid resource", func() {
 		up := updateprocessors.NewIPPoolUpdateProcessor()
 
@@ -460,4 +460,17 @@ var _ = Describe("Test the Rules Conversion Functions", func() {
 			Expect(rulev1.DstSelector).To(Equal(dste))
 		})
 	})
+
+	It("should ignore the rule metadata", func() {
+		r := apiv3.Rule{
+			Action: apiv3.Allow,
+			Source: apiv3.EntityRule{
+				Selector: "has(foo)",
+			},
+		}
+		withMetadata := r
+		withMetadata.Metadata = &apiv3.RuleMetadata{Annotations: map[string]string{"note": "audited"}}
+
+		Expect(updateprocessors.RuleAPIV2ToBackend(withMetadata, "namespace")).To(Equal(updateprocessors.RuleAPIV2ToBackend(r, "namespace")))
+	})
 })
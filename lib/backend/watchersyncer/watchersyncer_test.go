@@ -975,6 +975,14 @@ func (c *fakeClient) Get(ctx context.Context, key model.Key, revision string) (*
 	panic("should not be called")
 	return nil, nil
 }
+func (c *fakeClient) Exists(ctx context.Context, key model.Key, revision string) (bool, error) {
+	panic("should not be called")
+	return false, nil
+}
+func (c *fakeClient) Count(ctx context.Context, list model.ListInterface, revision string) (int, error) {
+	panic("should not be called")
+	return 0, nil
+}
 func (c *fakeClient) Syncer(callbacks api.SyncerCallbacks) api.Syncer {
 	panic("should not be called")
 	return nil
@@ -987,6 +995,10 @@ func (c *fakeClient) Clean() error {
 	panic("should not be called")
 	return nil
 }
+func (c *fakeClient) Close() error {
+	panic("should not be called")
+	return nil
+}
 
 func (c *fakeClient) List(ctx context.Context, list model.ListInterface, revision string) (*model.KVPairList, error) {
 	// Create a fake watcher keyed off the ListOptions (root path).
@@ -126,6 +126,21 @@ func (p poolAccessor) GetEnabledPools(ipVersion int) ([]net.IPNet, error) {
 	return enabled, nil
 }
 
+func (p poolAccessor) GetReservedCIDRs(ipVersion int) ([]net.IPNet, error) {
+	pools, err := p.client.IPPools().List(api.IPPoolMetadata{})
+	if err != nil {
+		return nil, err
+	}
+	reserved := []net.IPNet{}
+	for _, pool := range pools.Items {
+		if pool.Spec.Disabled || pool.Metadata.CIDR.Version() != ipVersion {
+			continue
+		}
+		reserved = append(reserved, pool.Spec.ReservedCIDRs...)
+	}
+	return reserved, nil
+}
+
 // Config returns an interface for managing system configuration..
 func (c *Client) Config() ConfigInterface {
 	return newConfigs(c)
@@ -28,6 +28,7 @@ import (
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 	"github.com/projectcalico/libcalico-go/lib/backend"
 	"github.com/projectcalico/libcalico-go/lib/clientv3"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
 	"github.com/projectcalico/libcalico-go/lib/numorstring"
 	"github.com/projectcalico/libcalico-go/lib/options"
 	"github.com/projectcalico/libcalico-go/lib/testutils"
@@ -104,6 +105,7 @@ var _ = testutils.E2eDatastoreDescribe("BGPConfiguration tests", testutils.Datas
 			}, options.SetOptions{})
 			Expect(outError).To(HaveOccurred())
 			Expect(outError.Error()).To(Equal("resource already exists: BGPConfiguration(" + name1 + ")"))
+			Expect(outError).To(BeAssignableToTypeOf(cerrors.ErrorResourceAlreadyExists{}))
 
 			By("Getting BGPConfiguration (name1) and comparing the output against specInfo")
 			res, outError := c.BGPConfigurations().Get(ctx, name1, options.GetOptions{})
@@ -213,12 +215,10 @@ var _ = testutils.E2eDatastoreDescribe("BGPConfiguration tests", testutils.Datas
 			testutils.ExpectResource(&outList.Items[0], apiv3.KindBGPConfiguration, testutils.ExpectNoNamespace, name1, specDebug)
 			testutils.ExpectResource(&outList.Items[1], apiv3.KindBGPConfiguration, testutils.ExpectNoNamespace, name2, specDebug)
 
-			if config.Spec.DatastoreType != apiconfig.Kubernetes {
-				By("Deleting BGPConfiguration (name1) with the old resource version")
-				_, outError = c.BGPConfigurations().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
-				Expect(outError).To(HaveOccurred())
-				Expect(outError.Error()).To(Equal("update conflict: BGPConfiguration(" + name1 + ")"))
-			}
+			By("Deleting BGPConfiguration (name1) with the old resource version")
+			_, outError = c.BGPConfigurations().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("update conflict: BGPConfiguration(" + name1 + ")"))
 
 			By("Deleting BGPConfiguration (name1) with the new resource version")
 			dres, outError := c.BGPConfigurations().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_2})
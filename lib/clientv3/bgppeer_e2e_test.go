@@ -202,12 +202,10 @@ var _ = testutils.E2eDatastoreDescribe("BGPPeer tests", testutils.DatastoreAll,
 			testutils.ExpectResource(&outList.Items[0], apiv3.KindBGPPeer, testutils.ExpectNoNamespace, name1, spec2)
 			testutils.ExpectResource(&outList.Items[1], apiv3.KindBGPPeer, testutils.ExpectNoNamespace, name2, spec2)
 
-			if config.Spec.DatastoreType != apiconfig.Kubernetes {
-				By("Deleting BGPPeer (name1) with the old resource version")
-				_, outError = c.BGPPeers().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
-				Expect(outError).To(HaveOccurred())
-				Expect(outError.Error()).To(Equal("update conflict: BGPPeer(" + name1 + ")"))
-			}
+			By("Deleting BGPPeer (name1) with the old resource version")
+			_, outError = c.BGPPeers().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("update conflict: BGPPeer(" + name1 + ")"))
 
 			By("Deleting BGPPeer (name1) with the new resource version")
 			dres, outError := c.BGPPeers().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_2})
@@ -0,0 +1,287 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+// CacheConfig configures the read-through cache of resource Gets.
+type CacheConfig struct {
+	// The time for which a cached resource is returned before it is fetched again from the
+	// datastore.  This must be greater than zero, since the cache is not notified of changes
+	// made by other clients.
+	TTL time.Duration
+
+	// The maximum number of cached resources.  When the cache is full the least recently
+	// used resource is evicted.  If zero, the number of cached resources is not limited.
+	MaxSize int
+}
+
+// WithCache enables a read-through cache of the Get operations for the Calico resources,
+// for clients that repeatedly Get the same resources.
+//
+// The cache is eventually consistent: a Get may return a resource that has since been
+// modified or deleted by another client, for up to the configured TTL.  Cached resources
+// are invalidated when they are written or deleted through this client, and when an event
+// for the resource is received on a Watch made through this client.  Get requests for a
+// specific revision, and the List, Exists and IPAM operations, are not cached.
+func WithCache(config CacheConfig) Option {
+	return func(c *client) {
+		c.cache = &config
+	}
+}
+
+// cacheEntry is a cached resource, stored as an element of the LRU list.
+type cacheEntry struct {
+	key     string
+	kvp     *model.KVPair
+	expires time.Time
+}
+
+// cachingBackend wraps a backend client, caching the results of Get operations.
+type cachingBackend struct {
+	bapi.Client
+	config CacheConfig
+	logger *log.Entry
+
+	// The current time.  This may be overridden for testing.
+	now func() time.Time
+
+	// Returns true if the client has been closed, in which case the cached resources are
+	// not returned.  If nil, the client is never considered closed.
+	closed func() bool
+
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+
+	// The number of invalidations.  A Get only caches its result if there were no
+	// invalidations while the resource was being fetched, since the result may be stale.
+	generation uint64
+}
+
+func newCachingBackend(be bapi.Client, config CacheConfig, logger *log.Entry) *cachingBackend {
+	return &cachingBackend{
+		Client:  be,
+		config:  config,
+		logger:  logger,
+		now:     time.Now,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+	}
+}
+
+// Get returns the cached resource if there is an unexpired entry for the key, otherwise it
+// gets the resource from the backend and caches it.
+func (c *cachingBackend) Get(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	if revision != "" {
+		return c.Client.Get(ctx, key, revision)
+	}
+	if c.closed != nil && c.closed() {
+		return nil, cerrors.ErrorClientClosed{}
+	}
+	k := key.String()
+	if kvp := c.lookup(k); kvp != nil {
+		c.logger.WithField("Key", k).Debug("Returning cached resource")
+		return kvp, nil
+	}
+
+	generation := c.currentGeneration()
+	kvp, err := c.Client.Get(ctx, key, revision)
+	if err != nil {
+		return nil, err
+	}
+	c.store(k, kvp, generation)
+	return kvp, nil
+}
+
+func (c *cachingBackend) Create(ctx context.Context, object *model.KVPair) (*model.KVPair, error) {
+	defer c.invalidate(object.Key)
+	return c.Client.Create(ctx, object)
+}
+
+func (c *cachingBackend) Update(ctx context.Context, object *model.KVPair) (*model.KVPair, error) {
+	defer c.invalidate(object.Key)
+	return c.Client.Update(ctx, object)
+}
+
+func (c *cachingBackend) Apply(ctx context.Context, object *model.KVPair) (*model.KVPair, error) {
+	defer c.invalidate(object.Key)
+	return c.Client.Apply(ctx, object)
+}
+
+func (c *cachingBackend) Delete(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	defer c.invalidate(key)
+	return c.Client.Delete(ctx, key, revision)
+}
+
+func (c *cachingBackend) Clean() error {
+	c.lock.Lock()
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+	c.generation++
+	c.lock.Unlock()
+	return c.Client.Clean()
+}
+
+// Watch starts a watch on the backend client, invalidating the cached resources for which
+// events are received.
+func (c *cachingBackend) Watch(ctx context.Context, list model.ListInterface, revision string) (bapi.WatchInterface, error) {
+	w, err := c.Client.Watch(ctx, list, revision)
+	if err != nil {
+		return nil, err
+	}
+	cw := &cachingWatch{
+		WatchInterface: w,
+		backend:        c,
+		results:        make(chan bapi.WatchEvent),
+		done:           make(chan struct{}),
+	}
+	go cw.run()
+	return cw, nil
+}
+
+// lookup returns a copy of the cached resource, or nil if the key is not cached or the
+// entry has expired.
+func (c *cachingBackend) lookup(key string) *model.KVPair {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := e.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(e)
+		return nil
+	}
+	c.lru.MoveToFront(e)
+	kvp, _ := copyKVPair(entry.kvp)
+	return kvp
+}
+
+// currentGeneration returns the number of invalidations so far.
+func (c *cachingBackend) currentGeneration() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.generation
+}
+
+// store caches a copy of the resource, evicting the least recently used resource if the
+// cache is full.  The resource is not cached if there have been any invalidations since the
+// generation at which it was fetched, or if it cannot be copied.
+func (c *cachingBackend) store(key string, kvp *model.KVPair, generation uint64) {
+	kvpCopy, ok := copyKVPair(kvp)
+	if !ok {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.generation != generation {
+		c.logger.WithField("Key", key).Debug("Not caching resource invalidated while fetching it")
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{
+		key:     key,
+		kvp:     kvpCopy,
+		expires: c.now().Add(c.config.TTL),
+	})
+	for c.config.MaxSize > 0 && c.lru.Len() > c.config.MaxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+// invalidate removes the cached resource for the key, if any.
+func (c *cachingBackend) invalidate(key model.Key) {
+	if key == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generation++
+	if e, ok := c.entries[key.String()]; ok {
+		c.remove(e)
+	}
+}
+
+// remove removes the entry from the cache.  The lock must be held.
+func (c *cachingBackend) remove(e *list.Element) {
+	c.lru.Remove(e)
+	delete(c.entries, e.Value.(*cacheEntry).key)
+}
+
+// copyKVPair returns a copy of the KVPair with a deep copy of the value, so that the cached
+// resource is not modified by the caller.  Returns false if the value cannot be copied.
+func copyKVPair(kvp *model.KVPair) (*model.KVPair, bool) {
+	obj, ok := kvp.Value.(runtime.Object)
+	if !ok {
+		return nil, false
+	}
+	kvpCopy := *kvp
+	kvpCopy.Value = obj.DeepCopyObject()
+	return &kvpCopy, true
+}
+
+// cachingWatch wraps a backend watch, invalidating the cached resources for which events
+// are received before passing the events on.
+type cachingWatch struct {
+	bapi.WatchInterface
+	backend  *cachingBackend
+	results  chan bapi.WatchEvent
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (w *cachingWatch) run() {
+	defer close(w.results)
+	for event := range w.WatchInterface.ResultChan() {
+		if event.Old != nil {
+			w.backend.invalidate(event.Old.Key)
+		}
+		if event.New != nil {
+			w.backend.invalidate(event.New.Key)
+		}
+		select {
+		case w.results <- event:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *cachingWatch) ResultChan() <-chan bapi.WatchEvent {
+	return w.results
+}
+
+func (w *cachingWatch) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+	})
+	w.WatchInterface.Stop()
+}
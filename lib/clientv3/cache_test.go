@@ -0,0 +1,197 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+// storeBackend implements the Get and Update methods of the backend client using an in-memory
+// store, counting the Gets.  Close does nothing and all other methods panic.
+type storeBackend struct {
+	bapi.Client
+	kvps     map[string]*model.KVPair
+	revision int
+	gets     int
+
+	// Called by Get after reading the resource, if set.
+	onGet func()
+}
+
+func (b *storeBackend) Get(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	b.gets++
+	kvp, ok := b.kvps[key.String()]
+	if !ok {
+		return nil, cerrors.ErrorResourceDoesNotExist{Identifier: key}
+	}
+	kvpCopy, _ := copyKVPair(kvp)
+	if b.onGet != nil {
+		b.onGet()
+	}
+	return kvpCopy, nil
+}
+
+func (b *storeBackend) Close() error {
+	return nil
+}
+
+func (b *storeBackend) Update(ctx context.Context, object *model.KVPair) (*model.KVPair, error) {
+	b.revision++
+	object.Revision = strconv.Itoa(b.revision)
+	b.kvps[object.Key.String()], _ = copyKVPair(object)
+	return object, nil
+}
+
+var _ = Describe("Client cache tests", func() {
+	ctx := context.Background()
+	var be *storeBackend
+	var cb *cachingBackend
+	var r *resources
+	var now time.Time
+
+	newNetworkSet := func(name string, nets ...string) *apiv3.GlobalNetworkSet {
+		gns := apiv3.NewGlobalNetworkSet()
+		gns.ObjectMeta = metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.Now(),
+			UID:               "test-uid",
+		}
+		gns.Spec.Nets = nets
+		return gns
+	}
+
+	store := func(gns *apiv3.GlobalNetworkSet) {
+		be.kvps[model.ResourceKey{Kind: apiv3.KindGlobalNetworkSet, Name: gns.Name}.String()] = &model.KVPair{
+			Key:      model.ResourceKey{Kind: apiv3.KindGlobalNetworkSet, Name: gns.Name},
+			Value:    gns,
+			Revision: "1",
+		}
+	}
+
+	get := func(name string) *apiv3.GlobalNetworkSet {
+		out, err := r.Get(ctx, options.GetOptions{}, apiv3.KindGlobalNetworkSet, "", name)
+		Expect(err).NotTo(HaveOccurred())
+		return out.(*apiv3.GlobalNetworkSet)
+	}
+
+	BeforeEach(func() {
+		be = &storeBackend{kvps: map[string]*model.KVPair{}, revision: 1}
+		store(newNetworkSet("networkset-1", "10.0.0.0/24"))
+		store(newNetworkSet("networkset-2", "10.0.1.0/24"))
+
+		now = time.Now()
+		logger := log.NewEntry(log.StandardLogger())
+		cb = newCachingBackend(be, CacheConfig{TTL: time.Minute, MaxSize: 1}, logger)
+		cb.now = func() time.Time { return now }
+		r = newResources(cb, nil, logger)
+	})
+
+	It("should return a cached resource without a backend Get", func() {
+		first := get("networkset-1")
+		Expect(be.gets).To(Equal(1))
+
+		By("Modifying the returned resource")
+		first.Spec.Nets = []string{"192.168.0.0/16"}
+
+		By("Getting the resource again")
+		second := get("networkset-1")
+		Expect(be.gets).To(Equal(1))
+		Expect(second.Spec.Nets).To(Equal([]string{"10.0.0.0/24"}))
+		Expect(second.ResourceVersion).To(Equal("1"))
+	})
+
+	It("should get the resource from the backend once the TTL has expired", func() {
+		get("networkset-1")
+		now = now.Add(59 * time.Second)
+		get("networkset-1")
+		Expect(be.gets).To(Equal(1))
+
+		now = now.Add(time.Second)
+		get("networkset-1")
+		Expect(be.gets).To(Equal(2))
+	})
+
+	It("should not cache a Get for a specific revision", func() {
+		get("networkset-1")
+		_, err := r.Get(ctx, options.GetOptions{ResourceVersion: "1"}, apiv3.KindGlobalNetworkSet, "", "networkset-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(be.gets).To(Equal(2))
+	})
+
+	It("should invalidate the cached resource when it is written", func() {
+		gns := get("networkset-1")
+		gns.Spec.Nets = []string{"192.168.0.0/16"}
+		_, err := r.Update(ctx, options.SetOptions{}, apiv3.KindGlobalNetworkSet, gns)
+		Expect(err).NotTo(HaveOccurred())
+
+		out := get("networkset-1")
+		Expect(be.gets).To(Equal(2))
+		Expect(out.Spec.Nets).To(Equal([]string{"192.168.0.0/16"}))
+		Expect(out.ResourceVersion).To(Equal("2"))
+	})
+
+	It("should not cache a resource that is written while it is being fetched", func() {
+		be.onGet = func() {
+			be.onGet = nil
+			gns := newNetworkSet("networkset-1", "192.168.0.0/16")
+			gns.ResourceVersion = "1"
+			_, err := r.Update(ctx, options.SetOptions{}, apiv3.KindGlobalNetworkSet, gns)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		out := get("networkset-1")
+		Expect(out.Spec.Nets).To(Equal([]string{"10.0.0.0/24"}))
+
+		out = get("networkset-1")
+		Expect(be.gets).To(Equal(2))
+		Expect(out.Spec.Nets).To(Equal([]string{"192.168.0.0/16"}))
+	})
+
+	It("should not return a cached resource once the client is closed", func() {
+		closable := newClosableBackend(be)
+		cb = newCachingBackend(closable, CacheConfig{TTL: time.Minute}, log.NewEntry(log.StandardLogger()))
+		cb.closed = closable.isClosed
+		r = newResources(cb, nil, log.NewEntry(log.StandardLogger()))
+		get("networkset-1")
+
+		Expect(closable.Close()).NotTo(HaveOccurred())
+		_, err := r.Get(ctx, options.GetOptions{}, apiv3.KindGlobalNetworkSet, "", "networkset-1")
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorClientClosed{}))
+		Expect(be.gets).To(Equal(1))
+	})
+
+	It("should evict the least recently used resource when the cache is full", func() {
+		get("networkset-1")
+		get("networkset-2")
+		Expect(be.gets).To(Equal(2))
+
+		get("networkset-2")
+		Expect(be.gets).To(Equal(2))
+		get("networkset-1")
+		Expect(be.gets).To(Equal(3))
+	})
+})
@@ -28,11 +28,13 @@ import (
 	"github.com/projectcalico/libcalico-go/lib/apis/v3"
 	"github.com/projectcalico/libcalico-go/lib/backend"
 	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
 	"github.com/projectcalico/libcalico-go/lib/ipam"
 	"github.com/projectcalico/libcalico-go/lib/net"
 	"github.com/projectcalico/libcalico-go/lib/options"
 	"github.com/projectcalico/libcalico-go/lib/set"
+	validator "github.com/projectcalico/libcalico-go/lib/validator/v3"
 	"github.com/satori/go.uuid"
 )
 
@@ -46,20 +48,117 @@ type client struct {
 
 	// The resources client used internally.
 	resources resourceInterface
+
+	// The logger used for all client logging.
+	logger *log.Entry
+
+	// The retry configuration for resource operations, or nil if not retrying.
+	retry *RetryConfig
+
+	// The prefix used to derive a WorkloadEndpoint interface name if one is not specified,
+	// or empty if interface names are not derived.
+	wepInterfacePrefix string
+
+	// The limits on the size of policies and profiles, or nil if not limited.
+	policyLimits *PolicyLimits
+
+	// The configuration of the resource Get cache, or nil if not caching.
+	cache *CacheConfig
+
+	// Whether policies are rejected if they have rules for directions not in their Types.
+	strictPolicyTypes bool
+
+	// Whether WorkloadEndpoints are rejected if they reference Profiles that do not exist.
+	validateProfileReferences bool
+}
+
+// Option is an optional setting applied to the client by New.
+type Option func(*client)
+
+// WithLogger sets the logger used by the client.  This allows the caller to add their own
+// fields (such as request IDs) to the client logs, and to control the log level and output.
+// If not specified, the client logs using the logrus standard logger.
+func WithLogger(logger *log.Entry) Option {
+	return func(c *client) {
+		c.logger = logger
+	}
+}
+
+// WithWorkloadEndpointInterfacePrefix configures the client to derive the InterfaceName of
+// a WorkloadEndpoint that is created without one.  The name is the prefix followed by a hash
+// of the endpoint namespace and workload, truncated to the maximum interface name length.
+// This matches the naming convention of the Calico CNI plugin when using the prefix "cali".
+func WithWorkloadEndpointInterfacePrefix(prefix string) Option {
+	return func(c *client) {
+		c.wepInterfacePrefix = prefix
+	}
+}
+
+// WithStrictPolicyTypes configures the client to reject a GlobalNetworkPolicy or NetworkPolicy
+// that specifies Types but has rules for a direction not included in the Types, for example
+// an Ingress-only policy with Egress rules.  By default such policies are accepted and the
+// rules for the other direction are ignored.
+func WithStrictPolicyTypes() Option {
+	return func(c *client) {
+		c.strictPolicyTypes = true
+	}
+}
+
+// WithProfileReferenceValidation configures the client to reject a WorkloadEndpoint that
+// references a Profile that does not exist when the endpoint is created or updated.  By
+// default the references are not checked, so that endpoints may be created before the profiles
+// they reference.
+func WithProfileReferenceValidation() Option {
+	return func(c *client) {
+		c.validateProfileReferences = true
+	}
+}
+
+// validatePolicyTypes checks the policy rules against the policy Types if the client was
+// configured using WithStrictPolicyTypes.
+func (c client) validatePolicyTypes(kind string, types []v3.PolicyType, ingress, egress []v3.Rule) error {
+	if !c.strictPolicyTypes {
+		return nil
+	}
+	return validator.ValidatePolicyTypes(kind, types, ingress, egress)
 }
 
 // New returns a connected client. The ClientConfig can either be created explicitly,
 // or can be loaded from a config file or environment variables using the LoadClientConfig() function.
-func New(config apiconfig.CalicoAPIConfig) (Interface, error) {
+func New(config apiconfig.CalicoAPIConfig, opts ...Option) (Interface, error) {
 	be, err := backend.NewClient(config)
 	if err != nil {
 		return nil, err
 	}
-	return client{
-		config:    config,
-		backend:   be,
-		resources: &resources{backend: be},
-	}, nil
+	closable := newClosableBackend(be)
+	c := client{
+		config:  config,
+		backend: closable,
+		logger:  log.NewEntry(log.StandardLogger()),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	be = c.backend
+	if c.cache != nil {
+		if c.cache.TTL <= 0 {
+			return nil, fmt.Errorf("the cache TTL must be greater than zero, got %v", c.cache.TTL)
+		}
+		cb := newCachingBackend(be, *c.cache, c.logger)
+		cb.closed = closable.isClosed
+		be = cb
+	}
+	c.resources = newResources(be, c.retry, c.logger)
+	return c, nil
+}
+
+// newResources returns the resources client used internally, wrapping the backend client
+// to retry transient errors if configured.
+func newResources(be bapi.Client, retry *RetryConfig, logger *log.Entry) *resources {
+	if retry != nil {
+		be = newRetryingBackend(be, *retry, logger)
+	}
+	return &resources{backend: be, logger: logger}
 }
 
 // NewFromEnv loads the config from ENV variables and returns a connected client.
@@ -73,6 +172,20 @@ func NewFromEnv() (Interface, error) {
 	return New(*config)
 }
 
+// NewFromConfig resolves the config from the specified file (if specified), the environment
+// or the in-cluster service account, and returns a connected client for the datastore type
+// chosen.  See apiconfig.ResolveClientConfig for the order in which the config sources and
+// datastore types are chosen.  The returned client presents the same resource interfaces
+// for each datastore type.
+func NewFromConfig(filename string, opts ...Option) (Interface, error) {
+	config, datastoreType, err := apiconfig.ResolveClientConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+	log.WithField("DatastoreType", datastoreType).Debug("Creating client for resolved config")
+	return New(*config, opts...)
+}
+
 // Nodes returns an interface for managing node resources.
 func (c client) Nodes() NodeInterface {
 	return nodes{client: c}
@@ -143,27 +256,39 @@ type poolAccessor struct {
 }
 
 func (p poolAccessor) GetEnabledPools(ipVersion int) ([]net.IPNet, error) {
-	pools, err := p.client.IPPools().List(context.Background(), options.ListOptions{})
+	pools, err := ListIPAMEligiblePools(context.Background(), p.client.IPPools(), ipVersion)
 	if err != nil {
 		return nil, err
 	}
-	log.Debugf("Got list of all IPPools: %v", pools)
 	enabled := []net.IPNet{}
-	for _, pool := range pools.Items {
-		if pool.Spec.Disabled {
-			continue
-		} else if _, cidr, err := net.ParseCIDR(pool.Spec.CIDR); err == nil && cidr.Version() == ipVersion {
-			log.Debugf("Adding pool (%s) to the enabled IPPool list", cidr.String())
-			enabled = append(enabled, *cidr)
-		} else if err != nil {
-			log.Warnf("Failed to parse the IPPool: %s. Ignoring that IPPool", pool.Spec.CIDR)
-		} else {
-			log.Debugf("Ignoring IPPool: %s. IP version is different.", pool.Spec.CIDR)
-		}
+	for _, pool := range pools {
+		// The CIDR has already been validated.
+		_, cidr, _ := net.ParseCIDR(pool.Spec.CIDR)
+		p.client.logger.Debugf("Adding pool (%s) to the enabled IPPool list", cidr.String())
+		enabled = append(enabled, *cidr)
 	}
 	return enabled, nil
 }
 
+func (p poolAccessor) GetReservedCIDRs(ipVersion int) ([]net.IPNet, error) {
+	pools, err := ListIPAMEligiblePools(context.Background(), p.client.IPPools(), ipVersion)
+	if err != nil {
+		return nil, err
+	}
+	reserved := []net.IPNet{}
+	for _, pool := range pools {
+		for _, r := range pool.Spec.ReservedCIDRs {
+			_, cidr, err := net.ParseCIDR(r)
+			if err != nil {
+				p.client.logger.WithField("Name", pool.Name).Warnf("Failed to parse the IPPool reserved CIDR: %s. Ignoring that CIDR", r)
+				continue
+			}
+			reserved = append(reserved, *cidr)
+		}
+	}
+	return reserved, nil
+}
+
 // EnsureInitialized is used to ensure the backend datastore is correctly
 // initialized for use by Calico.  This method may be called multiple times, and
 // will have no effect if the datastore is already correctly initialized.
@@ -184,6 +309,30 @@ func (c client) EnsureInitialized(ctx context.Context, calicoVersion, clusterTyp
 	return nil
 }
 
+// Ping checks that the backend datastore is reachable, that the client is authorized to
+// access it, and that it has been initialized, by getting the global ClusterInformation from
+// the backend.  This is a lightweight check that a consumer may use to fail fast at startup.
+//
+// Returns an ErrorDatastoreUnreachable if the datastore could not be reached, an
+// ErrorConnectionUnauthorized if the client is not authorized, and an
+// ErrorDatastoreUninitialized if the global ClusterInformation does not exist.
+func (c client) Ping(ctx context.Context) error {
+	key := model.ResourceKey{Kind: v3.KindClusterInformation, Name: globalClusterInfoName}
+	_, err := c.backend.Get(ctx, key, "")
+	switch err.(type) {
+	case nil:
+		return nil
+	case cerrors.ErrorResourceDoesNotExist:
+		c.logger.Info("Datastore is not initialized")
+		return cerrors.ErrorDatastoreUninitialized{Identifier: key}
+	case cerrors.ErrorConnectionUnauthorized, cerrors.ErrorClientClosed:
+		c.logger.WithError(err).Info("Unable to access datastore")
+		return err
+	}
+	c.logger.WithError(err).Info("Unable to reach datastore")
+	return cerrors.ErrorDatastoreUnreachable{Err: err}
+}
+
 const globalClusterInfoName = "default"
 
 // ensureClusterInformation ensures that the ClusterInformation fields i.e. ClusterType,
@@ -221,15 +370,15 @@ func (c client) ensureClusterInformation(ctx context.Context, calicoVersion, clu
 				_, err = c.ClusterInformation().Create(ctx, newClusterInfo, options.SetOptions{})
 				if err != nil {
 					if _, ok := err.(cerrors.ErrorResourceAlreadyExists); ok {
-						log.Info("Failed to create global ClusterInformation; another node got there first.")
+						c.logger.Info("Failed to create global ClusterInformation; another node got there first.")
 						time.Sleep(1 * time.Second)
 						continue
 					}
-					log.WithError(err).WithField("ClusterInformation", newClusterInfo).Errorf("Error creating cluster information config")
+					c.logger.WithError(err).WithField("ClusterInformation", newClusterInfo).Errorf("Error creating cluster information config")
 					return err
 				}
 			} else {
-				log.WithError(err).WithField("ClusterInformation", globalClusterInfoName).Errorf("Error getting cluster information config")
+				c.logger.WithError(err).WithField("ClusterInformation", globalClusterInfoName).Errorf("Error getting cluster information config")
 				return err
 			}
 			break
@@ -242,7 +391,7 @@ func (c client) ensureClusterInformation(ctx context.Context, calicoVersion, clu
 				clusterInfo.Spec.CalicoVersion = calicoVersion
 				updateNeeded = true
 			} else {
-				log.WithField("CalicoVersion", clusterInfo.Spec.CalicoVersion).Debug("Calico version value already assigned")
+				c.logger.WithField("CalicoVersion", clusterInfo.Spec.CalicoVersion).Debug("Calico version value already assigned")
 			}
 		}
 
@@ -250,7 +399,7 @@ func (c client) ensureClusterInformation(ctx context.Context, calicoVersion, clu
 			clusterInfo.Spec.ClusterGUID = fmt.Sprintf("%s", hex.EncodeToString(uuid.NewV4().Bytes()))
 			updateNeeded = true
 		} else {
-			log.WithField("ClusterGUID", clusterInfo.Spec.ClusterGUID).Debug("Cluster GUID value already set")
+			c.logger.WithField("ClusterGUID", clusterInfo.Spec.ClusterGUID).Debug("Cluster GUID value already set")
 		}
 
 		if clusterInfo.Spec.DatastoreReady == nil {
@@ -260,7 +409,7 @@ func (c client) ensureClusterInformation(ctx context.Context, calicoVersion, clu
 			clusterInfo.Spec.DatastoreReady = &datastoreReady
 			updateNeeded = true
 		} else {
-			log.WithField("DatastoreReady", clusterInfo.Spec.DatastoreReady).Debug("DatastoreReady value already set")
+			c.logger.WithField("DatastoreReady", clusterInfo.Spec.DatastoreReady).Debug("DatastoreReady value already set")
 		}
 
 		if clusterType != "" {
@@ -292,12 +441,12 @@ func (c client) ensureClusterInformation(ctx context.Context, calicoVersion, clu
 		if updateNeeded {
 			_, err = c.ClusterInformation().Update(ctx, clusterInfo, options.SetOptions{})
 			if _, ok := err.(cerrors.ErrorResourceUpdateConflict); ok {
-				log.WithError(err).WithField("ClusterInformation", clusterInfo).Warning(
+				c.logger.WithError(err).WithField("ClusterInformation", clusterInfo).Warning(
 					"Conflict while updating cluster information, may retry")
 				time.Sleep(1 * time.Second)
 				continue
 			} else if err != nil {
-				log.WithError(err).WithField("ClusterInformation", clusterInfo).Errorf(
+				c.logger.WithError(err).WithField("ClusterInformation", clusterInfo).Errorf(
 					"Error updating cluster information")
 				return err
 			}
@@ -308,6 +457,12 @@ func (c client) ensureClusterInformation(ctx context.Context, calicoVersion, clu
 	return nil
 }
 
+// Close releases the backend datastore connections and stops any active watches.  All
+// subsequent operations on the client return an ErrorClientClosed.
+func (c client) Close() error {
+	return c.backend.Close()
+}
+
 // Backend returns the backend client used by the v3 client.  Not exposed on the main
 // client API, but available publicly for consumers that require access to the backend
 // client (e.g. for syncer support).
@@ -0,0 +1,95 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend"
+	"github.com/projectcalico/libcalico-go/lib/clientv3"
+	"github.com/projectcalico/libcalico-go/lib/options"
+	"github.com/projectcalico/libcalico-go/lib/testutils"
+)
+
+var _ = testutils.E2eDatastoreDescribe("Client from config file tests", testutils.DatastoreAll, func(config apiconfig.CalicoAPIConfig) {
+
+	ctx := context.Background()
+	var filename string
+
+	BeforeEach(func() {
+		be, err := backend.NewClient(config)
+		Expect(err).NotTo(HaveOccurred())
+		be.Clean()
+
+		f, err := ioutil.TempFile("", "calico-config")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = fmt.Fprintf(f, `apiVersion: projectcalico.org/v3
+kind: CalicoAPIConfig
+spec:
+  datastoreType: %s
+  etcdEndpoints: %s
+  k8sAPIEndpoint: %s
+`, config.Spec.DatastoreType, config.Spec.EtcdEndpoints, config.Spec.K8sAPIEndpoint)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+		filename = f.Name()
+	})
+
+	AfterEach(func() {
+		os.Remove(filename)
+	})
+
+	It("should create a client for the configured datastore and round-trip an IPPool", func() {
+		c, err := clientv3.NewFromConfig(filename)
+		Expect(err).NotTo(HaveOccurred())
+		defer c.Close()
+
+		spec := apiv3.IPPoolSpec{
+			CIDR:        "1.2.3.0/24",
+			IPIPMode:    apiv3.IPIPModeAlways,
+			NATOutgoing: true,
+		}
+
+		By("Creating an IPPool")
+		created, err := c.IPPools().Create(ctx, &apiv3.IPPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "ippool-1"},
+			Spec:       spec,
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		testutils.ExpectResource(created, apiv3.KindIPPool, testutils.ExpectNoNamespace, "ippool-1", spec)
+
+		By("Getting the IPPool")
+		got, err := c.IPPools().Get(ctx, "ippool-1", options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		testutils.ExpectResource(got, apiv3.KindIPPool, testutils.ExpectNoNamespace, "ippool-1", spec)
+		Expect(got.ResourceVersion).To(Equal(created.ResourceVersion))
+		Expect(got.UID).To(Equal(created.UID))
+
+		By("Deleting the IPPool")
+		_, err = c.IPPools().Delete(ctx, "ippool-1", options.DeleteOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.IPPools().Get(ctx, "ippool-1", options.GetOptions{})
+		testutils.ExpectResourceDoesNotExist(err, apiv3.KindIPPool, testutils.ExpectNoNamespace, "ippool-1")
+	})
+})
@@ -0,0 +1,203 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"sync"
+
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+// closableBackend wraps a backend client, tracking the active watches so that they can be
+// stopped when the client is closed, and failing all operations once the client is closed.
+type closableBackend struct {
+	bapi.Client
+	lock    sync.Mutex
+	closed  bool
+	watches map[*closableWatch]bool
+}
+
+func newClosableBackend(be bapi.Client) *closableBackend {
+	return &closableBackend{Client: be, watches: map[*closableWatch]bool{}}
+}
+
+// Close stops all active watches and closes the backend client.  Returns an
+// ErrorClientClosed if the client is already closed.
+func (c *closableBackend) Close() error {
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return cerrors.ErrorClientClosed{}
+	}
+	c.closed = true
+	watches := c.watches
+	c.watches = nil
+	c.lock.Unlock()
+
+	for w := range watches {
+		w.Stop()
+	}
+	return c.Client.Close()
+}
+
+func (c *closableBackend) isClosed() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.closed
+}
+
+func (c *closableBackend) Create(ctx context.Context, object *model.KVPair) (*model.KVPair, error) {
+	if c.isClosed() {
+		return nil, cerrors.ErrorClientClosed{}
+	}
+	return c.Client.Create(ctx, object)
+}
+
+func (c *closableBackend) Update(ctx context.Context, object *model.KVPair) (*model.KVPair, error) {
+	if c.isClosed() {
+		return nil, cerrors.ErrorClientClosed{}
+	}
+	return c.Client.Update(ctx, object)
+}
+
+func (c *closableBackend) Apply(ctx context.Context, object *model.KVPair) (*model.KVPair, error) {
+	if c.isClosed() {
+		return nil, cerrors.ErrorClientClosed{}
+	}
+	return c.Client.Apply(ctx, object)
+}
+
+func (c *closableBackend) Delete(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	if c.isClosed() {
+		return nil, cerrors.ErrorClientClosed{}
+	}
+	return c.Client.Delete(ctx, key, revision)
+}
+
+func (c *closableBackend) Get(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	if c.isClosed() {
+		return nil, cerrors.ErrorClientClosed{}
+	}
+	return c.Client.Get(ctx, key, revision)
+}
+
+func (c *closableBackend) Exists(ctx context.Context, key model.Key, revision string) (bool, error) {
+	if c.isClosed() {
+		return false, cerrors.ErrorClientClosed{}
+	}
+	return c.Client.Exists(ctx, key, revision)
+}
+
+func (c *closableBackend) List(ctx context.Context, list model.ListInterface, revision string) (*model.KVPairList, error) {
+	if c.isClosed() {
+		return nil, cerrors.ErrorClientClosed{}
+	}
+	return c.Client.List(ctx, list, revision)
+}
+
+func (c *closableBackend) Count(ctx context.Context, list model.ListInterface, revision string) (int, error) {
+	if c.isClosed() {
+		return 0, cerrors.ErrorClientClosed{}
+	}
+	return c.Client.Count(ctx, list, revision)
+}
+
+func (c *closableBackend) EnsureInitialized() error {
+	if c.isClosed() {
+		return cerrors.ErrorClientClosed{}
+	}
+	return c.Client.EnsureInitialized()
+}
+
+func (c *closableBackend) Clean() error {
+	if c.isClosed() {
+		return cerrors.ErrorClientClosed{}
+	}
+	return c.Client.Clean()
+}
+
+// Watch starts a watch on the backend client and tracks it until it is stopped or terminates,
+// so that it can be stopped if the client is closed.  The backend watch is started without
+// holding the lock, so the client may have been closed in the meantime, in which case the
+// watch is stopped.
+func (c *closableBackend) Watch(ctx context.Context, list model.ListInterface, revision string) (bapi.WatchInterface, error) {
+	if c.isClosed() {
+		return nil, cerrors.ErrorClientClosed{}
+	}
+	w, err := c.Client.Watch(ctx, list, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		w.Stop()
+		return nil, cerrors.ErrorClientClosed{}
+	}
+	cw := &closableWatch{
+		WatchInterface: w,
+		backend:        c,
+		results:        make(chan bapi.WatchEvent),
+		done:           make(chan struct{}),
+	}
+	c.watches[cw] = true
+	go cw.run()
+	return cw, nil
+}
+
+// untrack removes the watch from the tracked watches.
+func (c *closableBackend) untrack(w *closableWatch) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.watches, w)
+}
+
+// closableWatch wraps a backend watch, removing it from the tracked watches when it is
+// stopped or its results channel is closed.
+type closableWatch struct {
+	bapi.WatchInterface
+	backend  *closableBackend
+	results  chan bapi.WatchEvent
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (w *closableWatch) run() {
+	defer close(w.results)
+	defer w.backend.untrack(w)
+	for event := range w.WatchInterface.ResultChan() {
+		select {
+		case w.results <- event:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *closableWatch) ResultChan() <-chan bapi.WatchEvent {
+	return w.results
+}
+
+func (w *closableWatch) Stop() {
+	w.backend.untrack(w)
+	w.stopOnce.Do(func() {
+		close(w.done)
+	})
+	w.WatchInterface.Stop()
+}
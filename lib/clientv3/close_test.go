@@ -0,0 +1,174 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+// closeTrackingBackend implements the Get, Watch and Close methods of the backend client,
+// recording whether the client and its watches have been closed.  If set, onWatch is called
+// when a watch is started.  All other methods panic.
+type closeTrackingBackend struct {
+	bapi.Client
+	closed  bool
+	watches []*closeTrackingWatch
+	onWatch func()
+}
+
+func (b *closeTrackingBackend) Get(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	return nil, cerrors.ErrorResourceDoesNotExist{Identifier: key}
+}
+
+func (b *closeTrackingBackend) Watch(ctx context.Context, list model.ListInterface, revision string) (bapi.WatchInterface, error) {
+	w := &closeTrackingWatch{results: make(chan bapi.WatchEvent)}
+	b.watches = append(b.watches, w)
+	if b.onWatch != nil {
+		b.onWatch()
+	}
+	return w, nil
+}
+
+func (b *closeTrackingBackend) Close() error {
+	b.closed = true
+	return nil
+}
+
+type closeTrackingWatch struct {
+	lock    sync.Mutex
+	results chan bapi.WatchEvent
+	stopped bool
+}
+
+func (w *closeTrackingWatch) Stop() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if !w.stopped {
+		w.stopped = true
+		close(w.results)
+	}
+}
+
+func (w *closeTrackingWatch) ResultChan() <-chan bapi.WatchEvent {
+	return w.results
+}
+
+func (w *closeTrackingWatch) HasTerminated() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.stopped
+}
+
+var _ = Describe("Client close tests", func() {
+	ctx := context.Background()
+	var be *closeTrackingBackend
+	var cb *closableBackend
+	var c client
+
+	trackedWatches := func() int {
+		cb.lock.Lock()
+		defer cb.lock.Unlock()
+		return len(cb.watches)
+	}
+
+	BeforeEach(func() {
+		be = &closeTrackingBackend{}
+		logger := log.NewEntry(log.StandardLogger())
+		cb = newClosableBackend(be)
+		c = client{
+			backend:   cb,
+			resources: newResources(cb, nil, logger),
+			logger:    logger,
+		}
+	})
+
+	It("should close the backend and stop active watches", func() {
+		w, err := c.IPPools().Watch(ctx, options.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(be.watches).To(HaveLen(1))
+
+		err = c.Close()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(be.closed).To(BeTrue())
+		Expect(be.watches[0].HasTerminated()).To(BeTrue())
+		Eventually(w.ResultChan()).Should(BeClosed())
+	})
+
+	It("should stop tracking a watch when it is stopped or terminates", func() {
+		w1, err := c.IPPools().Watch(ctx, options.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.IPPools().Watch(ctx, options.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trackedWatches()).To(Equal(2))
+
+		By("Stopping the first watch")
+		w1.Stop()
+		Expect(trackedWatches()).To(Equal(1))
+
+		By("Terminating the second watch in the backend")
+		be.watches[1].Stop()
+		Eventually(trackedWatches).Should(Equal(0))
+	})
+
+	It("should return a client closed error for operations after close", func() {
+		_, err := c.IPPools().Get(ctx, "pool1", options.GetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}))
+
+		err = c.Close()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = c.IPPools().Get(ctx, "pool1", options.GetOptions{})
+		Expect(err).To(Equal(cerrors.ErrorClientClosed{}))
+		Expect(err.Error()).To(Equal("client closed"))
+
+		_, err = c.IPPools().Watch(ctx, options.ListOptions{})
+		Expect(err).To(Equal(cerrors.ErrorClientClosed{}))
+
+		err = c.Close()
+		Expect(err).To(Equal(cerrors.ErrorClientClosed{}))
+	})
+
+	It("should stop the tracked watches on close so that they no longer send events", func() {
+		w, err := cb.Watch(ctx, model.ResourceListOptions{}, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = cb.Close()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.(*closableWatch).done).To(BeClosed())
+		Expect(be.watches[0].HasTerminated()).To(BeTrue())
+		Eventually(w.ResultChan()).Should(BeClosed())
+	})
+
+	It("should stop a watch that is started while the client is being closed", func() {
+		be.onWatch = func() {
+			Expect(cb.Close()).NotTo(HaveOccurred())
+		}
+		_, err := cb.Watch(ctx, model.ResourceListOptions{}, "")
+		Expect(err).To(Equal(cerrors.ErrorClientClosed{}))
+		Expect(be.closed).To(BeTrue())
+		Expect(be.watches[0].HasTerminated()).To(BeTrue())
+		Expect(trackedWatches()).To(Equal(0))
+	})
+})
@@ -346,12 +346,10 @@ var _ = testutils.E2eDatastoreDescribe("ClusterInformation tests", testutils.Dat
 			Expect(outList.Items).To(HaveLen(1))
 			testutils.ExpectResource(&outList.Items[0], apiv3.KindClusterInformation, testutils.ExpectNoNamespace, name, spec2)
 
-			if config.Spec.DatastoreType != apiconfig.Kubernetes {
-				By("Deleting ClusterInformation (name) with the old resource version")
-				_, outError = c.ClusterInformation().Delete(ctx, name, options.DeleteOptions{ResourceVersion: rv1_1})
-				Expect(outError).To(HaveOccurred())
-				Expect(outError.Error()).To(Equal("update conflict: ClusterInformation(" + name + ")"))
-			}
+			By("Deleting ClusterInformation (name) with the old resource version")
+			_, outError = c.ClusterInformation().Delete(ctx, name, options.DeleteOptions{ResourceVersion: rv1_1})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("update conflict: ClusterInformation(" + name + ")"))
 
 			By("Deleting ClusterInformation (name) with the new resource version")
 			dres, outError := c.ClusterInformation().Delete(ctx, name, options.DeleteOptions{ResourceVersion: rv1_2})
@@ -15,10 +15,12 @@
 package clientv3_test
 
 import (
+	"bytes"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"context"
@@ -76,4 +78,33 @@ var _ = testutils.E2eDatastoreDescribe("Common resource tests", testutils.Datast
 			testutils.ExpectResource(dres, apiv3.KindIPPool, testutils.ExpectNoNamespace, name1, spec1)
 		})
 	})
+
+	Describe("Client logger", func() {
+		It("Should log through the logger supplied using WithLogger", func() {
+			ctx := context.Background()
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			By("Creating a client with a custom logger")
+			buf := &bytes.Buffer{}
+			logger := log.New()
+			logger.Out = buf
+			logger.Level = log.DebugLevel
+			c, err := clientv3.New(config, clientv3.WithLogger(logger.WithField("RequestID", "req-1234")))
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a resource and checking the custom logger received the Create log")
+			_, err = c.GlobalNetworkSets().Create(ctx, &apiv3.GlobalNetworkSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "networkset-1"},
+				Spec: apiv3.GlobalNetworkSetSpec{
+					Nets: []string{"10.0.0.0/16"},
+				},
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(ContainSubstring("Creating resource"))
+			Expect(buf.String()).To(ContainSubstring("RequestID=req-1234"))
+			Expect(buf.String()).To(ContainSubstring("Name=networkset-1"))
+		})
+	})
 })
@@ -0,0 +1,57 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+// countedKinds are the resource kinds included in the ResourceCounts.
+var countedKinds = []string{
+	apiv3.KindBGPConfiguration,
+	apiv3.KindBGPPeer,
+	apiv3.KindClusterInformation,
+	apiv3.KindFelixConfiguration,
+	apiv3.KindGlobalNetworkPolicy,
+	apiv3.KindGlobalNetworkSet,
+	apiv3.KindHostEndpoint,
+	apiv3.KindIPPool,
+	apiv3.KindNetworkPolicy,
+	apiv3.KindNode,
+	apiv3.KindProfile,
+	apiv3.KindWorkloadEndpoint,
+}
+
+// ResourceCounts returns the number of resources of each kind, keyed by kind.  The resources
+// are counted without fetching their values where the datastore supports it.  Kinds that are
+// not supported by the datastore are omitted.
+func (c client) ResourceCounts(ctx context.Context) (map[string]int, error) {
+	counts := make(map[string]int, len(countedKinds))
+	for _, kind := range countedKinds {
+		n, err := c.resources.Count(ctx, options.ListOptions{}, kind)
+		if _, ok := err.(cerrors.ErrorOperationNotSupported); ok {
+			c.logger.WithField("Kind", kind).Debug("Datastore does not support kind - not counted")
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		counts[kind] = n
+	}
+	return counts, nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+// seededBackend implements the Count method of the backend client, counting the seeded keys
+// of the requested kind.  Kinds in the unsupported set return an ErrorOperationNotSupported.
+// All other methods panic.
+type seededBackend struct {
+	bapi.Client
+	keys        []model.ResourceKey
+	unsupported map[string]bool
+	err         error
+}
+
+func (b *seededBackend) Count(ctx context.Context, list model.ListInterface, revision string) (int, error) {
+	rlo := list.(model.ResourceListOptions)
+	if b.unsupported[rlo.Kind] {
+		return 0, cerrors.ErrorOperationNotSupported{Identifier: list, Operation: "Count"}
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	n := 0
+	for _, k := range b.keys {
+		if k.Kind == rlo.Kind {
+			n++
+		}
+	}
+	return n, nil
+}
+
+var _ = Describe("Resource count tests", func() {
+	ctx := context.Background()
+	var be *seededBackend
+	var c client
+
+	BeforeEach(func() {
+		be = &seededBackend{keys: []model.ResourceKey{
+			{Kind: apiv3.KindWorkloadEndpoint, Namespace: "ns1", Name: "wep-1"},
+			{Kind: apiv3.KindWorkloadEndpoint, Namespace: "ns1", Name: "wep-2"},
+			{Kind: apiv3.KindWorkloadEndpoint, Namespace: "ns2", Name: "wep-1"},
+			{Kind: apiv3.KindIPPool, Name: "pool-1"},
+			{Kind: apiv3.KindGlobalNetworkPolicy, Name: "policy-1"},
+			{Kind: apiv3.KindNetworkPolicy, Namespace: "ns1", Name: "policy-1"},
+			{Kind: apiv3.KindNetworkPolicy, Namespace: "ns2", Name: "policy-1"},
+		}}
+		logger := log.NewEntry(log.StandardLogger())
+		c = client{
+			backend:   be,
+			resources: newResources(be, nil, logger),
+			logger:    logger,
+		}
+	})
+
+	It("should return the count of each kind", func() {
+		counts, err := c.ResourceCounts(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counts).To(HaveLen(len(countedKinds)))
+		Expect(counts[apiv3.KindWorkloadEndpoint]).To(Equal(3))
+		Expect(counts[apiv3.KindIPPool]).To(Equal(1))
+		Expect(counts[apiv3.KindGlobalNetworkPolicy]).To(Equal(1))
+		Expect(counts[apiv3.KindNetworkPolicy]).To(Equal(2))
+		Expect(counts).To(HaveKeyWithValue(apiv3.KindHostEndpoint, 0))
+	})
+
+	It("should omit kinds that are not supported by the datastore", func() {
+		be.unsupported = map[string]bool{apiv3.KindHostEndpoint: true, apiv3.KindGlobalNetworkSet: true}
+		counts, err := c.ResourceCounts(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(counts).To(HaveLen(len(countedKinds) - 2))
+		Expect(counts).NotTo(HaveKey(apiv3.KindHostEndpoint))
+		Expect(counts).NotTo(HaveKey(apiv3.KindGlobalNetworkSet))
+		Expect(counts[apiv3.KindWorkloadEndpoint]).To(Equal(3))
+	})
+
+	It("should return other datastore errors", func() {
+		be.err = cerrors.ErrorDatastoreError{Err: errors.New("etcdserver: request timed out")}
+		_, err := c.ResourceCounts(ctx)
+		Expect(err).To(Equal(be.err))
+	})
+})
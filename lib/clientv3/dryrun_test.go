@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+// countingBackend implements the Create and Update methods of the backend client, counting
+// the number of calls, and a List method that returns no results.  All other methods panic.
+type countingBackend struct {
+	bapi.Client
+	calls int
+}
+
+func (b *countingBackend) Create(ctx context.Context, object *model.KVPair) (*model.KVPair, error) {
+	b.calls++
+	return object, nil
+}
+
+func (b *countingBackend) Update(ctx context.Context, object *model.KVPair) (*model.KVPair, error) {
+	b.calls++
+	return object, nil
+}
+
+func (b *countingBackend) List(ctx context.Context, list model.ListInterface, revision string) (*model.KVPairList, error) {
+	return &model.KVPairList{}, nil
+}
+
+var _ = Describe("Client dry run tests", func() {
+	ctx := context.Background()
+	var be *countingBackend
+	var c client
+
+	BeforeEach(func() {
+		be = &countingBackend{}
+		logger := log.NewEntry(log.StandardLogger())
+		c = client{
+			backend:   be,
+			resources: newResources(be, nil, logger),
+			logger:    logger,
+		}
+	})
+
+	spec := apiv3.WorkloadEndpointSpec{
+		Node:          "node-1",
+		Orchestrator:  "k8s",
+		Pod:           "abcdef",
+		Endpoint:      "eth0",
+		InterfaceName: "cali09123",
+	}
+
+	It("should validate a dry run Create and not call the backend", func() {
+		badSpec := spec
+		badSpec.InterfaceName = "not a valid interface"
+		_, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace-1"},
+			Spec:       badSpec,
+		}, options.SetOptions{DryRun: true})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		Expect(be.calls).To(Equal(0))
+	})
+
+	It("should return the resource that would be written for a dry run Create", func() {
+		out, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace-1"},
+			Spec:       spec,
+		}, options.SetOptions{DryRun: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Name).To(Equal("node--1-k8s-abcdef-eth0"))
+		Expect(out.Labels).To(HaveKeyWithValue(apiv3.LabelOrchestrator, "k8s"))
+		Expect(out.UID).NotTo(BeEmpty())
+		Expect(be.calls).To(Equal(0))
+	})
+
+	It("should call the backend when not a dry run", func() {
+		_, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace-1"},
+			Spec:       spec,
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(be.calls).To(Equal(1))
+	})
+})
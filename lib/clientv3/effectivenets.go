@@ -0,0 +1,165 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"fmt"
+	"sort"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/selector"
+)
+
+// EntityRuleNets contains the networks matched by the source or destination of a rule.
+type EntityRuleNets struct {
+	// All is true if the entity rule has neither Nets nor a Selector, in which case it matches
+	// all addresses other than the NotNets.
+	All bool
+	// The networks matched by both the Nets and the Selector of the entity rule.  If only one
+	// of them is specified, these are the networks matched by that field.
+	Nets []cnet.IPNet
+	// The networks excluded by the NotNets and the NotSelector of the entity rule.
+	NotNets []cnet.IPNet
+}
+
+// EffectiveNets returns the networks matched by the source or destination of a rule, resolving
+// its Selector and NotSelector to the addresses of the supplied workload endpoints, host
+// endpoints and global network sets whose labels they match.  This may be used to show the
+// concrete addresses matched by a rule, for example after tags were converted to selectors.
+//
+// The selectors are evaluated against the labels of every supplied endpoint and network set,
+// so for a NetworkPolicy the caller should only supply the resources in the namespace of the
+// policy.  Entity rules with a NamespaceSelector or ServiceAccounts are not supported, since
+// they require the namespace and service account labels.  Ports are ignored.  This does not
+// access the datastore, the resources are supplied by the caller.
+func EffectiveNets(
+	er apiv3.EntityRule, weps []apiv3.WorkloadEndpoint, heps []apiv3.HostEndpoint, gnss []apiv3.GlobalNetworkSet,
+) (*EntityRuleNets, error) {
+	if er.NamespaceSelector != "" || er.ServiceAccounts != nil {
+		return nil, fmt.Errorf("entity rules with a namespace selector or service accounts are not supported")
+	}
+	nets, err := parseNets(er.Nets)
+	if err != nil {
+		return nil, err
+	}
+	notNets, err := parseNets(er.NotNets)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &EntityRuleNets{All: len(er.Nets) == 0 && er.Selector == ""}
+	switch {
+	case er.Selector == "":
+		res.Nets = nets
+	case len(er.Nets) == 0:
+		if res.Nets, err = selectedNets(er.Selector, weps, heps, gnss); err != nil {
+			return nil, err
+		}
+	default:
+		selected, err := selectedNets(er.Selector, weps, heps, gnss)
+		if err != nil {
+			return nil, err
+		}
+		res.Nets = intersectNets(nets, selected)
+	}
+
+	if er.NotSelector != "" {
+		notSelected, err := selectedNets(er.NotSelector, weps, heps, gnss)
+		if err != nil {
+			return nil, err
+		}
+		notNets = append(notNets, notSelected...)
+	}
+	res.Nets = dedupeNets(res.Nets)
+	res.NotNets = dedupeNets(notNets)
+	return res, nil
+}
+
+// selectedNets returns the networks of the endpoints and network sets matched by the selector.
+// The addresses of host endpoints are returned as single address networks.
+func selectedNets(
+	sel string, weps []apiv3.WorkloadEndpoint, heps []apiv3.HostEndpoint, gnss []apiv3.GlobalNetworkSet,
+) ([]cnet.IPNet, error) {
+	parsed, err := selector.Parse(sel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %v", sel, err)
+	}
+	var addrs []string
+	for _, wep := range weps {
+		if parsed.Evaluate(wep.Labels) {
+			addrs = append(addrs, wep.Spec.IPNetworks...)
+		}
+	}
+	for _, hep := range heps {
+		if parsed.Evaluate(hep.Labels) {
+			addrs = append(addrs, hep.Spec.ExpectedIPs...)
+		}
+	}
+	for _, gns := range gnss {
+		if parsed.Evaluate(gns.Labels) {
+			addrs = append(addrs, gns.Spec.Nets...)
+		}
+	}
+	return parseNets(addrs)
+}
+
+// parseNets parses the CIDRs or IP addresses, returning the networks with the host bits masked.
+func parseNets(addrs []string) ([]cnet.IPNet, error) {
+	nets := []cnet.IPNet{}
+	for _, a := range addrs {
+		_, n, err := cnet.ParseCIDROrIP(a)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, *n)
+	}
+	return nets, nil
+}
+
+// intersectNets returns the networks contained in both lists.  Two CIDRs either do not overlap,
+// or one contains the other, in which case their intersection is the smaller network.
+func intersectNets(a, b []cnet.IPNet) []cnet.IPNet {
+	nets := []cnet.IPNet{}
+	for _, na := range a {
+		for _, nb := range b {
+			if !na.IsNetOverlap(nb.IPNet) {
+				continue
+			}
+			onesA, _ := na.Mask.Size()
+			onesB, _ := nb.Mask.Size()
+			if onesA >= onesB {
+				nets = append(nets, na)
+			} else {
+				nets = append(nets, nb)
+			}
+		}
+	}
+	return nets
+}
+
+// dedupeNets removes the duplicate networks, sorting them by their string representation.
+func dedupeNets(nets []cnet.IPNet) []cnet.IPNet {
+	seen := map[string]bool{}
+	deduped := []cnet.IPNet{}
+	for _, n := range nets {
+		if s := n.String(); !seen[s] {
+			seen[s] = true
+			deduped = append(deduped, n)
+		}
+	}
+	sort.Slice(deduped, func(i, j int) bool { return deduped[i].String() < deduped[j].String() })
+	return deduped
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+var _ = Describe("Effective nets tests", func() {
+	nets := func(cidrs ...string) []cnet.IPNet {
+		out := []cnet.IPNet{}
+		for _, c := range cidrs {
+			out = append(out, cnet.MustParseNetwork(c))
+		}
+		return out
+	}
+
+	weps := []apiv3.WorkloadEndpoint{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "wep1", Labels: map[string]string{"frontend": ""}},
+			Spec:       apiv3.WorkloadEndpointSpec{IPNetworks: []string{"10.0.0.1/32", "fd00::1/128"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "wep2", Labels: map[string]string{"frontend": "", "env": "dev"}},
+			Spec:       apiv3.WorkloadEndpointSpec{IPNetworks: []string{"10.1.0.2/32"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "wep3", Labels: map[string]string{"backend": ""}},
+			Spec:       apiv3.WorkloadEndpointSpec{IPNetworks: []string{"10.0.0.3/32"}},
+		},
+	}
+	heps := []apiv3.HostEndpoint{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "hep1", Labels: map[string]string{"frontend": ""}},
+			Spec:       apiv3.HostEndpointSpec{ExpectedIPs: []string{"192.168.0.1"}},
+		},
+	}
+	gnss := []apiv3.GlobalNetworkSet{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "gns1", Labels: map[string]string{"external": ""}},
+			Spec:       apiv3.GlobalNetworkSetSpec{Nets: []string{"172.16.0.0/12", "10.0.0.0/8"}},
+		},
+	}
+
+	It("should resolve a converted tag selector to the endpoint addresses", func() {
+		res, err := EffectiveNets(apiv3.EntityRule{Selector: "frontend == ''"}, weps, heps, gnss)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res).To(Equal(&EntityRuleNets{
+			Nets:    nets("10.0.0.1/32", "10.1.0.2/32", "192.168.0.1/32", "fd00::1/128"),
+			NotNets: nets(),
+		}))
+	})
+
+	It("should intersect the Nets with the selected addresses", func() {
+		res, err := EffectiveNets(apiv3.EntityRule{
+			Nets:        []string{"10.0.0.0/16", "172.16.1.0/24"},
+			Selector:    "has(frontend) || has(external)",
+			NotNets:     []string{"10.0.0.1"},
+			NotSelector: "env == 'dev'",
+		}, weps, heps, gnss)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.All).To(BeFalse())
+		Expect(res.Nets).To(Equal(nets("10.0.0.0/16", "10.0.0.1/32", "172.16.1.0/24")))
+		Expect(res.NotNets).To(Equal(nets("10.0.0.1/32", "10.1.0.2/32")))
+	})
+
+	It("should return the Nets of a rule without a selector", func() {
+		res, err := EffectiveNets(apiv3.EntityRule{Nets: []string{"10.0.0.0/24", "10.0.0.7/24"}}, weps, heps, gnss)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Nets).To(Equal(nets("10.0.0.0/24")))
+
+		res, err = EffectiveNets(apiv3.EntityRule{}, weps, heps, gnss)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.All).To(BeTrue())
+		Expect(res.Nets).To(BeEmpty())
+	})
+
+	It("should reject unsupported entity rules and invalid selectors", func() {
+		_, err := EffectiveNets(apiv3.EntityRule{NamespaceSelector: "all()"}, weps, heps, gnss)
+		Expect(err).To(HaveOccurred())
+		_, err = EffectiveNets(apiv3.EntityRule{Selector: "foo ="}, weps, heps, gnss)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("invalid selector \"foo =\""))
+	})
+})
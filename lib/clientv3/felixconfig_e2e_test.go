@@ -207,12 +207,10 @@ var _ = testutils.E2eDatastoreDescribe("FelixConfiguration tests", testutils.Dat
 			testutils.ExpectResource(&outList.Items[0], apiv3.KindFelixConfiguration, testutils.ExpectNoNamespace, name1, spec2)
 			testutils.ExpectResource(&outList.Items[1], apiv3.KindFelixConfiguration, testutils.ExpectNoNamespace, name2, spec2)
 
-			if config.Spec.DatastoreType != apiconfig.Kubernetes {
-				By("Deleting FelixConfiguration (name1) with the old resource version")
-				_, outError = c.FelixConfigurations().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
-				Expect(outError).To(HaveOccurred())
-				Expect(outError.Error()).To(Equal("update conflict: FelixConfiguration(" + name1 + ")"))
-			}
+			By("Deleting FelixConfiguration (name1) with the old resource version")
+			_, outError = c.FelixConfigurations().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("update conflict: FelixConfiguration(" + name1 + ")"))
 
 			By("Deleting FelixConfiguration (name1) with the new resource version")
 			dres, outError := c.FelixConfigurations().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_2})
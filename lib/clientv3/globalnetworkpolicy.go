@@ -53,6 +53,12 @@ func (r globalNetworkPolicies) Create(ctx context.Context, res *apiv3.GlobalNetw
 	if err := validator.Validate(res); err != nil {
 		return nil, err
 	}
+	if err := r.client.validatePolicyLimits(apiv3.KindGlobalNetworkPolicy, res.Spec.Selector, res.Spec.Ingress, res.Spec.Egress); err != nil {
+		return nil, err
+	}
+	if err := r.client.validatePolicyTypes(apiv3.KindGlobalNetworkPolicy, res.Spec.Types, res.Spec.Ingress, res.Spec.Egress); err != nil {
+		return nil, err
+	}
 
 	// Properly prefix the name
 	res.GetObjectMeta().SetName(convertPolicyNameForStorage(res.GetObjectMeta().GetName()))
@@ -82,6 +88,12 @@ func (r globalNetworkPolicies) Update(ctx context.Context, res *apiv3.GlobalNetw
 	if err := validator.Validate(res); err != nil {
 		return nil, err
 	}
+	if err := r.client.validatePolicyLimits(apiv3.KindGlobalNetworkPolicy, res.Spec.Selector, res.Spec.Ingress, res.Spec.Egress); err != nil {
+		return nil, err
+	}
+	if err := r.client.validatePolicyTypes(apiv3.KindGlobalNetworkPolicy, res.Spec.Types, res.Spec.Ingress, res.Spec.Egress); err != nil {
+		return nil, err
+	}
 
 	// Properly prefix the name
 	res.GetObjectMeta().SetName(convertPolicyNameForStorage(res.GetObjectMeta().GetName()))
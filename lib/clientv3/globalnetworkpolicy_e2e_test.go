@@ -234,12 +234,10 @@ var _ = testutils.E2eDatastoreDescribe("GlobalNetworkPolicy tests", testutils.Da
 			testutils.ExpectResource(&outList.Items[0], apiv3.KindGlobalNetworkPolicy, testutils.ExpectNoNamespace, name1, spec2)
 			testutils.ExpectResource(&outList.Items[1], apiv3.KindGlobalNetworkPolicy, testutils.ExpectNoNamespace, name2, spec2)
 
-			if config.Spec.DatastoreType != apiconfig.Kubernetes {
-				By("Deleting GlobalNetworkPolicy (name1) with the old resource version")
-				_, outError = c.GlobalNetworkPolicies().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
-				Expect(outError).To(HaveOccurred())
-				Expect(outError.Error()).To(Equal("update conflict: GlobalNetworkPolicy(default." + name1 + ")"))
-			}
+			By("Deleting GlobalNetworkPolicy (name1) with the old resource version")
+			_, outError = c.GlobalNetworkPolicies().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("update conflict: GlobalNetworkPolicy(default." + name1 + ")"))
 
 			By("Deleting GlobalNetworkPolicy (name1) with the new resource version")
 			dres, outError := c.GlobalNetworkPolicies().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_2})
@@ -477,3 +475,40 @@ var _ = testutils.E2eDatastoreDescribe("GlobalNetworkPolicy tests", testutils.Da
 		})
 	})
 })
+
+var _ = testutils.E2eDatastoreDescribe("GlobalNetworkPolicy rule metadata tests", testutils.DatastoreAll, func(config apiconfig.CalicoAPIConfig) {
+	ctx := context.Background()
+
+	It("should preserve the rule metadata on Create and Get", func() {
+		c, err := clientv3.New(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		be, err := backend.NewClient(config)
+		Expect(err).NotTo(HaveOccurred())
+		be.Clean()
+
+		rule := testutils.InRule1
+		rule.Metadata = &apiv3.RuleMetadata{
+			Annotations: map[string]string{"note": "allow monitoring", "example.com/ticket": "1234"},
+		}
+		_, err = c.GlobalNetworkPolicies().Create(ctx, &apiv3.GlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "rule-metadata"},
+			Spec:       apiv3.GlobalNetworkPolicySpec{Ingress: []apiv3.Rule{rule}},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := c.GlobalNetworkPolicies().Get(ctx, "rule-metadata", options.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Spec.Ingress).To(HaveLen(1))
+		Expect(res.Spec.Ingress[0].Metadata).To(Equal(rule.Metadata))
+
+		By("Rejecting rule metadata with an invalid annotation key")
+		rule.Metadata = &apiv3.RuleMetadata{Annotations: map[string]string{"not a key": "value"}}
+		_, err = c.GlobalNetworkPolicies().Create(ctx, &apiv3.GlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "rule-metadata-invalid"},
+			Spec:       apiv3.GlobalNetworkPolicySpec{Ingress: []apiv3.Rule{rule}},
+		}, options.SetOptions{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Rule.Metadata.Annotations[not a key] (key)"))
+	})
+})
@@ -27,6 +27,7 @@ import (
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 	"github.com/projectcalico/libcalico-go/lib/backend"
 	"github.com/projectcalico/libcalico-go/lib/clientv3"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
 	"github.com/projectcalico/libcalico-go/lib/options"
 	"github.com/projectcalico/libcalico-go/lib/testutils"
 	"github.com/projectcalico/libcalico-go/lib/watch"
@@ -94,6 +95,7 @@ var _ = testutils.E2eDatastoreDescribe("GlobalNetworkSet tests", testutils.Datas
 			}, options.SetOptions{})
 			Expect(outError).To(HaveOccurred())
 			Expect(outError.Error()).To(Equal("resource already exists: GlobalNetworkSet(" + name1 + ")"))
+			Expect(outError).To(BeAssignableToTypeOf(cerrors.ErrorResourceAlreadyExists{}))
 
 			By("Getting GlobalNetworkSet (name1) and comparing the output against spec1")
 			res, outError := c.GlobalNetworkSets().Get(ctx, name1, options.GetOptions{})
@@ -203,12 +205,10 @@ var _ = testutils.E2eDatastoreDescribe("GlobalNetworkSet tests", testutils.Datas
 			testutils.ExpectResource(&outList.Items[0], apiv3.KindGlobalNetworkSet, testutils.ExpectNoNamespace, name1, spec2)
 			testutils.ExpectResource(&outList.Items[1], apiv3.KindGlobalNetworkSet, testutils.ExpectNoNamespace, name2, spec2)
 
-			if config.Spec.DatastoreType != apiconfig.Kubernetes {
-				By("Deleting GlobalNetworkSet (name1) with the old resource version")
-				_, outError = c.GlobalNetworkSets().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
-				Expect(outError).To(HaveOccurred())
-				Expect(outError.Error()).To(Equal("update conflict: GlobalNetworkSet(" + name1 + ")"))
-			}
+			By("Deleting GlobalNetworkSet (name1) with the old resource version")
+			_, outError = c.GlobalNetworkSets().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("update conflict: GlobalNetworkSet(" + name1 + ")"))
 
 			By("Deleting GlobalNetworkSet (name1) with the new resource version")
 			dres, outError := c.GlobalNetworkSets().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_2})
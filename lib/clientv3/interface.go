@@ -47,6 +47,8 @@ type Interface interface {
 	FelixConfigurations() FelixConfigurationInterface
 	// ClusterInformation returns an interface for managing the cluster information resource.
 	ClusterInformation() ClusterInformationInterface
+	// ResourceCounts returns the number of resources of each kind, keyed by kind.
+	ResourceCounts(ctx context.Context) (map[string]int, error)
 	// EnsureInitialized is used to ensure the backend datastore is correctly
 	// initialized for use by Calico.  This method may be called multiple times, and
 	// will have no effect if the datastore is already correctly initialized.
@@ -54,6 +56,13 @@ type Interface interface {
 	// method and so a general consumer of this API can assume that the datastore
 	// is already initialized.
 	EnsureInitialized(ctx context.Context, calicoVersion, clusterType string) error
+	// Ping checks that the backend datastore is reachable, that the client is authorized to
+	// access it, and that it has been initialized.  It returns an ErrorDatastoreUnreachable,
+	// ErrorConnectionUnauthorized or ErrorDatastoreUninitialized respectively if not.
+	Ping(ctx context.Context) error
+	// Close releases the backend datastore connections and stops any active watches.
+	// All subsequent operations on the client return an ErrorClientClosed.
+	Close() error
 }
 
 // Compile-time assertion that our client implements its interface.
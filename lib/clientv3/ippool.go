@@ -23,6 +23,7 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
 	cnet "github.com/projectcalico/libcalico-go/lib/net"
 	"github.com/projectcalico/libcalico-go/lib/options"
@@ -37,6 +38,7 @@ type IPPoolInterface interface {
 	Delete(ctx context.Context, name string, opts options.DeleteOptions) (*apiv3.IPPool, error)
 	Get(ctx context.Context, name string, opts options.GetOptions) (*apiv3.IPPool, error)
 	List(ctx context.Context, opts options.ListOptions) (*apiv3.IPPoolList, error)
+	ListFiltered(ctx context.Context, opts options.IPPoolListOptions) (*apiv3.IPPoolList, error)
 	Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error)
 }
 
@@ -64,10 +66,12 @@ func (r ipPools) Create(ctx context.Context, res *apiv3.IPPool, opts options.Set
 	}
 
 	// Enable IPIP globally if required.  Do this before the Create so if it fails the user
-	// can retry the same command.
-	err := r.maybeEnableIPIP(ctx, res)
-	if err != nil {
-		return nil, err
+	// can retry the same command.  Skip this for a dry run since it updates the global
+	// configuration.
+	if !opts.DryRun {
+		if err := r.maybeEnableIPIP(ctx, res); err != nil {
+			return nil, err
+		}
 	}
 
 	out, err := r.client.resources.Create(ctx, opts, apiv3.KindIPPool, res)
@@ -103,10 +107,12 @@ func (r ipPools) Update(ctx context.Context, res *apiv3.IPPool, opts options.Set
 	}
 
 	// Enable IPIP globally if required.  Do this before the Update so if it fails the user
-	// can retry the same command.
-	err = r.maybeEnableIPIP(ctx, res)
-	if err != nil {
-		return nil, err
+	// can retry the same command.  Skip this for a dry run since it updates the global
+	// configuration.
+	if !opts.DryRun {
+		if err = r.maybeEnableIPIP(ctx, res); err != nil {
+			return nil, err
+		}
 	}
 
 	out, err := r.client.resources.Update(ctx, opts, apiv3.KindIPPool, res)
@@ -131,7 +137,7 @@ func (r ipPools) Delete(ctx context.Context, name string, opts options.DeleteOpt
 		return nil, err
 	}
 
-	logCxt := log.WithFields(log.Fields{
+	logCxt := r.client.logger.WithFields(log.Fields{
 		"CIDR": pool.Spec.CIDR,
 		"Name": name,
 	})
@@ -203,42 +209,65 @@ func (r ipPools) List(ctx context.Context, opts options.ListOptions) (*apiv3.IPP
 	return res, nil
 }
 
+// ListFiltered returns the list of IPPool objects that match the supplied options and
+// IPPool filters.
+func (r ipPools) ListFiltered(ctx context.Context, opts options.IPPoolListOptions) (*apiv3.IPPoolList, error) {
+	filter := model.IPPoolFilter{
+		Disabled:     opts.Disabled,
+		IPIPEnabled:  opts.IPIPEnabled,
+		IPVersion:    opts.IPVersion,
+		IPAMEligible: opts.IPAMEligible,
+	}
+	res := &apiv3.IPPoolList{}
+	if err := r.client.resources.ListFiltered(ctx, opts.ListOptions, apiv3.KindIPPool, apiv3.KindIPPoolList, filter, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ListIPAMEligiblePools returns the IPPools that IPAM may allocate IP addresses from, in the
+// order returned by List.  These are the pools that are not disabled (the pools that have IPAM
+// enabled when converted to the v1 model) and have a valid CIDR.  If ipVersion is non-zero,
+// only the pools of that IP version are returned.
+func ListIPAMEligiblePools(ctx context.Context, pools IPPoolInterface, ipVersion int) ([]apiv3.IPPool, error) {
+	list, err := pools.ListFiltered(ctx, options.IPPoolListOptions{IPAMEligible: true, IPVersion: ipVersion})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
 // Watch returns a watch.Interface that watches the IPPools that match the
 // supplied options.
 func (r ipPools) Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error) {
 	return r.client.resources.Watch(ctx, opts, apiv3.KindIPPool, nil)
 }
 
+// ValidateIPPool performs the validation of an IPPool that is performed by the client on
+// Create, checking that the CIDR is a valid, strictly masked subnet of at least the minimum
+// size that does not overlap the link local ranges, and that IPIP is not enabled for an IPv6
+// pool.  It does not require a datastore, so it may be used to validate an IPPool before a
+// client is created, for example in a validating webhook.  The check that the CIDR does not
+// overlap another pool is not included.  The IPPool is not modified.
+func ValidateIPPool(res *apiv3.IPPool) error {
+	resCopy := *res
+	if _, errFields, err := validateAndDefaultIPPoolSpec(&resCopy.Spec, log.NewEntry(log.StandardLogger())); err != nil {
+		return err
+	} else if len(errFields) > 0 {
+		return cerrors.ErrorValidation{ErroredFields: errFields}
+	}
+	return validator.Validate(&resCopy)
+}
+
 // validateAndSetDefaults validates IPPool fields and sets default values that are
 // not assigned.
 // The old pool will be unassigned for a Create.
 func (r ipPools) validateAndSetDefaults(ctx context.Context, new, old *apiv3.IPPool) error {
-	errFields := []cerrors.ErroredField{}
-
-	// Spec.CIDR field must not be empty.
-	if new.Spec.CIDR == "" {
-		return cerrors.ErrorValidation{
-			ErroredFields: []cerrors.ErroredField{{
-				Name:   "IPPool.Spec.CIDR",
-				Reason: "IPPool CIDR must be specified",
-			}},
-		}
-	}
-
-	// Make sure the CIDR is parsable.
-	ipAddr, cidr, err := cnet.ParseCIDR(new.Spec.CIDR)
+	cidr, specErrFields, err := validateAndDefaultIPPoolSpec(&new.Spec, r.client.logger)
 	if err != nil {
-		return cerrors.ErrorValidation{
-			ErroredFields: []cerrors.ErroredField{{
-				Name:   "IPPool.Spec.CIDR",
-				Reason: "IPPool CIDR must be a valid subnet",
-				Value:  new.Spec.CIDR,
-			}},
-		}
+		return err
 	}
-
-	// Normalize the CIDR before persisting.
-	new.Spec.CIDR = cidr.String()
+	errFields := []cerrors.ErroredField{}
 
 	// If there was a previous pool then this must be an Update, validate that the
 	// CIDR has not changed.  Since we are using normalized CIDRs we can just do a
@@ -267,7 +296,7 @@ func (r ipPools) validateAndSetDefaults(ctx context.Context, new, old *apiv3.IPP
 			}
 			_, otherCIDR, err := cnet.ParseCIDR(otherPool.Spec.CIDR)
 			if err != nil {
-				log.WithField("Name", otherPool.Name).WithError(err).Error("IPPool is configured with an invalid CIDR")
+				r.client.logger.WithField("Name", otherPool.Name).WithError(err).Error("IPPool is configured with an invalid CIDR")
 				continue
 			}
 			if otherCIDR.IsNetOverlap(cidr.IPNet) {
@@ -280,49 +309,92 @@ func (r ipPools) validateAndSetDefaults(ctx context.Context, new, old *apiv3.IPP
 		}
 	}
 
+	// Return the errors if we have one or more validation errors.
+	errFields = append(errFields, specErrFields...)
+	if len(errFields) > 0 {
+		return cerrors.ErrorValidation{
+			ErroredFields: errFields,
+		}
+	}
+
+	return nil
+}
+
+// validateAndDefaultIPPoolSpec validates the IPPool spec fields that do not depend on other
+// resources, normalizing the CIDR and defaulting the IPIPMode.  Returns the parsed CIDR and
+// the errored fields, or an error if the CIDR is missing or cannot be parsed.  Logs are
+// written to the supplied logger.
+func validateAndDefaultIPPoolSpec(spec *apiv3.IPPoolSpec, logger *log.Entry) (*cnet.IPNet, []cerrors.ErroredField, error) {
+	errFields := []cerrors.ErroredField{}
+
+	// Spec.CIDR field must not be empty.
+	if spec.CIDR == "" {
+		return nil, nil, cerrors.ErrorValidation{
+			ErroredFields: []cerrors.ErroredField{{
+				Name:   "IPPool.Spec.CIDR",
+				Reason: "IPPool CIDR must be specified",
+			}},
+		}
+	}
+
+	// Make sure the CIDR is parsable.
+	ipAddr, cidr, err := cnet.ParseCIDR(spec.CIDR)
+	if err != nil {
+		return nil, nil, cerrors.ErrorValidation{
+			ErroredFields: []cerrors.ErroredField{{
+				Name:   "IPPool.Spec.CIDR",
+				Reason: "IPPool CIDR must be a valid subnet",
+				Value:  spec.CIDR,
+			}},
+		}
+	}
+
+	// Normalize the CIDR before persisting.
+	spec.CIDR = cidr.String()
+
 	// Make sure IPIPMode is defaulted to "Never".
-	if len(new.Spec.IPIPMode) == 0 {
-		new.Spec.IPIPMode = apiv3.IPIPModeNever
+	if len(spec.IPIPMode) == 0 {
+		spec.IPIPMode = apiv3.IPIPModeNever
 	}
 
 	// IPIP cannot be enabled for IPv6.
-	if cidr.Version() == 6 && new.Spec.IPIPMode != apiv3.IPIPModeNever {
+	if cidr.Version() == 6 && spec.IPIPMode != apiv3.IPIPModeNever {
 		errFields = append(errFields, cerrors.ErroredField{
 			Name:   "IPPool.Spec.IPIPMode",
 			Reason: "IPIP is not supported on an IPv6 IP pool",
-			Value:  new.Spec.IPIPMode,
+			Value:  spec.IPIPMode,
 		})
 	}
 
 	// The Calico IPAM places restrictions on the minimum IP pool size.  If
 	// the ippool is enabled, check that the pool is at least the minimum size.
-	if !new.Spec.Disabled {
+	if !spec.Disabled {
 		ones, bits := cidr.Mask.Size()
-		log.Debugf("Pool CIDR: %s, num bits: %d", cidr.String(), bits-ones)
+		logger.Debugf("Pool CIDR: %s, num bits: %d", cidr.String(), bits-ones)
 		if bits-ones < 6 {
 			if cidr.Version() == 4 {
 				errFields = append(errFields, cerrors.ErroredField{
 					Name:   "IPPool.Spec.CIDR",
 					Reason: "IPv4 pool size is too small (min /26) for use with Calico IPAM",
-					Value:  new.Spec.CIDR,
+					Value:  spec.CIDR,
 				})
 			} else {
 				errFields = append(errFields, cerrors.ErroredField{
 					Name:   "IPPool.Spec.CIDR",
 					Reason: "IPv6 pool size is too small (min /122) for use with Calico IPAM",
-					Value:  new.Spec.CIDR,
+					Value:  spec.CIDR,
 				})
 			}
 		}
 	}
 
 	// The Calico CIDR should be strictly masked
-	log.Debugf("IPPool CIDR: %s, Masked IP: %d", new.Spec.CIDR, cidr.IP)
+	logger.Debugf("IPPool CIDR: %s, Masked IP: %d", spec.CIDR, cidr.IP)
 	if cidr.IP.String() != ipAddr.String() {
 		errFields = append(errFields, cerrors.ErroredField{
 			Name:   "IPPool.Spec.CIDR",
 			Reason: "IPPool CIDR is not strictly masked",
-			Value:  new.Spec.CIDR,
+			Value:  spec.CIDR,
 		})
 	}
 
@@ -342,7 +414,7 @@ func (r ipPools) validateAndSetDefaults(ctx context.Context, new, old *apiv3.IPP
 		errFields = append(errFields, cerrors.ErroredField{
 			Name:   "IPPool.Spec.CIDR",
 			Reason: "IPPool CIDR overlaps with IPv4 Link Local range 169.254.0.0/16",
-			Value:  new.Spec.CIDR,
+			Value:  spec.CIDR,
 		})
 	}
 
@@ -350,47 +422,69 @@ func (r ipPools) validateAndSetDefaults(ctx context.Context, new, old *apiv3.IPP
 		errFields = append(errFields, cerrors.ErroredField{
 			Name:   "IPPool.Spec.CIDR",
 			Reason: "IPPool CIDR overlaps with IPv6 Link Local range fe80::/10",
-			Value:  new.Spec.CIDR,
+			Value:  spec.CIDR,
 		})
 	}
 
-	// Return the errors if we have one or more validation errors.
-	if len(errFields) > 0 {
-		return cerrors.ErrorValidation{
-			ErroredFields: errFields,
+	// Each reserved CIDR must be within the pool CIDR.  Normalize the reserved CIDRs
+	// before persisting, into a new slice since the spec may be a shallow copy of the
+	// caller's IPPool.
+	poolOnes, _ := cidr.Mask.Size()
+	if spec.ReservedCIDRs != nil {
+		spec.ReservedCIDRs = append([]string(nil), spec.ReservedCIDRs...)
+	}
+	for i, r := range spec.ReservedCIDRs {
+		_, reserved, err := cnet.ParseCIDR(r)
+		if err != nil {
+			errFields = append(errFields, cerrors.ErroredField{
+				Name:   fmt.Sprintf("IPPool.Spec.ReservedCIDRs[%d]", i),
+				Reason: "IPPool reserved CIDR must be a valid subnet",
+				Value:  r,
+			})
+			continue
 		}
+		ones, _ := reserved.Mask.Size()
+		if reserved.Version() != cidr.Version() || ones < poolOnes || !cidr.Contains(reserved.IP) {
+			errFields = append(errFields, cerrors.ErroredField{
+				Name:   fmt.Sprintf("IPPool.Spec.ReservedCIDRs[%d]", i),
+				Reason: "IPPool reserved CIDR is not within the pool CIDR",
+				Value:  r,
+			})
+			continue
+		}
+		spec.ReservedCIDRs[i] = reserved.String()
 	}
 
-	return nil
+	return cidr, errFields, nil
 }
 
 // maybeEnableIPIP enables global IPIP if a default setting is not already configured
 // and the pool has IPIP enabled.
 func (c ipPools) maybeEnableIPIP(ctx context.Context, pool *apiv3.IPPool) error {
 	if pool.Spec.IPIPMode == apiv3.IPIPModeNever {
-		log.Debug("IPIP is not enabled for this pool - no need to check global setting")
+		c.client.logger.Debug("IPIP is not enabled for this pool - no need to check global setting")
 		return nil
 	}
 
 	var err error
 	ipEnabled := true
 	for i := 0; i < maxApplyRetries; i++ {
-		log.WithField("Retry", i).Debug("Checking global IPIP setting")
+		c.client.logger.WithField("Retry", i).Debug("Checking global IPIP setting")
 		res, err := c.client.FelixConfigurations().Get(ctx, "default", options.GetOptions{})
 		if _, ok := err.(cerrors.ErrorResourceDoesNotExist); !ok && err != nil {
-			log.WithError(err).Debug("Error getting current FelixConfiguration resource")
+			c.client.logger.WithError(err).Debug("Error getting current FelixConfiguration resource")
 			return err
 		}
 
 		if res == nil {
-			log.Debug("Global FelixConfiguration does not exist - creating")
+			c.client.logger.Debug("Global FelixConfiguration does not exist - creating")
 			res = apiv3.NewFelixConfiguration()
 			res.Name = "default"
 		} else if res.Spec.IPIPEnabled != nil {
 			// A value for the default config is set so leave unchanged.  It may be set to false,
 			// so log the actual value - but we shouldn't update it if someone has explicitly
 			// disabled it globally.
-			log.WithField("IPIPEnabled", res.Spec.IPIPEnabled).Debug("Global IPIPEnabled setting is already configured")
+			c.client.logger.WithField("IPIPEnabled", res.Spec.IPIPEnabled).Debug("Global IPIPEnabled setting is already configured")
 			return nil
 		}
 
@@ -399,27 +493,27 @@ func (c ipPools) maybeEnableIPIP(ctx context.Context, pool *apiv3.IPPool) error
 		if res.ResourceVersion == "" {
 			res, err = c.client.FelixConfigurations().Create(ctx, res, options.SetOptions{})
 			if _, ok := err.(cerrors.ErrorResourceAlreadyExists); ok {
-				log.Debug("FelixConfiguration already exists - retry update")
+				c.client.logger.Debug("FelixConfiguration already exists - retry update")
 				continue
 			}
 		} else {
 			res, err = c.client.FelixConfigurations().Update(ctx, res, options.SetOptions{})
 			if _, ok := err.(cerrors.ErrorResourceUpdateConflict); ok {
-				log.Debug("FelixConfiguration update conflict - retry update")
+				c.client.logger.Debug("FelixConfiguration update conflict - retry update")
 				continue
 			}
 		}
 
 		if err == nil {
-			log.Debug("FelixConfiguration updated successfully")
+			c.client.logger.Debug("FelixConfiguration updated successfully")
 			return nil
 		}
 
-		log.WithError(err).Debug("Error updating FelixConfiguration to enable IPIP")
+		c.client.logger.WithError(err).Debug("Error updating FelixConfiguration to enable IPIP")
 		return err
 	}
 
 	// Return the error from the final Update.
-	log.WithError(err).Info("Too many conflict failures attempting to update FelixConfiguration to enable IPIP")
+	c.client.logger.WithError(err).Info("Too many conflict failures attempting to update FelixConfiguration to enable IPIP")
 	return err
 }
@@ -229,12 +229,10 @@ var _ = testutils.E2eDatastoreDescribe("IPPool tests", testutils.DatastoreAll, f
 			testutils.ExpectResource(&outList.Items[0], apiv3.KindIPPool, testutils.ExpectNoNamespace, name1, spec1_2)
 			testutils.ExpectResource(&outList.Items[1], apiv3.KindIPPool, testutils.ExpectNoNamespace, name2, spec2)
 
-			if config.Spec.DatastoreType != apiconfig.Kubernetes {
-				By("Deleting IPPool (name1) with the old resource version")
-				_, outError = c.IPPools().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
-				Expect(outError).To(HaveOccurred())
-				Expect(outError.Error()).To(Equal("update conflict: IPPool(" + name1 + ")"))
-			}
+			By("Deleting IPPool (name1) with the old resource version")
+			_, outError = c.IPPools().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_1})
+			Expect(outError).To(HaveOccurred())
+			Expect(outError.Error()).To(Equal("update conflict: IPPool(" + name1 + ")"))
 
 			By("Deleting IPPool (name1) with the new resource version")
 			dres, outError := c.IPPools().Delete(ctx, name1, options.DeleteOptions{ResourceVersion: rv1_2})
@@ -628,6 +626,21 @@ var _ = testutils.E2eDatastoreDescribe("IPPool tests", testutils.DatastoreAll, f
 			be.Clean()
 		})
 
+		It("should store the CIDR with the host bits masked", func() {
+			pool, err := c.IPPools().Create(ctx, &apiv3.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: "ippool1"},
+				Spec: apiv3.IPPoolSpec{
+					CIDR: "1.2.3.5/24",
+				},
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pool.Spec.CIDR).To(Equal("1.2.3.0/24"))
+
+			pool, err = c.IPPools().Get(ctx, "ippool1", options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pool.Spec.CIDR).To(Equal("1.2.3.0/24"))
+		})
+
 		It("should prevent the CIDR being changed on an update", func() {
 			By("Creating a pool")
 			pool, err := c.IPPools().Create(ctx, &apiv3.IPPool{
@@ -700,4 +713,141 @@ var _ = testutils.E2eDatastoreDescribe("IPPool tests", testutils.DatastoreAll, f
 			Expect(err.Error()).To(ContainSubstring("IPPool(ippool4) CIDR overlaps with IPPool(ippool1) CIDR 1.2.3.0/24"))
 		})
 	})
+
+	Describe("Verify IPPool list filtering", func() {
+		var err error
+		var c clientv3.Interface
+		ptrFalse := false
+		ptrTrue := true
+
+		BeforeEach(func() {
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			By("Creating a mixed set of pools")
+			for _, p := range []apiv3.IPPool{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "enabled-ipip"},
+					Spec:       apiv3.IPPoolSpec{CIDR: "1.2.1.0/24", IPIPMode: apiv3.IPIPModeAlways},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "enabled-noipip"},
+					Spec:       apiv3.IPPoolSpec{CIDR: "1.2.2.0/24", IPIPMode: apiv3.IPIPModeNever},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "disabled-ipip"},
+					Spec:       apiv3.IPPoolSpec{CIDR: "1.2.3.0/24", IPIPMode: apiv3.IPIPModeCrossSubnet, Disabled: true},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "disabled-noipip"},
+					Spec:       apiv3.IPPoolSpec{CIDR: "1.2.4.0/24", Disabled: true},
+				},
+			} {
+				pool := p
+				_, err = c.IPPools().Create(ctx, &pool, options.SetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		poolNames := func(opts options.IPPoolListOptions) []string {
+			pools, err := c.IPPools().ListFiltered(ctx, opts)
+			Expect(err).NotTo(HaveOccurred())
+			names := []string{}
+			for _, p := range pools.Items {
+				names = append(names, p.Name)
+			}
+			return names
+		}
+
+		It("should list all pools when no filter is specified", func() {
+			Expect(poolNames(options.IPPoolListOptions{})).To(ConsistOf(
+				"enabled-ipip", "enabled-noipip", "disabled-ipip", "disabled-noipip",
+			))
+		})
+
+		It("should list only enabled pools", func() {
+			Expect(poolNames(options.IPPoolListOptions{Disabled: &ptrFalse})).To(ConsistOf("enabled-ipip", "enabled-noipip"))
+		})
+
+		It("should list only IPIP pools", func() {
+			Expect(poolNames(options.IPPoolListOptions{IPIPEnabled: &ptrTrue})).To(ConsistOf("enabled-ipip", "disabled-ipip"))
+		})
+
+		It("should AND multiple filters together", func() {
+			Expect(poolNames(options.IPPoolListOptions{Disabled: &ptrFalse, IPIPEnabled: &ptrFalse})).To(ConsistOf("enabled-noipip"))
+		})
+
+		It("should apply the filters to each page of a paginated List", func() {
+			opts := options.IPPoolListOptions{ListOptions: options.ListOptions{Limit: 2}, Disabled: &ptrTrue}
+			names := []string{}
+			for {
+				pools, err := c.IPPools().ListFiltered(ctx, opts)
+				Expect(err).NotTo(HaveOccurred())
+				for _, p := range pools.Items {
+					names = append(names, p.Name)
+				}
+				if pools.Continue == "" {
+					break
+				}
+				opts.Continue = pools.Continue
+			}
+			Expect(names).To(ConsistOf("disabled-ipip", "disabled-noipip"))
+		})
+	})
+})
+
+// These tests are not run on KDD since a List at a resource version is only guaranteed to be
+// a snapshot at that version for etcdv3.
+var _ = testutils.E2eDatastoreDescribe("IPPool list at resource version tests", testutils.DatastoreEtcdV3, func(config apiconfig.CalicoAPIConfig) {
+	ctx := context.Background()
+	name1 := "ippool-1"
+	name2 := "ippool-2"
+
+	It("should list a snapshot and watch from the returned resource version", func() {
+		c, err := clientv3.New(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		be, err := backend.NewClient(config)
+		Expect(err).NotTo(HaveOccurred())
+		be.Clean()
+
+		By("Creating name1 and listing at the current resource version")
+		_, err = c.IPPools().Create(ctx, &apiv3.IPPool{
+			ObjectMeta: metav1.ObjectMeta{Name: name1},
+			Spec:       apiv3.IPPoolSpec{CIDR: "1.2.3.0/24"},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		outList, err := c.IPPools().List(ctx, options.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outList.Items).To(HaveLen(1))
+		rev1 := outList.ResourceVersion
+
+		By("Creating name2 and listing at the earlier resource version")
+		outRes2, err := c.IPPools().Create(ctx, &apiv3.IPPool{
+			ObjectMeta: metav1.ObjectMeta{Name: name2},
+			Spec:       apiv3.IPPoolSpec{CIDR: "2.3.4.0/24"},
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		outList, err = c.IPPools().List(ctx, options.ListOptions{ResourceVersion: rev1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(outList.Items).To(HaveLen(1))
+		Expect(outList.Items[0].Name).To(Equal(name1))
+		Expect(outList.ResourceVersion).To(Equal(rev1))
+
+		By("Watching from the list resource version and checking only name2 is added")
+		w, err := c.IPPools().Watch(ctx, options.ListOptions{ResourceVersion: outList.ResourceVersion})
+		Expect(err).NotTo(HaveOccurred())
+		testWatcher := testutils.NewTestResourceWatch(config.Spec.DatastoreType, w)
+		defer testWatcher.Stop()
+		testWatcher.ExpectEvents(apiv3.KindIPPool, []watch.Event{
+			{
+				Type:   watch.Added,
+				Object: outRes2,
+			},
+		})
+	})
 })
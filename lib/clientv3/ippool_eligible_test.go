@@ -0,0 +1,91 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+)
+
+var _ = Describe("IPAM eligible IPPool tests", func() {
+	ctx := context.Background()
+	var c client
+
+	pool := func(name, cidr string, disabled bool) *model.KVPair {
+		return &model.KVPair{
+			Key: model.ResourceKey{Kind: apiv3.KindIPPool, Name: name},
+			Value: &apiv3.IPPool{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       apiv3.IPPoolSpec{CIDR: cidr, Disabled: disabled},
+			},
+		}
+	}
+
+	names := func(pools []apiv3.IPPool) []string {
+		out := []string{}
+		for _, p := range pools {
+			out = append(out, p.Name)
+		}
+		return out
+	}
+
+	BeforeEach(func() {
+		be := &listBackend{kvps: []*model.KVPair{
+			pool("disabled-v4", "10.0.0.0/24", true),
+			pool("eligible-v4", "10.0.1.0/24", false),
+			pool("invalid-cidr", "10.0.2.0/33", false),
+			pool("disabled-v6", "fd00::/120", true),
+			pool("eligible-v6", "fd00:1::/120", false),
+			pool("eligible-v4-2", "10.0.3.0/24", false),
+		}}
+		logger := log.NewEntry(log.StandardLogger())
+		c = client{
+			backend:   be,
+			resources: newResources(be, nil, logger),
+			logger:    logger,
+		}
+	})
+
+	It("should return the enabled pools with a valid CIDR", func() {
+		pools, err := ListIPAMEligiblePools(ctx, c.IPPools(), 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(pools)).To(Equal([]string{"eligible-v4", "eligible-v6", "eligible-v4-2"}))
+	})
+
+	It("should return the enabled pools of the requested IP version", func() {
+		pools, err := ListIPAMEligiblePools(ctx, c.IPPools(), 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(pools)).To(Equal([]string{"eligible-v4", "eligible-v4-2"}))
+
+		pools, err = ListIPAMEligiblePools(ctx, c.IPPools(), 6)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(pools)).To(Equal([]string{"eligible-v6"}))
+	})
+
+	It("should return the eligible pool CIDRs from the IPAM pool accessor", func() {
+		cidrs, err := poolAccessor{client: &c}.GetEnabledPools(4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cidrs).To(HaveLen(2))
+		Expect(cidrs[0].String()).To(Equal("10.0.1.0/24"))
+		Expect(cidrs[1].String()).To(Equal("10.0.3.0/24"))
+	})
+})
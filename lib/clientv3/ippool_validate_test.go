@@ -0,0 +1,134 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+var _ = Describe("IPPool validation without a backend", func() {
+	newIPPool := func(spec apiv3.IPPoolSpec) *apiv3.IPPool {
+		return &apiv3.IPPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "ippool-1"},
+			Spec:       spec,
+		}
+	}
+
+	It("should accept a valid IPPool without modifying it", func() {
+		pool := newIPPool(apiv3.IPPoolSpec{CIDR: "10.0.0.0/24"})
+		Expect(ValidateIPPool(pool)).NotTo(HaveOccurred())
+		Expect(pool.Spec.IPIPMode).To(Equal(apiv3.IPIPMode("")))
+
+		pool = newIPPool(apiv3.IPPoolSpec{CIDR: "fd00:0::/120", IPIPMode: apiv3.IPIPModeNever})
+		Expect(ValidateIPPool(pool)).NotTo(HaveOccurred())
+		Expect(pool.Spec.CIDR).To(Equal("fd00:0::/120"))
+	})
+
+	It("should accept a small IPPool that is disabled", func() {
+		Expect(ValidateIPPool(newIPPool(apiv3.IPPoolSpec{CIDR: "10.0.0.0/30", Disabled: true}))).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("should report the invalid fields of an IPPool",
+		func(spec apiv3.IPPoolSpec, reasons []string) {
+			err := ValidateIPPool(newIPPool(spec))
+			Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+			actual := []string{}
+			for _, f := range err.(cerrors.ErrorValidation).ErroredFields {
+				actual = append(actual, f.Reason)
+			}
+			Expect(actual).To(Equal(reasons))
+		},
+		Entry("empty CIDR",
+			apiv3.IPPoolSpec{},
+			[]string{"IPPool CIDR must be specified"},
+		),
+		Entry("invalid CIDR",
+			apiv3.IPPoolSpec{CIDR: "10.0.0.0/33"},
+			[]string{"IPPool CIDR must be a valid subnet"},
+		),
+		Entry("CIDR not strictly masked",
+			apiv3.IPPoolSpec{CIDR: "10.0.0.1/24"},
+			[]string{"IPPool CIDR is not strictly masked"},
+		),
+		Entry("IPv4 pool too small",
+			apiv3.IPPoolSpec{CIDR: "10.0.0.0/27"},
+			[]string{"IPv4 pool size is too small (min /26) for use with Calico IPAM"},
+		),
+		Entry("IPv6 pool too small",
+			apiv3.IPPoolSpec{CIDR: "fd00::/123"},
+			[]string{"IPv6 pool size is too small (min /122) for use with Calico IPAM"},
+		),
+		Entry("IPIP on an IPv6 pool",
+			apiv3.IPPoolSpec{CIDR: "fd00::/120", IPIPMode: apiv3.IPIPModeAlways},
+			[]string{"IPIP is not supported on an IPv6 IP pool"},
+		),
+		Entry("overlapping the IPv4 link local range",
+			apiv3.IPPoolSpec{CIDR: "169.254.0.0/24"},
+			[]string{"IPPool CIDR overlaps with IPv4 Link Local range 169.254.0.0/16"},
+		),
+		Entry("overlapping the IPv6 link local range",
+			apiv3.IPPoolSpec{CIDR: "fe80::/120"},
+			[]string{"IPPool CIDR overlaps with IPv6 Link Local range fe80::/10"},
+		),
+		Entry("reserved CIDR outside the pool",
+			apiv3.IPPoolSpec{CIDR: "10.0.0.0/24", ReservedCIDRs: []string{"10.0.0.1/32", "10.0.1.1/32"}},
+			[]string{"IPPool reserved CIDR is not within the pool CIDR"},
+		),
+		Entry("reserved CIDR larger than the pool",
+			apiv3.IPPoolSpec{CIDR: "10.0.0.0/24", ReservedCIDRs: []string{"10.0.0.0/16"}},
+			[]string{"IPPool reserved CIDR is not within the pool CIDR"},
+		),
+		Entry("reserved CIDR of a different IP version",
+			apiv3.IPPoolSpec{CIDR: "10.0.0.0/24", ReservedCIDRs: []string{"fd00::/128"}},
+			[]string{"IPPool reserved CIDR is not within the pool CIDR"},
+		),
+	)
+
+	It("should accept reserved CIDRs within the pool without modifying them", func() {
+		pool := newIPPool(apiv3.IPPoolSpec{CIDR: "10.0.0.0/24", ReservedCIDRs: []string{"10.0.0.1/32", "10.0.0.17/28"}})
+		Expect(ValidateIPPool(pool)).NotTo(HaveOccurred())
+		Expect(pool.Spec.ReservedCIDRs).To(Equal([]string{"10.0.0.1/32", "10.0.0.17/28"}))
+	})
+
+	It("should report an invalid IPIP mode", func() {
+		err := ValidateIPPool(newIPPool(apiv3.IPPoolSpec{CIDR: "10.0.0.0/24", IPIPMode: "Sometimes"}))
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		Expect(err.(cerrors.ErrorValidation).ErroredFields[0].Name).To(Equal("IPIPMode"))
+	})
+
+	It("should return the same error from Create, without creating the resource", func() {
+		be := &countingBackend{}
+		logger := log.NewEntry(log.StandardLogger())
+		c := client{backend: be, logger: logger, resources: newResources(be, nil, logger)}
+
+		pool := newIPPool(apiv3.IPPoolSpec{CIDR: "fd00::1/123", IPIPMode: apiv3.IPIPModeAlways})
+		expectedErr := ValidateIPPool(pool)
+		Expect(expectedErr).To(HaveOccurred())
+
+		_, err := c.IPPools().Create(context.Background(), pool, options.SetOptions{})
+		Expect(err).To(Equal(expectedErr))
+		Expect(be.calls).To(Equal(0))
+	})
+})
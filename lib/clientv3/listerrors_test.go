@@ -0,0 +1,83 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+var _ = Describe("List with unparseable entries tests", func() {
+	ctx := context.Background()
+	var c client
+
+	gns := func(name string) *model.KVPair {
+		return &model.KVPair{
+			Key: model.ResourceKey{Kind: apiv3.KindGlobalNetworkSet, Name: name},
+			Value: &apiv3.GlobalNetworkSet{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       apiv3.GlobalNetworkSetSpec{Nets: []string{"10.0.0.0/24"}},
+			},
+		}
+	}
+
+	parseErr := cerrors.ErrorParsingDatastoreEntry{
+		RawKey:   "/calico/resources/v3/projectcalico.org/globalnetworksets/networkset-2",
+		RawValue: "{not json",
+		Err:      errors.New("invalid character 'n' looking for beginning of object key string"),
+	}
+
+	BeforeEach(func() {
+		be := &listBackend{
+			kvps: []*model.KVPair{gns("networkset-1"), gns("networkset-3"), gns("networkset-4")},
+			errs: []error{parseErr},
+		}
+		logger := log.NewEntry(log.StandardLogger())
+		c = client{
+			backend:   be,
+			resources: newResources(be, nil, logger),
+			logger:    logger,
+		}
+	})
+
+	It("should return the valid resources, skipping the unparseable entry", func() {
+		l, err := c.GlobalNetworkSets().List(ctx, options.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		var names []string
+		for _, item := range l.Items {
+			names = append(names, item.Name)
+		}
+		Expect(names).To(Equal([]string{"networkset-1", "networkset-3", "networkset-4"}))
+	})
+
+	It("should fail with the parse errors when the List is strict", func() {
+		l, err := c.GlobalNetworkSets().List(ctx, options.ListOptions{Strict: true})
+		Expect(l).To(BeNil())
+		Expect(err).To(Equal(cerrors.ErrorCollectionFailure{
+			Operation: "List",
+			Errors:    []error{parseErr},
+		}))
+	})
+})
@@ -0,0 +1,86 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+// validateSortBy returns an error if the sort option is not supported.
+func validateSortBy(sortBy options.SortBy) error {
+	switch sortBy {
+	case "", options.SortByName, options.SortByNode, options.SortByCreationTimestamp:
+		return nil
+	}
+	return cerrors.ErrorValidation{
+		ErroredFields: []cerrors.ErroredField{{
+			Name:   "ListOptions.SortBy",
+			Value:  sortBy,
+			Reason: "unsupported sort option",
+		}},
+	}
+}
+
+// sortResources sorts the resources according to the sort option, breaking ties by namespace
+// and then name.  The resources are left in the backend order if the sort option is blank.
+func sortResources(resources []runtime.Object, sortBy options.SortBy) {
+	if sortBy == "" {
+		return
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		ri, rj := resources[i].(resource).GetObjectMeta(), resources[j].(resource).GetObjectMeta()
+		switch sortBy {
+		case options.SortByName:
+			if ri.GetName() != rj.GetName() {
+				return ri.GetName() < rj.GetName()
+			}
+		case options.SortByNode:
+			if ni, nj := resourceNode(resources[i]), resourceNode(resources[j]); ni != nj {
+				return ni < nj
+			}
+		case options.SortByCreationTimestamp:
+			ti, tj := ri.GetCreationTimestamp(), rj.GetCreationTimestamp()
+			if !ti.Equal(&tj) {
+				return ti.Before(&tj)
+			}
+		}
+		if ri.GetNamespace() != rj.GetNamespace() {
+			return ri.GetNamespace() < rj.GetNamespace()
+		}
+		return ri.GetName() < rj.GetName()
+	})
+}
+
+// resourceNode returns the node associated with the resource, or an empty string if the
+// resource is not associated with a node.
+func resourceNode(res runtime.Object) string {
+	switch r := res.(type) {
+	case *apiv3.WorkloadEndpoint:
+		return r.Spec.Node
+	case *apiv3.HostEndpoint:
+		return r.Spec.Node
+	case *apiv3.BGPPeer:
+		return r.Spec.Node
+	case *apiv3.Node:
+		return r.Name
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+// listBackend implements the List method of the backend client, returning the configured
+// KVPairs that match the filter of the list options in the configured order along with the
+// configured errors.  All other methods panic.
+type listBackend struct {
+	bapi.Client
+	kvps []*model.KVPair
+	errs []error
+}
+
+func (b *listBackend) List(ctx context.Context, list model.ListInterface, revision string) (*model.KVPairList, error) {
+	rlo, _ := list.(model.ResourceListOptions)
+	kvps := []*model.KVPair{}
+	for _, kvp := range b.kvps {
+		if rlo.MatchesFilter(kvp) {
+			kvps = append(kvps, kvp)
+		}
+	}
+	return &model.KVPairList{KVPairs: kvps, Revision: "1", Errors: b.errs}, nil
+}
+
+var _ = Describe("List sort option tests", func() {
+	ctx := context.Background()
+	var be *listBackend
+	var c client
+
+	created := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	wep := func(namespace, name, node string, age time.Duration) *model.KVPair {
+		return &model.KVPair{
+			Key: model.ResourceKey{Kind: apiv3.KindWorkloadEndpoint, Namespace: namespace, Name: name},
+			Value: &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:         namespace,
+					Name:              name,
+					CreationTimestamp: metav1.NewTime(created.Add(-age)),
+				},
+				Spec: apiv3.WorkloadEndpointSpec{Node: node},
+			},
+		}
+	}
+
+	names := func(l *apiv3.WorkloadEndpointList) []string {
+		var out []string
+		for _, w := range l.Items {
+			out = append(out, w.Namespace+"/"+w.Name)
+		}
+		return out
+	}
+
+	BeforeEach(func() {
+		be = &listBackend{kvps: []*model.KVPair{
+			wep("ns2", "wep-b", "node-1", 1*time.Hour),
+			wep("ns1", "wep-c", "node-2", 3*time.Hour),
+			wep("ns2", "wep-a", "node-2", 2*time.Hour),
+			wep("ns1", "wep-b", "node-1", 2*time.Hour),
+		}}
+		logger := log.NewEntry(log.StandardLogger())
+		c = client{
+			backend:   be,
+			resources: newResources(be, nil, logger),
+			logger:    logger,
+		}
+	})
+
+	It("should return the backend order when no sort option is specified", func() {
+		l, err := c.WorkloadEndpoints().List(ctx, options.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(l)).To(Equal([]string{"ns2/wep-b", "ns1/wep-c", "ns2/wep-a", "ns1/wep-b"}))
+	})
+
+	It("should return lexically ordered results when sorting by name", func() {
+		l, err := c.WorkloadEndpoints().List(ctx, options.ListOptions{SortBy: options.SortByName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(l)).To(Equal([]string{"ns2/wep-a", "ns1/wep-b", "ns2/wep-b", "ns1/wep-c"}))
+	})
+
+	It("should order results by node, breaking ties by namespace and name", func() {
+		l, err := c.WorkloadEndpoints().List(ctx, options.ListOptions{SortBy: options.SortByNode})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(l)).To(Equal([]string{"ns1/wep-b", "ns2/wep-b", "ns1/wep-c", "ns2/wep-a"}))
+	})
+
+	It("should order results by creation timestamp, oldest first", func() {
+		l, err := c.WorkloadEndpoints().List(ctx, options.ListOptions{SortBy: options.SortByCreationTimestamp})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(l)).To(Equal([]string{"ns1/wep-c", "ns1/wep-b", "ns2/wep-a", "ns2/wep-b"}))
+	})
+
+	It("should reject an unsupported sort option", func() {
+		_, err := c.WorkloadEndpoints().List(ctx, options.ListOptions{SortBy: "Color"})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+	})
+})
@@ -52,6 +52,12 @@ func (r networkPolicies) Create(ctx context.Context, res *apiv3.NetworkPolicy, o
 	if err := validator.Validate(res); err != nil {
 		return nil, err
 	}
+	if err := r.client.validatePolicyLimits(apiv3.KindNetworkPolicy, res.Spec.Selector, res.Spec.Ingress, res.Spec.Egress); err != nil {
+		return nil, err
+	}
+	if err := r.client.validatePolicyTypes(apiv3.KindNetworkPolicy, res.Spec.Types, res.Spec.Ingress, res.Spec.Egress); err != nil {
+		return nil, err
+	}
 
 	// Properly prefix the name
 	res.GetObjectMeta().SetName(convertPolicyNameForStorage(res.GetObjectMeta().GetName()))
@@ -81,6 +87,12 @@ func (r networkPolicies) Update(ctx context.Context, res *apiv3.NetworkPolicy, o
 	if err := validator.Validate(res); err != nil {
 		return nil, err
 	}
+	if err := r.client.validatePolicyLimits(apiv3.KindNetworkPolicy, res.Spec.Selector, res.Spec.Ingress, res.Spec.Egress); err != nil {
+		return nil, err
+	}
+	if err := r.client.validatePolicyTypes(apiv3.KindNetworkPolicy, res.Spec.Types, res.Spec.Ingress, res.Spec.Egress); err != nil {
+		return nil, err
+	}
 
 	// Properly prefix the name
 	res.GetObjectMeta().SetName(convertPolicyNameForStorage(res.GetObjectMeta().GetName()))
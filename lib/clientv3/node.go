@@ -27,7 +27,6 @@ import (
 	"github.com/projectcalico/libcalico-go/lib/options"
 	validator "github.com/projectcalico/libcalico-go/lib/validator/v3"
 	"github.com/projectcalico/libcalico-go/lib/watch"
-	log "github.com/sirupsen/logrus"
 )
 
 // NodeInterface has methods to work with Node resources.
@@ -111,7 +110,7 @@ func (r nodes) Delete(ctx context.Context, name string, opts options.DeleteOptio
 				ips = append(ips, *ipAddr)
 			} else {
 				// Validation for wep insists upon CIDR, so we should always succeed
-				log.WithError(err).Warnf("Failed to parse CIDR: %s", ip)
+				r.client.logger.WithError(err).Warnf("Failed to parse CIDR: %s", ip)
 			}
 		}
 	}
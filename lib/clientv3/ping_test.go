@@ -0,0 +1,85 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+// pingBackend implements the Get method of the backend client, returning the configured
+// KVPair or error and recording the requested key.  All other methods panic.
+type pingBackend struct {
+	bapi.Client
+	kvp  *model.KVPair
+	err  error
+	keys []model.Key
+}
+
+func (b *pingBackend) Get(ctx context.Context, key model.Key, revision string) (*model.KVPair, error) {
+	b.keys = append(b.keys, key)
+	return b.kvp, b.err
+}
+
+var _ = Describe("Client ping tests", func() {
+	ctx := context.Background()
+	clusterInfoKey := model.ResourceKey{Kind: apiv3.KindClusterInformation, Name: "default"}
+	var be *pingBackend
+	var c client
+
+	BeforeEach(func() {
+		be = &pingBackend{}
+		logger := log.NewEntry(log.StandardLogger())
+		c = client{
+			backend:   be,
+			resources: newResources(be, nil, logger),
+			logger:    logger,
+		}
+	})
+
+	It("should succeed if the datastore is initialized", func() {
+		be.kvp = &model.KVPair{Key: clusterInfoKey, Value: apiv3.NewClusterInformation()}
+		Expect(c.Ping(ctx)).NotTo(HaveOccurred())
+		Expect(be.keys).To(Equal([]model.Key{clusterInfoKey}))
+	})
+
+	It("should return an uninitialized error if the ClusterInformation does not exist", func() {
+		be.err = cerrors.ErrorResourceDoesNotExist{Identifier: clusterInfoKey}
+		err := c.Ping(ctx)
+		Expect(err).To(Equal(cerrors.ErrorDatastoreUninitialized{Identifier: clusterInfoKey}))
+		Expect(err.Error()).To(Equal("datastore is not initialized: ClusterInformation(default) does not exist"))
+	})
+
+	It("should return an unauthorized error if the client is not authorized", func() {
+		be.err = cerrors.ErrorConnectionUnauthorized{Err: errors.New("permission denied")}
+		Expect(c.Ping(ctx)).To(Equal(be.err))
+	})
+
+	It("should return an unreachable error if the datastore cannot be reached", func() {
+		be.err = cerrors.ErrorDatastoreError{Err: context.DeadlineExceeded}
+		err := c.Ping(ctx)
+		Expect(err).To(Equal(cerrors.ErrorDatastoreUnreachable{Err: be.err}))
+		Expect(err.Error()).To(Equal("datastore is unreachable: context deadline exceeded"))
+	})
+})
@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"fmt"
+	"sort"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/selector"
+)
+
+// ApplicablePolicy identifies a policy whose selector matches a workload endpoint.
+type ApplicablePolicy struct {
+	// The kind of the policy, either GlobalNetworkPolicy or NetworkPolicy.
+	Kind string
+	// The namespace of the policy, blank for a GlobalNetworkPolicy.
+	Namespace string
+	Name      string
+	Order     *float64
+}
+
+// PoliciesForWorkloadEndpoint returns the policies that apply to the workload endpoint, that is
+// the policies whose selector matches the labels of the endpoint.  NetworkPolicies only apply to
+// endpoints in the same namespace, and DoNotTrack and PreDNAT GlobalNetworkPolicies are not
+// included since they only apply to host endpoints.
+//
+// The policies are returned in the order in which they are applied: by ascending Order, with
+// policies without an Order last, and then by name.  This does not access the datastore, the
+// policies are supplied by the caller.  An error is returned if a policy selector is not valid.
+func PoliciesForWorkloadEndpoint(
+	wep *apiv3.WorkloadEndpoint, gnps []apiv3.GlobalNetworkPolicy, nps []apiv3.NetworkPolicy,
+) ([]ApplicablePolicy, error) {
+	var policies []ApplicablePolicy
+	for _, gnp := range gnps {
+		if gnp.Spec.DoNotTrack || gnp.Spec.PreDNAT {
+			continue
+		}
+		p := ApplicablePolicy{Kind: apiv3.KindGlobalNetworkPolicy, Name: gnp.Name, Order: gnp.Spec.Order}
+		if applies, err := selectorMatches(p, gnp.Spec.Selector, wep.Labels); err != nil {
+			return nil, err
+		} else if applies {
+			policies = append(policies, p)
+		}
+	}
+	for _, np := range nps {
+		if np.Namespace != wep.Namespace {
+			continue
+		}
+		p := ApplicablePolicy{Kind: apiv3.KindNetworkPolicy, Namespace: np.Namespace, Name: np.Name, Order: np.Spec.Order}
+		if applies, err := selectorMatches(p, np.Spec.Selector, wep.Labels); err != nil {
+			return nil, err
+		} else if applies {
+			policies = append(policies, p)
+		}
+	}
+
+	sort.SliceStable(policies, func(i, j int) bool {
+		oi, oj := policies[i].Order, policies[j].Order
+		switch {
+		case oi != nil && oj != nil && *oi != *oj:
+			return *oi < *oj
+		case oi != nil && oj == nil:
+			return true
+		case oi == nil && oj != nil:
+			return false
+		}
+		return policies[i].Name < policies[j].Name
+	})
+	return policies, nil
+}
+
+// selectorMatches returns whether the selector of the policy matches the labels.
+func selectorMatches(p ApplicablePolicy, sel string, labels map[string]string) (bool, error) {
+	parsed, err := selector.Parse(sel)
+	if err != nil {
+		return false, fmt.Errorf("invalid selector of %s %s: %v", p.Kind, p.Name, err)
+	}
+	return parsed.Evaluate(labels), nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+)
+
+var _ = Describe("Policy applicability tests", func() {
+	order := func(o float64) *float64 { return &o }
+
+	wep := &apiv3.WorkloadEndpoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node--1-k8s-pod-eth0",
+			Namespace: "namespace-1",
+			Labels:    map[string]string{"app": "frontend", "env": "prod"},
+		},
+	}
+
+	gnp := func(name string, o *float64, sel string) apiv3.GlobalNetworkPolicy {
+		p := apiv3.NewGlobalNetworkPolicy()
+		p.Name = name
+		p.Spec.Order = o
+		p.Spec.Selector = sel
+		return *p
+	}
+
+	np := func(namespace, name string, o *float64, sel string) apiv3.NetworkPolicy {
+		p := apiv3.NewNetworkPolicy()
+		p.Namespace = namespace
+		p.Name = name
+		p.Spec.Order = o
+		p.Spec.Selector = sel
+		return *p
+	}
+
+	It("should return the matching policies ordered by Order and then name", func() {
+		policies, err := PoliciesForWorkloadEndpoint(wep,
+			[]apiv3.GlobalNetworkPolicy{
+				gnp("gnp-no-order", nil, "app == 'frontend'"),
+				gnp("gnp-backend", order(1), "app == 'backend'"),
+				gnp("gnp-prod", order(10), "env == 'prod'"),
+				gnp("gnp-all", order(5), ""),
+			},
+			[]apiv3.NetworkPolicy{
+				np("namespace-1", "np-frontend", order(10), "app == 'frontend' && has(env)"),
+				np("namespace-1", "np-staging", order(1), "env == 'staging'"),
+				np("namespace-2", "np-other-namespace", order(1), "all()"),
+			},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policies).To(Equal([]ApplicablePolicy{
+			{Kind: apiv3.KindGlobalNetworkPolicy, Name: "gnp-all", Order: order(5)},
+			{Kind: apiv3.KindGlobalNetworkPolicy, Name: "gnp-prod", Order: order(10)},
+			{Kind: apiv3.KindNetworkPolicy, Namespace: "namespace-1", Name: "np-frontend", Order: order(10)},
+			{Kind: apiv3.KindGlobalNetworkPolicy, Name: "gnp-no-order"},
+		}))
+	})
+
+	It("should not return host endpoint only policies", func() {
+		untracked := gnp("gnp-untracked", order(1), "all()")
+		untracked.Spec.DoNotTrack = true
+		preDNAT := gnp("gnp-pre-dnat", order(1), "all()")
+		preDNAT.Spec.PreDNAT = true
+
+		policies, err := PoliciesForWorkloadEndpoint(wep, []apiv3.GlobalNetworkPolicy{untracked, preDNAT}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policies).To(BeEmpty())
+	})
+
+	It("should return no policies when no selectors match", func() {
+		policies, err := PoliciesForWorkloadEndpoint(wep,
+			[]apiv3.GlobalNetworkPolicy{gnp("gnp-backend", order(1), "app == 'backend'")},
+			[]apiv3.NetworkPolicy{np("namespace-1", "np-no-env", nil, "!has(env)")},
+		)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policies).To(BeEmpty())
+	})
+
+	It("should return an error for an invalid selector", func() {
+		_, err := PoliciesForWorkloadEndpoint(wep,
+			[]apiv3.GlobalNetworkPolicy{gnp("gnp-invalid", nil, "app ==")}, nil,
+		)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("invalid selector of GlobalNetworkPolicy gnp-invalid: "))
+	})
+})
@@ -0,0 +1,153 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"fmt"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	sel "github.com/projectcalico/libcalico-go/lib/selector"
+)
+
+// PolicyLimits configures the maximum size of the GlobalNetworkPolicy, NetworkPolicy and
+// Profile resources accepted by the client.  A zero value for a limit means it is not
+// enforced.
+type PolicyLimits struct {
+	// The maximum number of rules, summed across the ingress and egress rules.
+	MaxRules int
+
+	// The maximum rule expansion, summed across the ingress and egress rules.  The expansion
+	// of a rule is the number of combinations of source and destination nets and ports it
+	// matches, which approximates the number of dataplane rules required to program it.  For
+	// example, a rule with two source nets and three destination ports has an expansion of
+	// six.  A rule with no nets or ports has an expansion of one.
+	MaxRuleExpansion int
+
+	// The maximum length of each selector in the resource, including the selectors in the
+	// rules.
+	MaxSelectorLength int
+
+	// The maximum parenthesis nesting depth of each selector in the resource, including the
+	// selectors in the rules.  For example, "(a == 'b' || (c == 'd'))" has a depth of two.
+	MaxSelectorDepth int
+}
+
+// WithPolicyLimits enables validation that the GlobalNetworkPolicy, NetworkPolicy and Profile
+// resources written by the client do not exceed the supplied limits.  Resources exceeding the
+// limits are rejected with an ErrorValidation.
+func WithPolicyLimits(limits PolicyLimits) Option {
+	return func(c *client) {
+		c.policyLimits = &limits
+	}
+}
+
+// validatePolicyLimits checks the supplied selector and rules of a resource of the supplied
+// kind against the configured policy limits.  The selector is blank for resources that do not
+// have a selector.
+func (c client) validatePolicyLimits(kind, selector string, ingress, egress []apiv3.Rule) error {
+	if c.policyLimits == nil {
+		return nil
+	}
+
+	var fields []cerrors.ErroredField
+	numRules := len(ingress) + len(egress)
+	if c.policyLimits.MaxRules > 0 && numRules > c.policyLimits.MaxRules {
+		fields = append(fields, cerrors.ErroredField{
+			Name:   kind + ".Spec",
+			Value:  numRules,
+			Reason: fmt.Sprintf("number of rules (%d) exceeds the maximum of %d", numRules, c.policyLimits.MaxRules),
+		})
+	}
+
+	expansion := 0
+	for _, rules := range [][]apiv3.Rule{ingress, egress} {
+		for _, r := range rules {
+			expansion += entityRuleExpansion(r.Source) * entityRuleExpansion(r.Destination)
+		}
+	}
+	if c.policyLimits.MaxRuleExpansion > 0 && expansion > c.policyLimits.MaxRuleExpansion {
+		fields = append(fields, cerrors.ErroredField{
+			Name:   kind + ".Spec",
+			Value:  expansion,
+			Reason: fmt.Sprintf("rule expansion (%d) exceeds the maximum of %d", expansion, c.policyLimits.MaxRuleExpansion),
+		})
+	}
+
+	if c.policyLimits.MaxSelectorLength > 0 || c.policyLimits.MaxSelectorDepth > 0 {
+		fields = append(fields, c.validateSelectorLimits(kind+".Spec.Selector", selector)...)
+		for _, d := range []struct {
+			name  string
+			rules []apiv3.Rule
+		}{{kind + ".Spec.Ingress", ingress}, {kind + ".Spec.Egress", egress}} {
+			for i, r := range d.rules {
+				prefix := fmt.Sprintf("%s[%d]", d.name, i)
+				fields = append(fields, c.validateEntityRuleSelectorLimits(prefix+".Source", r.Source)...)
+				fields = append(fields, c.validateEntityRuleSelectorLimits(prefix+".Destination", r.Destination)...)
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return cerrors.ErrorValidation{ErroredFields: fields}
+	}
+	return nil
+}
+
+// validateEntityRuleSelectorLimits checks each of the selectors in the entity rule against
+// the configured selector limits.
+func (c client) validateEntityRuleSelectorLimits(name string, er apiv3.EntityRule) []cerrors.ErroredField {
+	fields := c.validateSelectorLimits(name+".Selector", er.Selector)
+	fields = append(fields, c.validateSelectorLimits(name+".NotSelector", er.NotSelector)...)
+	fields = append(fields, c.validateSelectorLimits(name+".NamespaceSelector", er.NamespaceSelector)...)
+	if er.ServiceAccounts != nil {
+		fields = append(fields, c.validateSelectorLimits(name+".ServiceAccounts.Selector", er.ServiceAccounts.Selector)...)
+	}
+	return fields
+}
+
+// validateSelectorLimits checks the selector against the configured maximum selector length
+// and depth.
+func (c client) validateSelectorLimits(name, selector string) []cerrors.ErroredField {
+	var fields []cerrors.ErroredField
+	if c.policyLimits.MaxSelectorLength > 0 && len(selector) > c.policyLimits.MaxSelectorLength {
+		fields = append(fields, cerrors.ErroredField{
+			Name:   name,
+			Value:  selector,
+			Reason: fmt.Sprintf("selector length (%d) exceeds the maximum of %d", len(selector), c.policyLimits.MaxSelectorLength),
+		})
+	}
+	if depth := sel.Depth(selector); c.policyLimits.MaxSelectorDepth > 0 && depth > c.policyLimits.MaxSelectorDepth {
+		fields = append(fields, cerrors.ErroredField{
+			Name:   name,
+			Value:  selector,
+			Reason: fmt.Sprintf("selector nesting depth (%d) exceeds the maximum of %d", depth, c.policyLimits.MaxSelectorDepth),
+		})
+	}
+	return fields
+}
+
+// entityRuleExpansion returns the number of combinations of nets and ports matched by the
+// entity rule.
+func entityRuleExpansion(er apiv3.EntityRule) int {
+	return atLeastOne(len(er.Nets)+len(er.NotNets)) * atLeastOne(len(er.Ports)+len(er.NotPorts))
+}
+
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/numorstring"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+var _ = Describe("Client policy limits tests", func() {
+	ctx := context.Background()
+	var be *countingBackend
+	var c client
+
+	BeforeEach(func() {
+		be = &countingBackend{}
+		logger := log.NewEntry(log.StandardLogger())
+		c = client{
+			backend:   be,
+			resources: newResources(be, nil, logger),
+			logger:    logger,
+		}
+		WithPolicyLimits(PolicyLimits{
+			MaxRules:          2,
+			MaxRuleExpansion:  4,
+			MaxSelectorLength: 40,
+			MaxSelectorDepth:  2,
+		})(&c)
+	})
+
+	tcp := numorstring.ProtocolFromString("TCP")
+	allow := apiv3.Rule{Action: apiv3.Allow}
+	expanded := apiv3.Rule{
+		Action:   apiv3.Allow,
+		Protocol: &tcp,
+		Source:   apiv3.EntityRule{Nets: []string{"10.0.0.0/24", "10.0.1.0/24"}},
+		Destination: apiv3.EntityRule{
+			Ports: []numorstring.Port{numorstring.SinglePort(80), numorstring.SinglePort(443)},
+		},
+	}
+
+	gnp := func(ingress, egress []apiv3.Rule) *apiv3.GlobalNetworkPolicy {
+		return &apiv3.GlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy1"},
+			Spec:       apiv3.GlobalNetworkPolicySpec{Ingress: ingress, Egress: egress},
+		}
+	}
+
+	It("should accept a policy at the limits", func() {
+		_, err := c.GlobalNetworkPolicies().Create(ctx, gnp([]apiv3.Rule{expanded}, nil), options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.GlobalNetworkPolicies().Create(ctx, gnp([]apiv3.Rule{allow}, []apiv3.Rule{allow}), options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(be.calls).To(Equal(2))
+	})
+
+	It("should reject a policy with too many rules", func() {
+		_, err := c.GlobalNetworkPolicies().Create(ctx, gnp([]apiv3.Rule{allow, allow}, []apiv3.Rule{allow}), options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		Expect(err.(cerrors.ErrorValidation).ErroredFields[0].Reason).To(Equal("number of rules (3) exceeds the maximum of 2"))
+		Expect(be.calls).To(Equal(0))
+	})
+
+	It("should reject a policy with too large a rule expansion", func() {
+		_, err := c.GlobalNetworkPolicies().Create(ctx, gnp([]apiv3.Rule{expanded}, []apiv3.Rule{allow}), options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		Expect(err.(cerrors.ErrorValidation).ErroredFields[0].Reason).To(Equal("rule expansion (5) exceeds the maximum of 4"))
+		Expect(be.calls).To(Equal(0))
+	})
+
+	It("should reject a profile over the limits", func() {
+		_, err := c.Profiles().Create(ctx, &apiv3.Profile{
+			ObjectMeta: metav1.ObjectMeta{Name: "profile1"},
+			Spec:       apiv3.ProfileSpec{Ingress: []apiv3.Rule{allow, allow, allow}},
+		}, options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		Expect(be.calls).To(Equal(0))
+	})
+
+	It("should accept selectors at the limits", func() {
+		policy := gnp([]apiv3.Rule{{
+			Action: apiv3.Allow,
+			Source: apiv3.EntityRule{Selector: "((a == 'b') || has(c)) && d == 'eeeeee'"},
+		}}, nil)
+		policy.Spec.Selector = "all()"
+		_, err := c.GlobalNetworkPolicies().Create(ctx, policy, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(be.calls).To(Equal(1))
+	})
+
+	It("should reject an over-length selector", func() {
+		policy := gnp(nil, nil)
+		policy.Spec.Selector = "label1 == 'value1' && label2 == 'value2' && label3 == 'value3'"
+		_, err := c.GlobalNetworkPolicies().Create(ctx, policy, options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		field := err.(cerrors.ErrorValidation).ErroredFields[0]
+		Expect(field.Name).To(Equal("GlobalNetworkPolicy.Spec.Selector"))
+		Expect(field.Reason).To(Equal("selector length (62) exceeds the maximum of 40"))
+		Expect(be.calls).To(Equal(0))
+	})
+
+	It("should reject a deeply nested rule selector", func() {
+		_, err := c.GlobalNetworkPolicies().Create(ctx, gnp(nil, []apiv3.Rule{allow, {
+			Action:      apiv3.Allow,
+			Destination: apiv3.EntityRule{NotSelector: "(((a == 'b')))"},
+		}}), options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		field := err.(cerrors.ErrorValidation).ErroredFields[0]
+		Expect(field.Name).To(Equal("GlobalNetworkPolicy.Spec.Egress[1].Destination.NotSelector"))
+		Expect(field.Reason).To(Equal("selector nesting depth (3) exceeds the maximum of 2"))
+		Expect(be.calls).To(Equal(0))
+	})
+
+	It("should not limit policies when the option is not set", func() {
+		c.policyLimits = nil
+		_, err := c.GlobalNetworkPolicies().Create(ctx, gnp([]apiv3.Rule{expanded, allow}, []apiv3.Rule{allow}), options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(be.calls).To(Equal(1))
+	})
+})
@@ -0,0 +1,88 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+var _ = Describe("Client strict policy types tests", func() {
+	ctx := context.Background()
+	var be *countingBackend
+	var c client
+
+	BeforeEach(func() {
+		be = &countingBackend{}
+		logger := log.NewEntry(log.StandardLogger())
+		c = client{
+			backend:   be,
+			resources: newResources(be, nil, logger),
+			logger:    logger,
+		}
+	})
+
+	allow := apiv3.Rule{Action: apiv3.Allow}
+	ingressOnly := []apiv3.PolicyType{apiv3.PolicyTypeIngress}
+
+	gnp := func(types []apiv3.PolicyType, ingress, egress []apiv3.Rule) *apiv3.GlobalNetworkPolicy {
+		return &apiv3.GlobalNetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy1"},
+			Spec:       apiv3.GlobalNetworkPolicySpec{Types: types, Ingress: ingress, Egress: egress},
+		}
+	}
+	np := func(types []apiv3.PolicyType, ingress, egress []apiv3.Rule) *apiv3.NetworkPolicy {
+		return &apiv3.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy1", Namespace: "default"},
+			Spec:       apiv3.NetworkPolicySpec{Types: types, Ingress: ingress, Egress: egress},
+		}
+	}
+
+	It("should accept an ingress policy with egress rules by default", func() {
+		_, err := c.GlobalNetworkPolicies().Create(ctx, gnp(ingressOnly, nil, []apiv3.Rule{allow}), options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(be.calls).To(Equal(1))
+	})
+
+	It("should accept consistent policies when strict", func() {
+		WithStrictPolicyTypes()(&c)
+		_, err := c.GlobalNetworkPolicies().Create(ctx, gnp(ingressOnly, []apiv3.Rule{allow}, nil), options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = c.NetworkPolicies().Create(ctx, np(nil, []apiv3.Rule{allow}, []apiv3.Rule{allow}), options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(be.calls).To(Equal(2))
+	})
+
+	It("should reject an ingress policy with egress rules when strict", func() {
+		WithStrictPolicyTypes()(&c)
+		_, err := c.GlobalNetworkPolicies().Create(ctx, gnp(ingressOnly, []apiv3.Rule{allow}, []apiv3.Rule{allow}), options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		Expect(err.Error()).To(Equal("error with field GlobalNetworkPolicy.Spec.Egress = '[Ingress]' (policy has egress rules but Types does not include Egress)"))
+
+		p := np(ingressOnly, nil, []apiv3.Rule{allow})
+		p.ResourceVersion = "1"
+		_, err = c.NetworkPolicies().Update(ctx, p, options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		Expect(be.calls).To(Equal(0))
+	})
+})
@@ -44,6 +44,9 @@ func (r profiles) Create(ctx context.Context, res *apiv3.Profile, opts options.S
 	if err := validator.Validate(res); err != nil {
 		return nil, err
 	}
+	if err := r.client.validatePolicyLimits(apiv3.KindProfile, "", res.Spec.Ingress, res.Spec.Egress); err != nil {
+		return nil, err
+	}
 
 	out, err := r.client.resources.Create(ctx, opts, apiv3.KindProfile, res)
 	if out != nil {
@@ -58,6 +61,9 @@ func (r profiles) Update(ctx context.Context, res *apiv3.Profile, opts options.S
 	if err := validator.Validate(res); err != nil {
 		return nil, err
 	}
+	if err := r.client.validatePolicyLimits(apiv3.KindProfile, "", res.Spec.Ingress, res.Spec.Egress); err != nil {
+		return nil, err
+	}
 
 	out, err := r.client.resources.Update(ctx, opts, apiv3.KindProfile, res)
 	if out != nil {
@@ -58,13 +58,17 @@ type resourceInterface interface {
 	Update(ctx context.Context, opts options.SetOptions, kind string, in resource) (resource, error)
 	Delete(ctx context.Context, opts options.DeleteOptions, kind, ns, name string) (resource, error)
 	Get(ctx context.Context, opts options.GetOptions, kind, ns, name string) (resource, error)
+	Exists(ctx context.Context, opts options.GetOptions, kind, ns, name string) (bool, error)
 	List(ctx context.Context, opts options.ListOptions, kind, listkind string, inout resourceList) error
+	ListFiltered(ctx context.Context, opts options.ListOptions, kind, listkind string, filter model.ResourceFilter, inout resourceList) error
+	Count(ctx context.Context, opts options.ListOptions, kind string) (int, error)
 	Watch(ctx context.Context, opts options.ListOptions, kind string, converter watcherConverter) (watch.Interface, error)
 }
 
 // resources implements resourceInterface.
 type resources struct {
 	backend bapi.Client
+	logger  *log.Entry
 }
 
 // Create creates a resource in the backend datastore.
@@ -86,7 +90,7 @@ func (c *resources) Create(ctx context.Context, opts options.SetOptions, kind st
 
 	// A ResourceVersion should never be specified on a Create.
 	if len(in.GetObjectMeta().GetResourceVersion()) != 0 {
-		logWithResource(in).Info("Rejecting Create request with non-empty resource version")
+		c.logWithResource(in).Info("Rejecting Create request with non-empty resource version")
 		return nil, cerrors.ErrorValidation{
 			ErroredFields: []cerrors.ErroredField{{
 				Name:   "Metadata.ResourceVersion",
@@ -109,8 +113,15 @@ func (c *resources) Create(ctx context.Context, opts options.SetOptions, kind st
 	}
 
 	// Convert the resource to a KVPair and pass that to the backend datastore, converting
-	// the response (if we get one) back to a resource.
-	kvp, err := c.backend.Create(ctx, c.resourceToKVPair(opts, kind, in))
+	// the response (if we get one) back to a resource.  For a dry run, return the resource
+	// that would have been written.
+	c.logWithResource(in).Debug("Creating resource")
+	kvp := c.resourceToKVPair(opts, kind, in)
+	if opts.DryRun {
+		c.logWithResource(in).Debug("Dry run - not creating resource")
+		return c.kvPairToResource(kvp), nil
+	}
+	kvp, err := c.backend.Create(ctx, kvp)
 	if kvp != nil {
 		return c.kvPairToResource(kvp), err
 	}
@@ -121,7 +132,7 @@ func (c *resources) Create(ctx context.Context, opts options.SetOptions, kind st
 func (c *resources) Update(ctx context.Context, opts options.SetOptions, kind string, in resource) (resource, error) {
 	// A ResourceVersion should always be specified on an Update.
 	if len(in.GetObjectMeta().GetResourceVersion()) == 0 {
-		logWithResource(in).Info("Rejecting Update request with empty resource version")
+		c.logWithResource(in).Info("Rejecting Update request with empty resource version")
 		return nil, cerrors.ErrorValidation{
 			ErroredFields: []cerrors.ErroredField{{
 				Name:   "Metadata.ResourceVersion",
@@ -154,8 +165,14 @@ func (c *resources) Update(ctx context.Context, opts options.SetOptions, kind st
 	}
 
 	// Convert the resource to a KVPair and pass that to the backend datastore, converting
-	// the response (if we get one) back to a resource.
-	kvp, err := c.backend.Update(ctx, c.resourceToKVPair(opts, kind, in))
+	// the response (if we get one) back to a resource.  For a dry run, return the resource
+	// that would have been written.
+	kvp := c.resourceToKVPair(opts, kind, in)
+	if opts.DryRun {
+		c.logWithResource(in).Debug("Dry run - not updating resource")
+		return c.kvPairToResource(kvp), nil
+	}
+	kvp, err := c.backend.Update(ctx, kvp)
 	if kvp != nil {
 		return c.kvPairToResource(kvp), err
 	}
@@ -198,13 +215,40 @@ func (c *resources) Get(ctx context.Context, opts options.GetOptions, kind, ns,
 	return out, nil
 }
 
+// Exists returns whether a resource exists in the backend datastore.
+func (c *resources) Exists(ctx context.Context, opts options.GetOptions, kind, ns, name string) (bool, error) {
+	if err := c.checkNamespace(ns, kind); err != nil {
+		return false, err
+	}
+	key := model.ResourceKey{
+		Kind:      kind,
+		Name:      name,
+		Namespace: ns,
+	}
+	return c.backend.Exists(ctx, key, opts.ResourceVersion)
+}
+
 // List lists a resource from the backend datastore.
 func (c *resources) List(ctx context.Context, opts options.ListOptions, kind, listKind string, listObj resourceList) error {
+	return c.ListFiltered(ctx, opts, kind, listKind, nil, listObj)
+}
+
+// ListFiltered lists a resource from the backend datastore, returning only the resources
+// that match the supplied filter.  The filter is applied by the backend.  A nil filter
+// matches all resources.
+func (c *resources) ListFiltered(ctx context.Context, opts options.ListOptions, kind, listKind string, filter model.ResourceFilter, listObj resourceList) error {
+	if err := validateSortBy(opts.SortBy); err != nil {
+		return err
+	}
+
 	list := model.ResourceListOptions{
 		Kind:      kind,
 		Name:      opts.Name,
 		Namespace: opts.Namespace,
 		Prefix:    opts.Prefix,
+		Limit:     opts.Limit,
+		Continue:  opts.Continue,
+		Filter:    filter,
 	}
 
 	// Query the backend.
@@ -213,18 +257,32 @@ func (c *resources) List(ctx context.Context, opts options.ListOptions, kind, li
 		return err
 	}
 
+	// The backend skips entries that cannot be parsed, returning their errors.  Fail the
+	// List if requested, otherwise just log them.
+	if len(kvps.Errors) > 0 {
+		if opts.Strict {
+			return cerrors.ErrorCollectionFailure{Operation: "List", Errors: kvps.Errors}
+		}
+		for _, e := range kvps.Errors {
+			c.logger.WithError(e).WithField("Kind", kind).Warning("Skipping resource that could not be parsed")
+		}
+	}
+
 	// Convert the slice of KVPairs to a slice of Objects.
 	resources := []runtime.Object{}
 	for _, kvp := range kvps.KVPairs {
 		resources = append(resources, c.kvPairToResource(kvp))
 	}
+	sortResources(resources, opts.SortBy)
 	err = meta.SetList(listObj, resources)
 	if err != nil {
 		return err
 	}
 
-	// Finally, set the resource version and api group version of the list object.
+	// Finally, set the resource version, continue token and api group version of the list
+	// object.
 	listObj.GetListMeta().SetResourceVersion(kvps.Revision)
+	listObj.GetListMeta().SetContinue(kvps.Continue)
 	listObj.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{
 		Group:   apiv3.Group,
 		Version: apiv3.VersionCurrent,
@@ -234,6 +292,18 @@ func (c *resources) List(ctx context.Context, opts options.ListOptions, kind, li
 	return nil
 }
 
+// Count returns the number of resources in the backend datastore that match the supplied
+// options.  Only the name, namespace and prefix options are used.
+func (c *resources) Count(ctx context.Context, opts options.ListOptions, kind string) (int, error) {
+	list := model.ResourceListOptions{
+		Kind:      kind,
+		Name:      opts.Name,
+		Namespace: opts.Namespace,
+		Prefix:    opts.Prefix,
+	}
+	return c.backend.Count(ctx, list, opts.ResourceVersion)
+}
+
 // Watch watches a specific resource or resource type.
 func (c *resources) Watch(ctx context.Context, opts options.ListOptions, kind string, converter watcherConverter) (watch.Interface, error) {
 	list := model.ResourceListOptions{
@@ -340,7 +410,7 @@ func (w *watcher) ResultChan() <-chan watch.Event {
 // run is the main watch loop, pulling events from the backend watcher and sending
 // down the results channel.
 func (w *watcher) run() {
-	log.Info("Main client watcher loop")
+	w.client.logger.Info("Main client watcher loop")
 
 	// Make sure we terminate resources if we exit.
 	defer w.terminate()
@@ -349,18 +419,18 @@ func (w *watcher) run() {
 		select {
 		case event, ok := <-w.backend.ResultChan():
 			if !ok {
-				log.Debug("Watcher results channel closed by remote")
+				w.client.logger.Debug("Watcher results channel closed by remote")
 				return
 			}
 			e := w.convertEvent(event)
 			select {
 			case w.results <- e:
 			case <-w.context.Done():
-				log.Info("Process backend watcher done event during watch event in main client")
+				w.client.logger.Info("Process backend watcher done event during watch event in main client")
 				return
 			}
 		case <-w.context.Done(): // user cancel
-			log.Info("Process backend watcher done event in main client")
+			w.client.logger.Info("Process backend watcher done event in main client")
 			return
 		}
 	}
@@ -368,7 +438,7 @@ func (w *watcher) run() {
 
 // terminate all resources associated with this watcher.
 func (w *watcher) terminate() {
-	log.Info("Terminating main client watcher loop")
+	w.client.logger.Info("Terminating main client watcher loop")
 	w.cancel()
 	close(w.results)
 	atomic.AddUint32(&w.terminated, 1)
@@ -413,13 +483,13 @@ func (w *watcher) convertEvent(backendEvent bapi.WatchEvent) watch.Event {
 func (w *watcher) hasTerminated() bool {
 	t := atomic.LoadUint32(&w.terminated) != 0
 	bt := w.backend.HasTerminated()
-	log.Infof("hasTerminated() terminated=%v; backend-terminated=%v", t, bt)
+	w.client.logger.Infof("hasTerminated() terminated=%v; backend-terminated=%v", t, bt)
 	return t && bt
 }
 
 // logWithResource returns a logrus entry with key resource attributes included.
-func logWithResource(res resource) *log.Entry {
-	return log.WithFields(log.Fields{
+func (c *resources) logWithResource(res resource) *log.Entry {
+	return c.logger.WithFields(log.Fields{
 		"Kind":            res.GetObjectKind().GroupVersionKind(),
 		"Name":            res.GetObjectMeta().GetName(),
 		"Namespace":       res.GetObjectMeta().GetNamespace(),
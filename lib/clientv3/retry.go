@@ -0,0 +1,209 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+// RetryConfig configures the retry of resource operations that fail with a transient
+// datastore error.
+type RetryConfig struct {
+	// The maximum number of attempts for each operation, including the first attempt.  A
+	// value less than two disables retries.
+	MaxAttempts int
+
+	// The backoff before the first retry.  The backoff is doubled for each subsequent
+	// retry up to MaxBackoff.
+	InitialBackoff time.Duration
+
+	// The maximum backoff between retries.  If zero, the backoff is not limited.
+	MaxBackoff time.Duration
+
+	// IsTransient classifies whether an error is transient and the operation should be
+	// retried.  If nil, IsTransientError is used.  A custom classifier may call
+	// IsTransientError to extend the default classification.
+	IsTransient func(error) bool
+}
+
+// WithRetry enables retry with exponential backoff of the Create, Update, Apply, Delete,
+// Get, Exists, List and Count operations for the Calico resources when the datastore
+// returns a transient error.  Watch and IPAM operations are not retried.
+func WithRetry(config RetryConfig) Option {
+	return func(c *client) {
+		c.retry = &config
+	}
+}
+
+// IsTransientError is the default classifier of transient errors.  A datastore error is
+// transient if the underlying cause is one that may succeed on retry: a Kubernetes API
+// server overload (429) or server error (5xx), a timeout, or etcd being unavailable (for
+// example during a leader election).  Other datastore errors (such as a Kubernetes API
+// request that is rejected as invalid), validation errors, not-found errors, already-exists
+// errors and update conflicts are not transient, since retrying the same request would not
+// succeed.
+func IsTransientError(err error) bool {
+	e, ok := err.(cerrors.ErrorDatastoreError)
+	if !ok || e.Err == nil {
+		return false
+	}
+	cause := e.Err
+
+	// Kubernetes API errors.
+	if s, ok := cause.(kerrors.APIStatus); ok {
+		code := s.Status().Code
+		return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+
+	// etcd errors.
+	switch cause {
+	case rpctypes.ErrNoLeader, rpctypes.ErrNotLeader, rpctypes.ErrLeaderChanged,
+		rpctypes.ErrTimeout, rpctypes.ErrTimeoutDueToLeaderFail, rpctypes.ErrTimeoutDueToConnectionLost,
+		rpctypes.ErrUnhealthy, rpctypes.ErrTooManyRequests:
+		return true
+	}
+	if s, ok := status.FromError(cause); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+			return true
+		}
+		return false
+	}
+
+	// Network timeouts.
+	if ne, ok := cause.(net.Error); ok && ne.Timeout() {
+		return true
+	}
+	return false
+}
+
+// retryingBackend wraps a backend client, retrying operations that fail with a transient
+// error.
+type retryingBackend struct {
+	bapi.Client
+	config RetryConfig
+	logger *log.Entry
+}
+
+func newRetryingBackend(be bapi.Client, config RetryConfig, logger *log.Entry) bapi.Client {
+	if config.IsTransient == nil {
+		config.IsTransient = IsTransientError
+	}
+	return &retryingBackend{Client: be, config: config, logger: logger}
+}
+
+// do calls the operation, retrying with exponential backoff while the operation returns
+// a transient error.
+func (r *retryingBackend) do(ctx context.Context, op string, f func() error) error {
+	backoff := r.config.InitialBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = f(); err == nil || !r.config.IsTransient(err) || attempt >= r.config.MaxAttempts {
+			return err
+		}
+		r.logger.WithError(err).WithFields(log.Fields{
+			"Operation": op,
+			"Attempt":   attempt,
+			"Backoff":   backoff,
+		}).Info("Transient datastore error - retrying")
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+		if r.config.MaxBackoff > 0 && backoff > r.config.MaxBackoff {
+			backoff = r.config.MaxBackoff
+		}
+	}
+}
+
+func (r *retryingBackend) Create(ctx context.Context, object *model.KVPair) (kvp *model.KVPair, err error) {
+	err = r.do(ctx, "Create", func() error {
+		kvp, err = r.Client.Create(ctx, object)
+		return err
+	})
+	return
+}
+
+func (r *retryingBackend) Update(ctx context.Context, object *model.KVPair) (kvp *model.KVPair, err error) {
+	err = r.do(ctx, "Update", func() error {
+		kvp, err = r.Client.Update(ctx, object)
+		return err
+	})
+	return
+}
+
+func (r *retryingBackend) Apply(ctx context.Context, object *model.KVPair) (kvp *model.KVPair, err error) {
+	err = r.do(ctx, "Apply", func() error {
+		kvp, err = r.Client.Apply(ctx, object)
+		return err
+	})
+	return
+}
+
+func (r *retryingBackend) Delete(ctx context.Context, key model.Key, revision string) (kvp *model.KVPair, err error) {
+	err = r.do(ctx, "Delete", func() error {
+		kvp, err = r.Client.Delete(ctx, key, revision)
+		return err
+	})
+	return
+}
+
+func (r *retryingBackend) Get(ctx context.Context, key model.Key, revision string) (kvp *model.KVPair, err error) {
+	err = r.do(ctx, "Get", func() error {
+		kvp, err = r.Client.Get(ctx, key, revision)
+		return err
+	})
+	return
+}
+
+func (r *retryingBackend) Exists(ctx context.Context, key model.Key, revision string) (exists bool, err error) {
+	err = r.do(ctx, "Exists", func() error {
+		exists, err = r.Client.Exists(ctx, key, revision)
+		return err
+	})
+	return
+}
+
+func (r *retryingBackend) List(ctx context.Context, list model.ListInterface, revision string) (kvps *model.KVPairList, err error) {
+	err = r.do(ctx, "List", func() error {
+		kvps, err = r.Client.List(ctx, list, revision)
+		return err
+	})
+	return
+}
+
+func (r *retryingBackend) Count(ctx context.Context, list model.ListInterface, revision string) (count int, err error) {
+	err = r.do(ctx, "Count", func() error {
+		count, err = r.Client.Count(ctx, list, revision)
+		return err
+	})
+	return
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	bapi "github.com/projectcalico/libcalico-go/lib/backend/api"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+// errorInjectingBackend implements the Create method of the backend client, returning each
+// of the injected errors in turn before succeeding.
+type errorInjectingBackend struct {
+	bapi.Client
+	errs  []error
+	calls int
+}
+
+func (b *errorInjectingBackend) Create(ctx context.Context, object *model.KVPair) (*model.KVPair, error) {
+	b.calls++
+	if len(b.errs) > 0 {
+		err := b.errs[0]
+		b.errs = b.errs[1:]
+		return nil, err
+	}
+	object.Revision = "1"
+	return object, nil
+}
+
+var _ = Describe("Client retry tests", func() {
+	ctx := context.Background()
+	retry := &RetryConfig{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+	}
+
+	newNetworkSet := func() *apiv3.GlobalNetworkSet {
+		gns := apiv3.NewGlobalNetworkSet()
+		gns.ObjectMeta = metav1.ObjectMeta{Name: "networkset-1"}
+		return gns
+	}
+
+	It("should retry a transient error until the operation succeeds", func() {
+		transient := cerrors.ErrorDatastoreError{Err: rpctypes.ErrLeaderChanged}
+		be := &errorInjectingBackend{errs: []error{transient, transient}}
+		r := newResources(be, retry, log.NewEntry(log.StandardLogger()))
+
+		out, err := r.Create(ctx, options.SetOptions{}, apiv3.KindGlobalNetworkSet, newNetworkSet())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.GetObjectMeta().GetResourceVersion()).To(Equal("1"))
+		Expect(be.calls).To(Equal(3))
+	})
+
+	It("should not retry a validation error", func() {
+		be := &errorInjectingBackend{errs: []error{cerrors.ErrorValidation{}}}
+		r := newResources(be, retry, log.NewEntry(log.StandardLogger()))
+
+		_, err := r.Create(ctx, options.SetOptions{}, apiv3.KindGlobalNetworkSet, newNetworkSet())
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		Expect(be.calls).To(Equal(1))
+	})
+
+	It("should give up after the maximum number of attempts", func() {
+		transient := cerrors.ErrorDatastoreError{Err: rpctypes.ErrLeaderChanged}
+		be := &errorInjectingBackend{errs: []error{transient, transient, transient, transient, transient, transient}}
+		r := newResources(be, retry, log.NewEntry(log.StandardLogger()))
+
+		_, err := r.Create(ctx, options.SetOptions{}, apiv3.KindGlobalNetworkSet, newNetworkSet())
+		Expect(err).To(Equal(transient))
+		Expect(be.calls).To(Equal(5))
+	})
+
+	It("should use a custom error classification", func() {
+		custom := *retry
+		custom.IsTransient = func(err error) bool {
+			_, ok := err.(cerrors.ErrorResourceUpdateConflict)
+			return ok || IsTransientError(err)
+		}
+		be := &errorInjectingBackend{errs: []error{cerrors.ErrorResourceUpdateConflict{}}}
+		r := newResources(be, &custom, log.NewEntry(log.StandardLogger()))
+
+		_, err := r.Create(ctx, options.SetOptions{}, apiv3.KindGlobalNetworkSet, newNetworkSet())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(be.calls).To(Equal(2))
+	})
+
+	DescribeTable("should classify transient errors by the underlying cause",
+		func(err error, expected bool) {
+			Expect(IsTransientError(err)).To(Equal(expected))
+		},
+		Entry("etcd leader changed", cerrors.ErrorDatastoreError{Err: rpctypes.ErrLeaderChanged}, true),
+		Entry("etcd unavailable", cerrors.ErrorDatastoreError{Err: status.Error(codes.Unavailable, "unavailable")}, true),
+		Entry("etcd invalid argument", cerrors.ErrorDatastoreError{Err: status.Error(codes.InvalidArgument, "invalid")}, false),
+		Entry("k8s too many requests", cerrors.ErrorDatastoreError{Err: kerrors.NewTooManyRequests("overloaded", 1)}, true),
+		Entry("k8s internal error", cerrors.ErrorDatastoreError{Err: kerrors.NewInternalError(errors.New("internal"))}, true),
+		Entry("k8s timeout", cerrors.ErrorDatastoreError{Err: kerrors.NewTimeoutError("timeout", 1)}, true),
+		Entry("k8s bad request", cerrors.ErrorDatastoreError{Err: kerrors.NewBadRequest("bad")}, false),
+		Entry("k8s invalid", cerrors.ErrorDatastoreError{Err: kerrors.NewInvalid(schema.GroupKind{Kind: "IPPool"}, "pool", nil)}, false),
+		Entry("unparseable data", cerrors.ErrorDatastoreError{Err: errors.New("unable to parse")}, false),
+		Entry("validation error", cerrors.ErrorValidation{}, false),
+	)
+})
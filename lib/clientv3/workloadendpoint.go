@@ -15,25 +15,57 @@
 package clientv3
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
 
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 	"github.com/projectcalico/libcalico-go/lib/errors"
 	"github.com/projectcalico/libcalico-go/lib/names"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
 	"github.com/projectcalico/libcalico-go/lib/options"
 	validator "github.com/projectcalico/libcalico-go/lib/validator/v3"
 	"github.com/projectcalico/libcalico-go/lib/watch"
 )
 
+// The number of WorkloadEndpoints listed in each page by ListOrphans, if the list options do not
+// specify a limit.
+const orphanListPageSize = 500
+
 // WorkloadEndpointInterface has methods to work with WorkloadEndpoint resources.
 type WorkloadEndpointInterface interface {
 	Create(ctx context.Context, res *apiv3.WorkloadEndpoint, opts options.SetOptions) (*apiv3.WorkloadEndpoint, error)
 	Update(ctx context.Context, res *apiv3.WorkloadEndpoint, opts options.SetOptions) (*apiv3.WorkloadEndpoint, error)
 	Delete(ctx context.Context, namespace, name string, opts options.DeleteOptions) (*apiv3.WorkloadEndpoint, error)
 	Get(ctx context.Context, namespace, name string, opts options.GetOptions) (*apiv3.WorkloadEndpoint, error)
+	Exists(ctx context.Context, namespace, name string, opts options.GetOptions) (bool, error)
 	List(ctx context.Context, opts options.ListOptions) (*apiv3.WorkloadEndpointList, error)
 	Watch(ctx context.Context, opts options.ListOptions) (watch.Interface, error)
+	GetOrCreate(ctx context.Context, res *apiv3.WorkloadEndpoint, opts options.SetOptions) (*apiv3.WorkloadEndpoint, bool, error)
+	Apply(ctx context.Context, res *apiv3.WorkloadEndpoint, opts options.SetOptions) (*apiv3.WorkloadEndpoint, error)
+	UpdateIf(ctx context.Context, namespace, name string, mutate func(*apiv3.WorkloadEndpoint) bool, opts options.SetOptions) (*apiv3.WorkloadEndpoint, bool, error)
+	AddLabels(ctx context.Context, namespace, name string, labels map[string]string, opts options.SetOptions) (*apiv3.WorkloadEndpoint, error)
+	RemoveLabels(ctx context.Context, namespace, name string, keys []string, opts options.SetOptions) (*apiv3.WorkloadEndpoint, error)
+	ListOrphans(ctx context.Context, opts options.ListOptions, checkProfiles bool) (*apiv3.WorkloadEndpointList, error)
+	DeleteCollection(ctx context.Context, opts options.ListOptions) (int, error)
+	Reconcile(ctx context.Context, desired []*apiv3.WorkloadEndpoint, opts options.ListOptions, setOpts options.SetOptions, deleteUndesired bool) (*WorkloadEndpointReconcileResult, error)
+}
+
+// WorkloadEndpointReconcileResult contains the changes made by a Reconcile.
+type WorkloadEndpointReconcileResult struct {
+	// The stored representations of the WorkloadEndpoints that were created.
+	Created []apiv3.WorkloadEndpoint
+	// The stored representations of the WorkloadEndpoints that were updated.
+	Updated []apiv3.WorkloadEndpoint
+	// The WorkloadEndpoints that were deleted.
+	Deleted []apiv3.WorkloadEndpoint
 }
 
 // workloadEndpoints implements WorkloadEndpointInterface
@@ -49,12 +81,19 @@ func (r workloadEndpoints) Create(ctx context.Context, res *apiv3.WorkloadEndpoi
 		// before we do so.
 		resCopy := *res
 		res = &resCopy
+		r.defaultSpec(res)
+		canonicalizeSpec(res)
 	}
-	if err := r.assignOrValidateName(res); err != nil {
+	if err := assignOrValidateName(res); err != nil {
 		return nil, err
-	} else if err := validator.Validate(res); err != nil {
+	} else if err := ValidateWorkloadEndpoint(res, opts); err != nil {
+		return nil, err
+	} else if err := r.maybeValidateProfileReferences(ctx, res); err != nil {
+		return nil, err
+	} else if err := r.validateNoConflicts(ctx, res, opts); err != nil {
 		return nil, err
 	}
+	res.Generation = 1
 	r.updateLabelsForStorage(res)
 	out, err := r.client.resources.Create(ctx, opts, apiv3.KindWorkloadEndpoint, res)
 	if out != nil {
@@ -65,16 +104,27 @@ func (r workloadEndpoints) Create(ctx context.Context, res *apiv3.WorkloadEndpoi
 
 // Update takes the representation of a WorkloadEndpoint and updates it. Returns the stored
 // representation of the WorkloadEndpoint, and an error, if there is any.
+//
+// The Generation of the WorkloadEndpoint is incremented if the spec differs from the stored
+// spec, and is otherwise left unchanged, so that a metadata-only update does not change it.
+// The Generation supplied in the request is ignored.
 func (r workloadEndpoints) Update(ctx context.Context, res *apiv3.WorkloadEndpoint, opts options.SetOptions) (*apiv3.WorkloadEndpoint, error) {
 	if res != nil {
 		// Since we're about to default some fields, take a (shallow) copy of the input data
 		// before we do so.
 		resCopy := *res
 		res = &resCopy
+		canonicalizeSpec(res)
 	}
-	if err := r.assignOrValidateName(res); err != nil {
+	if err := assignOrValidateName(res); err != nil {
+		return nil, err
+	} else if err := ValidateWorkloadEndpoint(res, opts); err != nil {
 		return nil, err
-	} else if err := validator.Validate(res); err != nil {
+	} else if err := r.maybeValidateProfileReferences(ctx, res); err != nil {
+		return nil, err
+	} else if err := r.validateNoConflicts(ctx, res, opts); err != nil {
+		return nil, err
+	} else if err := r.setGeneration(ctx, res); err != nil {
 		return nil, err
 	}
 	r.updateLabelsForStorage(res)
@@ -104,6 +154,13 @@ func (r workloadEndpoints) Get(ctx context.Context, namespace, name string, opts
 	return nil, err
 }
 
+// Exists returns whether the named WorkloadEndpoint exists, and an error if there is any.  This
+// is cheaper than a Get since, where the datastore supports it, the WorkloadEndpoint is not
+// fetched and parsed.
+func (r workloadEndpoints) Exists(ctx context.Context, namespace, name string, opts options.GetOptions) (bool, error) {
+	return r.client.resources.Exists(ctx, opts, apiv3.KindWorkloadEndpoint, namespace, name)
+}
+
 // List returns the list of WorkloadEndpoint objects that match the supplied options.
 func (r workloadEndpoints) List(ctx context.Context, opts options.ListOptions) (*apiv3.WorkloadEndpointList, error) {
 	res := &apiv3.WorkloadEndpointList{}
@@ -119,9 +176,818 @@ func (r workloadEndpoints) Watch(ctx context.Context, opts options.ListOptions)
 	return r.client.resources.Watch(ctx, opts, apiv3.KindWorkloadEndpoint, nil)
 }
 
+// GetOrCreate takes the representation of a WorkloadEndpoint and creates it if it does not
+// already exist.  Returns the stored representation of the WorkloadEndpoint, a flag indicating
+// whether the WorkloadEndpoint was created by this call, and an error, if there is any.  If a
+// WorkloadEndpoint with the same name already exists it is returned unmodified.
+func (r workloadEndpoints) GetOrCreate(ctx context.Context, res *apiv3.WorkloadEndpoint, opts options.SetOptions) (*apiv3.WorkloadEndpoint, bool, error) {
+	if res != nil {
+		// Since we're about to default some fields, take a (shallow) copy of the input data
+		// before we do so.
+		resCopy := *res
+		res = &resCopy
+	}
+	if err := assignOrValidateName(res); err != nil {
+		return nil, false, err
+	}
+
+	var err error
+	for i := 0; i < maxApplyRetries; i++ {
+		// The backend Create will only succeed if the resource does not exist (this is a
+		// transaction in etcdv3), so attempt the Create first.
+		var out *apiv3.WorkloadEndpoint
+		if out, err = r.Create(ctx, res, opts); err == nil {
+			return out, true, nil
+		} else if _, ok := err.(errors.ErrorResourceAlreadyExists); !ok {
+			return nil, false, err
+		}
+
+		// The resource already exists so get the current settings.  If the resource has
+		// been deleted in the meantime then retry the Create.
+		if out, err = r.Get(ctx, res.Namespace, res.Name, options.GetOptions{}); err == nil {
+			return out, false, nil
+		} else if _, ok := err.(errors.ErrorResourceDoesNotExist); !ok {
+			return nil, false, err
+		}
+		r.client.logger.WithField("Retry", i).Debug("WorkloadEndpoint deleted between Create and Get - retry")
+	}
+	return nil, false, err
+}
+
+// Apply takes the representation of a WorkloadEndpoint and creates it if it does not already
+// exist, or otherwise updates the labels, annotations and spec of the existing WorkloadEndpoint
+// to match.  If the existing WorkloadEndpoint already matches then no write is performed and
+// the revision is unchanged, so repeatedly applying the same WorkloadEndpoint does not generate
+// watch events.  Returns the stored (or, if unchanged, the current) representation of the
+// WorkloadEndpoint, and an error, if there is any.
+func (r workloadEndpoints) Apply(ctx context.Context, res *apiv3.WorkloadEndpoint, opts options.SetOptions) (*apiv3.WorkloadEndpoint, error) {
+	if res != nil {
+		// Since we're about to default some fields, take a (shallow) copy of the input data
+		// before we do so.
+		resCopy := *res
+		res = &resCopy
+		r.defaultSpec(res)
+		canonicalizeSpec(res)
+	}
+	if err := assignOrValidateName(res); err != nil {
+		return nil, err
+	}
+
+	var err error
+	for i := 0; i < maxApplyRetries; i++ {
+		// The backend Create will only succeed if the resource does not exist, so attempt the
+		// Create first.
+		var out *apiv3.WorkloadEndpoint
+		if out, err = r.Create(ctx, res, opts); err == nil {
+			return out, nil
+		} else if _, ok := err.(errors.ErrorResourceAlreadyExists); !ok {
+			return nil, err
+		}
+
+		// The resource already exists so update it, but only if the labels, annotations or
+		// spec differ from the current settings.  If the resource has been deleted in the
+		// meantime then retry the Create.
+		out, _, err = r.UpdateIf(ctx, res.Namespace, res.Name, func(wep *apiv3.WorkloadEndpoint) bool {
+			current := wep.DeepCopy()
+			wep.Labels = res.Labels
+			wep.Annotations = res.Annotations
+			wep.Spec = res.Spec
+			r.updateLabelsForStorage(wep)
+			return len(DiffWorkloadEndpoints(current, wep)) > 0
+		}, opts)
+		if err == nil {
+			return out, nil
+		} else if _, ok := err.(errors.ErrorResourceDoesNotExist); !ok {
+			return nil, err
+		}
+		r.client.logger.WithField("Retry", i).Debug("WorkloadEndpoint deleted between Create and Update - retry")
+	}
+	return nil, err
+}
+
+// UpdateIf gets the named WorkloadEndpoint and calls mutate with the current representation.
+// If mutate returns true the modified WorkloadEndpoint is written, but only if it has not been
+// modified since it was read.  If it has been modified, the read and mutate are retried.  If
+// mutate returns false no write is performed.  Returns the stored (or, if not updated, the
+// current) representation of the WorkloadEndpoint, a flag indicating whether the
+// WorkloadEndpoint was updated by this call, and an error, if there is any.
+func (r workloadEndpoints) UpdateIf(ctx context.Context, namespace, name string, mutate func(*apiv3.WorkloadEndpoint) bool, opts options.SetOptions) (*apiv3.WorkloadEndpoint, bool, error) {
+	var err error
+	for i := 0; i < maxApplyRetries; i++ {
+		var current *apiv3.WorkloadEndpoint
+		if current, err = r.Get(ctx, namespace, name, options.GetOptions{}); err != nil {
+			return nil, false, err
+		}
+
+		// Pass a copy to the mutate function so that the current settings are returned
+		// unmodified if the mutate function aborts the update.
+		res := current.DeepCopy()
+		if !mutate(res) {
+			r.client.logger.WithField("WorkloadEndpoint", name).Debug("Update aborted by mutate function")
+			return current, false, nil
+		}
+
+		// The Update is performed at the revision we read, so will fail if the resource
+		// has been modified in the meantime.
+		res.ResourceVersion = current.ResourceVersion
+		var out *apiv3.WorkloadEndpoint
+		if out, err = r.Update(ctx, res, opts); err == nil {
+			return out, true, nil
+		} else if _, ok := err.(errors.ErrorResourceUpdateConflict); !ok {
+			return nil, false, err
+		}
+		r.client.logger.WithField("Retry", i).Debug("WorkloadEndpoint modified between Get and Update - retry")
+	}
+	return nil, false, err
+}
+
+// AddLabels adds the supplied labels to the named WorkloadEndpoint, overwriting the values of
+// any labels that are already present.  Only the labels are modified and the update is retried
+// if the WorkloadEndpoint is modified concurrently.  Labels with the reserved Calico prefix
+// cannot be added.  Returns the stored representation of the WorkloadEndpoint, and an error,
+// if there is any.
+func (r workloadEndpoints) AddLabels(ctx context.Context, namespace, name string, labels map[string]string, opts options.SetOptions) (*apiv3.WorkloadEndpoint, error) {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	if err := validateLabelKeysNotReserved(keys); err != nil {
+		return nil, err
+	}
+	out, _, err := r.UpdateIf(ctx, namespace, name, func(wep *apiv3.WorkloadEndpoint) bool {
+		updated := false
+		for k, v := range labels {
+			if current, ok := wep.Labels[k]; !ok || current != v {
+				if wep.Labels == nil {
+					wep.Labels = map[string]string{}
+				}
+				wep.Labels[k] = v
+				updated = true
+			}
+		}
+		return updated
+	}, opts)
+	return out, err
+}
+
+// RemoveLabels removes the labels with the supplied keys from the named WorkloadEndpoint.  Keys
+// of labels that are not present are ignored.  Only the labels are modified and the update is
+// retried if the WorkloadEndpoint is modified concurrently.  Labels with the reserved Calico
+// prefix cannot be removed.  Returns the stored representation of the WorkloadEndpoint, and an
+// error, if there is any.
+func (r workloadEndpoints) RemoveLabels(ctx context.Context, namespace, name string, keys []string, opts options.SetOptions) (*apiv3.WorkloadEndpoint, error) {
+	if err := validateLabelKeysNotReserved(keys); err != nil {
+		return nil, err
+	}
+	out, _, err := r.UpdateIf(ctx, namespace, name, func(wep *apiv3.WorkloadEndpoint) bool {
+		updated := false
+		for _, k := range keys {
+			if _, ok := wep.Labels[k]; ok {
+				delete(wep.Labels, k)
+				updated = true
+			}
+		}
+		return updated
+	}, opts)
+	return out, err
+}
+
+// ListOrphans returns the WorkloadEndpoints that match the supplied list options and that
+// reference a Node that does not exist.  If checkProfiles is true, WorkloadEndpoints that
+// reference a Profile that does not exist are also returned.  The Nodes and Profiles are
+// listed once up front, and the WorkloadEndpoints are then listed a page at a time, using the
+// Limit of the list options as the page size, so that only the orphaned WorkloadEndpoints are
+// held in memory.  The Continue of the list options is ignored.
+func (r workloadEndpoints) ListOrphans(ctx context.Context, opts options.ListOptions, checkProfiles bool) (*apiv3.WorkloadEndpointList, error) {
+	nodes, err := r.client.Nodes().List(ctx, options.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodeNames := make(map[string]bool, len(nodes.Items))
+	for _, n := range nodes.Items {
+		nodeNames[n.Name] = true
+	}
+
+	var profileNames map[string]bool
+	if checkProfiles {
+		profiles, err := r.client.Profiles().List(ctx, options.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		profileNames = make(map[string]bool, len(profiles.Items))
+		for _, p := range profiles.Items {
+			profileNames[p.Name] = true
+		}
+	}
+
+	pageOpts := opts
+	pageOpts.Continue = ""
+	if pageOpts.Limit == 0 {
+		pageOpts.Limit = orphanListPageSize
+	}
+	orphans := apiv3.NewWorkloadEndpointList()
+	for {
+		page, err := r.List(ctx, pageOpts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range page.Items {
+			if r.isOrphan(&page.Items[i], nodeNames, profileNames) {
+				orphans.Items = append(orphans.Items, page.Items[i])
+			}
+		}
+		orphans.ResourceVersion = page.ResourceVersion
+		if page.Continue == "" {
+			return orphans, nil
+		}
+		pageOpts.Continue = page.Continue
+	}
+}
+
+// isOrphan returns true if the WorkloadEndpoint references a Node that is not in the set of
+// node names, or a Profile that is not in the set of profile names.  Profiles are not checked
+// if the set of profile names is nil.
+func (r workloadEndpoints) isOrphan(wep *apiv3.WorkloadEndpoint, nodeNames, profileNames map[string]bool) bool {
+	logCxt := r.client.logger.WithFields(log.Fields{"Namespace": wep.Namespace, "Name": wep.Name})
+	if !nodeNames[wep.Spec.Node] {
+		logCxt.WithField("Node", wep.Spec.Node).Debug("WorkloadEndpoint references a Node that does not exist")
+		return true
+	}
+	if profileNames == nil {
+		return false
+	}
+	for _, p := range wep.Spec.Profiles {
+		if !profileNames[p] {
+			logCxt.WithField("Profile", p).Debug("WorkloadEndpoint references a Profile that does not exist")
+			return true
+		}
+	}
+	return false
+}
+
+// setGeneration sets the Generation of the WorkloadEndpoint being updated to that of the stored
+// WorkloadEndpoint, incremented if the spec has changed.  The specs are compared in their
+// canonical form.
+func (r workloadEndpoints) setGeneration(ctx context.Context, res *apiv3.WorkloadEndpoint) error {
+	current, err := r.Get(ctx, res.Namespace, res.Name, options.GetOptions{})
+	if err != nil {
+		return err
+	}
+	canonicalizeSpec(current)
+	res.Generation = current.Generation
+	var diffs []FieldDiff
+	diffValues("Spec", reflect.ValueOf(current.Spec), reflect.ValueOf(res.Spec), &diffs)
+	if len(diffs) > 0 {
+		res.Generation++
+	}
+	return nil
+}
+
+// validateLabelKeysNotReserved returns a validation error if any of the label keys have the
+// reserved Calico prefix.  These labels are managed by Calico.
+func validateLabelKeysNotReserved(keys []string) error {
+	reserved := []string{}
+	for _, k := range keys {
+		if strings.HasPrefix(k, apiv3.Group+"/") {
+			reserved = append(reserved, k)
+		}
+	}
+	if len(reserved) == 0 {
+		return nil
+	}
+	sort.Strings(reserved)
+	return errors.ErrorValidation{
+		ErroredFields: []errors.ErroredField{{
+			Name:   "Metadata.Labels",
+			Value:  reserved,
+			Reason: "labels with the " + apiv3.Group + "/ prefix are reserved: " + strings.Join(reserved, ", "),
+		}},
+	}
+}
+
+// MergeWorkloadEndpointLabels returns the union of the labels of the WorkloadEndpoints in the
+// supplied list.  If the same label key has differing values on different WorkloadEndpoints,
+// the key is omitted from the returned labels and an ErrorValidation is returned listing each
+// conflicting key and its values.  The non-conflicting labels are returned in either case.
+func MergeWorkloadEndpointLabels(list *apiv3.WorkloadEndpointList) (map[string]string, error) {
+	merged := map[string]string{}
+	values := map[string][]string{}
+	for _, wep := range list.Items {
+		for k, v := range wep.Labels {
+			if existing, ok := values[k]; !ok {
+				values[k] = []string{v}
+			} else if !containsString(existing, v) {
+				values[k] = append(existing, v)
+			}
+		}
+	}
+
+	conflicts := []string{}
+	for k, vs := range values {
+		if len(vs) == 1 {
+			merged[k] = vs[0]
+		} else {
+			conflicts = append(conflicts, k)
+		}
+	}
+	if len(conflicts) == 0 {
+		return merged, nil
+	}
+
+	sort.Strings(conflicts)
+	err := errors.ErrorValidation{}
+	for _, k := range conflicts {
+		vs := values[k]
+		sort.Strings(vs)
+		err.ErroredFields = append(err.ErroredFields, errors.ErroredField{
+			Name:   "Metadata.Labels",
+			Value:  k,
+			Reason: "conflicting values for label " + k + ": " + strings.Join(vs, ", "),
+		})
+	}
+	return merged, err
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteCollection deletes all of the WorkloadEndpoints that match the supplied list options.
+// Returns the number of WorkloadEndpoints deleted, and an error if the List fails or if any of
+// the individual deletes failed (in which case the error is an ErrorCollectionFailure
+// containing the individual errors).  WorkloadEndpoints that are deleted by another client
+// while this request is in progress are not counted and are not treated as a failure.
+func (r workloadEndpoints) DeleteCollection(ctx context.Context, opts options.ListOptions) (int, error) {
+	list, err := r.List(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	// Delete each endpoint at the revision we listed so that we do not delete an endpoint
+	// that has been modified since the List.
+	deleted := 0
+	var errs []error
+	for _, wep := range list.Items {
+		_, err := r.Delete(ctx, wep.Namespace, wep.Name, options.DeleteOptions{ResourceVersion: wep.ResourceVersion})
+		if err == nil {
+			deleted++
+		} else if _, ok := err.(errors.ErrorResourceDoesNotExist); !ok {
+			r.client.logger.WithError(err).WithField("WorkloadEndpoint", wep.Name).Info("Failed to delete WorkloadEndpoint")
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return deleted, errors.ErrorCollectionFailure{
+			Operation: "delete",
+			Errors:    errs,
+		}
+	}
+	return deleted, nil
+}
+
+// Reconcile updates the WorkloadEndpoints that match the supplied list options to the desired
+// set of WorkloadEndpoints.  Desired endpoints that do not exist are created, and those whose
+// labels, annotations or spec differ from the current state are updated.  Endpoints that are
+// unchanged are not written.  If deleteUndesired is true, the endpoints that match the list
+// options but are not in the desired set are deleted.  The desired endpoints should match the
+// list options, otherwise they will be re-written on every pass.
+//
+// Each desired endpoint is defaulted and validated, as on Create and Update, before any changes
+// are made, and the set options are used for each of the creates and updates.  Failures to apply
+// individual changes do not stop the reconcile: the changes that were made are returned along
+// with an ErrorCollectionFailure containing the failures.
+func (r workloadEndpoints) Reconcile(ctx context.Context, desired []*apiv3.WorkloadEndpoint, opts options.ListOptions, setOpts options.SetOptions, deleteUndesired bool) (*WorkloadEndpointReconcileResult, error) {
+	// Default and validate the desired endpoints, and calculate the names so that we can match
+	// them against the current endpoints.
+	type key struct{ namespace, name string }
+	desiredByKey := make(map[key]*apiv3.WorkloadEndpoint, len(desired))
+	desiredCopies := make([]*apiv3.WorkloadEndpoint, 0, len(desired))
+	for _, res := range desired {
+		resCopy := *res
+		d := &resCopy
+		r.defaultSpec(d)
+		canonicalizeSpec(d)
+		if err := assignOrValidateName(d); err != nil {
+			return nil, err
+		} else if err := ValidateWorkloadEndpoint(d, setOpts); err != nil {
+			return nil, err
+		}
+		k := key{d.Namespace, d.Name}
+		if _, ok := desiredByKey[k]; ok {
+			return nil, errors.ErrorValidation{
+				ErroredFields: []errors.ErroredField{{
+					Name:   "Metadata.Name",
+					Value:  d.Name,
+					Reason: fmt.Sprintf("WorkloadEndpoint %s/%s is specified more than once", d.Namespace, d.Name),
+				}},
+			}
+		}
+		r.updateLabelsForStorage(d)
+		desiredByKey[k] = d
+		desiredCopies = append(desiredCopies, d)
+	}
+
+	list, err := r.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WorkloadEndpointReconcileResult{}
+	var errs []error
+	seen := make(map[key]bool, len(list.Items))
+	for i := range list.Items {
+		current := &list.Items[i]
+		k := key{current.Namespace, current.Name}
+		seen[k] = true
+		logCxt := r.client.logger.WithField("WorkloadEndpoint", current.Namespace+"/"+current.Name)
+
+		d, ok := desiredByKey[k]
+		if !ok {
+			if !deleteUndesired {
+				continue
+			}
+			_, err := r.Delete(ctx, current.Namespace, current.Name, options.DeleteOptions{ResourceVersion: current.ResourceVersion})
+			if err == nil {
+				result.Deleted = append(result.Deleted, *current)
+			} else if _, ok := err.(errors.ErrorResourceDoesNotExist); !ok {
+				logCxt.WithError(err).Info("Failed to delete WorkloadEndpoint")
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		// Update the current endpoint with the desired labels, annotations and spec, so that
+		// the remaining metadata (including the revision) is retained.
+		updated := *current
+		updated.Labels = d.Labels
+		updated.Annotations = d.Annotations
+		updated.Spec = d.Spec
+		if len(DiffWorkloadEndpoints(current, &updated)) == 0 {
+			logCxt.Debug("WorkloadEndpoint is unchanged")
+			continue
+		}
+		out, err := r.Update(ctx, &updated, setOpts)
+		if err != nil {
+			logCxt.WithError(err).Info("Failed to update WorkloadEndpoint")
+			errs = append(errs, err)
+			continue
+		}
+		result.Updated = append(result.Updated, *out)
+	}
+
+	// Create the desired endpoints that do not exist.  These are created in the order they were
+	// supplied.
+	for _, d := range desiredCopies {
+		if seen[key{d.Namespace, d.Name}] {
+			continue
+		}
+		out, err := r.Create(ctx, d, setOpts)
+		if err != nil {
+			r.client.logger.WithError(err).WithField("WorkloadEndpoint", d.Namespace+"/"+d.Name).Info("Failed to create WorkloadEndpoint")
+			errs = append(errs, err)
+			continue
+		}
+		result.Created = append(result.Created, *out)
+	}
+
+	if len(errs) > 0 {
+		return result, errors.ErrorCollectionFailure{
+			Operation: "reconcile",
+			Errors:    errs,
+		}
+	}
+	return result, nil
+}
+
+// maybeValidateProfileReferences checks that each of the profiles referenced by the
+// WorkloadEndpoint exists, if the client was configured using WithProfileReferenceValidation.
+// Returns an ErrorValidation listing the missing profiles if any do not exist.
+func (r workloadEndpoints) maybeValidateProfileReferences(ctx context.Context, res *apiv3.WorkloadEndpoint) error {
+	if !r.client.validateProfileReferences {
+		return nil
+	}
+
+	var missing []string
+	for _, profile := range res.Spec.Profiles {
+		_, err := r.client.Profiles().Get(ctx, profile, options.GetOptions{})
+		if _, ok := err.(errors.ErrorResourceDoesNotExist); ok {
+			missing = append(missing, profile)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		return errors.ErrorValidation{
+			ErroredFields: []errors.ErroredField{{
+				Name:   "WorkloadEndpoint.Spec.Profiles",
+				Reason: fmt.Sprintf("referenced profiles do not exist: %s", strings.Join(missing, ", ")),
+				Value:  missing,
+			}},
+		}
+	}
+	return nil
+}
+
+// ValidateWorkloadEndpoint performs the validation of a WorkloadEndpoint that is performed by
+// the client on Create and Update, checking that the name (if specified) matches the primary
+// identifiers in the Spec and that the fields are valid, for example the MAC address, the
+// gateway address families, the interface name and that each IPNAT is within the IPNetworks.
+// The checks of the IPNetworks and IPv6Gateway requested in the set options are also performed.
+// It does not require a datastore, so it may be used to validate a WorkloadEndpoint before a
+// client is created.  The checks against other resources are not included.  The
+// WorkloadEndpoint is not modified.
+func ValidateWorkloadEndpoint(res *apiv3.WorkloadEndpoint, opts options.SetOptions) error {
+	resCopy := *res
+	if err := assignOrValidateName(&resCopy); err != nil {
+		return err
+	} else if err := validator.Validate(&resCopy); err != nil {
+		return err
+	} else if err := maybeValidateHostRoutes(&resCopy, opts); err != nil {
+		return err
+	}
+	return maybeValidateIPv6Gateway(&resCopy, opts)
+}
+
+// maybeValidateHostRoutes checks that each of the IPNetworks of the WorkloadEndpoint is a host
+// route, if requested in the set options, and if also requested that there is at most one host
+// route of each IP family.  Returns an ErrorValidation listing the networks that are not host
+// routes, and the families with more than one address.
+func maybeValidateHostRoutes(res *apiv3.WorkloadEndpoint, opts options.SetOptions) error {
+	if !opts.ValidateIPNetworksHostRoutes {
+		return nil
+	}
+
+	var errFields []errors.ErroredField
+	for i, n := range res.Spec.IPNetworks {
+		// A bare IP address is treated as a host route.  Unparseable networks have already
+		// been rejected by the validator.
+		_, ipNet, err := cnet.ParseCIDROrIP(n)
+		if err != nil {
+			return err
+		}
+		if ones, bits := ipNet.Mask.Size(); ones != bits {
+			errFields = append(errFields, errors.ErroredField{
+				Name:   fmt.Sprintf("WorkloadEndpoint.Spec.IPNetworks[%d]", i),
+				Reason: fmt.Sprintf("IP network is not a /%d host route", bits),
+				Value:  n,
+			})
+		}
+	}
+
+	if opts.ValidateIPNetworksOnePerFamily {
+		v4, v6, err := WorkloadEndpointIPNetworksByFamily(res)
+		if err != nil {
+			return err
+		}
+		for _, family := range []struct {
+			version int
+			nets    []cnet.IPNet
+		}{{4, v4}, {6, v6}} {
+			if len(family.nets) <= 1 {
+				continue
+			}
+			addrs := make([]string, len(family.nets))
+			for i, n := range family.nets {
+				addrs[i] = n.String()
+			}
+			errFields = append(errFields, errors.ErroredField{
+				Name:   "WorkloadEndpoint.Spec.IPNetworks",
+				Reason: fmt.Sprintf("more than one IPv%d address", family.version),
+				Value:  strings.Join(addrs, ","),
+			})
+		}
+	}
+
+	if len(errFields) > 0 {
+		return errors.ErrorValidation{
+			ErroredFields: errFields,
+		}
+	}
+	return nil
+}
+
+// WorkloadEndpointIPNetworksByFamily returns the IPNetworks of the WorkloadEndpoint separated
+// into the IPv4 and IPv6 networks, in the order in which they are specified.  A bare IP address
+// is returned as a host route.  Returns an error if an IP network cannot be parsed.
+func WorkloadEndpointIPNetworksByFamily(res *apiv3.WorkloadEndpoint) (v4, v6 []cnet.IPNet, err error) {
+	for _, n := range res.Spec.IPNetworks {
+		_, ipNet, err := cnet.ParseCIDROrIP(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ipNet.Version() == 4 {
+			v4 = append(v4, *ipNet)
+		} else {
+			v6 = append(v6, *ipNet)
+		}
+	}
+	return v4, v6, nil
+}
+
+// maybeValidateIPv6Gateway checks that the IPv6Gateway of the WorkloadEndpoint, if set, is
+// on-link, if requested in the set options.  A link-local gateway is always on-link, otherwise
+// the gateway must be within one of the IPv6 IPNetworks of the WorkloadEndpoint.
+func maybeValidateIPv6Gateway(res *apiv3.WorkloadEndpoint, opts options.SetOptions) error {
+	if !opts.ValidateIPv6GatewayOnLink || res.Spec.IPv6Gateway == "" {
+		return nil
+	}
+
+	// The gateway address has already been validated by the validator.
+	gw := cnet.ParseIP(res.Spec.IPv6Gateway)
+	if gw.IsLinkLocalUnicast() {
+		return nil
+	}
+	for _, n := range res.Spec.IPNetworks {
+		_, ipNet, err := cnet.ParseCIDROrIP(n)
+		if err != nil {
+			return err
+		}
+		if ipNet.Version() == 6 && ipNet.Contains(gw.IP) {
+			return nil
+		}
+	}
+
+	return errors.ErrorValidation{
+		ErroredFields: []errors.ErroredField{{
+			Name:   "WorkloadEndpoint.Spec.IPv6Gateway",
+			Reason: "IPv6 gateway is not link-local or within an IPv6 IP network of the endpoint",
+			Value:  res.Spec.IPv6Gateway,
+		}},
+	}
+}
+
+// validateNoConflicts checks, if requested in the set options, that no other WorkloadEndpoint
+// on the same Node uses the same InterfaceName, since the endpoints would collide in the
+// dataplane, and that none of the IPNetworks of the WorkloadEndpoint are claimed by another
+// WorkloadEndpoint in the same namespace.  Endpoints without an InterfaceName are not checked
+// for interface name conflicts.
+//
+// The datastore does not index endpoints by interface name or IP.  The endpoints on the Node
+// are listed using the node prefix of the endpoint names, and the endpoints in the namespace
+// are checked against an index of the addresses of the WorkloadEndpoint being written.  The
+// checks are not atomic with the write, so concurrent writes of conflicting endpoints may both
+// succeed.
+func (r workloadEndpoints) validateNoConflicts(ctx context.Context, res *apiv3.WorkloadEndpoint, opts options.SetOptions) error {
+	checkInterface := opts.ValidateInterfaceNameUnique && res.Spec.InterfaceName != ""
+	checkIPs := opts.ValidateIPNetworksUnique && len(res.Spec.IPNetworks) > 0
+	if !checkInterface && !checkIPs {
+		return nil
+	}
+
+	if checkInterface {
+		// The prefix match may also return endpoints on other nodes whose names share the
+		// prefix, so the Node of each endpoint is also checked.
+		prefix, err := names.WorkloadEndpointIdentifiers{Node: res.Spec.Node}.CalculateWorkloadEndpointName(true)
+		if err != nil {
+			return err
+		}
+		list, err := r.List(ctx, options.ListOptions{Prefix: true, Name: prefix})
+		if err != nil {
+			return err
+		}
+		for _, wep := range list.Items {
+			if wep.Namespace == res.Namespace && wep.Name == res.Name {
+				continue
+			}
+			if wep.Spec.Node == res.Spec.Node && wep.Spec.InterfaceName == res.Spec.InterfaceName {
+				return errors.ErrorValidation{
+					ErroredFields: []errors.ErroredField{{
+						Name:   "WorkloadEndpoint.Spec.InterfaceName",
+						Reason: fmt.Sprintf("interface name is already used by WorkloadEndpoint %s/%s on node %s", wep.Namespace, wep.Name, wep.Spec.Node),
+						Value:  res.Spec.InterfaceName,
+					}},
+				}
+			}
+		}
+	}
+	if !checkIPs {
+		return nil
+	}
+
+	// Index the addresses by their canonical string form so that, for example, 10.0.0.1 and
+	// 10.0.0.1/32 are treated as the same address.
+	ips := make(map[string]int, len(res.Spec.IPNetworks))
+	for i, n := range res.Spec.IPNetworks {
+		if _, ipn, err := cnet.ParseCIDROrIP(n); err == nil {
+			ips[ipn.String()] = i
+		}
+	}
+
+	list, err := r.List(ctx, options.ListOptions{Namespace: res.Namespace})
+	if err != nil {
+		return err
+	}
+	for _, wep := range list.Items {
+		if wep.Name == res.Name {
+			continue
+		}
+		for _, n := range wep.Spec.IPNetworks {
+			_, ipn, err := cnet.ParseCIDROrIP(n)
+			if err != nil {
+				continue
+			}
+			if i, ok := ips[ipn.String()]; ok {
+				return errors.ErrorValidation{
+					ErroredFields: []errors.ErroredField{{
+						Name:   fmt.Sprintf("WorkloadEndpoint.Spec.IPNetworks[%d]", i),
+						Reason: fmt.Sprintf("IP network is already claimed by WorkloadEndpoint %s/%s on node %s", wep.Namespace, wep.Name, wep.Spec.Node),
+						Value:  res.Spec.IPNetworks[i],
+					}},
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// defaultSpec fills in the defaults for fields that are not specified in the Spec.  If an
+// interface name prefix is configured on the client and the InterfaceName is not specified,
+// the InterfaceName is derived from the namespace and workload.
+func (r workloadEndpoints) defaultSpec(res *apiv3.WorkloadEndpoint) {
+	if res.Spec.InterfaceName == "" && r.client.wepInterfacePrefix != "" {
+		workload := res.Spec.Pod
+		if workload == "" {
+			workload = res.Spec.Workload
+		}
+		res.Spec.InterfaceName = workloadEndpointInterfaceName(r.client.wepInterfacePrefix, res.Namespace, workload)
+	}
+}
+
+// canonicalizeSpec sorts the IPNetworks and IPNATs of the WorkloadEndpoint so that logically
+// identical endpoints are stored identically, regardless of the order they were specified in.
+// IPNetworks are sorted with IPv4 before IPv6, then by address, then by prefix length.  IPNATs
+// are sorted by InternalIP and then by ExternalIP using the same ordering.  Values that cannot
+// be parsed (and will fail validation) are sorted after the valid values.  Profiles are not
+// sorted since the order of the profiles is significant.
+//
+// The slices are copied before sorting since the WorkloadEndpoint is a shallow copy of the
+// caller's resource.
+func canonicalizeSpec(res *apiv3.WorkloadEndpoint) {
+	if len(res.Spec.IPNetworks) > 1 {
+		nets := append([]string(nil), res.Spec.IPNetworks...)
+		sort.SliceStable(nets, func(i, j int) bool {
+			return ipNetStringLess(nets[i], nets[j])
+		})
+		res.Spec.IPNetworks = nets
+	}
+	if len(res.Spec.IPNATs) > 1 {
+		nats := append([]apiv3.IPNAT(nil), res.Spec.IPNATs...)
+		sort.SliceStable(nats, func(i, j int) bool {
+			if nats[i].InternalIP != nats[j].InternalIP {
+				return ipNetStringLess(nats[i].InternalIP, nats[j].InternalIP)
+			}
+			return ipNetStringLess(nats[i].ExternalIP, nats[j].ExternalIP)
+		})
+		res.Spec.IPNATs = nats
+	}
+}
+
+// ipNetStringLess orders IP and CIDR strings with IPv4 before IPv6, then by address, then by
+// prefix length.  Values that cannot be parsed are ordered after valid values, by string.
+func ipNetStringLess(a, b string) bool {
+	ipa, neta, erra := cnet.ParseCIDROrIP(a)
+	ipb, netb, errb := cnet.ParseCIDROrIP(b)
+	switch {
+	case erra != nil && errb != nil:
+		return a < b
+	case erra != nil:
+		return false
+	case errb != nil:
+		return true
+	}
+	if ipa.Version() != ipb.Version() {
+		return ipa.Version() < ipb.Version()
+	}
+	if c := bytes.Compare(ipa.To16(), ipb.To16()); c != 0 {
+		return c < 0
+	}
+	onesa, _ := neta.Mask.Size()
+	onesb, _ := netb.Mask.Size()
+	return onesa < onesb
+}
+
+// workloadEndpointInterfaceName returns the interface name derived from the prefix, the
+// namespace and the workload.  The name is truncated to the maximum Linux interface name
+// length of 15 characters.
+func workloadEndpointInterfaceName(prefix, namespace, workload string) string {
+	h := sha1.Sum([]byte(fmt.Sprintf("%s.%s", namespace, workload)))
+	name := prefix + hex.EncodeToString(h[:])
+	if len(name) > 15 {
+		name = name[:15]
+	}
+	return name
+}
+
 // assignOrValidateName either assigns the name calculated from the Spec fields, or validates
 // the name against the spec fields.
-func (r workloadEndpoints) assignOrValidateName(res *apiv3.WorkloadEndpoint) error {
+func assignOrValidateName(res *apiv3.WorkloadEndpoint) error {
 	// Validate the workload endpoint indices and the name match.
 	wepids := names.WorkloadEndpointIdentifiers{
 		Node:         res.Spec.Node,
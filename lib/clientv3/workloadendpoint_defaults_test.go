@@ -0,0 +1,85 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+var _ = Describe("WorkloadEndpoint spec defaulting tests", func() {
+	ctx := context.Background()
+	var be *countingBackend
+
+	newClient := func(opts ...Option) client {
+		be = &countingBackend{}
+		logger := log.NewEntry(log.StandardLogger())
+		c := client{backend: be, logger: logger}
+		for _, opt := range opts {
+			opt(&c)
+		}
+		c.resources = newResources(be, nil, logger)
+		return c
+	}
+
+	spec := apiv3.WorkloadEndpointSpec{
+		Node:         "node-1",
+		Orchestrator: "k8s",
+		Pod:          "abcdef",
+		Endpoint:     "eth0",
+	}
+
+	It("should derive the interface name when a prefix is configured", func() {
+		c := newClient(WithWorkloadEndpointInterfacePrefix("cali"))
+		in := &apiv3.WorkloadEndpoint{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace-1"},
+			Spec:       spec,
+		}
+		out, err := c.WorkloadEndpoints().Create(ctx, in, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Spec.InterfaceName).To(Equal("cali39efb0f0330"))
+		Expect(in.Spec.InterfaceName).To(Equal(""))
+	})
+
+	It("should not override a specified interface name", func() {
+		c := newClient(WithWorkloadEndpointInterfacePrefix("cali"))
+		specWithInterface := spec
+		specWithInterface.InterfaceName = "cali09123"
+		out, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace-1"},
+			Spec:       specWithInterface,
+		}, options.SetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.Spec.InterfaceName).To(Equal("cali09123"))
+	})
+
+	It("should not derive the interface name when no prefix is configured", func() {
+		c := newClient()
+		_, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace-1"},
+			Spec:       spec,
+		}, options.SetOptions{})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		Expect(be.calls).To(Equal(0))
+	})
+})
@@ -28,6 +28,8 @@ import (
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 	"github.com/projectcalico/libcalico-go/lib/backend"
 	"github.com/projectcalico/libcalico-go/lib/clientv3"
+	"github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/names"
 	"github.com/projectcalico/libcalico-go/lib/numorstring"
 	"github.com/projectcalico/libcalico-go/lib/options"
 	"github.com/projectcalico/libcalico-go/lib/testutils"
@@ -107,12 +109,12 @@ var _ = testutils.E2eDatastoreDescribe("WorkloadEndpoint tests", testutils.Datas
 				Spec:       spec1_1,
 			}, options.SetOptions{})
 			Expect(outError).To(HaveOccurred())
-			Expect(outError.Error()).To(Equal("resource does not exist: WorkloadEndpoint(" + namespace1 + "/" + name1 + ")"))
+			testutils.ExpectResourceDoesNotExist(outError, apiv3.KindWorkloadEndpoint, namespace1, name1)
 
 			By("Attempting to get a WorkloadEndpoint before it is created")
 			_, outError = c.WorkloadEndpoints().Get(ctx, namespace1, name1, options.GetOptions{})
 			Expect(outError).To(HaveOccurred())
-			Expect(outError.Error()).To(Equal("resource does not exist: WorkloadEndpoint(" + namespace1 + "/" + name1 + ")"))
+			testutils.ExpectResourceDoesNotExist(outError, apiv3.KindWorkloadEndpoint, namespace1, name1)
 
 			By("Attempting to create a new WorkloadEndpoint with name1/spec1_1 and a non-empty ResourceVersion")
 			_, outError = c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
@@ -157,7 +159,7 @@ var _ = testutils.E2eDatastoreDescribe("WorkloadEndpoint tests", testutils.Datas
 			By("Getting WorkloadEndpoint (name2) before it is created")
 			_, outError = c.WorkloadEndpoints().Get(ctx, namespace2, name2, options.GetOptions{})
 			Expect(outError).To(HaveOccurred())
-			Expect(outError.Error()).To(Equal("resource does not exist: WorkloadEndpoint(" + namespace2 + "/" + name2 + ")"))
+			testutils.ExpectResourceDoesNotExist(outError, apiv3.KindWorkloadEndpoint, namespace2, name2)
 
 			By("Listing all the WorkloadEndpoints in namespace1, expecting a single result with name1/spec1_1")
 			outList, outError := c.WorkloadEndpoints().List(ctx, options.ListOptions{Namespace: namespace1})
@@ -286,7 +288,7 @@ var _ = testutils.E2eDatastoreDescribe("WorkloadEndpoint tests", testutils.Datas
 			time.Sleep(2 * time.Second)
 			_, outError = c.WorkloadEndpoints().Get(ctx, namespace2, name2, options.GetOptions{})
 			Expect(outError).To(HaveOccurred())
-			Expect(outError.Error()).To(Equal("resource does not exist: WorkloadEndpoint(" + namespace2 + "/" + name2 + ")"))
+			testutils.ExpectResourceDoesNotExist(outError, apiv3.KindWorkloadEndpoint, namespace2, name2)
 
 			By("Creating WorkloadEndpoint name2 with a 2s TTL and waiting for the entry to be deleted")
 			_, outError = c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
@@ -300,12 +302,12 @@ var _ = testutils.E2eDatastoreDescribe("WorkloadEndpoint tests", testutils.Datas
 			time.Sleep(2 * time.Second)
 			_, outError = c.WorkloadEndpoints().Get(ctx, namespace2, name2, options.GetOptions{})
 			Expect(outError).To(HaveOccurred())
-			Expect(outError.Error()).To(Equal("resource does not exist: WorkloadEndpoint(" + namespace2 + "/" + name2 + ")"))
+			testutils.ExpectResourceDoesNotExist(outError, apiv3.KindWorkloadEndpoint, namespace2, name2)
 
 			By("Attempting to deleting WorkloadEndpoint (name2) again")
 			_, outError = c.WorkloadEndpoints().Delete(ctx, namespace2, name2, options.DeleteOptions{})
 			Expect(outError).To(HaveOccurred())
-			Expect(outError.Error()).To(Equal("resource does not exist: WorkloadEndpoint(" + namespace2 + "/" + name2 + ")"))
+			testutils.ExpectResourceDoesNotExist(outError, apiv3.KindWorkloadEndpoint, namespace2, name2)
 
 			By("Listing all WorkloadEndpoints and expecting no items")
 			outList, outError = c.WorkloadEndpoints().List(ctx, options.ListOptions{})
@@ -315,7 +317,7 @@ var _ = testutils.E2eDatastoreDescribe("WorkloadEndpoint tests", testutils.Datas
 			By("Getting WorkloadEndpoint (name2) and expecting an error")
 			_, outError = c.WorkloadEndpoints().Get(ctx, namespace2, name2, options.GetOptions{})
 			Expect(outError).To(HaveOccurred())
-			Expect(outError.Error()).To(Equal("resource does not exist: WorkloadEndpoint(" + namespace2 + "/" + name2 + ")"))
+			testutils.ExpectResourceDoesNotExist(outError, apiv3.KindWorkloadEndpoint, namespace2, name2)
 		},
 
 		// Test 1: Pass two fully populated WorkloadEndpointSpecs and expect the series of operations to succeed.
@@ -511,7 +513,7 @@ var _ = testutils.E2eDatastoreDescribe("WorkloadEndpoint tests", testutils.Datas
 						Orchestrator:  "k8s",
 						Pod:           "pod-1",
 						Endpoint:      "eth0",
-						InterfaceName: "cali1234",
+						InterfaceName: "cali1236",
 					},
 				},
 				options.SetOptions{},
@@ -578,6 +580,1067 @@ var _ = testutils.E2eDatastoreDescribe("WorkloadEndpoint tests", testutils.Datas
 		})
 	})
 
+	Describe("WorkloadEndpoint paginated list", func() {
+		It("should return all workload endpoints across multiple pages", func() {
+			c, err := clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			By("Creating five WorkloadEndpoints")
+			for _, pod := range []string{"pod-a", "pod-b", "pod-c", "pod-d", "pod-e"} {
+				_, err = c.WorkloadEndpoints().Create(
+					ctx,
+					&apiv3.WorkloadEndpoint{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "namespace1"},
+						Spec: apiv3.WorkloadEndpointSpec{
+							Node:          "node-1",
+							Orchestrator:  "k8s",
+							Pod:           pod,
+							Endpoint:      "eth0",
+							InterfaceName: "cali" + pod,
+						},
+					},
+					options.SetOptions{},
+				)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("Listing with a page size of 2 until the continue token is empty")
+			var pods []string
+			var pages int
+			opts := options.ListOptions{Namespace: "namespace1", Limit: 2}
+			for {
+				outList, err := c.WorkloadEndpoints().List(ctx, opts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(outList.Items)).To(BeNumerically("<=", 2))
+				for _, wep := range outList.Items {
+					pods = append(pods, wep.Spec.Pod)
+				}
+				pages++
+				if outList.Continue == "" {
+					break
+				}
+				opts.Continue = outList.Continue
+			}
+			Expect(pages).To(Equal(3))
+			Expect(pods).To(Equal([]string{"pod-a", "pod-b", "pod-c", "pod-d", "pod-e"}))
+
+			By("Listing without a limit returns everything in a single page")
+			outList, err := c.WorkloadEndpoints().List(ctx, options.ListOptions{Namespace: "namespace1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outList.Items).To(HaveLen(5))
+			Expect(outList.Continue).To(Equal(""))
+
+			By("Listing with an invalid continue token")
+			_, err = c.WorkloadEndpoints().List(ctx, options.ListOptions{Namespace: "namespace1", Limit: 2, Continue: "foobar"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("WorkloadEndpoint Exists", func() {
+		var c clientv3.Interface
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+		})
+
+		It("should return true for an existing WorkloadEndpoint", func() {
+			_, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec:       spec1_1,
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			exists, err := c.WorkloadEndpoints().Exists(ctx, namespace1, name1, options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+		})
+
+		It("should return false for a non-existent WorkloadEndpoint", func() {
+			exists, err := c.WorkloadEndpoints().Exists(ctx, namespace1, name1, options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+
+		It("should return an error if the backend request fails", func() {
+			cancelledCtx, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err := c.WorkloadEndpoints().Exists(cancelledCtx, namespace1, name1, options.GetOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorDatastoreError{}))
+		})
+	})
+
+	Describe("WorkloadEndpoint GetOrCreate", func() {
+		var c clientv3.Interface
+		wep := &apiv3.WorkloadEndpoint{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+			Spec:       spec1_1,
+		}
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+		})
+
+		It("should create the WorkloadEndpoint if it does not exist", func() {
+			out, created, err := c.WorkloadEndpoints().GetOrCreate(ctx, wep, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeTrue())
+			testutils.ExpectResource(out, apiv3.KindWorkloadEndpoint, namespace1, name1, spec1_1)
+		})
+
+		It("should return the existing WorkloadEndpoint if it already exists", func() {
+			existing, err := c.WorkloadEndpoints().Create(ctx, wep, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			out, created, err := c.WorkloadEndpoints().GetOrCreate(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec:       spec1_2,
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeFalse())
+			testutils.ExpectResource(out, apiv3.KindWorkloadEndpoint, namespace1, name1, spec1_1)
+			Expect(out.ResourceVersion).To(Equal(existing.ResourceVersion))
+		})
+
+		It("should only create the WorkloadEndpoint once when racing", func() {
+			numRacers := 5
+			results := make(chan bool, numRacers)
+			for i := 0; i < numRacers; i++ {
+				go func() {
+					defer GinkgoRecover()
+					out, created, err := c.WorkloadEndpoints().GetOrCreate(ctx, wep, options.SetOptions{})
+					Expect(err).NotTo(HaveOccurred())
+					testutils.ExpectResource(out, apiv3.KindWorkloadEndpoint, namespace1, name1, spec1_1)
+					results <- created
+				}()
+			}
+
+			numCreated := 0
+			for i := 0; i < numRacers; i++ {
+				if <-results {
+					numCreated++
+				}
+			}
+			Expect(numCreated).To(Equal(1))
+		})
+	})
+
+	Describe("WorkloadEndpoint Apply", func() {
+		var c clientv3.Interface
+		wep := &apiv3.WorkloadEndpoint{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace1,
+				Labels:      map[string]string{"app": "frontend"},
+				Annotations: map[string]string{"owner": "team-a"},
+			},
+			Spec: apiv3.WorkloadEndpointSpec{
+				Node:          "node-1",
+				Orchestrator:  "k8s",
+				Pod:           "abcdef",
+				ContainerID:   "a12345a",
+				Endpoint:      "eth0",
+				IPNetworks:    []string{"10.0.0.1/32", "fd00::1/128"},
+				IPNATs:        []apiv3.IPNAT{{InternalIP: "10.0.0.1", ExternalIP: "172.16.0.1"}},
+				IPv4Gateway:   "10.0.0.254",
+				IPv6Gateway:   "fd00::fe",
+				Profiles:      []string{"profile-1", "profile-2"},
+				InterfaceName: "cali09123",
+				MAC:           "ee:ee:ee:ee:ee:ee",
+				Ports: []apiv3.EndpointPort{
+					{
+						Port:     1234,
+						Name:     "foobar",
+						Protocol: numorstring.ProtocolFromString("TCP"),
+					},
+				},
+			},
+		}
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+		})
+
+		It("should create the WorkloadEndpoint if it does not exist", func() {
+			out, err := c.WorkloadEndpoints().Apply(ctx, wep, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			testutils.ExpectResource(out, apiv3.KindWorkloadEndpoint, namespace1, name1, wep.Spec)
+		})
+
+		It("should not write the WorkloadEndpoint when applying an unchanged spec", func() {
+			first, err := c.WorkloadEndpoints().Apply(ctx, wep, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Applying the same WorkloadEndpoint a second time")
+			second, err := c.WorkloadEndpoints().Apply(ctx, wep, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second.ResourceVersion).To(Equal(first.ResourceVersion))
+			testutils.ExpectResource(second, apiv3.KindWorkloadEndpoint, namespace1, name1, wep.Spec)
+
+			By("Checking the stored revision is unchanged")
+			current, err := c.WorkloadEndpoints().Get(ctx, namespace1, name1, options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(current.ResourceVersion).To(Equal(first.ResourceVersion))
+		})
+
+		It("should update the WorkloadEndpoint when the spec is changed", func() {
+			first, err := c.WorkloadEndpoints().Apply(ctx, wep, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			changed := wep.DeepCopy()
+			changed.Spec.Profiles = []string{"profile-3"}
+			second, err := c.WorkloadEndpoints().Apply(ctx, changed, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second.ResourceVersion).NotTo(Equal(first.ResourceVersion))
+			testutils.ExpectResource(second, apiv3.KindWorkloadEndpoint, namespace1, name1, changed.Spec)
+		})
+	})
+
+	Describe("WorkloadEndpoint UpdateIf", func() {
+		var c clientv3.Interface
+		var existing *apiv3.WorkloadEndpoint
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			existing, err = c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace1,
+					Labels:    map[string]string{"phase": "ready"},
+				},
+				Spec: spec1_1,
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		setProfilesIfReady := func(wep *apiv3.WorkloadEndpoint) bool {
+			if wep.Labels["phase"] != "ready" {
+				return false
+			}
+			wep.Spec.Profiles = []string{"profile-1"}
+			return true
+		}
+
+		It("should apply the update when the mutate function returns true", func() {
+			out, updated, err := c.WorkloadEndpoints().UpdateIf(ctx, namespace1, name1, setProfilesIfReady, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(BeTrue())
+			Expect(out.Spec.Profiles).To(Equal([]string{"profile-1"}))
+			Expect(out.ResourceVersion).NotTo(Equal(existing.ResourceVersion))
+		})
+
+		It("should not write when the mutate function returns false", func() {
+			existing.Labels["phase"] = "pending"
+			existing, err := c.WorkloadEndpoints().Update(ctx, existing, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			out, updated, err := c.WorkloadEndpoints().UpdateIf(ctx, namespace1, name1, setProfilesIfReady, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(BeFalse())
+			Expect(out.Spec.Profiles).To(BeNil())
+			Expect(out.ResourceVersion).To(Equal(existing.ResourceVersion))
+		})
+
+		It("should retry the mutate function on an update conflict", func() {
+			calls := 0
+			out, updated, err := c.WorkloadEndpoints().UpdateIf(ctx, namespace1, name1, func(wep *apiv3.WorkloadEndpoint) bool {
+				calls++
+				if calls == 1 {
+					// Modify the WorkloadEndpoint behind the back of UpdateIf to force a conflict.
+					modified := wep.DeepCopy()
+					modified.Labels["other"] = "value"
+					_, err := c.WorkloadEndpoints().Update(ctx, modified, options.SetOptions{})
+					Expect(err).NotTo(HaveOccurred())
+				}
+				return setProfilesIfReady(wep)
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated).To(BeTrue())
+			Expect(calls).To(Equal(2))
+			Expect(out.Spec.Profiles).To(Equal([]string{"profile-1"}))
+			Expect(out.Labels).To(HaveKeyWithValue("other", "value"))
+		})
+
+		It("should return an error if the WorkloadEndpoint does not exist", func() {
+			_, updated, err := c.WorkloadEndpoints().UpdateIf(ctx, namespace1, name2, setProfilesIfReady, options.SetOptions{})
+			Expect(updated).To(BeFalse())
+			testutils.ExpectResourceDoesNotExist(err, apiv3.KindWorkloadEndpoint, namespace1, name2)
+		})
+	})
+
+	Describe("WorkloadEndpoint label helpers", func() {
+		var c clientv3.Interface
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			_, err = c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace1,
+					Labels:    map[string]string{"phase": "ready", "tier": "web"},
+				},
+				Spec: spec1_1,
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should add labels without modifying the spec", func() {
+			out, err := c.WorkloadEndpoints().AddLabels(ctx, namespace1, name1, map[string]string{"phase": "done", "team": "a"}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Labels).To(HaveKeyWithValue("phase", "done"))
+			Expect(out.Labels).To(HaveKeyWithValue("team", "a"))
+			Expect(out.Labels).To(HaveKeyWithValue("tier", "web"))
+			Expect(out.Labels).To(HaveKeyWithValue(apiv3.LabelNamespace, namespace1))
+			Expect(out.Spec).To(Equal(spec1_1))
+		})
+
+		It("should remove labels and ignore labels that are not present", func() {
+			out, err := c.WorkloadEndpoints().RemoveLabels(ctx, namespace1, name1, []string{"phase", "missing"}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Labels).NotTo(HaveKey("phase"))
+			Expect(out.Labels).To(HaveKeyWithValue("tier", "web"))
+			Expect(out.Labels).To(HaveKeyWithValue(apiv3.LabelOrchestrator, "k8s"))
+		})
+
+		It("should reject adding or removing reserved labels", func() {
+			_, err := c.WorkloadEndpoints().AddLabels(ctx, namespace1, name1, map[string]string{apiv3.LabelNamespace: "other"}, options.SetOptions{})
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+
+			_, err = c.WorkloadEndpoints().RemoveLabels(ctx, namespace1, name1, []string{"tier", apiv3.LabelOrchestrator}, options.SetOptions{})
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+
+			out, err := c.WorkloadEndpoints().Get(ctx, namespace1, name1, options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Labels).To(HaveKeyWithValue(apiv3.LabelNamespace, namespace1))
+			Expect(out.Labels).To(HaveKeyWithValue("tier", "web"))
+		})
+	})
+
+	Describe("WorkloadEndpoint annotations", func() {
+		It("should preserve the annotations across create, get, update and apply", func() {
+			c, err := clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			annotations := map[string]string{"example.com/owner": "team-a"}
+			out, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   namespace1,
+					Labels:      map[string]string{"tier": "web"},
+					Annotations: annotations,
+				},
+				Spec: spec1_1,
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Annotations).To(Equal(annotations))
+
+			By("Getting the WorkloadEndpoint")
+			out, err = c.WorkloadEndpoints().Get(ctx, namespace1, name1, options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Annotations).To(Equal(annotations))
+			Expect(out.Labels).NotTo(HaveKey("example.com/owner"))
+
+			By("Updating the spec")
+			out.Spec.Profiles = []string{"profile-updated"}
+			out, err = c.WorkloadEndpoints().Update(ctx, out, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Annotations).To(Equal(annotations))
+
+			By("Updating the annotations with an apply")
+			updated := &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   namespace1,
+					Labels:      map[string]string{"tier": "web"},
+					Annotations: map[string]string{"example.com/owner": "team-b"},
+				},
+				Spec: out.Spec,
+			}
+			out, err = c.WorkloadEndpoints().Apply(ctx, updated, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Annotations).To(Equal(updated.Annotations))
+
+			out, err = c.WorkloadEndpoints().Get(ctx, namespace1, name1, options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Annotations).To(Equal(updated.Annotations))
+		})
+	})
+
+	Describe("WorkloadEndpoint generation", func() {
+		var c clientv3.Interface
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+		})
+
+		It("should only increment the generation when the spec changes", func() {
+			out, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec:       spec1_1,
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Generation).To(Equal(int64(1)))
+
+			By("Updating the labels only")
+			out.Labels["tier"] = "web"
+			out, err = c.WorkloadEndpoints().Update(ctx, out, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Labels).To(HaveKeyWithValue("tier", "web"))
+			Expect(out.Generation).To(Equal(int64(1)))
+
+			By("Updating with the IP networks in a different order")
+			reordered := out.DeepCopy()
+			reordered.Spec.IPNetworks = []string{"10.100.10.1/32", "1.2.3.4/32"}
+			out.Spec.IPNetworks = []string{"1.2.3.4/32", "10.100.10.1/32"}
+			out, err = c.WorkloadEndpoints().Update(ctx, out, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Generation).To(Equal(int64(2)))
+			reordered.ResourceVersion = out.ResourceVersion
+			out, err = c.WorkloadEndpoints().Update(ctx, reordered, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Generation).To(Equal(int64(2)))
+
+			By("Updating the spec")
+			out.Spec.Profiles = []string{"profile-updated"}
+			out.Generation = 10
+			out, err = c.WorkloadEndpoints().Update(ctx, out, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Generation).To(Equal(int64(3)))
+
+			By("Applying an unchanged spec with new annotations")
+			out, err = c.WorkloadEndpoints().Apply(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   namespace1,
+					Labels:      map[string]string{"tier": "web"},
+					Annotations: map[string]string{"example.com/owner": "team-a"},
+				},
+				Spec: out.Spec,
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Annotations).To(HaveKeyWithValue("example.com/owner", "team-a"))
+			Expect(out.Generation).To(Equal(int64(3)))
+		})
+	})
+
+	Describe("WorkloadEndpoint canonical ordering", func() {
+		It("should store the IPNetworks and IPNATs in a canonical order", func() {
+			c, err := clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			spec := spec1_1
+			spec.IPNetworks = []string{"fd00::1/128", "10.0.0.10/32", "10.0.0.2/32"}
+			spec.IPNATs = []apiv3.IPNAT{
+				{InternalIP: "10.0.0.10", ExternalIP: "172.16.0.2"},
+				{InternalIP: "10.0.0.2", ExternalIP: "172.16.0.1"},
+			}
+			inNets := append([]string(nil), spec.IPNetworks...)
+
+			By("Creating a WorkloadEndpoint with unordered IPNetworks and IPNATs")
+			out, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec:       spec,
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.Spec.IPNetworks).To(Equal([]string{"10.0.0.2/32", "10.0.0.10/32", "fd00::1/128"}))
+			Expect(out.Spec.IPNATs).To(Equal([]apiv3.IPNAT{
+				{InternalIP: "10.0.0.2", ExternalIP: "172.16.0.1"},
+				{InternalIP: "10.0.0.10", ExternalIP: "172.16.0.2"},
+			}))
+			Expect(spec.IPNetworks).To(Equal(inNets), "input IPNetworks should not be modified")
+
+			By("Getting the WorkloadEndpoint and checking the order is stable")
+			stored, err := c.WorkloadEndpoints().Get(ctx, namespace1, name1, options.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Spec).To(Equal(out.Spec))
+
+			By("Updating with a reordered but equivalent spec")
+			stored.Spec.IPNetworks = []string{"fd00::1/128", "10.0.0.2/32", "10.0.0.10/32"}
+			stored.Spec.IPNATs = []apiv3.IPNAT{stored.Spec.IPNATs[1], stored.Spec.IPNATs[0]}
+			updated, err := c.WorkloadEndpoints().Update(ctx, stored, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.Spec).To(Equal(out.Spec))
+		})
+	})
+
+	Describe("WorkloadEndpoint interface name uniqueness", func() {
+		uniqueOpts := options.SetOptions{ValidateInterfaceNameUnique: true}
+
+		It("should reject an endpoint using the interface name of another endpoint on the same node", func() {
+			c, err := clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			By("Creating a WorkloadEndpoint on node-1")
+			wep1, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec:       spec1_1,
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a WorkloadEndpoint on node-1 with the same interface name")
+			spec := apiv3.WorkloadEndpointSpec{
+				Node:          "node-1",
+				Orchestrator:  "k8s",
+				Pod:           "ghijkl",
+				Endpoint:      "eth0",
+				InterfaceName: spec1_1.InterfaceName,
+			}
+			_, err = c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace2},
+				Spec:       spec,
+			}, uniqueOpts)
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+			Expect(err.Error()).To(Equal("error with field WorkloadEndpoint.Spec.InterfaceName = '" + spec1_1.InterfaceName +
+				"' (interface name is already used by WorkloadEndpoint " + namespace1 + "/" + name1 + " on node node-1)"))
+
+			By("Creating the WorkloadEndpoint on node-1 with a distinct interface name")
+			spec.InterfaceName = "cali0b"
+			wep2, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace2},
+				Spec:       spec,
+			}, uniqueOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Updating the second WorkloadEndpoint to use the interface name of the first")
+			wep2.Spec.InterfaceName = spec1_1.InterfaceName
+			_, err = c.WorkloadEndpoints().Update(ctx, wep2, uniqueOpts)
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+
+			By("Updating the first WorkloadEndpoint without changing its interface name")
+			wep1.Labels = map[string]string{"a": "b"}
+			_, err = c.WorkloadEndpoints().Update(ctx, wep1, uniqueOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a WorkloadEndpoint on node-2 with the same interface name")
+			spec2 := spec2_1
+			spec2.InterfaceName = spec1_1.InterfaceName
+			_, err = c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace2},
+				Spec:       spec2,
+			}, uniqueOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Updating the second WorkloadEndpoint to use the interface name of the first without the check")
+			wep2.Spec.InterfaceName = spec1_1.InterfaceName
+			_, err = c.WorkloadEndpoints().Update(ctx, wep2, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("WorkloadEndpoint IP network conflicts", func() {
+		var c clientv3.Interface
+		uniqueOpts := options.SetOptions{ValidateIPNetworksUnique: true}
+		spec1 := spec1_1
+		spec1.IPNetworks = []string{"10.0.0.1/32", "fd00::1/128"}
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+		})
+
+		It("should reject an endpoint claiming the IP network of another endpoint", func() {
+			By("Creating a WorkloadEndpoint with two IP networks")
+			_, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec:       spec1,
+			}, uniqueOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating a WorkloadEndpoint claiming one of the same addresses")
+			spec2 := spec2_1
+			spec2.IPNetworks = []string{"10.0.0.2/32", "fd00::1"}
+			wep2 := &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec:       spec2,
+			}
+			_, err = c.WorkloadEndpoints().Create(ctx, wep2, uniqueOpts)
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+			Expect(err.Error()).To(Equal("error with field WorkloadEndpoint.Spec.IPNetworks[1] = 'fd00::1' " +
+				"(IP network is already claimed by WorkloadEndpoint " + namespace1 + "/" + name1 + " on node node-1)"))
+
+			By("Creating the WorkloadEndpoint in another namespace")
+			wep2.Namespace = namespace2
+			_, err = c.WorkloadEndpoints().Create(ctx, wep2, uniqueOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating the same WorkloadEndpoint without the check")
+			wep2.Namespace = namespace1
+			_, err = c.WorkloadEndpoints().Create(ctx, wep2, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should allow an endpoint to reclaim its own IP networks", func() {
+			wep1, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec:       spec1,
+			}, uniqueOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Updating the WorkloadEndpoint keeping the same IP networks")
+			wep1.Labels = map[string]string{"a": "b"}
+			wep1, err = c.WorkloadEndpoints().Update(ctx, wep1, uniqueOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Updating the WorkloadEndpoint with an additional IP network")
+			wep1.Spec.IPNetworks = append(wep1.Spec.IPNetworks, "10.0.0.3/32")
+			_, err = c.WorkloadEndpoints().Update(ctx, wep1, uniqueOpts)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("WorkloadEndpoint host route IP networks", func() {
+		var c clientv3.Interface
+		hostRouteOpts := options.SetOptions{ValidateIPNetworksHostRoutes: true}
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+		})
+
+		createWithIPNetworks := func(opts options.SetOptions, ipNetworks ...string) error {
+			spec := spec1_1
+			spec.IPNetworks = ipNetworks
+			_, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec:       spec,
+			}, opts)
+			return err
+		}
+
+		It("should accept an IPv4 /32 host route", func() {
+			Expect(createWithIPNetworks(hostRouteOpts, "10.0.0.1/32")).NotTo(HaveOccurred())
+		})
+
+		It("should accept an IPv6 /128 host route", func() {
+			Expect(createWithIPNetworks(hostRouteOpts, "fd00::1/128")).NotTo(HaveOccurred())
+		})
+
+		It("should reject an IPv4 /24", func() {
+			err := createWithIPNetworks(hostRouteOpts, "fd00::1/128", "10.0.0.0/24")
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+			Expect(err.Error()).To(Equal("error with field WorkloadEndpoint.Spec.IPNetworks[1] = '10.0.0.0/24' " +
+				"(IP network is not a /32 host route)"))
+
+			By("Creating the same WorkloadEndpoint without the check")
+			Expect(createWithIPNetworks(options.SetOptions{}, "fd00::1/128", "10.0.0.0/24")).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("WorkloadEndpoint IPv6 gateway validation", func() {
+		var c clientv3.Interface
+		gatewayOpts := options.SetOptions{ValidateIPv6GatewayOnLink: true}
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+		})
+
+		createWithGateway := func(opts options.SetOptions, gateway string, ipNetworks ...string) error {
+			spec := spec1_1
+			spec.IPv6Gateway = gateway
+			spec.IPNetworks = ipNetworks
+			_, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec:       spec,
+			}, opts)
+			return err
+		}
+
+		It("should accept a gateway within an IPv6 IP network", func() {
+			Expect(createWithGateway(gatewayOpts, "fd00::1", "10.0.0.1/32", "fd00::/64")).NotTo(HaveOccurred())
+		})
+
+		It("should accept a link-local gateway", func() {
+			Expect(createWithGateway(gatewayOpts, "fe80::1", "fd00::10/128")).NotTo(HaveOccurred())
+		})
+
+		It("should reject a gateway that is not within an IPv6 IP network", func() {
+			err := createWithGateway(gatewayOpts, "fd00:1::1", "10.0.0.1/32", "fd00::/64")
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+			Expect(err.Error()).To(Equal("error with field WorkloadEndpoint.Spec.IPv6Gateway = 'fd00:1::1' " +
+				"(IPv6 gateway is not link-local or within an IPv6 IP network of the endpoint)"))
+
+			By("Creating the same WorkloadEndpoint without the check")
+			Expect(createWithGateway(options.SetOptions{}, "fd00:1::1", "10.0.0.1/32", "fd00::/64")).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("WorkloadEndpoint ListOrphans", func() {
+		var c clientv3.Interface
+		name3 := "node--1-k8s-ghijkl-eth0"
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+
+			By("Creating node-1 and profile-1 only")
+			_, err = c.Nodes().Create(ctx, &apiv3.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = c.Profiles().Create(ctx, &apiv3.Profile{ObjectMeta: metav1.ObjectMeta{Name: "profile-1"}}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Creating endpoints on node-1 and node-2, one referencing a missing profile")
+			_, err = c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec:       spec1_1,
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace2},
+				Spec:       spec2_1,
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+				Spec: apiv3.WorkloadEndpointSpec{
+					Node:          "node-1",
+					Orchestrator:  "k8s",
+					Pod:           "ghijkl",
+					Endpoint:      "eth0",
+					InterfaceName: "cali0a",
+					Profiles:      []string{"profile-1", "profile-missing"},
+				},
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		orphanNames := func(list *apiv3.WorkloadEndpointList) []string {
+			out := []string{}
+			for _, wep := range list.Items {
+				out = append(out, wep.Name)
+			}
+			return out
+		}
+
+		It("should return endpoints whose node does not exist", func() {
+			orphans, err := c.WorkloadEndpoints().ListOrphans(ctx, options.ListOptions{}, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphanNames(orphans)).To(ConsistOf(name2))
+		})
+
+		It("should also return endpoints whose profiles do not exist if requested", func() {
+			orphans, err := c.WorkloadEndpoints().ListOrphans(ctx, options.ListOptions{}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphanNames(orphans)).To(ConsistOf(name2, name3))
+		})
+
+		It("should only check the endpoints matching the list options", func() {
+			orphans, err := c.WorkloadEndpoints().ListOrphans(ctx, options.ListOptions{Namespace: namespace1}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphanNames(orphans)).To(ConsistOf(name3))
+		})
+
+		It("should return the orphans from every page when listing with a limit", func() {
+			orphans, err := c.WorkloadEndpoints().ListOrphans(ctx, options.ListOptions{Limit: 1}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphanNames(orphans)).To(ConsistOf(name2, name3))
+			Expect(orphans.Continue).To(Equal(""))
+		})
+	})
+
+	Describe("WorkloadEndpoint DeleteCollection", func() {
+		var c clientv3.Interface
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+		})
+
+		It("should delete all WorkloadEndpoints on a node", func() {
+			By("Creating WorkloadEndpoints on two nodes")
+			for _, spec := range []apiv3.WorkloadEndpointSpec{spec1_1, spec2_1} {
+				_, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+					Spec:       spec,
+				}, options.SetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			_, err := c.WorkloadEndpoints().Create(ctx, &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace2},
+				Spec: apiv3.WorkloadEndpointSpec{
+					Node:          "node-1",
+					Orchestrator:  "k8s",
+					Pod:           "ghijkl",
+					Endpoint:      "eth0",
+					InterfaceName: "cali12345",
+				},
+			}, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Deleting the WorkloadEndpoints on node-1")
+			prefix, err := names.WorkloadEndpointIdentifiers{Node: "node-1", Orchestrator: "k8s"}.CalculateWorkloadEndpointName(true)
+			Expect(err).NotTo(HaveOccurred())
+			deleted, err := c.WorkloadEndpoints().DeleteCollection(ctx, options.ListOptions{Name: prefix, Prefix: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(Equal(2))
+
+			By("Checking only the WorkloadEndpoint on node-2 remains")
+			outList, err := c.WorkloadEndpoints().List(ctx, options.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outList.Items).To(HaveLen(1))
+			testutils.ExpectResource(&outList.Items[0], apiv3.KindWorkloadEndpoint, namespace1, name2, spec2_1)
+		})
+
+		It("should return zero when nothing matches", func() {
+			deleted, err := c.WorkloadEndpoints().DeleteCollection(ctx, options.ListOptions{Namespace: namespace1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(Equal(0))
+		})
+	})
+
+	Describe("WorkloadEndpoint Reconcile", func() {
+		var c clientv3.Interface
+		wep := func(spec apiv3.WorkloadEndpointSpec, labels map[string]string) *apiv3.WorkloadEndpoint {
+			return &apiv3.WorkloadEndpoint{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace1, Labels: labels},
+				Spec:       spec,
+			}
+		}
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+		})
+
+		It("should create, update and delete WorkloadEndpoints to match the desired state", func() {
+			By("Reconciling to add two WorkloadEndpoints")
+			res, err := c.WorkloadEndpoints().Reconcile(ctx, []*apiv3.WorkloadEndpoint{
+				wep(spec1_1, nil), wep(spec2_1, nil),
+			}, options.ListOptions{Namespace: namespace1}, options.SetOptions{}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Created).To(HaveLen(2))
+			Expect(res.Updated).To(BeEmpty())
+			Expect(res.Deleted).To(BeEmpty())
+			testutils.ExpectResource(&res.Created[0], apiv3.KindWorkloadEndpoint, namespace1, name1, spec1_1)
+			testutils.ExpectResource(&res.Created[1], apiv3.KindWorkloadEndpoint, namespace1, name2, spec2_1)
+
+			By("Reconciling the same state again")
+			res, err = c.WorkloadEndpoints().Reconcile(ctx, []*apiv3.WorkloadEndpoint{
+				wep(spec2_1, nil), wep(spec1_1, nil),
+			}, options.ListOptions{Namespace: namespace1}, options.SetOptions{}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Created).To(BeEmpty())
+			Expect(res.Updated).To(BeEmpty())
+			Expect(res.Deleted).To(BeEmpty())
+
+			By("Reconciling with a changed spec and labels")
+			res, err = c.WorkloadEndpoints().Reconcile(ctx, []*apiv3.WorkloadEndpoint{
+				wep(spec1_2, map[string]string{"app": "frontend"}), wep(spec2_1, nil),
+			}, options.ListOptions{Namespace: namespace1}, options.SetOptions{}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Created).To(BeEmpty())
+			Expect(res.Updated).To(HaveLen(1))
+			Expect(res.Deleted).To(BeEmpty())
+			testutils.ExpectResource(&res.Updated[0], apiv3.KindWorkloadEndpoint, namespace1, name1, spec1_2)
+			Expect(res.Updated[0].Labels).To(HaveKeyWithValue("app", "frontend"))
+
+			By("Reconciling without an endpoint and without deletion enabled")
+			res, err = c.WorkloadEndpoints().Reconcile(ctx, []*apiv3.WorkloadEndpoint{
+				wep(spec1_2, map[string]string{"app": "frontend"}),
+			}, options.ListOptions{Namespace: namespace1}, options.SetOptions{}, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Created).To(BeEmpty())
+			Expect(res.Updated).To(BeEmpty())
+			Expect(res.Deleted).To(BeEmpty())
+
+			By("Reconciling without an endpoint and with deletion enabled")
+			res, err = c.WorkloadEndpoints().Reconcile(ctx, []*apiv3.WorkloadEndpoint{
+				wep(spec1_2, map[string]string{"app": "frontend"}),
+			}, options.ListOptions{Namespace: namespace1}, options.SetOptions{}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Created).To(BeEmpty())
+			Expect(res.Updated).To(BeEmpty())
+			Expect(res.Deleted).To(HaveLen(1))
+			testutils.ExpectResource(&res.Deleted[0], apiv3.KindWorkloadEndpoint, namespace1, name2, spec2_1)
+
+			By("Checking only the desired WorkloadEndpoint remains")
+			outList, err := c.WorkloadEndpoints().List(ctx, options.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outList.Items).To(HaveLen(1))
+			testutils.ExpectResource(&outList.Items[0], apiv3.KindWorkloadEndpoint, namespace1, name1, spec1_2)
+		})
+
+		It("should reject a desired set containing the same WorkloadEndpoint twice", func() {
+			_, err := c.WorkloadEndpoints().Reconcile(ctx, []*apiv3.WorkloadEndpoint{
+				wep(spec1_1, nil), wep(spec1_2, nil),
+			}, options.ListOptions{Namespace: namespace1}, options.SetOptions{}, true)
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+
+			By("Checking nothing was created")
+			outList, err := c.WorkloadEndpoints().List(ctx, options.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outList.Items).To(BeEmpty())
+		})
+
+		It("should reject a desired set containing an invalid WorkloadEndpoint before writing", func() {
+			invalidSpec := spec2_1
+			invalidSpec.MAC = "01:23:45:67:89"
+			_, err := c.WorkloadEndpoints().Reconcile(ctx, []*apiv3.WorkloadEndpoint{
+				wep(spec1_1, nil), wep(invalidSpec, nil),
+			}, options.ListOptions{Namespace: namespace1}, options.SetOptions{}, true)
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+
+			By("Checking nothing was created")
+			outList, err := c.WorkloadEndpoints().List(ctx, options.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outList.Items).To(BeEmpty())
+		})
+
+		It("should reject a desired set failing the checks of the set options before writing", func() {
+			subnetSpec := spec2_1
+			subnetSpec.IPNetworks = []string{"10.100.0.0/24"}
+			_, err := c.WorkloadEndpoints().Reconcile(ctx, []*apiv3.WorkloadEndpoint{
+				wep(spec1_1, nil), wep(subnetSpec, nil),
+			}, options.ListOptions{Namespace: namespace1}, options.SetOptions{ValidateIPNetworksHostRoutes: true}, true)
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+
+			By("Checking nothing was created")
+			outList, err := c.WorkloadEndpoints().List(ctx, options.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outList.Items).To(BeEmpty())
+		})
+	})
+
+	Describe("WorkloadEndpoint profile reference validation", func() {
+		var c, validatingClient clientv3.Interface
+		specWithProfiles := spec1_1
+		specWithProfiles.Profiles = []string{"profile-1", "profile-2", "profile-3"}
+		wep := &apiv3.WorkloadEndpoint{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace1},
+			Spec:       specWithProfiles,
+		}
+
+		createProfiles := func(names ...string) {
+			for _, name := range names {
+				_, err := c.Profiles().Create(ctx, &apiv3.Profile{
+					ObjectMeta: metav1.ObjectMeta{Name: name},
+				}, options.SetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}
+
+		BeforeEach(func() {
+			var err error
+			c, err = clientv3.New(config)
+			Expect(err).NotTo(HaveOccurred())
+			validatingClient, err = clientv3.New(config, clientv3.WithProfileReferenceValidation())
+			Expect(err).NotTo(HaveOccurred())
+
+			be, err := backend.NewClient(config)
+			Expect(err).NotTo(HaveOccurred())
+			be.Clean()
+		})
+
+		It("should create the WorkloadEndpoint when all referenced profiles exist", func() {
+			createProfiles("profile-1", "profile-2", "profile-3")
+			out, err := validatingClient.WorkloadEndpoints().Create(ctx, wep, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			testutils.ExpectResource(out, apiv3.KindWorkloadEndpoint, namespace1, name1, specWithProfiles)
+		})
+
+		It("should reject the WorkloadEndpoint listing the missing profiles", func() {
+			createProfiles("profile-2")
+			_, err := validatingClient.WorkloadEndpoints().Create(ctx, wep, options.SetOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+			Expect(err.Error()).To(ContainSubstring("referenced profiles do not exist: profile-1, profile-3"))
+
+			By("Checking the WorkloadEndpoint was not created")
+			_, err = c.WorkloadEndpoints().Get(ctx, namespace1, name1, options.GetOptions{})
+			testutils.ExpectResourceDoesNotExist(err, apiv3.KindWorkloadEndpoint, namespace1, name1)
+
+			By("Checking an Update is also rejected")
+			out, err := c.WorkloadEndpoints().Create(ctx, wep, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = validatingClient.WorkloadEndpoints().Update(ctx, out, options.SetOptions{})
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+		})
+
+		It("should not check the referenced profiles unless configured", func() {
+			out, err := c.WorkloadEndpoints().Create(ctx, wep, options.SetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			testutils.ExpectResource(out, apiv3.KindWorkloadEndpoint, namespace1, name1, specWithProfiles)
+		})
+	})
+
 	Describe("WorkloadEndpoint names based on primary identifiers in Spec", func() {
 		It("should handle prefix lists of workload endpoints", func() {
 			c, err := clientv3.New(config)
@@ -0,0 +1,63 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+)
+
+func wepWithLabels(name string, labels map[string]string) apiv3.WorkloadEndpoint {
+	return apiv3.WorkloadEndpoint{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "namespace-1", Name: name, Labels: labels},
+	}
+}
+
+var _ = Describe("MergeWorkloadEndpointLabels tests", func() {
+	It("should return the union of consistent labels", func() {
+		list := &apiv3.WorkloadEndpointList{Items: []apiv3.WorkloadEndpoint{
+			wepWithLabels("wep1", map[string]string{"app": "web", "tier": "frontend"}),
+			wepWithLabels("wep2", map[string]string{"app": "web", "zone": "a"}),
+		}}
+		labels, err := MergeWorkloadEndpointLabels(list)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(Equal(map[string]string{"app": "web", "tier": "frontend", "zone": "a"}))
+	})
+
+	It("should report conflicting labels and return the remaining labels", func() {
+		list := &apiv3.WorkloadEndpointList{Items: []apiv3.WorkloadEndpoint{
+			wepWithLabels("wep1", map[string]string{"app": "web", "tier": "frontend"}),
+			wepWithLabels("wep2", map[string]string{"app": "web", "tier": "backend"}),
+		}}
+		labels, err := MergeWorkloadEndpointLabels(list)
+		Expect(err).To(HaveOccurred())
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		fields := err.(cerrors.ErrorValidation).ErroredFields
+		Expect(fields).To(HaveLen(1))
+		Expect(fields[0].Value).To(Equal("tier"))
+		Expect(fields[0].Reason).To(Equal("conflicting values for label tier: backend, frontend"))
+		Expect(labels).To(Equal(map[string]string{"app": "web"}))
+	})
+
+	It("should return no labels for an empty list", func() {
+		labels, err := MergeWorkloadEndpointLabels(&apiv3.WorkloadEndpointList{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(BeEmpty())
+	})
+})
@@ -0,0 +1,170 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/options"
+)
+
+var _ = Describe("WorkloadEndpoint validation without a backend", func() {
+	validSpec := func() apiv3.WorkloadEndpointSpec {
+		return apiv3.WorkloadEndpointSpec{
+			Node:          "node-1",
+			Orchestrator:  "k8s",
+			Pod:           "abcdef",
+			Endpoint:      "eth0",
+			InterfaceName: "cali09123",
+			IPNetworks:    []string{"10.0.0.1/32", "fd00::1/128"},
+			IPNATs:        []apiv3.IPNAT{{InternalIP: "10.0.0.1", ExternalIP: "172.16.0.1"}},
+			IPv4Gateway:   "10.0.0.254",
+			IPv6Gateway:   "fe80::1",
+			MAC:           "01:23:45:67:89:ab",
+		}
+	}
+
+	newWorkloadEndpoint := func(name string, spec apiv3.WorkloadEndpointSpec) *apiv3.WorkloadEndpoint {
+		return &apiv3.WorkloadEndpoint{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace-1", Name: name},
+			Spec:       spec,
+		}
+	}
+
+	It("should accept a valid WorkloadEndpoint without modifying it", func() {
+		wep := newWorkloadEndpoint("", validSpec())
+		Expect(ValidateWorkloadEndpoint(wep, options.SetOptions{})).NotTo(HaveOccurred())
+		Expect(wep.Name).To(Equal(""))
+
+		wep = newWorkloadEndpoint("node--1-k8s-abcdef-eth0", validSpec())
+		Expect(ValidateWorkloadEndpoint(wep, options.SetOptions{})).NotTo(HaveOccurred())
+	})
+
+	It("should perform the checks requested in the set options", func() {
+		spec := validSpec()
+		spec.IPNetworks = []string{"10.0.0.0/24", "fd00::1/128"}
+		wep := newWorkloadEndpoint("", spec)
+		Expect(ValidateWorkloadEndpoint(wep, options.SetOptions{})).NotTo(HaveOccurred())
+
+		err := ValidateWorkloadEndpoint(wep, options.SetOptions{ValidateIPNetworksHostRoutes: true})
+		Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+		Expect(err.(cerrors.ErrorValidation).ErroredFields[0].Name).To(Equal("WorkloadEndpoint.Spec.IPNetworks[0]"))
+	})
+
+	DescribeTable("should report the invalid fields of a WorkloadEndpoint",
+		func(name string, mutate func(spec *apiv3.WorkloadEndpointSpec), fields []string) {
+			spec := validSpec()
+			mutate(&spec)
+			err := ValidateWorkloadEndpoint(newWorkloadEndpoint(name, spec), options.SetOptions{})
+			Expect(err).To(BeAssignableToTypeOf(cerrors.ErrorValidation{}))
+			names := []string{}
+			for _, f := range err.(cerrors.ErrorValidation).ErroredFields {
+				names = append(names, f.Name)
+			}
+			Expect(names).To(Equal(fields))
+		},
+		Entry("name not matching the identifiers", "node--1-k8s-ghijkl-eth0",
+			func(spec *apiv3.WorkloadEndpointSpec) {},
+			[]string{"Name"},
+		),
+		Entry("invalid interface name", "",
+			func(spec *apiv3.WorkloadEndpointSpec) { spec.InterfaceName = "cali/0" },
+			[]string{"InterfaceName"},
+		),
+		Entry("invalid MAC, gateway families and NAT outside the networks", "",
+			func(spec *apiv3.WorkloadEndpointSpec) {
+				spec.MAC = "01:23:45:67:89"
+				spec.IPv4Gateway = "fd00::254"
+				spec.IPv6Gateway = "10.0.0.254"
+				spec.IPNATs = []apiv3.IPNAT{{InternalIP: "10.0.1.1", ExternalIP: "172.16.0.1"}}
+			},
+			[]string{
+				"WorkloadEndpoint.Spec.IPNATs[0].InternalIP",
+				"WorkloadEndpoint.Spec.IPv4Gateway",
+				"WorkloadEndpoint.Spec.IPv6Gateway",
+				"WorkloadEndpoint.Spec.MAC",
+			},
+		),
+	)
+
+	It("should return the same error from Create, without accessing the backend", func() {
+		be := &countingBackend{}
+		logger := log.NewEntry(log.StandardLogger())
+		c := client{backend: be, logger: logger, resources: newResources(be, nil, logger)}
+
+		spec := validSpec()
+		spec.MAC = "01:23:45:67:89"
+		spec.IPv4Gateway = "fd00::254"
+		wep := newWorkloadEndpoint("", spec)
+		expectedErr := ValidateWorkloadEndpoint(wep, options.SetOptions{})
+		Expect(expectedErr).To(HaveOccurred())
+
+		_, err := c.WorkloadEndpoints().Create(context.Background(), wep, options.SetOptions{})
+		Expect(err).To(Equal(expectedErr))
+		Expect(be.calls).To(Equal(0))
+	})
+	Describe("IP networks by family", func() {
+		familyOpts := options.SetOptions{ValidateIPNetworksHostRoutes: true, ValidateIPNetworksOnePerFamily: true}
+
+		It("should report the IPv4 and IPv6 networks separately", func() {
+			spec := validSpec()
+			spec.IPNetworks = []string{"fd00::1/128", "10.0.0.1", "10.0.1.0/24"}
+			v4, v6, err := WorkloadEndpointIPNetworksByFamily(newWorkloadEndpoint("", spec))
+			Expect(err).NotTo(HaveOccurred())
+			strs := func(nets []cnet.IPNet) []string {
+				s := []string{}
+				for _, n := range nets {
+					s = append(s, n.String())
+				}
+				return s
+			}
+			Expect(strs(v4)).To(Equal([]string{"10.0.0.1/32", "10.0.1.0/24"}))
+			Expect(strs(v6)).To(Equal([]string{"fd00::1/128"}))
+		})
+
+		It("should accept a dual-stack endpoint with one address of each family", func() {
+			Expect(maybeValidateHostRoutes(newWorkloadEndpoint("", validSpec()), familyOpts)).NotTo(HaveOccurred())
+		})
+
+		It("should reject an endpoint with two IPv4 addresses", func() {
+			spec := validSpec()
+			spec.IPNetworks = []string{"10.0.0.1/32", "fd00::1/128", "10.0.0.2/32"}
+			wep := newWorkloadEndpoint("", spec)
+			err := maybeValidateHostRoutes(wep, familyOpts)
+			Expect(err).To(Equal(cerrors.ErrorValidation{
+				ErroredFields: []cerrors.ErroredField{{
+					Name:   "WorkloadEndpoint.Spec.IPNetworks",
+					Reason: "more than one IPv4 address",
+					Value:  "10.0.0.1/32,10.0.0.2/32",
+				}},
+			}))
+
+			By("Validating without the per family check")
+			Expect(maybeValidateHostRoutes(wep, options.SetOptions{ValidateIPNetworksHostRoutes: true})).NotTo(HaveOccurred())
+
+			By("Validating without the host route check")
+			Expect(maybeValidateHostRoutes(wep, options.SetOptions{ValidateIPNetworksOnePerFamily: true})).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,139 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+)
+
+// FieldDiff describes a single field that differs between two resources.
+type FieldDiff struct {
+	// The path of the field, for example "Spec.IPNetworks[1]" or "ObjectMeta.Labels[app]".
+	Path string
+	// The value of the field in the first resource, or nil if it is not present.
+	A interface{}
+	// The value of the field in the second resource, or nil if it is not present.
+	B interface{}
+}
+
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %v != %v", d.Path, d.A, d.B)
+}
+
+// DiffWorkloadEndpoints compares two WorkloadEndpoints and returns the fields that differ, or
+// nil if the endpoints are equivalent.  This may be used to avoid writing an update
+// that does not change anything.
+//
+// The ResourceVersion and UID are ignored, and the specs are canonicalized in the same way as
+// on Create and Update before comparison, so that IPNetworks and IPNATs specified in a
+// different order are not reported as a difference.
+func DiffWorkloadEndpoints(a, b *apiv3.WorkloadEndpoint) []FieldDiff {
+	ac, bc := *a, *b
+	ac.ResourceVersion, bc.ResourceVersion = "", ""
+	ac.UID, bc.UID = "", ""
+	canonicalizeSpec(&ac)
+	canonicalizeSpec(&bc)
+
+	var diffs []FieldDiff
+	diffValues("", reflect.ValueOf(ac), reflect.ValueOf(bc), &diffs)
+	return diffs
+}
+
+// diffValues appends the differences between a and b, which are of the same type, to diffs.
+// Structs, maps, slices and pointers are compared element by element so that the reported
+// paths identify the individual fields that differ.
+func diffValues(path string, a, b reflect.Value, diffs *[]FieldDiff) {
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*diffs = append(*diffs, FieldDiff{Path: path, A: valueOrNil(a), B: valueOrNil(b)})
+			}
+			return
+		}
+		diffValues(path, a.Elem(), b.Elem(), diffs)
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				// The struct has unexported fields (for example, a time), so compare it as
+				// a whole.
+				diffDeepEqual(path, a, b, diffs)
+				return
+			}
+		}
+		for i := 0; i < t.NumField(); i++ {
+			diffValues(joinPath(path, t.Field(i).Name), a.Field(i), b.Field(i), diffs)
+		}
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			diffDeepEqual(path, a, b, diffs)
+			return
+		}
+		for i := 0; i < a.Len(); i++ {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i), diffs)
+		}
+	case reflect.Map:
+		keys := map[string]reflect.Value{}
+		for _, k := range a.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		for _, k := range b.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for n := range keys {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			kpath := fmt.Sprintf("%s[%s]", path, n)
+			av, bv := a.MapIndex(keys[n]), b.MapIndex(keys[n])
+			if !av.IsValid() || !bv.IsValid() {
+				*diffs = append(*diffs, FieldDiff{Path: kpath, A: valueOrNil(av), B: valueOrNil(bv)})
+				continue
+			}
+			diffValues(kpath, av, bv, diffs)
+		}
+	default:
+		diffDeepEqual(path, a, b, diffs)
+	}
+}
+
+// diffDeepEqual appends a difference for path to diffs if a and b are not deeply equal.
+func diffDeepEqual(path string, a, b reflect.Value, diffs *[]FieldDiff) {
+	if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		*diffs = append(*diffs, FieldDiff{Path: path, A: a.Interface(), B: b.Interface()})
+	}
+}
+
+// valueOrNil returns the interface value of v, or nil if v is invalid or a nil pointer.
+func valueOrNil(v reflect.Value) interface{} {
+	if !v.IsValid() || ((v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()) {
+		return nil
+	}
+	return v.Interface()
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
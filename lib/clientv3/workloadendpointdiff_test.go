@@ -0,0 +1,111 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+)
+
+var _ = Describe("WorkloadEndpoint diff tests", func() {
+	var a, b *apiv3.WorkloadEndpoint
+
+	BeforeEach(func() {
+		a = &apiv3.WorkloadEndpoint{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "node--1-k8s-pod-eth0",
+				Namespace:       "namespace-1",
+				Labels:          map[string]string{"app": "frontend"},
+				ResourceVersion: "1234",
+				UID:             "uid-1",
+			},
+			Spec: apiv3.WorkloadEndpointSpec{
+				Node:          "node-1",
+				Orchestrator:  "k8s",
+				Pod:           "pod",
+				Endpoint:      "eth0",
+				InterfaceName: "cali1234",
+				IPNetworks:    []string{"10.0.0.1/32", "10.0.0.2/32"},
+				IPNATs: []apiv3.IPNAT{
+					{InternalIP: "10.0.0.1", ExternalIP: "172.16.0.1"},
+					{InternalIP: "10.0.0.2", ExternalIP: "172.16.0.2"},
+				},
+				Profiles: []string{"profile-1", "profile-2"},
+			},
+		}
+		b = a.DeepCopy()
+	})
+
+	It("should report no differences for identical endpoints", func() {
+		Expect(DiffWorkloadEndpoints(a, b)).To(BeEmpty())
+	})
+
+	It("should ignore the resource version and UID", func() {
+		b.ResourceVersion = "5678"
+		b.UID = "uid-2"
+		Expect(DiffWorkloadEndpoints(a, b)).To(BeEmpty())
+	})
+
+	It("should report a single changed spec field", func() {
+		b.Spec.InterfaceName = "cali5678"
+		diffs := DiffWorkloadEndpoints(a, b)
+		Expect(diffs).To(Equal([]FieldDiff{
+			{Path: "Spec.InterfaceName", A: "cali1234", B: "cali5678"},
+		}))
+		Expect(diffs[0].String()).To(Equal("Spec.InterfaceName: cali1234 != cali5678"))
+	})
+
+	It("should report changed, added and removed labels by key", func() {
+		b.Labels = map[string]string{"app": "backend", "tier": "web"}
+		Expect(DiffWorkloadEndpoints(a, b)).To(Equal([]FieldDiff{
+			{Path: "ObjectMeta.Labels[app]", A: "frontend", B: "backend"},
+			{Path: "ObjectMeta.Labels[tier]", A: nil, B: "web"},
+		}))
+	})
+
+	It("should report a changed slice element by index", func() {
+		b.Spec.IPNATs[1].ExternalIP = "172.16.0.3"
+		Expect(DiffWorkloadEndpoints(a, b)).To(Equal([]FieldDiff{
+			{Path: "Spec.IPNATs[1].ExternalIP", A: "172.16.0.2", B: "172.16.0.3"},
+		}))
+	})
+
+	It("should report no differences when IPNetworks and IPNATs are reordered", func() {
+		b.Spec.IPNetworks = []string{"10.0.0.2/32", "10.0.0.1/32"}
+		b.Spec.IPNATs = []apiv3.IPNAT{a.Spec.IPNATs[1], a.Spec.IPNATs[0]}
+		Expect(DiffWorkloadEndpoints(a, b)).To(BeEmpty())
+
+		// The supplied endpoints are not modified.
+		Expect(b.Spec.IPNetworks).To(Equal([]string{"10.0.0.2/32", "10.0.0.1/32"}))
+	})
+
+	It("should report reordered profiles since the order is significant", func() {
+		b.Spec.Profiles = []string{"profile-2", "profile-1"}
+		Expect(DiffWorkloadEndpoints(a, b)).To(Equal([]FieldDiff{
+			{Path: "Spec.Profiles[0]", A: "profile-1", B: "profile-2"},
+			{Path: "Spec.Profiles[1]", A: "profile-2", B: "profile-1"},
+		}))
+	})
+
+	It("should report a slice with a different length as a whole", func() {
+		b.Spec.IPNetworks = []string{"10.0.0.1/32"}
+		Expect(DiffWorkloadEndpoints(a, b)).To(Equal([]FieldDiff{
+			{Path: "Spec.IPNetworks", A: []string{"10.0.0.1/32", "10.0.0.2/32"}, B: []string{"10.0.0.1/32"}},
+		}))
+	})
+})
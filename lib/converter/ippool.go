@@ -64,6 +64,7 @@ func (p IPPoolConverter) ConvertAPIToKVPair(a unversioned.Resource) (*model.KVPa
 			Masquerade:    ap.Spec.NATOutgoing,
 			IPAM:          !ap.Spec.Disabled,
 			Disabled:      ap.Spec.Disabled,
+			ReservedCIDRs: ap.Spec.ReservedCIDRs,
 		},
 	}
 
@@ -79,6 +80,7 @@ func (_ IPPoolConverter) ConvertKVPairToAPI(d *model.KVPair) (unversioned.Resour
 	apiPool.Metadata.CIDR = backendPool.CIDR
 	apiPool.Spec.NATOutgoing = backendPool.Masquerade
 	apiPool.Spec.Disabled = backendPool.Disabled
+	apiPool.Spec.ReservedCIDRs = backendPool.ReservedCIDRs
 
 	// If any IPIP configuration is present then include the IPIP spec..
 	if backendPool.IPIPInterface != "" || backendPool.IPIPMode != ipip.Undefined {
@@ -0,0 +1,57 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter_test
+
+import (
+	. "github.com/projectcalico/libcalico-go/lib/converter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	api "github.com/projectcalico/libcalico-go/lib/apis/v1"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+var _ = Describe("IPPoolConverter", func() {
+	c := IPPoolConverter{}
+
+	It("should convert reserved CIDRs to and from the backend model", func() {
+		pool := api.NewIPPool()
+		pool.Metadata.CIDR = cidr1Net
+		pool.Spec.ReservedCIDRs = []net.IPNet{net.MustParseNetwork("10.0.0.1/32")}
+
+		kvp, err := c.ConvertAPIToKVPair(*pool)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvp.Value.(*model.IPPool).ReservedCIDRs).To(Equal(pool.Spec.ReservedCIDRs))
+
+		out, err := c.ConvertKVPairToAPI(kvp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.(*api.IPPool).Spec.ReservedCIDRs).To(Equal(pool.Spec.ReservedCIDRs))
+	})
+
+	It("should default to no reserved CIDRs", func() {
+		pool := api.NewIPPool()
+		pool.Metadata.CIDR = cidr1Net
+
+		kvp, err := c.ConvertAPIToKVPair(*pool)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kvp.Value.(*model.IPPool).ReservedCIDRs).To(BeNil())
+
+		out, err := c.ConvertKVPairToAPI(kvp)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.(*api.IPPool).Spec.ReservedCIDRs).To(BeNil())
+	})
+})
@@ -18,11 +18,15 @@ import (
 	api "github.com/projectcalico/libcalico-go/lib/apis/v1"
 	"github.com/projectcalico/libcalico-go/lib/apis/v1/unversioned"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/upgrade/converters"
 )
 
 // PolicyConverter implements a set of functions used for converting between
 // API and backend representations of the Policy resource.
-type PolicyConverter struct{}
+type PolicyConverter struct {
+	// Deprecated, if set, records the rules that use deprecated fields.
+	Deprecated *converters.DeprecatedFields
+}
 
 // ConvertMetadataToKey converts a PolicyMetadata to a PolicyKey
 func (p PolicyConverter) ConvertMetadataToKey(m unversioned.ResourceMetadata) (model.Key, error) {
@@ -56,6 +60,14 @@ func (p PolicyConverter) ConvertAPIToKVPair(a unversioned.Resource) (*model.KVPa
 		},
 	}
 
+	bp := d.Value.(*model.Policy)
+	if err = p.Deprecated.RecordRules(k, "inbound", bp.InboundRules); err != nil {
+		return nil, err
+	}
+	if err = p.Deprecated.RecordRules(k, "outbound", bp.OutboundRules); err != nil {
+		return nil, err
+	}
+
 	if ap.Spec.DoNotTrack || ap.Spec.PreDNAT {
 		// This case happens when there is a pre-existing policy in the datastore, from before
 		// the ApplyOnForward feature was available. DoNotTrack or PreDNAT policy applies to
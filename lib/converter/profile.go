@@ -18,12 +18,16 @@ import (
 	api "github.com/projectcalico/libcalico-go/lib/apis/v1"
 	"github.com/projectcalico/libcalico-go/lib/apis/v1/unversioned"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/upgrade/converters"
 	log "github.com/sirupsen/logrus"
 )
 
 // ProfileConverter implements a set of functions used for converting between
 // API and backend representations of the Profile resource.
-type ProfileConverter struct{}
+type ProfileConverter struct {
+	// Deprecated, if set, records the rules that use deprecated fields.
+	Deprecated *converters.DeprecatedFields
+}
 
 // ConvertMetadataToKey converts a ProfileMetadata to a ProfileKey
 func (p ProfileConverter) ConvertMetadataToKey(m unversioned.ResourceMetadata) (model.Key, error) {
@@ -69,6 +73,14 @@ func (c ProfileConverter) ConvertAPIToKVPair(a unversioned.Resource) (*model.KVP
 		},
 	}
 
+	bp := d.Value.(*model.Profile)
+	if err = c.Deprecated.RecordRules(k, "inbound", bp.Rules.InboundRules); err != nil {
+		return nil, err
+	}
+	if err = c.Deprecated.RecordRules(k, "outbound", bp.Rules.OutboundRules); err != nil {
+		return nil, err
+	}
+
 	return &d, nil
 }
 
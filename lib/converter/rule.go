@@ -15,10 +15,6 @@
 package converter
 
 import (
-	"sync"
-
-	log "github.com/sirupsen/logrus"
-
 	api "github.com/projectcalico/libcalico-go/lib/apis/v1"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	"github.com/projectcalico/libcalico-go/lib/net"
@@ -50,8 +46,6 @@ func RulesBackendToAPI(brs []model.Rule) []api.Rule {
 	return ars
 }
 
-var logDeprecationOnce sync.Once
-
 // ruleAPIToBackend converts an API Rule structure to a Backend Rule structure.
 func ruleAPIToBackend(ar api.Rule) model.Rule {
 	var icmpCode, icmpType, notICMPCode, notICMPType *int
@@ -65,14 +59,6 @@ func ruleAPIToBackend(ar api.Rule) model.Rule {
 		notICMPType = ar.NotICMP.Type
 	}
 
-	if ar.Source.Net != nil || ar.Source.NotNet != nil ||
-		ar.Destination.Net != nil || ar.Destination.NotNet != nil {
-		logDeprecationOnce.Do(func() {
-			log.Warning("The Net and NotNet fields in Source/Destination " +
-				"EntityRules are deprecated.  Please use Nets or NotNets.")
-		})
-	}
-
 	return model.Rule{
 		Action:      ruleActionAPIToBackend(ar.Action),
 		IPVersion:   ar.IPVersion,
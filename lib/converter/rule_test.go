@@ -17,12 +17,14 @@ package converter_test
 import (
 	. "github.com/projectcalico/libcalico-go/lib/converter"
 
+	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	api "github.com/projectcalico/libcalico-go/lib/apis/v1"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	"github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/upgrade/converters"
 )
 
 var (
@@ -125,3 +127,35 @@ var _ = DescribeTable("RulesBackendToAPI",
 		},
 	),
 )
+
+var _ = Describe("PolicyConverter deprecated fields", func() {
+	It("should record each rule that uses deprecated Net fields", func() {
+		deprecated := &converters.DeprecatedFields{}
+		p := PolicyConverter{Deprecated: deprecated}
+		_, err := p.ConvertAPIToKVPair(api.Policy{
+			Metadata: api.PolicyMetadata{Name: "policy1"},
+			Spec: api.PolicySpec{
+				IngressRules: []api.Rule{
+					{Action: "allow", Source: api.EntityRule{Net: &cidr1Net}},
+					{Action: "allow", Source: api.EntityRule{Selector: "has(label1)"}},
+					{Action: "deny", Destination: api.EntityRule{NotNet: &cidr2Net}},
+				},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deprecated.Usages).To(Equal([]converters.DeprecatedFieldUsage{
+			{
+				Key:       model.PolicyKey{Name: "policy1"},
+				Direction: "inbound",
+				Index:     0,
+				Fields:    []string{"SrcNet"},
+			},
+			{
+				Key:       model.PolicyKey{Name: "policy1"},
+				Direction: "inbound",
+				Index:     2,
+				Fields:    []string{"NotDstNet"},
+			},
+		}))
+	})
+})
@@ -84,6 +84,7 @@ func (w *WorkloadEndpointConverter) ConvertAPIToKVPair(a unversioned.Resource) (
 		Key: k,
 		Value: &model.WorkloadEndpoint{
 			Labels:           ah.Metadata.Labels,
+			Annotations:      ah.Metadata.Annotations,
 			ActiveInstanceID: ah.Metadata.ActiveInstanceID,
 			State:            "active",
 			Name:             ah.Spec.InterfaceName,
@@ -126,6 +127,7 @@ func (w *WorkloadEndpointConverter) ConvertKVPairToAPI(d *model.KVPair) (unversi
 	ah.Metadata.Workload = bk.WorkloadID
 	ah.Metadata.Name = bk.EndpointID
 	ah.Metadata.Labels = bh.Labels
+	ah.Metadata.Annotations = bh.Annotations
 	ah.Spec.InterfaceName = bh.Name
 	ah.Metadata.ActiveInstanceID = bh.ActiveInstanceID
 	ah.Spec.MAC = bh.Mac
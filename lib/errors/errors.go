@@ -28,8 +28,10 @@ func (e ErrorDatastoreError) Error() string {
 	return e.Err.Error()
 }
 
-// Error indicating a resource does not exist.  Used when attempting to delete or
-// udpate a non-existent resource.
+// Error indicating a resource does not exist.  Used when attempting to get, delete or
+// udpate a non-existent resource.  For the v3 resource clients the Identifier is the
+// model.ResourceKey of the resource, which contains the resource kind, namespace and name.
+// Callers should check for this error by type rather than by the error string.
 type ErrorResourceDoesNotExist struct {
 	Err        error
 	Identifier interface{}
@@ -74,6 +76,25 @@ func (e ErrorConnectionUnauthorized) Error() string {
 	return "connection is unauthorized"
 }
 
+// Error indicating the datastore could not be reached.
+type ErrorDatastoreUnreachable struct {
+	Err error
+}
+
+func (e ErrorDatastoreUnreachable) Error() string {
+	return fmt.Sprintf("datastore is unreachable: %v", e.Err)
+}
+
+// Error indicating the datastore is reachable but has not been initialized for use by
+// Calico.  The datastore is initialized by the client EnsureInitialized method.
+type ErrorDatastoreUninitialized struct {
+	Identifier interface{}
+}
+
+func (e ErrorDatastoreUninitialized) Error() string {
+	return fmt.Sprintf("datastore is not initialized: %v does not exist", e.Identifier)
+}
+
 // Validation error containing the fields that are failed validation.
 type ErrorValidation struct {
 	ErroredFields []ErroredField
@@ -144,6 +165,24 @@ func (e ErrorPartialFailure) Error() string {
 	return "operation partially failed"
 }
 
+// Error indicating that an operation on a collection of resources failed for one or
+// more of the resources in the collection.  Each failure is recorded in Errors.
+type ErrorCollectionFailure struct {
+	Operation string
+	Errors    []error
+}
+
+func (e ErrorCollectionFailure) Error() string {
+	if len(e.Errors) == 1 {
+		return fmt.Sprintf("operation %s failed for 1 resource: %v", e.Operation, e.Errors[0])
+	}
+	s := fmt.Sprintf("operation %s failed for %d resources:\n", e.Operation, len(e.Errors))
+	for _, err := range e.Errors {
+		s = s + fmt.Sprintf("-  %v\n", err)
+	}
+	return s
+}
+
 // UpdateErrorIdentifier modifies the supplied error to use the new resource
 // identifier.
 func UpdateErrorIdentifier(err error, id interface{}) error {
@@ -181,6 +220,13 @@ func (e ErrorWatchTerminated) Error() string {
 	return fmt.Sprintf("watch terminated (closedByRemote:%v): %v", e.ClosedByRemote, e.Err)
 }
 
+// Error indicating that the client has been closed and can no longer be used.
+type ErrorClientClosed struct{}
+
+func (e ErrorClientClosed) Error() string {
+	return "client closed"
+}
+
 // Error indicating the datastore has failed to parse an entry.
 type ErrorParsingDatastoreEntry struct {
 	RawKey   string
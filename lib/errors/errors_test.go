@@ -49,4 +49,25 @@ var _ = DescribeTable(
 		},
 		"operation apply is not supported on foo.bar.baz: cannot mix foobar with baz",
 	),
+	Entry(
+		"Collection failure with a single error",
+		errors.ErrorCollectionFailure{
+			Operation: "delete",
+			Errors: []error{
+				errors.ErrorResourceUpdateConflict{Identifier: "foo.bar.baz"},
+			},
+		},
+		"operation delete failed for 1 resource: update conflict: foo.bar.baz",
+	),
+	Entry(
+		"Collection failure with multiple errors",
+		errors.ErrorCollectionFailure{
+			Operation: "delete",
+			Errors: []error{
+				errors.ErrorResourceUpdateConflict{Identifier: "foo"},
+				errors.ErrorResourceUpdateConflict{Identifier: "bar"},
+			},
+		},
+		"operation delete failed for 2 resources:\n-  update conflict: foo\n-  update conflict: bar\n",
+	),
 )
@@ -70,6 +70,10 @@ type Interface interface {
 	// the specified pool across all hosts.
 	ReleasePoolAffinities(ctx context.Context, pool cnet.IPNet) error
 
+	// GetPoolUsage returns the number of addresses in the pool with the given CIDR, and how
+	// many of those are assigned, based on the IPAM blocks allocated from the pool.
+	GetPoolUsage(ctx context.Context, pool cnet.IPNet) (*PoolUsage, error)
+
 	// GetIPAMConfig returns the global IPAM configuration.  If no IPAM configuration
 	// has been set, returns a default configuration with StrictAffinity disabled
 	// and AutoAllocateBlocks enabled.
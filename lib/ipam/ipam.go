@@ -18,6 +18,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
+	"sort"
 
 	log "github.com/sirupsen/logrus"
 
@@ -208,15 +210,16 @@ func (c ipamClient) determinePools(requestedPools []net.IPNet, version ipVersion
 
 	if len(requestedPools) > 0 {
 		log.Debugf("requested IPPools: %v", requestedPools)
-		// Build a map so we can lookup existing pools.
+		// Build a map so we can lookup existing pools.  Key on the masked network so that
+		// a requested pool with host bits set still matches.
 		pm := map[string]bool{}
 		for _, p := range enabledPools {
-			pm[p.String()] = true
+			pm[p.MaskedString()] = true
 		}
 
 		// Make sure each requested pool exists.
 		for _, rp := range requestedPools {
-			if _, ok := pm[rp.String()]; !ok {
+			if _, ok := pm[rp.MaskedString()]; !ok {
 				// The requested pool doesn't exist.
 				return nil, fmt.Errorf("the given pool (%s) does not exist, or is not enabled", rp.IPNet.String())
 			}
@@ -667,10 +670,17 @@ func (c ipamClient) assignFromExistingBlock(ctx context.Context, block *model.KV
 	}
 	logCtx.Infof("Attempting to assign %d addresses from block", num)
 
+	// Get the reserved CIDRs that must not be assigned from the block.
+	reserved, err := c.pools.GetReservedCIDRs(blockCIDR.Version())
+	if err != nil {
+		logCtx.WithError(err).Errorf("Error getting reserved CIDRs")
+		return nil, err
+	}
+
 	// Pull out the block.
 	b := allocationBlock{block.Value.(*model.AllocationBlock)}
 
-	ips, err := b.autoAssign(num, handleID, host, attrs, affCheck)
+	ips, err := b.autoAssign(num, handleID, host, attrs, affCheck, reserved)
 	if err != nil {
 		logCtx.WithError(err).Errorf("Error in auto assign")
 		return nil, err
@@ -970,6 +980,96 @@ func (c ipamClient) hostBlockPairs(ctx context.Context, pool net.IPNet) (map[str
 	return pairs, nil
 }
 
+// GetPoolUsage returns the number of addresses in the pool with the given CIDR, and how
+// many of those are assigned, based on the IPAM blocks allocated from the pool.
+func (c ipamClient) GetPoolUsage(ctx context.Context, pool net.IPNet) (*PoolUsage, error) {
+	logCtx := log.WithField("pool", pool.String())
+	logCtx.Debug("Getting IP pool usage")
+
+	usage := &PoolUsage{CIDR: pool}
+
+	// Determine whether IPAM assigns addresses from the pool.
+	enabled, err := c.pools.GetEnabledPools(pool.Version())
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range enabled {
+		if p.MaskedEqual(pool) {
+			usage.IPAMEnabled = true
+			break
+		}
+	}
+
+	// Count the assigned addresses in each of the blocks within the pool.
+	objs, err := c.client.List(ctx, model.BlockListOptions{IPVersion: pool.Version()}, "")
+	if err != nil {
+		logCtx.WithError(err).Error("Error querying IPAM blocks")
+		return nil, err
+	}
+	for _, o := range objs.KVPairs {
+		b := allocationBlock{o.Value.(*model.AllocationBlock)}
+		if !pool.Contains(b.CIDR.IP) {
+			continue
+		}
+		usage.Blocks++
+		if b.Affinity != nil {
+			usage.AffineBlocks++
+		}
+		usage.Allocated += blockSize - b.numFreeAddresses()
+	}
+
+	// The reserved addresses are never assigned, so they are excluded from the total.
+	reserved, err := c.pools.GetReservedCIDRs(pool.Version())
+	if err != nil {
+		return nil, err
+	}
+	usage.Reserved = reservedAddresses(pool, reserved)
+	usage.Total = new(big.Int).Sub(cidrAddresses(pool), usage.Reserved)
+	usage.Free = new(big.Int).Sub(usage.Total, big.NewInt(int64(usage.Allocated)))
+	return usage, nil
+}
+
+// cidrAddresses returns the number of addresses in the CIDR.
+func cidrAddresses(cidr net.IPNet) *big.Int {
+	ones, bits := cidr.Mask.Size()
+	return new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+}
+
+// reservedAddresses returns the number of addresses in the pool covered by the reserved
+// CIDRs.  Two CIDRs are either disjoint or one contains the other, so the addresses of a
+// reserved CIDR are only counted if it is not within a larger reserved CIDR.
+func reservedAddresses(pool net.IPNet, reserved []net.IPNet) *big.Int {
+	poolOnes, _ := pool.Mask.Size()
+	inPool := []net.IPNet{}
+	for _, r := range reserved {
+		if ones, _ := r.Mask.Size(); ones >= poolOnes && pool.Contains(r.IP) {
+			inPool = append(inPool, r)
+		}
+	}
+	sort.SliceStable(inPool, func(i, j int) bool {
+		iOnes, _ := inPool[i].Mask.Size()
+		jOnes, _ := inPool[j].Mask.Size()
+		return iOnes < jOnes
+	})
+
+	total := big.NewInt(0)
+	counted := []net.IPNet{}
+	for _, r := range inPool {
+		contained := false
+		for _, c := range counted {
+			if c.Contains(r.IP) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			counted = append(counted, r)
+			total.Add(total, cidrAddresses(r))
+		}
+	}
+	return total
+}
+
 // IpsByHandle returns a list of all IP addresses that have been
 // assigned using the provided handle.
 func (c ipamClient) IPsByHandle(ctx context.Context, handleID string) ([]net.IP, error) {
@@ -74,7 +74,7 @@ func newBlock(cidr cnet.IPNet) allocationBlock {
 }
 
 func (b *allocationBlock) autoAssign(
-	num int, handleID *string, host string, attrs map[string]string, affinityCheck bool) ([]cnet.IP, error) {
+	num int, handleID *string, host string, attrs map[string]string, affinityCheck bool, reserved []cnet.IPNet) ([]cnet.IP, error) {
 
 	// Determine if we need to check for affinity.
 	checkAffinity := b.StrictAffinity || affinityCheck
@@ -90,12 +90,22 @@ func (b *allocationBlock) autoAssign(
 		}
 	}
 
-	// Walk the allocations until we find enough addresses.
+	// Walk the allocations until we find enough addresses.  Reserved addresses are
+	// skipped, and remain unallocated.
 	ordinals := []int{}
-	for len(b.Unallocated) > 0 && len(ordinals) < num {
-		ordinals = append(ordinals, b.Unallocated[0])
-		b.Unallocated = b.Unallocated[1:]
+	unallocated := []int{}
+	for i, o := range b.Unallocated {
+		if len(ordinals) == num {
+			unallocated = append(unallocated, b.Unallocated[i:]...)
+			break
+		}
+		if isReserved(incrementIP(cnet.IP{b.CIDR.IP}, big.NewInt(int64(o))), reserved) {
+			unallocated = append(unallocated, o)
+			continue
+		}
+		ordinals = append(ordinals, o)
 	}
+	b.Unallocated = unallocated
 
 	// Create slice of IPs and perform the allocations.
 	ips := []cnet.IP{}
@@ -109,6 +119,16 @@ func (b *allocationBlock) autoAssign(
 	return ips, nil
 }
 
+// isReserved returns true if the IP address is within one of the reserved CIDRs.
+func isReserved(ip cnet.IP, reserved []cnet.IPNet) bool {
+	for _, r := range reserved {
+		if r.Contains(ip.IP) {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *allocationBlock) assign(address cnet.IP, handleID *string, attrs map[string]string, host string) error {
 	if b.StrictAffinity && b.Affinity != nil && !hostAffinityMatches(host, b.AllocationBlock) {
 		// Affinity check is enabled but the host does not match - error.
@@ -39,7 +39,8 @@ import (
 // of the accessor that we populate directly, rather than requiring the pool
 // data to be persisted in etcd.
 type ipPoolAccessor struct {
-	pools map[string]bool
+	pools    map[string]bool
+	reserved []string
 }
 
 func (i *ipPoolAccessor) GetEnabledPools(ipVersion int) ([]cnet.IPNet, error) {
@@ -68,6 +69,17 @@ func (i *ipPoolAccessor) GetEnabledPools(ipVersion int) ([]cnet.IPNet, error) {
 	return cidrs, nil
 }
 
+func (i *ipPoolAccessor) GetReservedCIDRs(ipVersion int) ([]cnet.IPNet, error) {
+	cidrs := []cnet.IPNet{}
+	for _, r := range i.reserved {
+		c := cnet.MustParseCIDR(r)
+		if c.Version() == ipVersion {
+			cidrs = append(cidrs, c)
+		}
+	}
+	return cidrs, nil
+}
+
 var (
 	ipPools = &ipPoolAccessor{pools: map[string]bool{}}
 )
@@ -15,6 +15,8 @@
 package ipam
 
 import (
+	"math/big"
+
 	cnet "github.com/projectcalico/libcalico-go/lib/net"
 )
 
@@ -80,3 +82,34 @@ type IPAMConfig struct {
 	// If false, then StrictAffinity must be true.  The default value is true.
 	AutoAllocateBlocks bool
 }
+
+// PoolUsage describes the address usage of an IP pool, based on the IPAM blocks allocated
+// from the pool.
+type PoolUsage struct {
+	// The pool CIDR.
+	CIDR cnet.IPNet
+
+	// Whether Calico IPAM assigns addresses from the pool.  This is false if the pool is
+	// disabled (or does not exist), in which case the usage only reflects any addresses
+	// assigned before the pool was disabled.
+	IPAMEnabled bool
+
+	// The total number of addresses in the pool that may be assigned, which excludes the
+	// reserved addresses.
+	Total *big.Int
+
+	// The number of addresses in the pool that are reserved by the ReservedCIDRs of the pool,
+	// and so are not assigned by IPAM.  Reserved CIDRs are only known for enabled pools.
+	Reserved *big.Int
+
+	// The number of addresses assigned from the pool.
+	Allocated int
+
+	// The number of addresses in the pool that are not assigned.
+	Free *big.Int
+
+	// The number of IPAM blocks allocated from the pool, and how many of those blocks have
+	// affinity to a host.
+	Blocks       int
+	AffineBlocks int
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"context"
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+// usageBlock returns a block KVPair with the given number of addresses assigned.
+func usageBlock(cidr string, assigned int, affinity *string) *model.KVPair {
+	b := newBlock(cnet.MustParseCIDR(cidr))
+	b.Unallocated = b.Unallocated[assigned:]
+	b.Affinity = affinity
+	return &model.KVPair{
+		Key:   model.BlockKey{CIDR: b.CIDR},
+		Value: b.AllocationBlock,
+	}
+}
+
+var _ = Describe("IP pool usage", func() {
+	ctx := context.Background()
+	var ic Interface
+	var listed model.BlockListOptions
+	var pools *ipPoolAccessor
+
+	BeforeEach(func() {
+		host := "host:hostA"
+		fc := newFakeClient()
+		fc.listFuncs["default"] = func(ctx context.Context, list model.ListInterface, revision string) (*model.KVPairList, error) {
+			listed = list.(model.BlockListOptions)
+			return &model.KVPairList{
+				KVPairs: []*model.KVPair{
+					usageBlock("10.0.0.0/26", 3, &host),
+					usageBlock("10.0.0.64/26", 1, nil),
+					usageBlock("10.1.0.0/26", 5, &host),
+				},
+			}, nil
+		}
+		pools = &ipPoolAccessor{pools: map[string]bool{"10.0.0.0/24": true, "10.1.0.0/24": false}}
+		ic = NewIPAMClient(fc, pools)
+	})
+
+	It("should count the addresses assigned in the blocks within an enabled pool", func() {
+		usage, err := ic.GetPoolUsage(ctx, cnet.MustParseCIDR("10.0.0.0/24"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listed.IPVersion).To(Equal(4))
+		Expect(usage.CIDR.String()).To(Equal("10.0.0.0/24"))
+		Expect(usage.IPAMEnabled).To(BeTrue())
+		Expect(usage.Total.String()).To(Equal("256"))
+		Expect(usage.Allocated).To(Equal(4))
+		Expect(usage.Free.String()).To(Equal("252"))
+		Expect(usage.Blocks).To(Equal(2))
+		Expect(usage.AffineBlocks).To(Equal(1))
+	})
+
+	It("should match the pool when the CIDR has host bits set", func() {
+		usage, err := ic.GetPoolUsage(ctx, cnet.MustParseCIDR("10.0.0.5/24"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(usage.IPAMEnabled).To(BeTrue())
+		Expect(usage.Allocated).To(Equal(4))
+	})
+
+	It("should exclude the reserved addresses within the pool", func() {
+		pools.reserved = []string{"10.0.0.0/28", "10.0.0.8/30", "10.0.0.128/31", "10.1.0.0/28"}
+		usage, err := ic.GetPoolUsage(ctx, cnet.MustParseCIDR("10.0.0.0/24"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(usage.Reserved.String()).To(Equal("18"))
+		Expect(usage.Total.String()).To(Equal("238"))
+		Expect(usage.Allocated).To(Equal(4))
+		Expect(usage.Free.String()).To(Equal("234"))
+	})
+
+	It("should report that IPAM is not enabled for a disabled pool", func() {
+		usage, err := ic.GetPoolUsage(ctx, cnet.MustParseCIDR("10.1.0.0/24"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(usage.IPAMEnabled).To(BeFalse())
+		Expect(usage.Allocated).To(Equal(5))
+		Expect(usage.Free.String()).To(Equal("251"))
+		Expect(usage.Blocks).To(Equal(1))
+	})
+
+	It("should report the size of a large IPv6 pool", func() {
+		usage, err := ic.GetPoolUsage(ctx, cnet.MustParseCIDR("fd00::/48"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listed.IPVersion).To(Equal(6))
+		Expect(usage.IPAMEnabled).To(BeFalse())
+		Expect(usage.Total.String()).To(Equal(new(big.Int).Lsh(big.NewInt(1), 80).String()))
+		Expect(usage.Free.String()).To(Equal(usage.Total.String()))
+		Expect(usage.Blocks).To(Equal(0))
+	})
+})
@@ -19,4 +19,6 @@ import cnet "github.com/projectcalico/libcalico-go/lib/net"
 type PoolAccessorInterface interface {
 	// Returns a list of enabled pools sorted in alphanumeric name order.
 	GetEnabledPools(ipVersion int) ([]cnet.IPNet, error)
+	// Returns the CIDRs within the enabled pools that IPAM does not automatically assign.
+	GetReservedCIDRs(ipVersion int) ([]cnet.IPNet, error)
 }
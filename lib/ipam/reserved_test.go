@@ -0,0 +1,87 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cnet "github.com/projectcalico/libcalico-go/lib/net"
+)
+
+var _ = Describe("IP pool reserved CIDRs", func() {
+	ctx := context.Background()
+	host := "hostA"
+	affinity := "host:" + host
+	var fc *fakeClient
+	var pools *ipPoolAccessor
+	var updated *model.AllocationBlock
+
+	BeforeEach(func() {
+		updated = nil
+		fc = newFakeClient()
+		fc.updateFuncs["default"] = func(ctx context.Context, object *model.KVPair) (*model.KVPair, error) {
+			updated = object.Value.(*model.AllocationBlock)
+			return object, nil
+		}
+		pools = &ipPoolAccessor{pools: map[string]bool{"10.0.0.0/24": true}}
+	})
+
+	newAffineBlock := func() *model.KVPair {
+		b := newBlock(cnet.MustParseCIDR("10.0.0.0/26"))
+		b.Affinity = &affinity
+		return &model.KVPair{Key: model.BlockKey{CIDR: b.CIDR}, Value: b.AllocationBlock}
+	}
+
+	It("should not assign the reserved addresses", func() {
+		pools.reserved = []string{"10.0.0.0/31", "10.0.0.3/32", "fd00::/127"}
+		ic := ipamClient{client: fc, pools: pools}
+
+		ips, err := ic.assignFromExistingBlock(ctx, newAffineBlock(), 3, nil, nil, host, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(HaveLen(3))
+		Expect(ips[0].String()).To(Equal("10.0.0.2"))
+		Expect(ips[1].String()).To(Equal("10.0.0.4"))
+		Expect(ips[2].String()).To(Equal("10.0.0.5"))
+
+		// The reserved addresses remain unallocated.
+		Expect(updated.Unallocated[:3]).To(Equal([]int{0, 1, 3}))
+		Expect(updated.Allocations[0]).To(BeNil())
+		Expect(updated.Allocations[3]).To(BeNil())
+	})
+
+	It("should assign from the start of the block with no reserved CIDRs", func() {
+		ic := ipamClient{client: fc, pools: pools}
+
+		ips, err := ic.assignFromExistingBlock(ctx, newAffineBlock(), 2, nil, nil, host, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(HaveLen(2))
+		Expect(ips[0].String()).To(Equal("10.0.0.0"))
+		Expect(ips[1].String()).To(Equal("10.0.0.1"))
+	})
+
+	It("should treat a block with only reserved addresses free as full", func() {
+		pools.reserved = []string{"10.0.0.0/26"}
+		ic := ipamClient{client: fc, pools: pools}
+
+		ips, err := ic.assignFromExistingBlock(ctx, newAffineBlock(), 1, nil, nil, host, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(BeEmpty())
+		Expect(updated).To(BeNil())
+	})
+})
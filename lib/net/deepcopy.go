@@ -0,0 +1,82 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net
+
+import "net"
+
+// DeepCopyInto copies the IP into out.  The copy does not share the underlying bytes of the IP.
+func (in *IP) DeepCopyInto(out *IP) {
+	if in.IP == nil {
+		out.IP = nil
+		return
+	}
+	out.IP = make(net.IP, len(in.IP))
+	copy(out.IP, in.IP)
+}
+
+// DeepCopy returns a copy of the IP that does not share its underlying bytes.
+func (in *IP) DeepCopy() *IP {
+	if in == nil {
+		return nil
+	}
+	out := new(IP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the IPNet into out.  The copy does not share the underlying bytes of the
+// IP or mask.
+func (in *IPNet) DeepCopyInto(out *IPNet) {
+	out.IP, out.Mask = nil, nil
+	if in.IP != nil {
+		out.IP = make(net.IP, len(in.IP))
+		copy(out.IP, in.IP)
+	}
+	if in.Mask != nil {
+		out.Mask = make(net.IPMask, len(in.Mask))
+		copy(out.Mask, in.Mask)
+	}
+}
+
+// DeepCopy returns a copy of the IPNet that does not share the underlying bytes of the IP or
+// mask.
+func (in *IPNet) DeepCopy() *IPNet {
+	if in == nil {
+		return nil
+	}
+	out := new(IPNet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the MAC into out.  The copy does not share the underlying bytes of the MAC.
+func (in *MAC) DeepCopyInto(out *MAC) {
+	if in.HardwareAddr == nil {
+		out.HardwareAddr = nil
+		return
+	}
+	out.HardwareAddr = make(net.HardwareAddr, len(in.HardwareAddr))
+	copy(out.HardwareAddr, in.HardwareAddr)
+}
+
+// DeepCopy returns a copy of the MAC that does not share its underlying bytes.
+func (in *MAC) DeepCopy() *MAC {
+	if in == nil {
+		return nil
+	}
+	out := new(MAC)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -24,8 +24,13 @@ type IP struct {
 	net.IP
 }
 
-// MarshalJSON interface for an IP
+// MarshalJSON interface for an IP.  IPv4-mapped IPv6 addresses are written
+// in dotted-quad IPv4 form so that the same address always serializes the same
+// way.
 func (i IP) MarshalJSON() ([]byte, error) {
+	if ipv4 := i.To4(); ipv4 != nil {
+		i.IP = ipv4
+	}
 	s, err := i.MarshalText()
 	if err != nil {
 		return nil, err
@@ -33,7 +38,8 @@ func (i IP) MarshalJSON() ([]byte, error) {
 	return json.Marshal(string(s))
 }
 
-// UnmarshalJSON interface for an IP
+// UnmarshalJSON interface for an IP.  IPv4 and IPv4-mapped IPv6 addresses are
+// both stored in the 4-byte IPv4 form.
 func (i *IP) UnmarshalJSON(b []byte) error {
 	var s string
 	if err := json.Unmarshal(b, &s); err != nil {
@@ -65,7 +71,8 @@ func ParseIP(ip string) *IP {
 	return &IP{addr}
 }
 
-// Version returns the IP version for an IP, or 0 if the IP is not valid.
+// Version returns the IP version for an IP, or 0 if the IP is not valid.  IPv4-mapped
+// IPv6 addresses are treated as IPv4.
 func (i IP) Version() int {
 	if i.To4() != nil {
 		return 4
@@ -75,6 +82,16 @@ func (i IP) Version() int {
 	return 0
 }
 
+// Is4 returns true if the IP is an IPv4 (or IPv4-mapped IPv6) address.
+func (i IP) Is4() bool {
+	return i.Version() == 4
+}
+
+// Is6 returns true if the IP is a native IPv6 address.
+func (i IP) Is6() bool {
+	return i.Version() == 6
+}
+
 // Network returns the IP address as a fully masked IPNet type.
 func (i *IP) Network() *IPNet {
 	// Unmarshaling an IPv4 address returns a 16-byte format of the
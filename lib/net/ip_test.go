@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net_test
+
+import (
+	"encoding/json"
+	gonet "net"
+
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+func init() {
+	// Perform tests of JSON marshaling and unmarshaling of IP addresses.  The
+	// input is parsed with the standard library so that IPv4 addresses are held
+	// in their 16-byte IPv4-mapped form before marshaling.
+	DescribeTable("IPJSONRoundTrip",
+		func(in, expectedJSON string) {
+			ip := net.IP{gonet.ParseIP(in)}
+			b, err := json.Marshal(ip)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(expectedJSON))
+
+			var out net.IP
+			err = json.Unmarshal(b, &out)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(net.MustParseIP(in)))
+
+			// Unmarshaling the input form directly gives the same value.
+			var direct net.IP
+			err = json.Unmarshal([]byte(`"`+in+`"`), &direct)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(direct).To(Equal(out))
+		},
+		Entry("IPv4 address", "10.0.0.1", `"10.0.0.1"`),
+		Entry("IPv4-mapped IPv6 address", "::ffff:10.0.0.1", `"10.0.0.1"`),
+		Entry("native IPv6 address", "fd00::1", `"fd00::1"`),
+	)
+
+	DescribeTable("IPJSONCanonicalForm",
+		func(in string, expectedLen int) {
+			var ip net.IP
+			err := json.Unmarshal([]byte(`"`+in+`"`), &ip)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.IP).To(HaveLen(expectedLen))
+		},
+		Entry("IPv4 address is stored as 4 bytes", "10.0.0.1", gonet.IPv4len),
+		Entry("IPv4-mapped IPv6 address is stored as 4 bytes", "::ffff:10.0.0.1", gonet.IPv4len),
+		Entry("native IPv6 address is stored as 16 bytes", "fd00::1", gonet.IPv6len),
+	)
+
+	// Perform tests of the IP address family helpers.  The input is parsed with the
+	// standard library so that IPv4 addresses are held in their 16-byte form.
+	DescribeTable("IPVersion",
+		func(in string, expectedVersion int) {
+			ip := net.IP{gonet.ParseIP(in)}
+			Expect(ip.Version()).To(Equal(expectedVersion))
+			Expect(ip.Is4()).To(Equal(expectedVersion == 4))
+			Expect(ip.Is6()).To(Equal(expectedVersion == 6))
+		},
+		Entry("IPv4 address", "10.0.0.1", 4),
+		Entry("IPv4-mapped IPv6 address", "::ffff:10.0.0.1", 4),
+		Entry("native IPv6 address", "fd00::1", 6),
+		Entry("invalid address", "not an IP", 0),
+	)
+}
@@ -16,6 +16,8 @@ package net
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/big"
 	"net"
 )
 
@@ -67,6 +69,74 @@ func (i *IPNet) Network() *IPNet {
 	return n
 }
 
+// Subnets returns the ordered list of subnets with the given prefix length that the network
+// decomposes into, for example the /26 blocks of a /24 IP pool.  An error is returned if the
+// prefix length is shorter than the prefix length of the network, or longer than the address
+// length.  Since the number of subnets grows exponentially with the prefix length, use
+// SubnetIterator to enumerate large splits without holding all of the subnets in memory.
+func (i IPNet) Subnets(prefixLen int) ([]IPNet, error) {
+	it, err := i.SubnetIterator(prefixLen)
+	if err != nil {
+		return nil, err
+	}
+	subnets := []IPNet{}
+	for n := it.Next(); n != nil; n = it.Next() {
+		subnets = append(subnets, *n)
+	}
+	return subnets, nil
+}
+
+// SubnetIterator returns an iterator over the subnets with the given prefix length that the
+// network decomposes into.  The same restrictions on the prefix length apply as for Subnets.
+func (i IPNet) SubnetIterator(prefixLen int) (*SubnetIterator, error) {
+	ones, bits := i.Mask.Size()
+	if bits == 0 {
+		return nil, fmt.Errorf("invalid network %s", i.String())
+	}
+	if prefixLen < ones || prefixLen > bits {
+		return nil, fmt.Errorf("cannot split network %s into /%d subnets: prefix length must be between %d and %d",
+			i.String(), prefixLen, ones, bits)
+	}
+
+	ip := i.IP.Mask(i.Mask)
+	if bits == 32 {
+		ip = ip.To4()
+	}
+	start := new(big.Int).SetBytes(ip)
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	return &SubnetIterator{
+		next:   start,
+		end:    new(big.Int).Add(start, size),
+		step:   new(big.Int).Lsh(big.NewInt(1), uint(bits-prefixLen)),
+		length: len(ip),
+		mask:   net.CIDRMask(prefixLen, bits),
+	}, nil
+}
+
+// SubnetIterator enumerates the subnets of a network in order.
+type SubnetIterator struct {
+	next   *big.Int
+	end    *big.Int
+	step   *big.Int
+	length int
+	mask   net.IPMask
+}
+
+// Next returns the next subnet, or nil when all subnets have been returned.
+func (s *SubnetIterator) Next() *IPNet {
+	if s.next.Cmp(s.end) >= 0 {
+		return nil
+	}
+
+	// Convert the big.Int to an IP, padding to the full address length.
+	b := s.next.Bytes()
+	ip := make(net.IP, s.length)
+	copy(ip[s.length-len(b):], b)
+	s.next.Add(s.next, s.step)
+
+	return &IPNet{net.IPNet{IP: ip, Mask: s.mask}}
+}
+
 func ParseCIDR(c string) (*IP, *IPNet, error) {
 	netIP, netIPNet, e := net.ParseCIDR(c)
 	if netIPNet == nil || e != nil {
@@ -88,7 +158,8 @@ func ParseCIDR(c string) (*IP, *IPNet, error) {
 }
 
 // Parse a CIDR or an IP address and return the IP, CIDR or error.  If an IP address
-// string is supplied, then the CIDR returned is the fully masked IP address (i.e /32 or /128)
+// string is supplied, then the CIDR returned is the fully masked IP address (i.e /32 or /128).
+// If a CIDR is supplied, then the CIDR returned is the network, with the host bits masked.
 func ParseCIDROrIP(c string) (*IP, *IPNet, error) {
 	// First try parsing as a CIDR.
 	ip, cidr, err := ParseCIDR(c)
@@ -106,8 +177,8 @@ func ParseCIDROrIP(c string) (*IP, *IPNet, error) {
 		return ip, n, nil
 	}
 
-	// That failed too, return the original error.
-	return nil, nil, err
+	// That failed too.
+	return nil, nil, fmt.Errorf("%q is not a valid IP address or CIDR", c)
 }
 
 // String returns a friendly name for the network.  The standard net package
@@ -118,6 +189,22 @@ func (i IPNet) String() string {
 	return ip.String()
 }
 
+// MaskedString returns the string form of the network with the host bits masked, so that
+// IPNets for the same network always have the same string, for example both 10.0.0.5/24 and
+// 10.0.0.0/24 return "10.0.0.0/24".  Use this rather than String when keying on the network.
+func (i IPNet) MaskedString() string {
+	n := net.IPNet{IP: i.IP.Mask(i.Mask), Mask: i.Mask}
+	return n.String()
+}
+
+// MaskedEqual returns true if the two IPNets are the same network, ignoring the host bits,
+// for example 10.0.0.5/24 and 10.0.0.0/24.
+func (i IPNet) MaskedEqual(other IPNet) bool {
+	ones, bits := i.Mask.Size()
+	otherOnes, otherBits := other.Mask.Size()
+	return ones == otherOnes && bits == otherBits && i.IP.Mask(i.Mask).Equal(other.IP.Mask(other.Mask))
+}
+
 // MustParseNetwork parses the string into a IPNet.  The IP address in the
 // IPNet is masked.
 func MustParseNetwork(c string) IPNet {
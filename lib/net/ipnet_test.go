@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+func init() {
+	// Perform tests of splitting a network into subnets.
+	DescribeTable("IPNetSubnets",
+		func(in string, prefixLen int, expected []string) {
+			subnets, err := net.MustParseCIDR(in).Subnets(prefixLen)
+			Expect(err).NotTo(HaveOccurred())
+			actual := []string{}
+			for _, s := range subnets {
+				actual = append(actual, s.String())
+			}
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("IPv4 /24 into /26", "10.0.0.0/24", 26, []string{
+			"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26",
+		}),
+		Entry("IPv4 unmasked /24 into /26", "10.0.0.10/24", 26, []string{
+			"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26",
+		}),
+		Entry("IPv4 /26 into /26", "10.0.0.64/26", 26, []string{"10.0.0.64/26"}),
+		Entry("IPv4 top of range", "255.255.255.0/25", 26, []string{"255.255.255.0/26", "255.255.255.64/26"}),
+		Entry("IPv6 /120 into /122", "fd00::/120", 122, []string{
+			"fd00::/122", "fd00::40/122", "fd00::80/122", "fd00::c0/122",
+		}),
+	)
+
+	DescribeTable("IPNetSubnetsInvalid",
+		func(in string, prefixLen int) {
+			_, err := net.MustParseCIDR(in).Subnets(prefixLen)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("IPv4 target shorter than pool prefix", "10.0.0.0/24", 23),
+		Entry("IPv4 /24 target on a /28 pool", "10.0.0.0/28", 24),
+		Entry("IPv4 target longer than address", "10.0.0.0/24", 33),
+		Entry("IPv6 target longer than address", "fd00::/120", 129),
+	)
+
+	DescribeTable("IPNetMaskedString",
+		func(in, expected string) {
+			Expect(net.MustParseCIDR(in).MaskedString()).To(Equal(expected))
+		},
+		Entry("IPv4 masked", "10.0.0.0/24", "10.0.0.0/24"),
+		Entry("IPv4 with host bits", "10.0.0.5/24", "10.0.0.0/24"),
+		Entry("IPv4 host route", "10.0.0.5/32", "10.0.0.5/32"),
+		Entry("IPv6 with host bits", "fd00::1:5/112", "fd00::1:0/112"),
+	)
+
+	DescribeTable("IPNetMaskedEqual",
+		func(a, b string, expected bool) {
+			Expect(net.MustParseCIDR(a).MaskedEqual(net.MustParseCIDR(b))).To(Equal(expected))
+			Expect(net.MustParseCIDR(b).MaskedEqual(net.MustParseCIDR(a))).To(Equal(expected))
+		},
+		Entry("IPv4 with and without host bits", "10.0.0.5/24", "10.0.0.0/24", true),
+		Entry("IPv4 with different host bits", "10.0.0.5/24", "10.0.0.6/24", true),
+		Entry("IPv4 different networks", "10.0.0.5/24", "10.0.1.0/24", false),
+		Entry("IPv4 different prefix lengths", "10.0.0.0/24", "10.0.0.0/25", false),
+		Entry("IPv6 with and without host bits", "fd00::5/120", "fd00::/120", true),
+		Entry("IPv4 and IPv6", "10.0.0.0/24", "::ffff:10.0.0.0/120", false),
+	)
+
+	DescribeTable("ParseCIDROrIP",
+		func(in, expectedIP, expectedCIDR string) {
+			ip, cidr, err := net.ParseCIDROrIP(in)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal(expectedIP))
+			Expect(cidr.String()).To(Equal(expectedCIDR))
+		},
+		Entry("bare IPv4 address", "10.0.0.1", "10.0.0.1", "10.0.0.1/32"),
+		Entry("bare IPv6 address", "fd00::1", "fd00::1", "fd00::1/128"),
+		Entry("IPv4 CIDR", "10.0.0.0/24", "10.0.0.0", "10.0.0.0/24"),
+		Entry("IPv4 CIDR with host bits", "10.0.0.10/24", "10.0.0.10", "10.0.0.0/24"),
+		Entry("IPv6 CIDR with host bits", "fd00::1/120", "fd00::1", "fd00::/120"),
+	)
+
+	DescribeTable("ParseCIDROrIPInvalid",
+		func(in string) {
+			_, _, err := net.ParseCIDROrIP(in)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("\"" + in + "\" is not a valid IP address or CIDR"))
+		},
+		Entry("empty string", ""),
+		Entry("garbage", "not-an-ip"),
+		Entry("out of range octet", "10.0.0.256"),
+		Entry("out of range prefix", "10.0.0.0/33"),
+	)
+}
+
+var _ = Describe("IPNetSubnetIterator", func() {
+	It("should iterate over a large split without building a list", func() {
+		it, err := net.MustParseCIDR("fd00::/48").SubnetIterator(122)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(it.Next().String()).To(Equal("fd00::/122"))
+		Expect(it.Next().String()).To(Equal("fd00::40/122"))
+	})
+
+	It("should return nil once exhausted", func() {
+		it, err := net.MustParseCIDR("10.0.0.0/25").SubnetIterator(26)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(it.Next()).NotTo(BeNil())
+		Expect(it.Next()).NotTo(BeNil())
+		Expect(it.Next()).To(BeNil())
+	})
+})
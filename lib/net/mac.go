@@ -24,9 +24,34 @@ type MAC struct {
 	net.HardwareAddr
 }
 
+// MarshalText interface for a MAC.  The MAC is written as a lowercase,
+// colon-separated string, and an empty MAC is written as an empty string.
+func (m MAC) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText interface for a MAC.  An empty string unmarshals to an empty
+// MAC.
+func (m *MAC) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		m.HardwareAddr = nil
+		return nil
+	}
+	mac, err := net.ParseMAC(string(b))
+	if err != nil {
+		return err
+	}
+	m.HardwareAddr = mac
+	return nil
+}
+
 // MarshalJSON interface for a MAC
 func (m MAC) MarshalJSON() ([]byte, error) {
-	return json.Marshal(m.String())
+	s, err := m.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(s))
 }
 
 // UnmarshalJSON interface for a MAC
@@ -35,10 +60,5 @@ func (m *MAC) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &s); err != nil {
 		return err
 	}
-	if mac, err := net.ParseMAC(s); err != nil {
-		return err
-	} else {
-		m.HardwareAddr = mac
-		return nil
-	}
+	return m.UnmarshalText([]byte(s))
 }
@@ -0,0 +1,76 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net_test
+
+import (
+	"encoding/json"
+	gonet "net"
+
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+func mustParseMAC(s string) net.MAC {
+	mac, err := gonet.ParseMAC(s)
+	if err != nil {
+		panic(err)
+	}
+	return net.MAC{mac}
+}
+
+func init() {
+	DescribeTable("MACTextRoundTrip",
+		func(mac net.MAC, expectedText string) {
+			b, err := mac.MarshalText()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(b)).To(Equal(expectedText))
+
+			var out net.MAC
+			err = out.UnmarshalText(b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal(mac))
+
+			// Check the JSON form matches the text form.
+			j, err := json.Marshal(mac)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(j)).To(Equal(`"` + expectedText + `"`))
+
+			var outJSON net.MAC
+			err = json.Unmarshal(j, &outJSON)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(outJSON).To(Equal(mac))
+		},
+		Entry("standard 6-byte MAC", mustParseMAC("AA:BB:CC:01:02:03"), "aa:bb:cc:01:02:03"),
+		Entry("empty MAC", net.MAC{}, ""),
+	)
+
+	DescribeTable("MACUnmarshalText",
+		func(text string, expected *net.MAC) {
+			var mac net.MAC
+			err := mac.UnmarshalText([]byte(text))
+			if expected == nil {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mac).To(Equal(*expected))
+		},
+		Entry("uppercase MAC", "AA:BB:CC:01:02:03", &net.MAC{gonet.HardwareAddr{0xaa, 0xbb, 0xcc, 0x01, 0x02, 0x03}}),
+		Entry("dash-separated MAC", "aa-bb-cc-01-02-03", &net.MAC{gonet.HardwareAddr{0xaa, 0xbb, 0xcc, 0x01, 0x02, 0x03}}),
+		Entry("invalid MAC", "aa:bb:cc", nil),
+	)
+}
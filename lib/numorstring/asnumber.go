@@ -1,4 +1,4 @@
-// Copyright (c) 2016 Tigera, Inc. All rights reserved.
+// Copyright (c) 2016-2018 Tigera, Inc. All rights reserved.
 
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -22,13 +22,19 @@ import (
 	"strings"
 )
 
+// ASNumber is a BGP AS number.  4-byte AS numbers are supported, so the valid range is
+// 0-4294967295.
 type ASNumber uint32
 
 // ASNumberFromString creates an ASNumber struct from a string value.  The
-// string value may simply be a number or may be the ASN in dotted notation.
+// string value may simply be a number or may be the ASN in dotted notation,
+// e.g. "1.10" is the AS number 65546.
 func ASNumberFromString(s string) (ASNumber, error) {
-	if num, err := strconv.ParseUint(s, 10, 32); err == nil {
+	num, err := strconv.ParseUint(s, 10, 32)
+	if err == nil {
 		return ASNumber(num), nil
+	} else if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+		return 0, fmt.Errorf("AS Number %s is out of range 0-4294967295", s)
 	}
 
 	parts := strings.Split(s, ".")
@@ -67,7 +73,8 @@ func (a *ASNumber) UnmarshalJSON(b []byte) error {
 	}
 }
 
-// String returns the string value, or the Itoa of the uint value.
+// String returns the canonical string value of the AS number, which is the plain
+// (asplain) decimal value regardless of whether it was parsed from dotted notation.
 func (a ASNumber) String() string {
 	return strconv.FormatUint(uint64(a), 10)
 }
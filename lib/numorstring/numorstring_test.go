@@ -190,6 +190,58 @@ func init() {
 		// Protocol tests.
 		Entry("protocol udp -> UDP", numorstring.ProtocolFromInt(2), numorstring.ProtocolFromInt(2)),
 		Entry("protocol tcp -> TCP", numorstring.ProtocolFromString("TCP"), numorstring.ProtocolFromStringV1("TCP")),
+		Entry("protocol SCTP -> sctp", numorstring.ProtocolFromString("SCTP"), numorstring.ProtocolFromStringV1("sctp")),
+		Entry("protocol 132 -> 132", numorstring.ProtocolFromInt(132), numorstring.ProtocolFromInt(132)),
+	)
+
+	// Perform tests of ProtocolV3FromProtocolV1.
+	DescribeTable("NumOrStringProtocols V3FromV1",
+		func(input, expected numorstring.Protocol) {
+			p, err := numorstring.ProtocolV3FromProtocolV1(input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p).To(Equal(expected), "expected converted protocol to match")
+		},
+		Entry("protocol tcp -> TCP", numorstring.ProtocolFromStringV1("tcp"), numorstring.ProtocolFromString("TCP")),
+		Entry("protocol sctp -> SCTP", numorstring.ProtocolFromStringV1("sctp"), numorstring.ProtocolFromString("SCTP")),
+		Entry("protocol 132 -> 132", numorstring.ProtocolFromInt(132), numorstring.ProtocolFromInt(132)),
+		Entry("protocol \"132\" -> 132", numorstring.ProtocolFromStringV1("132"), numorstring.ProtocolFromInt(132)),
+		Entry("protocol \"255\" -> 255", numorstring.ProtocolFromStringV1("255"), numorstring.ProtocolFromInt(255)),
+		Entry("unknown protocol xxx", numorstring.ProtocolFromStringV1("xxx"), numorstring.ProtocolFromStringV1("xxx")),
+	)
+
+	DescribeTable("NumOrStringProtocols V3FromV1 rejects out of range protocol numbers",
+		func(input string) {
+			_, err := numorstring.ProtocolV3FromProtocolV1(numorstring.ProtocolFromStringV1(input))
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("protocol 256", "256"),
+		Entry("protocol -1", "-1"),
+	)
+
+	// Perform tests of ASNumberFromString.
+	DescribeTable("NumOrStringASNumber FromString",
+		func(input string, expected numorstring.ASNumber, canonical string) {
+			a, err := numorstring.ASNumberFromString(input)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a).To(Equal(expected), "expected parsed AS number to match")
+			Expect(a.String()).To(Equal(canonical), "expected canonical AS number string to match")
+		},
+		Entry("2-byte AS number 64512", "64512", numorstring.ASNumber(64512), "64512"),
+		Entry("4-byte AS number 4200000000", "4200000000", numorstring.ASNumber(4200000000), "4200000000"),
+		Entry("asdot AS number 1.10", "1.10", numorstring.ASNumber(65546), "65546"),
+		Entry("asdot AS number 0.64512", "0.64512", numorstring.ASNumber(64512), "64512"),
+	)
+
+	DescribeTable("NumOrStringASNumber FromString rejects invalid AS numbers",
+		func(input, errMsg string) {
+			_, err := numorstring.ASNumberFromString(input)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(errMsg))
+		},
+		Entry("out of range AS number 4294967296", "4294967296", "AS Number 4294967296 is out of range 0-4294967295"),
+		Entry("out of range asdot AS number 65536.1", "65536.1", "invalid AS Number format (65536.1)"),
+		Entry("AS number with too many parts", "1.2.3", "invalid AS Number format (1.2.3)"),
+		Entry("non-numeric AS number", "foo", "invalid AS Number format (foo)"),
 	)
 }
 
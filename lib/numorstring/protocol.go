@@ -14,7 +14,11 @@
 
 package numorstring
 
-import "strings"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 const (
 	ProtocolUDP     = "UDP"
@@ -49,21 +53,30 @@ func ProtocolFromInt(p uint8) Protocol {
 }
 
 // ProtocolV3FromProtocolV1 creates a v3 Protocol from a v1 Protocol,
-// while handling case conversion.
-func ProtocolV3FromProtocolV1(p Protocol) Protocol {
+// while handling case conversion.  A protocol number stored as a string is
+// converted to a numeric Protocol, and an error is returned if it is outside
+// the range 0-255.
+func ProtocolV3FromProtocolV1(p Protocol) (Protocol, error) {
 	if p.Type == NumOrStringNum {
-		return p
+		return p, nil
+	}
+
+	if num, err := strconv.Atoi(p.StrVal); err == nil {
+		if num < 0 || num > 255 {
+			return p, fmt.Errorf("protocol %d is out of range 0-255", num)
+		}
+		return ProtocolFromInt(uint8(num)), nil
 	}
 
 	for _, n := range allProtocolNames {
 		if strings.ToLower(n) == strings.ToLower(p.StrVal) {
 			return Protocol(
 				Uint8OrString{Type: NumOrStringString, StrVal: n},
-			)
+			), nil
 		}
 	}
 
-	return p
+	return p, nil
 }
 
 // ProtocolFromString creates a Protocol struct from a string value.
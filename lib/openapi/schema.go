@@ -0,0 +1,249 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/numorstring"
+)
+
+// Schema is an OpenAPI v3 schema object.  Only the subset of the schema keywords needed to
+// describe the Calico resources is supported.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+// V3Schemas returns the schemas of the Calico v3 resources, keyed by the resource kind.
+func V3Schemas() map[string]*Schema {
+	return map[string]*Schema{
+		apiv3.KindBGPConfiguration:    SchemaFor(apiv3.BGPConfiguration{}),
+		apiv3.KindBGPPeer:             SchemaFor(apiv3.BGPPeer{}),
+		apiv3.KindClusterInformation:  SchemaFor(apiv3.ClusterInformation{}),
+		apiv3.KindFelixConfiguration:  SchemaFor(apiv3.FelixConfiguration{}),
+		apiv3.KindGlobalNetworkPolicy: SchemaFor(apiv3.GlobalNetworkPolicy{}),
+		apiv3.KindGlobalNetworkSet:    SchemaFor(apiv3.GlobalNetworkSet{}),
+		apiv3.KindHostEndpoint:        SchemaFor(apiv3.HostEndpoint{}),
+		apiv3.KindIPPool:              SchemaFor(apiv3.IPPool{}),
+		apiv3.KindNetworkPolicy:       SchemaFor(apiv3.NetworkPolicy{}),
+		apiv3.KindNode:                SchemaFor(apiv3.Node{}),
+		apiv3.KindProfile:             SchemaFor(apiv3.Profile{}),
+		apiv3.KindWorkloadEndpoint:    SchemaFor(apiv3.WorkloadEndpoint{}),
+	}
+}
+
+// SchemaFor returns the schema of the JSON representation of the supplied value, derived
+// from the Go type and its json struct tags.  A field is required if it is not omitempty and
+// its validate struct tag rejects the empty value.
+func SchemaFor(v interface{}) *Schema {
+	g := generator{inProgress: map[reflect.Type]bool{}}
+	return g.schemaForType(reflect.TypeOf(v))
+}
+
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// emptyValueValidators are the field validators that accept the empty value, so do not make
+// a field required.  An empty selector selects all endpoints.
+var emptyValueValidators = map[string]bool{
+	"selector": true,
+}
+
+// customTypes are the types that have a custom JSON representation, mapped to a function
+// returning the schema of that representation.
+var customTypes = map[reflect.Type]func() *Schema{
+	reflect.TypeOf(numorstring.Port{}): func() *Schema {
+		return intOrString(0, math.MaxUint16)
+	},
+	reflect.TypeOf(numorstring.Protocol{}): func() *Schema {
+		return intOrString(0, math.MaxUint8)
+	},
+	reflect.TypeOf(numorstring.Uint8OrString{}): func() *Schema {
+		return intOrString(0, math.MaxUint8)
+	},
+	reflect.TypeOf(numorstring.ASNumber(0)): func() *Schema {
+		return intOrString(0, math.MaxUint32)
+	},
+	reflect.TypeOf(metav1.Time{}): func() *Schema {
+		return &Schema{Type: "string", Format: "date-time", Nullable: true}
+	},
+	reflect.TypeOf(metav1.Duration{}): func() *Schema {
+		return &Schema{Type: "string"}
+	},
+}
+
+// intOrString returns the schema of a value that may be an integer in the given range, or a
+// string.
+func intOrString(min, max float64) *Schema {
+	return &Schema{
+		AnyOf: []*Schema{
+			{Type: "integer", Minimum: &min, Maximum: &max},
+			{Type: "string"},
+		},
+	}
+}
+
+// generator builds schemas from Go types, tracking the struct types being processed so that
+// recursive types do not recurse indefinitely.
+type generator struct {
+	inProgress map[reflect.Type]bool
+}
+
+func (g generator) schemaForType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if f, ok := customTypes[t]; ok {
+		return f()
+	}
+	if t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType) {
+		// The JSON representation of an unknown custom type could be anything.
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Int8:
+		return integer("int32", math.MinInt8, math.MaxInt8)
+	case reflect.Int16:
+		return integer("int32", math.MinInt16, math.MaxInt16)
+	case reflect.Int32:
+		return integer("int32", math.MinInt32, math.MaxInt32)
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8:
+		return integer("int32", 0, math.MaxUint8)
+	case reflect.Uint16:
+		return integer("int32", 0, math.MaxUint16)
+	case reflect.Uint32:
+		return integer("int64", 0, math.MaxUint32)
+	case reflect.Uint, reflect.Uint64:
+		return integer("int64", 0, math.MaxUint64)
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaForType(t.Elem())}
+	case reflect.Struct:
+		return g.schemaForStruct(t)
+	}
+	return &Schema{}
+}
+
+func integer(format string, min, max float64) *Schema {
+	return &Schema{Type: "integer", Format: format, Minimum: &min, Maximum: &max}
+}
+
+func (g generator) schemaForStruct(t reflect.Type) *Schema {
+	if g.inProgress[t] {
+		return &Schema{Type: "object"}
+	}
+	g.inProgress[t] = true
+	defer delete(g.inProgress, t)
+
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields adds the properties for the fields of the struct type to the schema, following
+// the field naming and embedding rules of encoding/json.
+func (g generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			// Unexported field.
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+		omitEmpty := false
+		for _, opt := range parts[1:] {
+			if opt == "omitempty" {
+				omitEmpty = true
+			}
+		}
+
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			// Embedded structs without a name have their fields promoted.
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		p := g.schemaForType(f.Type)
+		if !omitEmpty {
+			if isRequired(f) {
+				s.Required = append(s.Required, name)
+			}
+			switch f.Type.Kind() {
+			case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+				// A nil value is written as null.
+				p.Nullable = true
+			}
+		}
+		s.Properties[name] = p
+	}
+}
+
+// isRequired returns true if the validate struct tag of the field rejects the empty value.
+// Fields that are not validated, or are only validated when set (omitempty), are optional.
+func isRequired(f reflect.StructField) bool {
+	tag := f.Tag.Get("validate")
+	if tag == "" {
+		return false
+	}
+	for _, v := range strings.Split(tag, ",") {
+		if v == "omitempty" || emptyValueValidators[v] {
+			return false
+		}
+	}
+	return true
+}
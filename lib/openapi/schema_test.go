@@ -0,0 +1,157 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/numorstring"
+	"github.com/projectcalico/libcalico-go/lib/openapi"
+)
+
+var _ = Describe("OpenAPI schema generation", func() {
+	schemas := openapi.V3Schemas()
+
+	// validate marshals the resource and validates it against the schema for the kind.
+	validate := func(kind string, res interface{}) error {
+		data, err := json.Marshal(res)
+		Expect(err).NotTo(HaveOccurred())
+		return schemas[kind].ValidateJSON(data)
+	}
+
+	tcp := numorstring.ProtocolFromString("TCP")
+	sctp := numorstring.ProtocolFromInt(132)
+	icmpType := 8
+	order := 100.5
+	port, err := numorstring.PortFromRange(80, 90)
+	if err != nil {
+		panic(err)
+	}
+
+	policy := apiv3.NewGlobalNetworkPolicy()
+	policy.ObjectMeta = metav1.ObjectMeta{
+		Name:              "policy-1",
+		Labels:            map[string]string{"a": "b"},
+		CreationTimestamp: metav1.Now(),
+	}
+	policy.Spec = apiv3.GlobalNetworkPolicySpec{
+		Order:    &order,
+		Selector: "thing == 'value'",
+		Types:    []apiv3.PolicyType{apiv3.PolicyTypeIngress, apiv3.PolicyTypeEgress},
+		Ingress: []apiv3.Rule{
+			{
+				Action:   apiv3.Allow,
+				Protocol: &tcp,
+				Source: apiv3.EntityRule{
+					Nets:     []string{"10.0.0.0/8"},
+					Selector: "all()",
+				},
+				Destination: apiv3.EntityRule{
+					Ports: []numorstring.Port{numorstring.SinglePort(443), port, numorstring.NamedPort("http")},
+				},
+			},
+			{
+				Action:   apiv3.Deny,
+				Protocol: &sctp,
+			},
+		},
+		Egress: []apiv3.Rule{
+			{
+				Action:   apiv3.Allow,
+				Protocol: &tcp,
+				ICMP:     &apiv3.ICMPFields{Type: &icmpType},
+			},
+		},
+	}
+
+	It("should validate the example policy", func() {
+		Expect(validate(apiv3.KindGlobalNetworkPolicy, policy)).NotTo(HaveOccurred())
+	})
+
+	It("should validate an example IPPool", func() {
+		pool := apiv3.NewIPPool()
+		pool.Name = "pool-1"
+		pool.Spec = apiv3.IPPoolSpec{CIDR: "10.0.0.0/16", IPIPMode: apiv3.IPIPModeAlways, NATOutgoing: true}
+		Expect(validate(apiv3.KindIPPool, pool)).NotTo(HaveOccurred())
+	})
+
+	It("should validate an example WorkloadEndpoint", func() {
+		wep := apiv3.NewWorkloadEndpoint()
+		wep.Namespace = "namespace-1"
+		wep.Name = "node--1-k8s-abcdef-eth0"
+		wep.Spec = apiv3.WorkloadEndpointSpec{
+			Node:          "node-1",
+			Orchestrator:  "k8s",
+			Pod:           "abcdef",
+			Endpoint:      "eth0",
+			InterfaceName: "cali09123",
+			IPNetworks:    []string{"10.0.0.1/32"},
+			IPNATs:        []apiv3.IPNAT{{InternalIP: "10.0.0.1", ExternalIP: "172.16.0.1"}},
+			Ports: []apiv3.EndpointPort{
+				{Name: "http", Protocol: numorstring.ProtocolFromString("TCP"), Port: 8080},
+			},
+		}
+		Expect(validate(apiv3.KindWorkloadEndpoint, wep)).NotTo(HaveOccurred())
+	})
+
+	It("should represent the numorstring types as an integer or a string", func() {
+		rule := openapi.SchemaFor(apiv3.Rule{})
+		protocol := rule.Properties["protocol"]
+		Expect(protocol.AnyOf).To(HaveLen(2))
+		Expect(protocol.AnyOf[0].Type).To(Equal("integer"))
+		Expect(*protocol.AnyOf[0].Maximum).To(Equal(255.0))
+		Expect(protocol.AnyOf[1].Type).To(Equal("string"))
+
+		ports := openapi.SchemaFor(apiv3.EntityRule{}).Properties["ports"]
+		Expect(ports.Type).To(Equal("array"))
+		Expect(ports.Items.AnyOf).To(HaveLen(2))
+		Expect(*ports.Items.AnyOf[0].Maximum).To(Equal(65535.0))
+	})
+
+	It("should require the fields whose validation rejects the empty value", func() {
+		Expect(openapi.SchemaFor(apiv3.Rule{}).Required).To(Equal([]string{"action"}))
+		Expect(openapi.SchemaFor(apiv3.IPPoolSpec{}).Required).To(Equal([]string{"cidr"}))
+		Expect(openapi.SchemaFor(apiv3.EndpointPort{}).Required).To(Equal([]string{"name", "port"}))
+	})
+
+	It("should not require the fields that may be empty", func() {
+		Expect(openapi.SchemaFor(apiv3.GlobalNetworkPolicySpec{}).Required).To(BeEmpty())
+		Expect(openapi.SchemaFor(apiv3.NetworkPolicySpec{}).Required).To(BeEmpty())
+		Expect(openapi.SchemaFor(apiv3.BGPPeerSpec{}).Required).To(BeEmpty())
+		Expect(schemas[apiv3.KindGlobalNetworkPolicy].ValidateJSON([]byte(`{"spec": {"order": 10}}`))).NotTo(HaveOccurred())
+		Expect(schemas[apiv3.KindBGPPeer].ValidateJSON([]byte(`{"spec": {"node": "node-1", "asNumber": 64512}}`))).NotTo(HaveOccurred())
+	})
+
+	It("should reject invalid documents", func() {
+		s := schemas[apiv3.KindGlobalNetworkPolicy]
+		Expect(s.ValidateJSON([]byte(`{"spec": {"selector": "all()", "ingress": [{"action": "Allow", "protocol": true}]}}`))).To(
+			MatchError("spec.ingress[0].protocol: does not match any of the allowed types"))
+		Expect(s.ValidateJSON([]byte(`{"spec": {"selector": "all()", "ingress": [{"action": "Allow", "protocol": 256}]}}`))).To(
+			MatchError("spec.ingress[0].protocol: does not match any of the allowed types"))
+		Expect(s.ValidateJSON([]byte(`{"spec": {"selector": "all()", "ingress": [{"protocol": "TCP"}]}}`))).To(
+			MatchError("spec.ingress[0].action: required field is missing"))
+		Expect(s.ValidateJSON([]byte(`{"spec": {"selector": "all()", "order": "first"}}`))).To(
+			MatchError("spec.order: must be a number"))
+		Expect(s.ValidateJSON([]byte(`{"spec": {"selector": "all()", "egress": [{"action": "Allow", "icmp": {"type": 1.5}}]}}`))).To(
+			MatchError("spec.egress[0].icmp.type: must be an integer"))
+		Expect(s.ValidateJSON([]byte(`[]`))).To(MatchError("(root): must be an object"))
+	})
+})
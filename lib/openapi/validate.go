@@ -0,0 +1,128 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// ValidateJSON checks that the JSON document conforms to the schema.
+func (s *Schema) ValidateJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return s.Validate(v)
+}
+
+// Validate checks that a value decoded from JSON conforms to the schema.  Returns an error
+// describing the first field that does not conform.
+func (s *Schema) Validate(v interface{}) error {
+	return s.validate("", v)
+}
+
+func (s *Schema) validate(path string, v interface{}) error {
+	if v == nil {
+		if s.Nullable || (s.Type == "" && len(s.AnyOf) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%s: must not be null", displayPath(path))
+	}
+
+	if len(s.AnyOf) > 0 {
+		for _, as := range s.AnyOf {
+			if as.validate(path, v) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: does not match any of the allowed types", displayPath(path))
+	}
+
+	switch s.Type {
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: must be an object", displayPath(path))
+		}
+		for _, r := range s.Required {
+			if _, ok := m[r]; !ok {
+				return fmt.Errorf("%s: required field is missing", joinPath(path, r))
+			}
+		}
+		for k, fv := range m {
+			fs := s.Properties[k]
+			if fs == nil {
+				fs = s.AdditionalProperties
+			}
+			if fs == nil {
+				continue
+			}
+			if err := fs.validate(joinPath(path, k), fv); err != nil {
+				return err
+			}
+		}
+	case "array":
+		a, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: must be an array", displayPath(path))
+		}
+		if s.Items != nil {
+			for i, iv := range a {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), iv); err != nil {
+					return err
+				}
+			}
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: must be a string", displayPath(path))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: must be a boolean", displayPath(path))
+		}
+	case "integer", "number":
+		n, ok := v.(float64)
+		if !ok {
+			return fmt.Errorf("%s: must be a number", displayPath(path))
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			return fmt.Errorf("%s: must be an integer", displayPath(path))
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Errorf("%s: must be at least %v", displayPath(path), *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fmt.Errorf("%s: must be at most %v", displayPath(path), *s.Maximum)
+		}
+	}
+	return nil
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
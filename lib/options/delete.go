@@ -16,9 +16,9 @@ package options
 
 // DeleteOptions is the standard options for deleting a resource through the Calico API.
 type DeleteOptions struct {
-	// When specified:
-	// - if unset, then the result is returned from remote storage based on quorum-read flag;
-	// - if set to non zero, then the result is at least as fresh as given rv.
+	// When specified, the resource is only deleted if its current resource version matches,
+	// otherwise an update conflict error is returned.  When unset, the resource is deleted
+	// regardless of its resource version.
 	// +optional
 	ResourceVersion string
 }
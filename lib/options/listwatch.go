@@ -27,13 +27,87 @@ type ListOptions struct {
 	// The resource version to List or Watch from.
 	// When specified for list:
 	// - if unset, then the result is returned from remote storage based on quorum-read flag;
-	// - if set to non zero, then the result is at least as fresh as given rv.
+	// - if set to non zero, then the result is at least as fresh as given rv.  For etcdv3
+	//   the result is the snapshot at the given rv.
+	// The ResourceVersion of the returned list may be used as the ResourceVersion of a
+	// subsequent Watch to receive exactly the events that occurred after the List.
 	// +optional
 	ResourceVersion string
 
+	// The maximum number of results to return in a single List.  If zero, all matching
+	// results are returned.  When a limit is specified, the returned list may contain
+	// fewer results than the limit even when there are further results available - the
+	// caller should continue listing until the returned continue token is empty.
+	// The snapshot semantics of a paginated List follow those of the backend datastore:
+	// for etcdv3 all pages are read at the revision of the first page, for KDD the
+	// Kubernetes API chunking semantics apply.  Ignored by Watch.
+	// +optional
+	Limit int64
+
+	// The continue token returned from a previous paginated List.  If blank, the List
+	// starts from the beginning.  Ignored by Watch.
+	// +optional
+	Continue string
+
 	// Whether the Name specified is a prefix rather than the full name.  This is fully supported
 	// for etcdv3, and is supported in a very limited fashion in KDD for WorkloadEndpoints only
 	// as a mechanism for enumerating endpoints within a Pod (since the name construction for a
 	// Workload endpoint is hierarchically constructed).
 	Prefix bool
+
+	// The order of the List results.  If blank, the results are returned in the order
+	// provided by the backend datastore.  Ties are broken by the namespace and then the
+	// name so that the order is deterministic.  When a Limit is specified each page of
+	// results is sorted independently.  Ignored by Watch.
+	// +optional
+	SortBy SortBy
+
+	// Whether the List fails if any of the matching entries in the datastore cannot be
+	// parsed.  By default such entries are skipped, so that one malformed entry does not
+	// prevent the remaining resources from being listed.  When set, the List instead returns
+	// an ErrorCollectionFailure containing the error for each entry that could not be parsed.
+	// Ignored by Watch.
+	// +optional
+	Strict bool
+}
+
+// SortBy is the order in which List results are returned.
+type SortBy string
+
+const (
+	// SortByName orders the results by name.
+	SortByName SortBy = "Name"
+
+	// SortByNode orders the results by node.  This is the Spec.Node of WorkloadEndpoints,
+	// HostEndpoints and BGPPeers, and the name of Nodes.  Resources that are not associated
+	// with a node are ordered as having a blank node.
+	SortByNode SortBy = "Node"
+
+	// SortByCreationTimestamp orders the results by creation timestamp, oldest first.
+	SortByCreationTimestamp SortBy = "CreationTimestamp"
+)
+
+// IPPoolListOptions is the query options for a filtered List of IPPools.  Each filter that
+// is set must match for a pool to be returned.  The filters are applied by the backend
+// datastore.
+type IPPoolListOptions struct {
+	ListOptions
+
+	// If set, only return pools whose Disabled field matches this value.
+	// +optional
+	Disabled *bool
+
+	// If set, only return pools with IPIP enabled (an IPIPMode other than Never) when
+	// true, or with IPIP disabled when false.
+	// +optional
+	IPIPEnabled *bool
+
+	// If non-zero, only return pools with a valid CIDR of this IP version.
+	// +optional
+	IPVersion int
+
+	// If true, only return the pools that IPAM may allocate from, that is the pools that
+	// are not disabled and have a valid CIDR.
+	// +optional
+	IPAMEligible bool
 }
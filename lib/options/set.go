@@ -22,4 +22,44 @@ type SetOptions struct {
 	// TTL for the datastore entry.
 	// +optional
 	TTL time.Duration
+
+	// Whether to verify that no other resource on the same node uses the interface name of
+	// the resource.  This is currently only used for WorkloadEndpoints.  It is off by default
+	// since it requires reading all of the WorkloadEndpoints on the node.
+	// +optional
+	ValidateInterfaceNameUnique bool
+
+	// Whether to verify that none of the IPNetworks of the resource are already claimed by
+	// a different resource in the same namespace.  This is currently only used for
+	// WorkloadEndpoints.  It is off by default since it requires reading all of the
+	// WorkloadEndpoints in the namespace.
+	// +optional
+	ValidateIPNetworksUnique bool
+
+	// Whether to verify that each of the IPNetworks of the resource is a host route, i.e. a
+	// /32 for IPv4 or a /128 for IPv6.  This is currently only used for WorkloadEndpoints.
+	// It is off by default for backwards compatibility with endpoints that use wider
+	// prefixes.
+	// +optional
+	ValidateIPNetworksHostRoutes bool
+
+	// Whether to verify that the IPNetworks of the resource contain at most one address of
+	// each IP family, i.e. at most one IPv4 and one IPv6 host route for a dual-stack
+	// endpoint.  This is only checked when ValidateIPNetworksHostRoutes is also set.  This is
+	// currently only used for WorkloadEndpoints.
+	// +optional
+	ValidateIPNetworksOnePerFamily bool
+
+	// Whether to verify that the IPv6Gateway of the resource, if set, is within one of the
+	// IPv6 IPNetworks of the resource, so that the gateway is on-link.  A link-local gateway
+	// is always on-link.  This is currently only used for WorkloadEndpoints.  It is off by
+	// default since the gateway is usually link-local and the IPNetworks host routes.
+	// +optional
+	ValidateIPv6GatewayOnLink bool
+
+	// Whether this is a dry run.  A dry run performs all of the defaulting, conversion
+	// and validation of a normal request and returns the resource that would be written,
+	// but does not write to the datastore.
+	// +optional
+	DryRun bool
 }
@@ -14,7 +14,11 @@
 
 package selector
 
-import "github.com/projectcalico/libcalico-go/lib/selector/parser"
+import (
+	"unicode"
+
+	"github.com/projectcalico/libcalico-go/lib/selector/parser"
+)
 
 // Selector represents a label selector.
 type Selector interface {
@@ -36,3 +40,55 @@ type Selector interface {
 func Parse(selector string) (sel Selector, err error) {
 	return parser.Parse(selector)
 }
+
+// Validate checks that a string representation of a selector expression is syntactically
+// valid, returning the parse error if it is not.
+func Validate(selector string) error {
+	_, err := parser.Parse(selector)
+	return err
+}
+
+// Depth returns the maximum parenthesis nesting depth of a string representation of a
+// selector expression.  Parentheses within quoted strings and those of function calls, such
+// as has(label), are ignored.  The selector is not validated.
+func Depth(selector string) int {
+	depth, max := 0, 0
+	var quote rune
+	var prev rune
+	// For each open parenthesis, whether it counts towards the depth.
+	var open []bool
+	for _, ch := range selector {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '(':
+			nested := !isIdentifierChar(prev)
+			open = append(open, nested)
+			if nested {
+				depth++
+				if depth > max {
+					max = depth
+				}
+			}
+		case ch == ')':
+			if len(open) > 0 {
+				if open[len(open)-1] {
+					depth--
+				}
+				open = open[:len(open)-1]
+			}
+		}
+		if !unicode.IsSpace(ch) {
+			prev = ch
+		}
+	}
+	return max
+}
+
+func isIdentifierChar(ch rune) bool {
+	return ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selector_test
+
+import (
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectcalico/libcalico-go/lib/selector"
+)
+
+var _ = DescribeTable("Selector validation",
+	func(sel string, valid bool) {
+		err := selector.Validate(sel)
+		if valid {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+	Entry("empty selector", "", true),
+	Entry("all()", "all()", true),
+	Entry("equality", "a == 'b'", true),
+	Entry("compound", "a == 'b' && has(c) || d in {'e', 'f'}", true),
+	Entry("unterminated string", "a == 'b", false),
+	Entry("missing operand", "a ==", false),
+	Entry("unbalanced parentheses", "(a == 'b'", false),
+)
+
+var _ = DescribeTable("Selector evaluation",
+	func(sel string, labels map[string]string, expected bool) {
+		s, err := selector.Parse(sel)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Evaluate(labels)).To(Equal(expected))
+	},
+	Entry("matching equality", "a == 'b'", map[string]string{"a": "b"}, true),
+	Entry("non-matching equality", "a == 'b'", map[string]string{"a": "c"}, false),
+	Entry("has with label", "has(a)", map[string]string{"a": ""}, true),
+	Entry("has without label", "has(a)", map[string]string{"b": ""}, false),
+	Entry("in set", "a in {'b', 'c'}", map[string]string{"a": "c"}, true),
+	Entry("all() with no labels", "all()", map[string]string{}, true),
+)
+
+var _ = DescribeTable("Selector depth",
+	func(sel string, expected int) {
+		Expect(selector.Depth(sel)).To(Equal(expected))
+	},
+	Entry("empty selector", "", 0),
+	Entry("function calls are not nested", "all() || has(a)", 0),
+	Entry("single level", "(a == 'b' || has(c)) && d == 'e'", 1),
+	Entry("two levels", "(a == 'b' || (c == 'd'))", 2),
+	Entry("parentheses in strings are ignored", "a == '((' && b == \")\"", 0),
+	Entry("deeply nested", "((((a == 'b'))))", 4),
+)
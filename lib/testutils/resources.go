@@ -29,6 +29,8 @@ import (
 	"github.com/projectcalico/go-yaml-wrapper"
 	"github.com/projectcalico/libcalico-go/lib/apiconfig"
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	cerrors "github.com/projectcalico/libcalico-go/lib/errors"
 	"github.com/projectcalico/libcalico-go/lib/watch"
 	"k8s.io/apimachinery/pkg/conversion"
 )
@@ -49,6 +51,18 @@ func ExpectResource(res runtime.Object, kind, namespace, name string, spec inter
 	ExpectWithOffset(1, getSpec(res)).To(Equal(spec), optionalDescription...)
 }
 
+// ExpectResourceDoesNotExist is a test validation function that checks the supplied error
+// is an ErrorResourceDoesNotExist that identifies the resource by the supplied kind,
+// namespace and name.  This should be called within a Ginkgo test.
+func ExpectResourceDoesNotExist(err error, kind, namespace, name string, optionalDescription ...interface{}) {
+	ExpectWithOffset(1, err).To(BeAssignableToTypeOf(cerrors.ErrorResourceDoesNotExist{}), optionalDescription...)
+	ExpectWithOffset(1, err.(cerrors.ErrorResourceDoesNotExist).Identifier).To(Equal(model.ResourceKey{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+	}), optionalDescription...)
+}
+
 // TestResourceWatch is a test helper used to validate a set of events are received
 // from a watcher.  The caller creates a watch.Interface from the resource-specific
 // client and passes that to TestResourceWatch to create a TestResourceWatchInterface.
@@ -160,6 +160,36 @@ var bgpPeerTable = []struct {
 			},
 		},
 	},
+	{
+		description: "global scoped BGPPeer with 4-byte AS number",
+		v1API: &apiv1.BGPPeer{
+			Metadata: apiv1.BGPPeerMetadata{
+				Scope:  scope.Global,
+				PeerIP: *net.ParseIP("10.0.0.2"),
+			},
+			Spec: apiv1.BGPPeerSpec{
+				ASNumber: 4200000000,
+			},
+		},
+		v1KVP: &model.KVPair{
+			Key: model.GlobalBGPPeerKey{
+				PeerIP: *net.ParseIP("10.0.0.2"),
+			},
+			Value: &model.BGPPeer{
+				PeerIP: *net.ParseIP("10.0.0.2"),
+				ASNum:  4200000000,
+			},
+		},
+		v3API: apiv3.BGPPeer{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "10-0-0-2",
+			},
+			Spec: apiv3.BGPPeerSpec{
+				PeerIP:   "10.0.0.2",
+				ASNumber: 4200000000,
+			},
+		},
+	},
 }
 
 func TestCanConvertV1ToV3BGPPeer(t *testing.T) {
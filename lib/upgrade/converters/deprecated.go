@@ -0,0 +1,106 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+)
+
+// DeprecatedFieldUsage identifies a rule that uses a deprecated field.
+type DeprecatedFieldUsage struct {
+	// The v1 key of the policy or profile containing the rule.
+	Key model.Key
+
+	// The rule direction ("inbound" or "outbound") and the index of the rule.
+	Direction string
+	Index     int
+
+	// The deprecated fields used by the rule.
+	Fields []string
+}
+
+// DeprecatedFields accumulates the rules that use deprecated fields over a conversion run.
+// A single warning is logged for the first rule recorded, with subsequent rules only logged
+// at debug level, so that a large conversion does not spam the logs.  Rules may be recorded
+// concurrently by converters running in parallel.
+type DeprecatedFields struct {
+	Usages []DeprecatedFieldUsage
+
+	// Strict, if set, fails the conversion of a rule that uses a deprecated field rather
+	// than converting and recording it.
+	Strict bool
+
+	lock sync.Mutex
+}
+
+// RecordRules records the rules in the slice that use deprecated fields, or returns an error
+// for the first such rule in strict mode.  It is safe to call on a nil DeprecatedFields, in
+// which case nothing is recorded.
+func (d *DeprecatedFields) RecordRules(key model.Key, direction string, rules []model.Rule) error {
+	if d == nil {
+		return nil
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for i, r := range rules {
+		var fields []string
+		if r.SrcNet != nil {
+			fields = append(fields, "SrcNet")
+		}
+		if r.DstNet != nil {
+			fields = append(fields, "DstNet")
+		}
+		if r.NotSrcNet != nil {
+			fields = append(fields, "NotSrcNet")
+		}
+		if r.NotDstNet != nil {
+			fields = append(fields, "NotDstNet")
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if d.Strict {
+			return fmt.Errorf("invalid %s rule %d: deprecated fields %s are not allowed, use Nets and NotNets instead",
+				direction, i, strings.Join(fields, ", "))
+		}
+
+		logCxt := log.WithFields(log.Fields{
+			"Key":       key,
+			"Direction": direction,
+			"Index":     i,
+			"Fields":    fields,
+		})
+		if len(d.Usages) == 0 {
+			logCxt.Warn("Rule uses deprecated Net fields, these are converted to Nets. " +
+				"Further rules using deprecated fields are included in the conversion report")
+		} else {
+			logCxt.Debug("Rule uses deprecated Net fields")
+		}
+
+		d.Usages = append(d.Usages, DeprecatedFieldUsage{
+			Key:       key,
+			Direction: direction,
+			Index:     i,
+			Fields:    fields,
+		})
+	}
+	return nil
+}
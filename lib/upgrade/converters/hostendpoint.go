@@ -103,9 +103,13 @@ func (_ HostEndpoint) BackendV1ToAPIV3(d *model.KVPair) (Resource, error) {
 
 	var ports []apiv3.EndpointPort
 	for _, port := range bh.Ports {
+		protocol, err := numorstring.ProtocolV3FromProtocolV1(port.Protocol)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %s: %v", port.Name, err)
+		}
 		ports = append(ports, apiv3.EndpointPort{
 			Name:     port.Name,
-			Protocol: numorstring.ProtocolV3FromProtocolV1(port.Protocol),
+			Protocol: protocol,
 			Port:     port.Port,
 		})
 	}
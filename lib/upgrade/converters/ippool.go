@@ -16,6 +16,7 @@ package converters
 
 import (
 	"fmt"
+	"net"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -57,6 +58,7 @@ func (_ IPPool) APIV1ToBackendV1(rIn unversioned.Resource) (*model.KVPair, error
 			Masquerade:    p.Spec.NATOutgoing,
 			IPAM:          !p.Spec.Disabled,
 			Disabled:      p.Spec.Disabled,
+			ReservedCIDRs: p.Spec.ReservedCIDRs,
 		},
 	}
 
@@ -69,15 +71,29 @@ func (_ IPPool) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error) {
 	if !ok {
 		return nil, fmt.Errorf("value is not a valid IPPool resource Value")
 	}
+	key, ok := kvp.Key.(model.IPPoolKey)
+	if !ok {
+		return nil, fmt.Errorf("value is not a valid IPPool resource key")
+	}
+
+	// The v1 CIDR may have the host bits set, so mask it before deriving the name and the
+	// v3 CIDR.  The CIDR encoded in the key must identify the same network.
+	cidr := maskCIDR(pool.CIDR)
+	if keyCIDR := maskCIDR(key.CIDR); keyCIDR.String() != cidr.String() {
+		return nil, fmt.Errorf("IPPool CIDR %s does not match the CIDR %s of the IPPool key", pool.CIDR, key.CIDR)
+	}
 
 	ipp := apiv3.NewIPPool()
-	ipp.Name = cidrToName(pool.CIDR)
+	ipp.Name = cidrToName(cidr)
 	ipp.Spec = apiv3.IPPoolSpec{
-		CIDR:        pool.CIDR.String(),
+		CIDR:        cidr.String(),
 		IPIPMode:    convertIPIPMode(pool.IPIPMode, pool.IPIPInterface),
 		NATOutgoing: pool.Masquerade,
 		Disabled:    pool.Disabled,
 	}
+	for _, r := range pool.ReservedCIDRs {
+		ipp.Spec.ReservedCIDRs = append(ipp.Spec.ReservedCIDRs, maskCIDR(r).String())
+	}
 
 	return ipp, nil
 }
@@ -93,10 +109,17 @@ func convertIPIPMode(mode ipip.Mode, ipipInterface string) apiv3.IPIPMode {
 	return apiv3.IPIPModeAlways
 }
 
+// maskCIDR returns the CIDR with the host bits of the IP address cleared.
+func maskCIDR(cidr cnet.IPNet) cnet.IPNet {
+	return cnet.IPNet{IPNet: net.IPNet{IP: cidr.IP.Mask(cidr.Mask), Mask: cidr.Mask}}
+}
+
+// cidrToName converts the CIDR to a v3 resource name made up of the address, in the format
+// of convertIpToName, and the prefix length, for example 10-0-0-0-24.  IPv6 addresses are fully
+// expanded so that, for example, ::/0 does not produce a name with consecutive dashes.
 func cidrToName(cidr cnet.IPNet) string {
-	name := strings.Replace(cidr.String(), ".", "-", 3)
-	name = strings.Replace(name, ":", "-", 7)
-	name = strings.Replace(name, "/", "-", 1)
+	ones, _ := cidr.Mask.Size()
+	name := fmt.Sprintf("%s-%d", convertIpToName(cidr.IP), ones)
 
 	log.WithFields(log.Fields{
 		"Name":  name,
@@ -64,10 +64,10 @@ var poolTable = []struct {
 		},
 		v3API: apiv3.IPPool{
 			ObjectMeta: v1.ObjectMeta{
-				Name: "10-0-0-1-24",
+				Name: "10-0-0-0-24",
 			},
 			Spec: apiv3.IPPoolSpec{
-				CIDR:        "10.0.0.1/24",
+				CIDR:        "10.0.0.0/24",
 				IPIPMode:    apiv3.IPIPModeAlways,
 				NATOutgoing: false,
 				Disabled:    false,
@@ -104,7 +104,7 @@ var poolTable = []struct {
 		},
 		v3API: apiv3.IPPool{
 			ObjectMeta: v1.ObjectMeta{
-				Name: "2001---120",
+				Name: "2001-0000-0000-0000-0000-0000-0000-0000-120",
 			},
 			Spec: apiv3.IPPoolSpec{
 				CIDR:        "2001::/120",
@@ -144,10 +144,10 @@ var poolTable = []struct {
 		},
 		v3API: apiv3.IPPool{
 			ObjectMeta: v1.ObjectMeta{
-				Name: "5-5-5-5-25",
+				Name: "5-5-5-0-25",
 			},
 			Spec: apiv3.IPPoolSpec{
-				CIDR:        "5.5.5.5/25",
+				CIDR:        "5.5.5.0/25",
 				IPIPMode:    apiv3.IPIPModeNever,
 				NATOutgoing: true,
 				Disabled:    true,
@@ -181,10 +181,10 @@ var poolTable = []struct {
 		},
 		v3API: apiv3.IPPool{
 			ObjectMeta: v1.ObjectMeta{
-				Name: "6-6-6-6-26",
+				Name: "6-6-6-0-26",
 			},
 			Spec: apiv3.IPPoolSpec{
-				CIDR:        "6.6.6.6/26",
+				CIDR:        "6.6.6.0/26",
 				IPIPMode:    apiv3.IPIPModeNever,
 				NATOutgoing: true,
 				Disabled:    true,
@@ -221,10 +221,10 @@ var poolTable = []struct {
 		},
 		v3API: apiv3.IPPool{
 			ObjectMeta: v1.ObjectMeta{
-				Name: "1-1-1-1-11",
+				Name: "1-0-0-0-11",
 			},
 			Spec: apiv3.IPPoolSpec{
-				CIDR:        "1.1.1.1/11",
+				CIDR:        "1.0.0.0/11",
 				IPIPMode:    apiv3.IPIPModeCrossSubnet,
 				NATOutgoing: false,
 				Disabled:    true,
@@ -233,6 +233,22 @@ var poolTable = []struct {
 	},
 }
 
+func TestConvertV1ToV3IPPoolReservedCIDRs(t *testing.T) {
+	RegisterTestingT(t)
+
+	reserved := []cnet.IPNet{cnet.MustParseCIDR("10.0.0.1/32"), cnet.MustParseCIDR("10.0.0.17/28")}
+	kvp, err := IPPool{}.APIV1ToBackendV1(&apiv1.IPPool{
+		Metadata: apiv1.IPPoolMetadata{CIDR: cnet.MustParseCIDR("10.0.0.0/24")},
+		Spec:     apiv1.IPPoolSpec{ReservedCIDRs: reserved},
+	})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(kvp.Value.(*model.IPPool).ReservedCIDRs).To(Equal(reserved))
+
+	res, err := IPPool{}.BackendV1ToAPIV3(kvp)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(res.(*apiv3.IPPool).Spec.ReservedCIDRs).To(Equal([]string{"10.0.0.1/32", "10.0.0.16/28"}))
+}
+
 func TestCanConvertV1ToV3IPPool(t *testing.T) {
 
 	for _, entry := range poolTable {
@@ -255,3 +271,40 @@ func TestCanConvertV1ToV3IPPool(t *testing.T) {
 		})
 	}
 }
+
+func TestCIDRToName(t *testing.T) {
+	RegisterTestingT(t)
+
+	for cidr, name := range map[string]string{
+		"10.0.0.0/24":              "10-0-0-0-24",
+		"0.0.0.0/0":                "0-0-0-0-0",
+		"1.2.3.4/32":               "1-2-3-4-32",
+		"::/0":                     "0000-0000-0000-0000-0000-0000-0000-0000-0",
+		"fd00::/64":                "fd00-0000-0000-0000-0000-0000-0000-0000-64",
+		"fd00::1:0/112":            "fd00-0000-0000-0000-0000-0000-0001-0000-112",
+		"2001:db8:0:1:2:3:4:5/128": "2001-0db8-0000-0001-0002-0003-0004-0005-128",
+	} {
+		Expect(cidrToName(cnet.MustParseCIDR(cidr))).To(Equal(name), cidr)
+	}
+}
+
+func TestIPPoolKeyCIDRMismatch(t *testing.T) {
+	RegisterTestingT(t)
+
+	// A key CIDR with different host bits identifies the same network.
+	res, err := IPPool{}.BackendV1ToAPIV3(&model.KVPair{
+		Key:   model.IPPoolKey{CIDR: cnet.MustParseCIDR("10.0.0.0/24")},
+		Value: &model.IPPool{CIDR: cnet.MustParseCIDR("10.0.0.5/24")},
+	})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(res.(*apiv3.IPPool).Name).To(Equal("10-0-0-0-24"))
+	Expect(res.(*apiv3.IPPool).Spec.CIDR).To(Equal("10.0.0.0/24"))
+
+	// A key CIDR for a different network is rejected.
+	_, err = IPPool{}.BackendV1ToAPIV3(&model.KVPair{
+		Key:   model.IPPoolKey{CIDR: cnet.MustParseCIDR("10.0.1.0/24")},
+		Value: &model.IPPool{CIDR: cnet.MustParseCIDR("10.0.0.5/24")},
+	})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("IPPool CIDR 10.0.0.5/24 does not match the CIDR 10.0.1.0/24 of the IPPool key"))
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+)
+
+// AnnotationV1LogRules is the annotation used to record the rules of a policy or profile
+// whose Log action was converted to an Allow action.  The value is a comma separated list of
+// the rule direction and index, for example "inbound/0,outbound/2".
+const AnnotationV1LogRules = "projectcalico.org/v1-log-rules"
+
+// LogActionMode configures how rules with a Log action are converted.
+type LogActionMode string
+
+const (
+	// LogActionKeep converts rules with a Log action to v3 rules with a Log action.  This is
+	// the default.
+	LogActionKeep LogActionMode = ""
+
+	// LogActionDropRule removes rules with a Log action from the converted rules.
+	LogActionDropRule LogActionMode = "DropRule"
+
+	// LogActionConvertToAllow converts rules with a Log action to v3 rules with an Allow
+	// action, and records the converted rules in the AnnotationV1LogRules annotation of the
+	// converted resource.  Note that a Log rule does not terminate policy evaluation whereas
+	// an Allow rule does, so this changes the behavior of any subsequent rules.
+	LogActionConvertToAllow LogActionMode = "ConvertToAllow"
+)
+
+// convertRules applies the mode to the converted v3 rules of one direction ("inbound" or
+// "outbound"), returning the resulting rules.  The rules converted to Allow are appended to
+// converted, which is also returned.
+func (m LogActionMode) convertRules(rules []apiv3.Rule, direction string, converted []string) ([]apiv3.Rule, []string, error) {
+	switch m {
+	case LogActionKeep:
+		return rules, converted, nil
+	case LogActionDropRule:
+		kept := rules[:0]
+		for i, r := range rules {
+			if r.Action == apiv3.Log {
+				log.Debugf("Dropping %s rule %d with Log action", direction, i)
+				continue
+			}
+			kept = append(kept, r)
+		}
+		return kept, converted, nil
+	case LogActionConvertToAllow:
+		for i := range rules {
+			if rules[i].Action == apiv3.Log {
+				log.Debugf("Converting %s rule %d with Log action to Allow", direction, i)
+				rules[i].Action = apiv3.Allow
+				converted = append(converted, fmt.Sprintf("%s/%d", direction, i))
+			}
+		}
+		return rules, converted, nil
+	}
+	return nil, nil, fmt.Errorf("unknown Log action mode: '%s'", m)
+}
+
+// annotateLogRules returns the annotations with the AnnotationV1LogRules annotation set to
+// the converted rules.  The annotations are copied so that the v1 resource is not modified.
+// If no rules were converted the annotations are returned unchanged.
+func annotateLogRules(annotations map[string]string, converted []string) map[string]string {
+	if len(converted) == 0 {
+		return annotations
+	}
+	annotated := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		annotated[k] = v
+	}
+	annotated[AnnotationV1LogRules] = strings.Join(converted, ",")
+	return annotated
+}
@@ -23,6 +23,10 @@ import (
 	"strings"
 )
 
+// AnnotationV1Name is the annotation used to record the original v1 name of a resource
+// whose name was modified by the conversion to a valid v3 name.
+const AnnotationV1Name = "projectcalico.org/v1-name"
+
 var (
 	nonNameChar               = regexp.MustCompile("[^-.a-z0-9]+")
 	dotDashSeq                = regexp.MustCompile("[.-]*[.][.-]*")
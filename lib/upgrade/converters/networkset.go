@@ -0,0 +1,152 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+)
+
+// TagNetworkSets accumulates the tags referenced by the rules in v1 policies and profiles,
+// and the networks of the v1 endpoints that carry each tag (through their profiles).  It is
+// used to synthesize a v3 GlobalNetworkSet for each distinct tag, labeled with the tag, so
+// that the tag selectors created by the rule conversion also match the tag members' networks.
+type TagNetworkSets struct {
+	// The tags referenced in rules.
+	referencedTags map[string]bool
+
+	// The tags for each profile, keyed off profile name.
+	profileTags map[string][]string
+
+	// The profiles and networks for each endpoint.
+	endpoints []tagEndpoint
+}
+
+// TagGlobalNetworkSet is a GlobalNetworkSet synthesized for a tag.
+type TagGlobalNetworkSet struct {
+	Tag              string
+	GlobalNetworkSet *apiv3.GlobalNetworkSet
+}
+
+type tagEndpoint struct {
+	profileIDs []string
+	nets       []string
+}
+
+// NewTagNetworkSets returns an empty TagNetworkSets.
+func NewTagNetworkSets() *TagNetworkSets {
+	return &TagNetworkSets{
+		referencedTags: map[string]bool{},
+		profileTags:    map[string][]string{},
+	}
+}
+
+// Add records the tags and networks from a v1 KVPair.  Policies, profiles, workload endpoints
+// and host endpoints are processed, all other KVPairs are ignored.
+func (t *TagNetworkSets) Add(kvp *model.KVPair) {
+	switch v := kvp.Value.(type) {
+	case *model.Policy:
+		t.addRules(v.InboundRules)
+		t.addRules(v.OutboundRules)
+	case *model.Profile:
+		t.addRules(v.Rules.InboundRules)
+		t.addRules(v.Rules.OutboundRules)
+		if pk, ok := kvp.Key.(model.ProfileKey); ok {
+			t.profileTags[pk.Name] = v.Tags
+		}
+	case *model.WorkloadEndpoint:
+		ep := tagEndpoint{profileIDs: v.ProfileIDs}
+		for _, n := range v.IPv4Nets {
+			ep.nets = append(ep.nets, n.Network().String())
+		}
+		for _, n := range v.IPv6Nets {
+			ep.nets = append(ep.nets, n.Network().String())
+		}
+		t.endpoints = append(t.endpoints, ep)
+	case *model.HostEndpoint:
+		ep := tagEndpoint{profileIDs: v.ProfileIDs}
+		for _, ip := range v.ExpectedIPv4Addrs {
+			ep.nets = append(ep.nets, ip.Network().String())
+		}
+		for _, ip := range v.ExpectedIPv6Addrs {
+			ep.nets = append(ep.nets, ip.Network().String())
+		}
+		t.endpoints = append(t.endpoints, ep)
+	}
+}
+
+func (t *TagNetworkSets) addRules(rules []model.Rule) {
+	for _, r := range rules {
+		for _, tag := range []string{r.SrcTag, r.DstTag, r.NotSrcTag, r.NotDstTag} {
+			if tag != "" {
+				t.referencedTags[tag] = true
+			}
+		}
+	}
+}
+
+// GlobalNetworkSets returns a GlobalNetworkSet for each distinct tag referenced in the rules
+// that have been added, sorted by tag.  Each network set is labeled with the tag as the key
+// and an empty value, and contains the networks of the endpoints whose profiles carry the
+// tag.  Networks are only included for profiles and endpoints that have been added.
+func (t *TagNetworkSets) GlobalNetworkSets() []TagGlobalNetworkSet {
+	// Determine the networks for each referenced tag.
+	tagNets := map[string]map[string]bool{}
+	for tag := range t.referencedTags {
+		tagNets[tag] = map[string]bool{}
+	}
+	for _, ep := range t.endpoints {
+		for _, profileID := range ep.profileIDs {
+			for _, tag := range t.profileTags[profileID] {
+				nets, ok := tagNets[tag]
+				if !ok {
+					continue
+				}
+				for _, n := range ep.nets {
+					nets[n] = true
+				}
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(tagNets))
+	for tag := range tagNets {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	sets := make([]TagGlobalNetworkSet, 0, len(tags))
+	for _, tag := range tags {
+		gns := apiv3.NewGlobalNetworkSet()
+		gns.Name = convertNameNoDots("tag-" + tag)
+		gns.Labels = map[string]string{tag: ""}
+		for n := range tagNets[tag] {
+			gns.Spec.Nets = append(gns.Spec.Nets, n)
+		}
+		sort.Strings(gns.Spec.Nets)
+
+		log.WithFields(log.Fields{
+			"Tag":  tag,
+			"Name": gns.Name,
+			"Nets": gns.Spec.Nets,
+		}).Debug("Synthesized GlobalNetworkSet from tag")
+		sets = append(sets, TagGlobalNetworkSet{Tag: tag, GlobalNetworkSet: gns})
+	}
+	return sets
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/net"
+)
+
+func TestTagNetworkSets(t *testing.T) {
+	RegisterTestingT(t)
+
+	tns := NewTagNetworkSets()
+
+	// Two policies referencing the same tag.
+	tns.Add(&model.KVPair{
+		Key: model.PolicyKey{Name: "policy1"},
+		Value: &model.Policy{
+			InboundRules: []model.Rule{{Action: "allow", SrcTag: "tag1"}},
+		},
+	})
+	tns.Add(&model.KVPair{
+		Key: model.PolicyKey{Name: "policy2"},
+		Value: &model.Policy{
+			OutboundRules: []model.Rule{{Action: "allow", DstTag: "tag1"}},
+		},
+	})
+
+	// A profile carrying the tag, and endpoints using the profile.
+	tns.Add(&model.KVPair{
+		Key: model.ProfileKey{Name: "profile1"},
+		Value: &model.Profile{
+			Tags: []string{"tag1", "unreferenced"},
+		},
+	})
+	tns.Add(&model.KVPair{
+		Key: model.WorkloadEndpointKey{Hostname: "host1", OrchestratorID: "k8s", WorkloadID: "wl1", EndpointID: "eth0"},
+		Value: &model.WorkloadEndpoint{
+			ProfileIDs: []string{"profile1"},
+			IPv4Nets:   []net.IPNet{net.MustParseNetwork("10.0.0.1/32")},
+		},
+	})
+	tns.Add(&model.KVPair{
+		Key: model.HostEndpointKey{Hostname: "host1", EndpointID: "eth0"},
+		Value: &model.HostEndpoint{
+			ProfileIDs:        []string{"profile1"},
+			ExpectedIPv4Addrs: []net.IP{net.MustParseIP("192.168.0.1")},
+		},
+	})
+	tns.Add(&model.KVPair{
+		Key: model.WorkloadEndpointKey{Hostname: "host1", OrchestratorID: "k8s", WorkloadID: "wl2", EndpointID: "eth0"},
+		Value: &model.WorkloadEndpoint{
+			ProfileIDs: []string{"profile2"},
+			IPv4Nets:   []net.IPNet{net.MustParseNetwork("10.0.0.2/32")},
+		},
+	})
+
+	sets := tns.GlobalNetworkSets()
+	Expect(sets).To(HaveLen(1))
+	Expect(sets[0].Tag).To(Equal("tag1"))
+	Expect(sets[0].GlobalNetworkSet.Name).To(Equal("tag-tag1"))
+	Expect(sets[0].GlobalNetworkSet.Labels).To(Equal(map[string]string{"tag1": ""}))
+	Expect(sets[0].GlobalNetworkSet.Spec.Nets).To(Equal([]string{"10.0.0.1/32", "192.168.0.1/32"}))
+}
@@ -29,6 +29,7 @@ import (
 )
 
 var asn, _ = numorstring.ASNumberFromString("1")
+var asnDotted, _ = numorstring.ASNumberFromString("65000.100")
 var ipv4String = "192.168.1.1/24"
 var ipv4IPNet = cnet.MustParseCIDR(ipv4String)
 var ipv4IPNetMask = ipv4IPNet.Network()
@@ -230,6 +231,73 @@ var nodeTable = []struct {
 			},
 		},
 	},
+	{
+		description: "Conversion with full BGP config, a 4-byte AS number and OrchRefs",
+		v1API: &apiv1.Node{
+			Metadata: apiv1.NodeMetadata{
+				Name: "my-node",
+			},
+			Spec: apiv1.NodeSpec{
+				BGP: &apiv1.NodeBGPSpec{
+					ASNumber:    &asnDotted,
+					IPv4Address: &ipv4IPNet,
+					IPv6Address: &ipv6IPNet,
+				},
+				OrchRefs: []apiv1.OrchRef{
+					{Orchestrator: "k8s", NodeName: "k8sNodeName"},
+				},
+			},
+		},
+		v1KVP: &model.KVPair{
+			Key: model.NodeKey{
+				Hostname: "my-node",
+			},
+			Value: &model.Node{
+				BGPASNumber: &asnDotted,
+				BGPIPv4Addr: &ipv4IP,
+				BGPIPv4Net:  ipv4IPNetMask,
+				BGPIPv6Addr: &ipv6IP,
+				BGPIPv6Net:  ipv6IPNetMask,
+				OrchRefs: []model.OrchRef{
+					{Orchestrator: "k8s", NodeName: "k8sNodeName"},
+				},
+			},
+		},
+		v3API: apiv3.Node{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "my-node",
+			},
+			Spec: apiv3.NodeSpec{
+				BGP: &apiv3.NodeBGPSpec{
+					ASNumber:    &asnDotted,
+					IPv4Address: ipv4String,
+					IPv6Address: ipv6String,
+				},
+				OrchRefs: []apiv3.OrchRef{
+					{Orchestrator: "k8s", NodeName: "k8sNodeName"},
+				},
+			},
+		},
+	},
+	{
+		description: "Conversion with no BGP config",
+		v1API: &apiv1.Node{
+			Metadata: apiv1.NodeMetadata{
+				Name: "my-node",
+			},
+		},
+		v1KVP: &model.KVPair{
+			Key: model.NodeKey{
+				Hostname: "my-node",
+			},
+			Value: &model.Node{},
+		},
+		v3API: apiv3.Node{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "my-node",
+			},
+		},
+	},
 }
 
 func TestCanConvertV1ToV3Node(t *testing.T) {
@@ -250,6 +318,13 @@ func TestCanConvertV1ToV3Node(t *testing.T) {
 			Expect(err).NotTo(HaveOccurred(), tdata.description)
 			Expect(convertedv3.(*apiv3.Node).ObjectMeta).To(Equal(tdata.v3API.ObjectMeta), tdata.description)
 			Expect(convertedv3.(*apiv3.Node).Spec).To(Equal(tdata.v3API.Spec), tdata.description)
+
+			// Check the AS number round-trips through its string form.
+			if bgp := convertedv3.(*apiv3.Node).Spec.BGP; bgp != nil && bgp.ASNumber != nil {
+				rt, err := numorstring.ASNumberFromString(bgp.ASNumber.String())
+				Expect(err).NotTo(HaveOccurred(), tdata.description)
+				Expect(rt).To(Equal(*bgp.ASNumber), tdata.description)
+			}
 		})
 	}
 }
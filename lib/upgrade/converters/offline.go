@@ -0,0 +1,146 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	yaml "github.com/projectcalico/go-yaml-wrapper"
+
+	"github.com/projectcalico/libcalico-go/lib/apis/v1/unversioned"
+	validatorv3 "github.com/projectcalico/libcalico-go/lib/validator/v3"
+)
+
+// OfflineReport is the report of an offline conversion of v1 API resources.
+type OfflineReport struct {
+	// The number of v1 resources that were converted.
+	Converted int `json:"converted"`
+
+	// The number of GlobalNetworkSets synthesized for the tags referenced in rules.
+	TagNetworkSets int `json:"tagNetworkSets"`
+
+	// The v1 resources that could not be converted, or whose converted resource is not valid.
+	Errors []OfflineError `json:"errors,omitempty"`
+
+	// The rules that use deprecated fields.
+	DeprecatedFields []DeprecatedFieldUsage `json:"deprecatedFields,omitempty"`
+}
+
+// OfflineError describes a v1 resource that could not be converted.
+type OfflineError struct {
+	// The index of the resource in the input.
+	Index int `json:"index"`
+
+	// The v1 kind of the resource, and its identifiers if it could be parsed.
+	Kind     string `json:"kind"`
+	Resource string `json:"resource,omitempty"`
+
+	Error string `json:"error"`
+}
+
+// ConvertV1Resources converts v1 API resources to v3 without accessing a datastore, for example
+// to test the conversion of a datastore dump offline.  The input is a YAML or JSON document
+// containing either a single v1 resource or a list of v1 resources, in the format used by
+// calicoctl v1.  Each resource is converted to its v1 backend representation and then to v3 in
+// the same way as the migration, and GlobalNetworkSets are synthesized for the tags referenced
+// in rules.
+//
+// Returns the converted v3 resources as a YAML list, in the order of the input followed by the
+// synthesized GlobalNetworkSets, and the OfflineReport as YAML.  Resources that cannot be
+// converted are recorded in the report rather than failing the conversion, so an error is only
+// returned if the input cannot be parsed.
+func ConvertV1Resources(data []byte) ([]byte, []byte, error) {
+	j, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var docs []json.RawMessage
+	if j = bytes.TrimSpace(j); len(j) > 0 && j[0] == '[' {
+		if err := json.Unmarshal(j, &docs); err != nil {
+			return nil, nil, err
+		}
+	} else if len(j) > 0 && !bytes.Equal(j, []byte("null")) {
+		docs = []json.RawMessage{j}
+	}
+
+	report := OfflineReport{}
+	deprecated := &DeprecatedFields{}
+	tags := NewTagNetworkSets()
+	resources := []Resource{}
+	for i, doc := range docs {
+		var tm unversioned.TypeMetadata
+		if err := json.Unmarshal(doc, &tm); err != nil {
+			report.Errors = append(report.Errors, OfflineError{Index: i, Error: err.Error()})
+			continue
+		}
+		rv1, converter, ok := newOfflineResource(tm.Kind, deprecated)
+		if !ok {
+			report.Errors = append(report.Errors, OfflineError{
+				Index: i, Kind: tm.Kind, Error: fmt.Sprintf("unsupported kind '%s'", tm.Kind),
+			})
+			continue
+		}
+		if err := json.Unmarshal(doc, rv1); err != nil {
+			report.Errors = append(report.Errors, OfflineError{Index: i, Kind: tm.Kind, Error: err.Error()})
+			continue
+		}
+
+		r, err := convertV1Resource(rv1, converter, tags)
+		if err != nil {
+			report.Errors = append(report.Errors, OfflineError{
+				Index: i, Kind: tm.Kind, Resource: rv1.String(), Error: err.Error(),
+			})
+			continue
+		}
+		resources = append(resources, r)
+		report.Converted++
+	}
+
+	for _, tgns := range tags.GlobalNetworkSets() {
+		resources = append(resources, tgns.GlobalNetworkSet)
+		report.TagNetworkSets++
+	}
+	report.DeprecatedFields = deprecated.Usages
+
+	out, err := yaml.Marshal(resources)
+	if err != nil {
+		return nil, nil, err
+	}
+	reportOut, err := yaml.Marshal(report)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, reportOut, nil
+}
+
+// convertV1Resource converts a v1 API resource to v3 through the v1 backend representation,
+// recording its tags, and validates the converted resource.
+func convertV1Resource(rv1 unversioned.Resource, converter Converter, tags *TagNetworkSets) (Resource, error) {
+	kvp, err := converter.APIV1ToBackendV1(rv1)
+	if err != nil {
+		return nil, err
+	}
+	tags.Add(kvp)
+	r, err := converter.BackendV1ToAPIV3(kvp)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatorv3.Validate(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
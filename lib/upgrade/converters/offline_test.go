@@ -0,0 +1,82 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"io/ioutil"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	yaml "github.com/projectcalico/go-yaml-wrapper"
+)
+
+func TestConvertV1Resources(t *testing.T) {
+	RegisterTestingT(t)
+
+	data, err := ioutil.ReadFile("testdata/v1-resources.yaml")
+	Expect(err).NotTo(HaveOccurred())
+
+	out, reportOut, err := ConvertV1Resources(data)
+	Expect(err).NotTo(HaveOccurred())
+
+	// The converted resources are in the input order, followed by the synthesized network set.
+	var resources []struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	Expect(yaml.Unmarshal(out, &resources)).NotTo(HaveOccurred())
+	var ids []string
+	for _, r := range resources {
+		ids = append(ids, r.Kind+"/"+r.Metadata.Name)
+	}
+	Expect(ids).To(Equal([]string{
+		"GlobalNetworkPolicy/allow-tcp-6379",
+		"Profile/frontend",
+		"IPPool/192-168-0-0-16",
+		"HostEndpoint/host1.eth0",
+		"GlobalNetworkSet/tag-frontend",
+	}))
+
+	var report OfflineReport
+	Expect(yaml.Unmarshal(reportOut, &report)).NotTo(HaveOccurred())
+	Expect(report.Converted).To(Equal(4))
+	Expect(report.TagNetworkSets).To(Equal(1))
+	Expect(report.Errors).To(HaveLen(2))
+	Expect(report.Errors[0]).To(Equal(OfflineError{Index: 4, Kind: "tier", Error: "unsupported kind 'tier'"}))
+	Expect(report.Errors[1].Index).To(Equal(5))
+	Expect(report.Errors[1].Resource).To(Equal("Policy(Name=bad-action)"))
+	Expect(report.Errors[1].Error).To(ContainSubstring("unknown action 'foo'"))
+
+	// The conversion is deterministic, so the output can be compared against a golden file.
+	out2, reportOut2, err := ConvertV1Resources(data)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(out2).To(Equal(out))
+	Expect(reportOut2).To(Equal(reportOut))
+}
+
+func TestConvertV1ResourcesSingleResource(t *testing.T) {
+	RegisterTestingT(t)
+
+	out, _, err := ConvertV1Resources([]byte(`{"apiVersion": "v1", "kind": "profile", "metadata": {"name": "p1"}}`))
+	Expect(err).NotTo(HaveOccurred())
+	var resources []map[string]interface{}
+	Expect(yaml.Unmarshal(out, &resources)).NotTo(HaveOccurred())
+	Expect(resources).To(HaveLen(1))
+
+	_, _, err = ConvertV1Resources([]byte("- kind: [unterminated"))
+	Expect(err).To(HaveOccurred())
+}
@@ -16,6 +16,7 @@ package converters
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -24,15 +25,42 @@ import (
 	"github.com/projectcalico/libcalico-go/lib/apis/v1/unversioned"
 	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	validatorv3 "github.com/projectcalico/libcalico-go/lib/validator/v3"
 )
 
 // Policy implements the Converter interface.
-type Policy struct{}
+type Policy struct {
+	// DisableApplyOnForwardInference disables setting ApplyOnForward to true for DoNotTrack
+	// and PreDNAT policies.  By default ApplyOnForward is inferred for these policies since
+	// they may have been created before the ApplyOnForward field was available.  When set,
+	// ApplyOnForward is converted exactly as stored.
+	DisableApplyOnForwardInference bool
+
+	// Deprecated, if set, records the rules that use deprecated fields.
+	Deprecated *DeprecatedFields
+
+	// AnnotateOriginalName records the v1 name of a policy in the AnnotationV1Name annotation
+	// of the converted policy if the name was modified by the conversion.
+	AnnotateOriginalName bool
+
+	// LogActions configures how rules with a Log action are converted.  By default they
+	// are converted to rules with a Log action.
+	LogActions LogActionMode
+
+	// StrictTypes rejects a policy that has rules for a direction not included in its Types.
+	// By default such policies are converted unchanged, and the rules for the other
+	// direction continue to be ignored.
+	StrictTypes bool
+}
 
 // APIV1ToBackendV1 converts v1 Policy API to v1 Policy KVPair.
-func (_ Policy) APIV1ToBackendV1(a unversioned.Resource) (*model.KVPair, error) {
+func (p Policy) APIV1ToBackendV1(a unversioned.Resource) (*model.KVPair, error) {
 	ap := a.(*apiv1.Policy)
 
+	if err := validateOrder(ap.Spec.Order); err != nil {
+		return nil, err
+	}
+
 	d := model.KVPair{
 		Key: model.PolicyKey{
 			Name: ap.Metadata.Name,
@@ -49,7 +77,7 @@ func (_ Policy) APIV1ToBackendV1(a unversioned.Resource) (*model.KVPair, error)
 		},
 	}
 
-	if ap.Spec.DoNotTrack || ap.Spec.PreDNAT {
+	if !p.DisableApplyOnForwardInference && (ap.Spec.DoNotTrack || ap.Spec.PreDNAT) {
 		// This case happens when there is a pre-existing policy in the datastore, from before
 		// the ApplyOnForward feature was available. DoNotTrack or PreDNAT policy applies to
 		// forward traffic by nature. So in this case we return ApplyOnForward flag as true.
@@ -90,7 +118,7 @@ func (_ Policy) APIV1ToBackendV1(a unversioned.Resource) (*model.KVPair, error)
 }
 
 // BackendV1ToAPIV3 converts v1 Policy KVPair to v3 API.
-func (_ Policy) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error) {
+func (p Policy) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error) {
 	bp, ok := kvp.Value.(*model.Policy)
 	if !ok {
 		return nil, fmt.Errorf("value is not a valid Policy resource")
@@ -99,20 +127,51 @@ func (_ Policy) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error) {
 	if !ok {
 		return nil, fmt.Errorf("value is not a valid Policy resource key")
 	}
+	if err := validateOrder(bp.Order); err != nil {
+		return nil, err
+	}
 
 	ap := apiv3.NewGlobalNetworkPolicy()
 	ap.Name = convertNameNoDots(bk.Name)
 	ap.Annotations = bp.Annotations
+	if p.AnnotateOriginalName && ap.Name != bk.Name {
+		// Copy the annotations so that we do not modify the v1 policy.
+		ap.Annotations = make(map[string]string, len(bp.Annotations)+1)
+		for k, v := range bp.Annotations {
+			ap.Annotations[k] = v
+		}
+		ap.Annotations[AnnotationV1Name] = bk.Name
+	}
+	// A nil Order is preserved as nil, which orders the policy at the end of the chain.
 	ap.Spec.Order = bp.Order
-	ap.Spec.Ingress = rulesV1BackendToV3API(bp.InboundRules)
-	ap.Spec.Egress = rulesV1BackendToV3API(bp.OutboundRules)
+	var err error
+	if ap.Spec.Ingress, err = rulesV1BackendToV3API(bp.InboundRules, "inbound"); err != nil {
+		return nil, err
+	}
+	if ap.Spec.Egress, err = rulesV1BackendToV3API(bp.OutboundRules, "outbound"); err != nil {
+		return nil, err
+	}
+	if err = p.Deprecated.RecordRules(bk, "inbound", bp.InboundRules); err != nil {
+		return nil, err
+	}
+	if err = p.Deprecated.RecordRules(bk, "outbound", bp.OutboundRules); err != nil {
+		return nil, err
+	}
+	var logRules []string
+	if ap.Spec.Ingress, logRules, err = p.LogActions.convertRules(ap.Spec.Ingress, "inbound", logRules); err != nil {
+		return nil, err
+	}
+	if ap.Spec.Egress, logRules, err = p.LogActions.convertRules(ap.Spec.Egress, "outbound", logRules); err != nil {
+		return nil, err
+	}
+	ap.Annotations = annotateLogRules(ap.Annotations, logRules)
 	ap.Spec.Selector = convertSelector(bp.Selector)
 	ap.Spec.DoNotTrack = bp.DoNotTrack
 	ap.Spec.PreDNAT = bp.PreDNAT
 	ap.Spec.ApplyOnForward = bp.ApplyOnForward
 	ap.Spec.Types = nil // Set later.
 
-	if !bp.ApplyOnForward && (bp.DoNotTrack || bp.PreDNAT) {
+	if !p.DisableApplyOnForwardInference && !bp.ApplyOnForward && (bp.DoNotTrack || bp.PreDNAT) {
 		// This case happens when there is a pre-existing policy in the datastore, from before
 		// the ApplyOnForward feature was available. DoNotTrack or PreDNAT policy applies to
 		// forward traffic by nature. So in this case we return ApplyOnForward flag as true.
@@ -143,6 +202,12 @@ func (_ Policy) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error) {
 		}
 	}
 
+	if p.StrictTypes {
+		if err := validatorv3.ValidatePolicyTypes(apiv3.KindGlobalNetworkPolicy, ap.Spec.Types, ap.Spec.Ingress, ap.Spec.Egress); err != nil {
+			return nil, err
+		}
+	}
+
 	log.WithFields(log.Fields{
 		"KVPairV1": bp,
 		"APIv3":    ap,
@@ -150,3 +215,12 @@ func (_ Policy) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error) {
 
 	return ap, nil
 }
+
+// validateOrder checks that a policy Order, if specified, is a finite number.  A nil Order
+// is valid.
+func validateOrder(order *float64) error {
+	if order != nil && (math.IsNaN(*order) || math.IsInf(*order, 0)) {
+		return fmt.Errorf("invalid policy order: %v", *order)
+	}
+	return nil
+}
@@ -15,6 +15,7 @@
 package converters
 
 import (
+	"math"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -446,3 +447,431 @@ func TestCanConvertKVModelToV3Policy(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyOnForwardInference(t *testing.T) {
+	v1KVP := &model.KVPair{
+		Key: model.PolicyKey{
+			Name: "policy1",
+		},
+		Value: &model.Policy{
+			Order:         &order1,
+			InboundRules:  []model.Rule{V1ModelInRule2},
+			OutboundRules: []model.Rule{},
+			PreDNAT:       true,
+			Types:         []string{"ingress"},
+		},
+	}
+
+	for _, entry := range []struct {
+		description            string
+		disableInference       bool
+		expectedApplyOnForward bool
+	}{
+		{
+			description:            "missing ApplyOnForward with PreDNAT true is inferred by default",
+			disableInference:       false,
+			expectedApplyOnForward: true,
+		},
+		{
+			description:            "missing ApplyOnForward with PreDNAT true is not inferred when disabled",
+			disableInference:       true,
+			expectedApplyOnForward: false,
+		},
+	} {
+		t.Run(entry.description, func(t *testing.T) {
+			RegisterTestingT(t)
+
+			p := Policy{DisableApplyOnForwardInference: entry.disableInference}
+
+			// Test and assert v1 API to v1 backend logic.
+			v1KVPResult, err := p.APIV1ToBackendV1(&apiv1.Policy{
+				Metadata: apiv1.PolicyMetadata{
+					Name: "policy1",
+				},
+				Spec: apiv1.PolicySpec{
+					Order:        &order1,
+					IngressRules: []apiv1.Rule{V1InRule2},
+					PreDNAT:      true,
+					Types:        []apiv1.PolicyType{apiv1.PolicyTypeIngress},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred(), entry.description)
+			Expect(v1KVPResult.Value.(*model.Policy).ApplyOnForward).To(Equal(entry.expectedApplyOnForward), entry.description)
+
+			// Test and assert v1 backend to v3 API logic.
+			v3APIResult, err := p.BackendV1ToAPIV3(v1KVP)
+			Expect(err).NotTo(HaveOccurred(), entry.description)
+			Expect(v3APIResult.(*apiv3.GlobalNetworkPolicy).Spec.ApplyOnForward).To(Equal(entry.expectedApplyOnForward), entry.description)
+			Expect(v3APIResult.(*apiv3.GlobalNetworkPolicy).Spec.PreDNAT).To(BeTrue(), entry.description)
+		})
+	}
+}
+
+func TestPolicyTypesInference(t *testing.T) {
+	for _, entry := range []struct {
+		description   string
+		ingressRules  []apiv1.Rule
+		egressRules   []apiv1.Rule
+		expectedTypes []apiv3.PolicyType
+	}{
+		{
+			description:   "empty Types with ingress rules is inferred as Ingress",
+			ingressRules:  []apiv1.Rule{V1InRule2},
+			expectedTypes: []apiv3.PolicyType{apiv3.PolicyTypeIngress},
+		},
+		{
+			description:   "empty Types with egress rules is inferred as Egress",
+			egressRules:   []apiv1.Rule{V1EgressRule1},
+			expectedTypes: []apiv3.PolicyType{apiv3.PolicyTypeEgress},
+		},
+		{
+			description:   "empty Types with ingress and egress rules is inferred as Ingress and Egress",
+			ingressRules:  []apiv1.Rule{V1InRule2},
+			egressRules:   []apiv1.Rule{V1EgressRule1},
+			expectedTypes: []apiv3.PolicyType{apiv3.PolicyTypeIngress, apiv3.PolicyTypeEgress},
+		},
+		{
+			description:   "empty Types with no rules is inferred as Ingress",
+			expectedTypes: []apiv3.PolicyType{apiv3.PolicyTypeIngress},
+		},
+	} {
+		t.Run(entry.description, func(t *testing.T) {
+			RegisterTestingT(t)
+
+			p := Policy{}
+
+			// Convert a v1 API policy with no Types through to the v3 API.
+			v1KVPResult, err := p.APIV1ToBackendV1(&apiv1.Policy{
+				Metadata: apiv1.PolicyMetadata{
+					Name: "policy1",
+				},
+				Spec: apiv1.PolicySpec{
+					Order:        &order1,
+					IngressRules: entry.ingressRules,
+					EgressRules:  entry.egressRules,
+					Selector:     "type=='database'",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred(), entry.description)
+
+			v3APIResult, err := p.BackendV1ToAPIV3(v1KVPResult)
+			Expect(err).NotTo(HaveOccurred(), entry.description)
+			Expect(v3APIResult.(*apiv3.GlobalNetworkPolicy).Spec.Types).To(Equal(entry.expectedTypes), entry.description)
+		})
+	}
+}
+
+func TestPolicyOrderConversion(t *testing.T) {
+	nanOrder := math.NaN()
+	infOrder := math.Inf(1)
+
+	for _, entry := range []struct {
+		description string
+		order       *float64
+		expectError bool
+	}{
+		{
+			description: "nil order converts to nil order",
+			order:       nil,
+		},
+		{
+			description: "NaN order should error",
+			order:       &nanOrder,
+			expectError: true,
+		},
+		{
+			description: "infinite order should error",
+			order:       &infOrder,
+			expectError: true,
+		},
+	} {
+		t.Run(entry.description, func(t *testing.T) {
+			RegisterTestingT(t)
+
+			p := Policy{}
+
+			// Test and assert v1 API to v1 backend logic.
+			v1KVPResult, err := p.APIV1ToBackendV1(&apiv1.Policy{
+				Metadata: apiv1.PolicyMetadata{
+					Name: "policy1",
+				},
+				Spec: apiv1.PolicySpec{
+					Order:    entry.order,
+					Selector: "type=='database'",
+				},
+			})
+			if entry.expectError {
+				Expect(err).To(HaveOccurred(), entry.description)
+			} else {
+				Expect(err).NotTo(HaveOccurred(), entry.description)
+				Expect(v1KVPResult.Value.(*model.Policy).Order).To(BeNil(), entry.description)
+			}
+
+			// Test and assert v1 backend to v3 API logic.
+			v3APIResult, err := p.BackendV1ToAPIV3(&model.KVPair{
+				Key: model.PolicyKey{
+					Name: "policy1",
+				},
+				Value: &model.Policy{
+					Order:    entry.order,
+					Selector: "type=='database'",
+					Types:    []string{"ingress"},
+				},
+			})
+			if entry.expectError {
+				Expect(err).To(HaveOccurred(), entry.description)
+			} else {
+				Expect(err).NotTo(HaveOccurred(), entry.description)
+				Expect(v3APIResult.(*apiv3.GlobalNetworkPolicy).Spec.Order).To(BeNil(), entry.description)
+			}
+		})
+	}
+}
+
+func TestDeprecatedFieldsRecorded(t *testing.T) {
+	RegisterTestingT(t)
+
+	deprecated := &DeprecatedFields{}
+	p := Policy{Deprecated: deprecated}
+
+	kvp := &model.KVPair{
+		Key: model.PolicyKey{
+			Name: "policy1",
+		},
+		Value: &model.Policy{
+			InboundRules: []model.Rule{
+				{Action: "allow", SrcNet: &cidr1Net},
+				{Action: "allow", SrcSelector: "has(label1)"},
+				{Action: "deny", DstNet: &cidr2Net, NotSrcNet: &cidr3Net},
+			},
+			Selector: "all()",
+			Types:    []string{"ingress"},
+		},
+	}
+	_, err := p.BackendV1ToAPIV3(kvp)
+	Expect(err).NotTo(HaveOccurred())
+
+	// Both rules with deprecated fields are recorded, not just the first.
+	Expect(deprecated.Usages).To(Equal([]DeprecatedFieldUsage{
+		{
+			Key:       model.PolicyKey{Name: "policy1"},
+			Direction: "inbound",
+			Index:     0,
+			Fields:    []string{"SrcNet"},
+		},
+		{
+			Key:       model.PolicyKey{Name: "policy1"},
+			Direction: "inbound",
+			Index:     2,
+			Fields:    []string{"DstNet", "NotSrcNet"},
+		},
+	}))
+
+	// Converting without a recorder is still supported.
+	_, err = Policy{}.BackendV1ToAPIV3(kvp)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+func TestDeprecatedFieldsStrict(t *testing.T) {
+	RegisterTestingT(t)
+
+	kvp := &model.KVPair{
+		Key: model.PolicyKey{
+			Name: "policy1",
+		},
+		Value: &model.Policy{
+			InboundRules: []model.Rule{
+				{Action: "allow", SrcSelector: "has(label1)"},
+			},
+			OutboundRules: []model.Rule{
+				{Action: "allow", SrcSelector: "has(label1)"},
+				{Action: "allow", DstNet: &cidr1Net, NotDstNet: &cidr2Net},
+			},
+			Selector: "all()",
+			Types:    []string{"ingress", "egress"},
+		},
+	}
+
+	// By default the rule using Net is converted and recorded.
+	deprecated := &DeprecatedFields{}
+	_, err := Policy{Deprecated: deprecated}.BackendV1ToAPIV3(kvp)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(deprecated.Usages).To(HaveLen(1))
+
+	// In strict mode the rule using Net is rejected.
+	deprecated = &DeprecatedFields{Strict: true}
+	_, err = Policy{Deprecated: deprecated}.BackendV1ToAPIV3(kvp)
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("invalid outbound rule 1: deprecated fields DstNet, NotDstNet are not allowed, use Nets and NotNets instead"))
+
+	// The same applies to profiles.
+	_, err = Profile{Deprecated: deprecated}.BackendV1ToAPIV3(&model.KVPair{
+		Key: model.ProfileKey{Name: "profile1"},
+		Value: &model.Profile{
+			Rules: model.ProfileRules{
+				InboundRules: []model.Rule{{Action: "allow", SrcNet: &cidr1Net}},
+			},
+		},
+	})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("invalid inbound rule 0: deprecated fields SrcNet are not allowed, use Nets and NotNets instead"))
+}
+
+func TestPolicyOriginalNameAnnotation(t *testing.T) {
+	RegisterTestingT(t)
+
+	kvp := func(name string) *model.KVPair {
+		return &model.KVPair{
+			Key: model.PolicyKey{Name: name},
+			Value: &model.Policy{
+				Selector:    "all()",
+				Annotations: map[string]string{"foo": "bar"},
+			},
+		}
+	}
+
+	// By default the original name is not recorded.
+	res, err := Policy{}.BackendV1ToAPIV3(kvp("MaKe.-.MaKe"))
+	Expect(err).NotTo(HaveOccurred())
+	gnp := res.(*apiv3.GlobalNetworkPolicy)
+	Expect(gnp.Name).To(Equal("make-make-1b6971c8"))
+	Expect(gnp.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+
+	// With the option set, the original name of a modified name is recorded.
+	v1KVP := kvp("MaKe.-.MaKe")
+	res, err = Policy{AnnotateOriginalName: true}.BackendV1ToAPIV3(v1KVP)
+	Expect(err).NotTo(HaveOccurred())
+	gnp = res.(*apiv3.GlobalNetworkPolicy)
+	Expect(gnp.Name).To(Equal("make-make-1b6971c8"))
+	Expect(gnp.Annotations).To(Equal(map[string]string{"foo": "bar", AnnotationV1Name: "MaKe.-.MaKe"}))
+	Expect(v1KVP.Value.(*model.Policy).Annotations).To(Equal(map[string]string{"foo": "bar"}))
+
+	// A name that is not modified is not annotated.
+	res, err = Policy{AnnotateOriginalName: true}.BackendV1ToAPIV3(kvp("make"))
+	Expect(err).NotTo(HaveOccurred())
+	gnp = res.(*apiv3.GlobalNetworkPolicy)
+	Expect(gnp.Name).To(Equal("make"))
+	Expect(gnp.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+}
+
+func TestLogActionModes(t *testing.T) {
+	for _, entry := range []struct {
+		description         string
+		mode                LogActionMode
+		expectedIngress     []apiv3.Action
+		expectedEgress      []apiv3.Action
+		expectedAnnotations map[string]string
+	}{
+		{
+			description:         "keep",
+			mode:                LogActionKeep,
+			expectedIngress:     []apiv3.Action{apiv3.Log, apiv3.Allow},
+			expectedEgress:      []apiv3.Action{apiv3.Deny, apiv3.Log},
+			expectedAnnotations: map[string]string{"foo": "bar"},
+		},
+		{
+			description:         "drop rule",
+			mode:                LogActionDropRule,
+			expectedIngress:     []apiv3.Action{apiv3.Allow},
+			expectedEgress:      []apiv3.Action{apiv3.Deny},
+			expectedAnnotations: map[string]string{"foo": "bar"},
+		},
+		{
+			description:     "convert to allow with annotation",
+			mode:            LogActionConvertToAllow,
+			expectedIngress: []apiv3.Action{apiv3.Allow, apiv3.Allow},
+			expectedEgress:  []apiv3.Action{apiv3.Deny, apiv3.Allow},
+			expectedAnnotations: map[string]string{
+				"foo":                "bar",
+				AnnotationV1LogRules: "inbound/0,outbound/1",
+			},
+		},
+	} {
+		t.Run(entry.description, func(t *testing.T) {
+			RegisterTestingT(t)
+
+			actions := func(rules []apiv3.Rule) []apiv3.Action {
+				var a []apiv3.Action
+				for _, r := range rules {
+					a = append(a, r.Action)
+				}
+				return a
+			}
+
+			v1KVP := &model.KVPair{
+				Key: model.PolicyKey{Name: "policy1"},
+				Value: &model.Policy{
+					InboundRules:  []model.Rule{{Action: "log"}, {Action: "allow"}},
+					OutboundRules: []model.Rule{{Action: "deny"}, {Action: "log"}},
+					Selector:      "all()",
+					Annotations:   map[string]string{"foo": "bar"},
+				},
+			}
+			res, err := Policy{LogActions: entry.mode}.BackendV1ToAPIV3(v1KVP)
+			Expect(err).NotTo(HaveOccurred())
+			gnp := res.(*apiv3.GlobalNetworkPolicy)
+			Expect(actions(gnp.Spec.Ingress)).To(Equal(entry.expectedIngress))
+			Expect(actions(gnp.Spec.Egress)).To(Equal(entry.expectedEgress))
+			Expect(gnp.Annotations).To(Equal(entry.expectedAnnotations))
+			Expect(v1KVP.Value.(*model.Policy).Annotations).To(Equal(map[string]string{"foo": "bar"}))
+
+			res, err = Profile{LogActions: entry.mode}.BackendV1ToAPIV3(&model.KVPair{
+				Key: model.ProfileKey{Name: "profile1"},
+				Value: &model.Profile{
+					Rules: model.ProfileRules{
+						InboundRules:  []model.Rule{{Action: "log"}, {Action: "allow"}},
+						OutboundRules: []model.Rule{{Action: "deny"}, {Action: "log"}},
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			profile := res.(*apiv3.Profile)
+			Expect(actions(profile.Spec.Ingress)).To(Equal(entry.expectedIngress))
+			Expect(actions(profile.Spec.Egress)).To(Equal(entry.expectedEgress))
+			if entry.mode == LogActionConvertToAllow {
+				Expect(profile.Annotations).To(Equal(map[string]string{AnnotationV1LogRules: "inbound/0,outbound/1"}))
+			} else {
+				Expect(profile.Annotations).To(BeEmpty())
+			}
+		})
+	}
+
+	RegisterTestingT(t)
+	_, err := Policy{LogActions: "Unknown"}.BackendV1ToAPIV3(&model.KVPair{
+		Key:   model.PolicyKey{Name: "policy1"},
+		Value: &model.Policy{Selector: "all()"},
+	})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("unknown Log action mode: 'Unknown'"))
+}
+
+func TestStrictPolicyTypes(t *testing.T) {
+	RegisterTestingT(t)
+
+	kvp := func(types ...string) *model.KVPair {
+		return &model.KVPair{
+			Key: model.PolicyKey{Name: "policy1"},
+			Value: &model.Policy{
+				Types:         types,
+				InboundRules:  []model.Rule{{Action: "allow"}},
+				OutboundRules: []model.Rule{{Action: "deny"}},
+			},
+		}
+	}
+
+	// By default, the rules for a direction not in the Types are converted.
+	res, err := Policy{}.BackendV1ToAPIV3(kvp("ingress"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(res.(*apiv3.GlobalNetworkPolicy).Spec.Egress).To(HaveLen(1))
+
+	// A consistent policy is converted when strict.
+	p := Policy{StrictTypes: true}
+	res, err = p.BackendV1ToAPIV3(kvp("ingress", "egress"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(res.(*apiv3.GlobalNetworkPolicy).Spec.Types).To(Equal([]apiv3.PolicyType{apiv3.PolicyTypeIngress, apiv3.PolicyTypeEgress}))
+
+	// An ingress policy with egress rules is rejected when strict.
+	_, err = p.BackendV1ToAPIV3(kvp("ingress"))
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("error with field GlobalNetworkPolicy.Spec.Egress = '[Ingress]' (policy has egress rules but Types does not include Egress)"))
+}
@@ -27,7 +27,14 @@ import (
 )
 
 // Profile implements the Converter interface.
-type Profile struct{}
+type Profile struct {
+	// Deprecated, if set, records the rules that use deprecated fields.
+	Deprecated *DeprecatedFields
+
+	// LogActions configures how rules with a Log action are converted.  By default they
+	// are converted to rules with a Log action.
+	LogActions LogActionMode
+}
 
 // APIV1ToBackendV1 converts v1 Profile API to v1 Profile KVPair.
 func (_ Profile) APIV1ToBackendV1(a unversioned.Resource) (*model.KVPair, error) {
@@ -70,7 +77,7 @@ func (_ Profile) APIV1ToBackendV1(a unversioned.Resource) (*model.KVPair, error)
 }
 
 // BackendV1ToAPIV3 converts v1 Profile KVPair to v3 API.
-func (_ Profile) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error) {
+func (p Profile) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error) {
 	bp, ok := kvp.Value.(*model.Profile)
 	if !ok {
 		return nil, fmt.Errorf("value is not a valid Profile resource")
@@ -83,10 +90,15 @@ func (_ Profile) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error) {
 	ap := apiv3.NewProfile()
 	ap.Name = convertProfileName(bk.Name)
 
-	// Merge Tags and Labels into LabelsToApply.
+	// Merge Tags and Labels into LabelsToApply.  Each tag becomes a label with an empty
+	// value, matching the conversion of tags in rule selectors.  The labels are copied so
+	// that the v1 resource is not modified.
 	combinedLabelsToApply := bp.Labels
-	if combinedLabelsToApply == nil && len(bp.Tags) != 0 {
-		combinedLabelsToApply = map[string]string{}
+	if len(bp.Tags) != 0 {
+		combinedLabelsToApply = make(map[string]string, len(bp.Labels)+len(bp.Tags))
+		for k, v := range bp.Labels {
+			combinedLabelsToApply[k] = v
+		}
 	}
 	for _, t := range bp.Tags {
 		// Check to make sure the key doesn't already exist before merging it.
@@ -98,8 +110,27 @@ func (_ Profile) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error) {
 
 	ap.Spec.LabelsToApply = combinedLabelsToApply
 
-	ap.Spec.Ingress = rulesV1BackendToV3API(bp.Rules.InboundRules)
-	ap.Spec.Egress = rulesV1BackendToV3API(bp.Rules.OutboundRules)
+	var err error
+	if ap.Spec.Ingress, err = rulesV1BackendToV3API(bp.Rules.InboundRules, "inbound"); err != nil {
+		return nil, err
+	}
+	if ap.Spec.Egress, err = rulesV1BackendToV3API(bp.Rules.OutboundRules, "outbound"); err != nil {
+		return nil, err
+	}
+	if err = p.Deprecated.RecordRules(bk, "inbound", bp.Rules.InboundRules); err != nil {
+		return nil, err
+	}
+	if err = p.Deprecated.RecordRules(bk, "outbound", bp.Rules.OutboundRules); err != nil {
+		return nil, err
+	}
+	var logRules []string
+	if ap.Spec.Ingress, logRules, err = p.LogActions.convertRules(ap.Spec.Ingress, "inbound", logRules); err != nil {
+		return nil, err
+	}
+	if ap.Spec.Egress, logRules, err = p.LogActions.convertRules(ap.Spec.Egress, "outbound", logRules); err != nil {
+		return nil, err
+	}
+	ap.Annotations = annotateLogRules(ap.Annotations, logRules)
 
 	log.WithFields(log.Fields{
 		"KVPairV1": bp,
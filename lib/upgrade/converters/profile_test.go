@@ -106,6 +106,42 @@ var profileTable = []struct {
 			},
 		},
 	},
+	{
+		description: "Profile with tags and no labels",
+		v1API: &apiv1.Profile{
+			Metadata: apiv1.ProfileMetadata{
+				Name: "tagged",
+				Tags: []string{"tag1", "kingindanorth"},
+			},
+			Spec: apiv1.ProfileSpec{
+				IngressRules: []apiv1.Rule{V1InRule1},
+				EgressRules:  []apiv1.Rule{V1EgressRule1},
+			},
+		},
+		v1KVP: &model.KVPair{
+			Key: model.ProfileKey{
+				Name: "tagged",
+			},
+			Value: &model.Profile{
+				Rules: model.ProfileRules{
+					InboundRules:  []model.Rule{V1ModelInRule1},
+					OutboundRules: []model.Rule{V1ModelEgressRule1},
+				},
+				Tags:   []string{"tag1", "kingindanorth"},
+				Labels: map[string]string{},
+			},
+		},
+		v3API: apiv3.Profile{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "tagged",
+			},
+			Spec: apiv3.ProfileSpec{
+				Ingress:       []apiv3.Rule{V3InRule1},
+				Egress:        []apiv3.Rule{V3EgressRule1},
+				LabelsToApply: map[string]string{"tag1": "", "kingindanorth": ""},
+			},
+		},
+	},
 }
 
 func TestCanConvertV1ToV3Profile(t *testing.T) {
@@ -183,6 +219,30 @@ func TestNoLabelsOnBackend(t *testing.T) {
 		Expect(err).NotTo(HaveOccurred())
 	})
 }
+
+func TestTagsDoNotModifyBackendLabels(t *testing.T) {
+	t.Run("Profile conversion should not add the tags to the v1 labels", func(t *testing.T) {
+		RegisterTestingT(t)
+
+		p := Profile{}
+
+		v1KVP := &model.KVPair{
+			Key: model.ProfileKey{
+				Name: "makemake",
+			},
+			Value: &model.Profile{
+				Tags:   []string{"lalala"},
+				Labels: map[string]string{"thing1": "val1"},
+			},
+		}
+
+		v3APIResult, err := p.BackendV1ToAPIV3(v1KVP)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v3APIResult.(*apiv3.Profile).Spec.LabelsToApply).To(Equal(map[string]string{"thing1": "val1", "lalala": ""}))
+		Expect(v1KVP.Value.(*model.Profile).Labels).To(Equal(map[string]string{"thing1": "val1"}))
+	})
+}
+
 func TestNoLabelsOrTagsOnBackend(t *testing.T) {
 	t.Run("Profile conversion should succeed when there are no tags or labels", func(t *testing.T) {
 		RegisterTestingT(t)
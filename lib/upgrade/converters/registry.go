@@ -0,0 +1,129 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	apiv1 "github.com/projectcalico/libcalico-go/lib/apis/v1"
+	"github.com/projectcalico/libcalico-go/lib/apis/v1/unversioned"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+)
+
+// ConverterFactory returns an empty v1 API resource of a kind, into which the v1 resource is
+// unmarshaled, and the converter for the resource.  The deprecated fields, if set, record the
+// rules that use deprecated fields and should be passed to converters that support them.
+type ConverterFactory func(deprecated *DeprecatedFields) (unversioned.ResourceObject, Converter)
+
+// DatastoreConverter is a converter registered for the migration of the v1 resources of a
+// kind from the datastore.
+type DatastoreConverter struct {
+	// The v1 kind, in lower case.
+	Kind string
+
+	// The options used to list the v1 resources of the kind from the datastore.
+	List model.ListInterface
+
+	// The converter for the resources of the kind.
+	Converter Converter
+}
+
+var (
+	convertersLock                sync.RWMutex
+	registeredConverters          = map[string]ConverterFactory{}
+	registeredDatastoreConverters = map[string]DatastoreConverter{}
+)
+
+func init() {
+	RegisterConverter("bgppeer", func(_ *DeprecatedFields) (unversioned.ResourceObject, Converter) {
+		return apiv1.NewBGPPeer(), BGPPeer{}
+	})
+	RegisterConverter("hostendpoint", func(_ *DeprecatedFields) (unversioned.ResourceObject, Converter) {
+		return apiv1.NewHostEndpoint(), HostEndpoint{}
+	})
+	RegisterConverter("ippool", func(_ *DeprecatedFields) (unversioned.ResourceObject, Converter) {
+		return apiv1.NewIPPool(), IPPool{}
+	})
+	RegisterConverter("node", func(_ *DeprecatedFields) (unversioned.ResourceObject, Converter) {
+		return apiv1.NewNode(), Node{}
+	})
+	RegisterConverter("policy", func(deprecated *DeprecatedFields) (unversioned.ResourceObject, Converter) {
+		return apiv1.NewPolicy(), Policy{Deprecated: deprecated}
+	})
+	RegisterConverter("profile", func(deprecated *DeprecatedFields) (unversioned.ResourceObject, Converter) {
+		return apiv1.NewProfile(), Profile{Deprecated: deprecated}
+	})
+	RegisterConverter("workloadendpoint", func(_ *DeprecatedFields) (unversioned.ResourceObject, Converter) {
+		return apiv1.NewWorkloadEndpoint(), WorkloadEndpoint{}
+	})
+}
+
+// RegisterConverter registers the factory of the converter for a v1 kind, so that resources
+// of the kind are converted by ConvertV1Resources.  The kind is matched case-insensitively.
+// This panics if a converter is already registered for the kind.
+func RegisterConverter(kind string, factory ConverterFactory) {
+	convertersLock.Lock()
+	defer convertersLock.Unlock()
+	kind = strings.ToLower(kind)
+	if _, ok := registeredConverters[kind]; ok {
+		panic(fmt.Sprintf("converter already registered for kind '%s'", kind))
+	}
+	registeredConverters[kind] = factory
+}
+
+// newOfflineResource returns an empty v1 resource of the kind and its converter, or false if
+// no converter is registered for the kind.  The kind is matched case-insensitively.
+func newOfflineResource(kind string, deprecated *DeprecatedFields) (unversioned.ResourceObject, Converter, bool) {
+	convertersLock.RLock()
+	factory, ok := registeredConverters[strings.ToLower(kind)]
+	convertersLock.RUnlock()
+	if !ok {
+		return nil, nil, false
+	}
+	rv1, converter := factory(deprecated)
+	return rv1, converter, true
+}
+
+// RegisterDatastoreConverter registers the converter for the v1 resources of a kind, so that
+// the resources listed from the datastore using the list options are converted by the
+// migration, after the built-in kinds.  The built-in kinds are converted by the migration
+// with options that depend on its configuration, so they are not registered here.  The kind
+// is matched case-insensitively.  This panics if a converter is already registered for the
+// kind.
+func RegisterDatastoreConverter(kind string, list model.ListInterface, converter Converter) {
+	convertersLock.Lock()
+	defer convertersLock.Unlock()
+	kind = strings.ToLower(kind)
+	if _, ok := registeredDatastoreConverters[kind]; ok {
+		panic(fmt.Sprintf("datastore converter already registered for kind '%s'", kind))
+	}
+	registeredDatastoreConverters[kind] = DatastoreConverter{Kind: kind, List: list, Converter: converter}
+}
+
+// DatastoreConverters returns the converters registered using RegisterDatastoreConverter,
+// ordered by kind.
+func DatastoreConverters() []DatastoreConverter {
+	convertersLock.RLock()
+	defer convertersLock.RUnlock()
+	dcs := make([]DatastoreConverter, 0, len(registeredDatastoreConverters))
+	for _, dc := range registeredDatastoreConverters {
+		dcs = append(dcs, dc)
+	}
+	sort.Slice(dcs, func(i, j int) bool { return dcs[i].Kind < dcs[j].Kind })
+	return dcs
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	yaml "github.com/projectcalico/go-yaml-wrapper"
+
+	apiv1 "github.com/projectcalico/libcalico-go/lib/apis/v1"
+	"github.com/projectcalico/libcalico-go/lib/apis/v1/unversioned"
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+)
+
+// prefixedProfile converts v1 Profiles, prefixing the names of the converted v3 Profiles.
+type prefixedProfile struct {
+	Profile
+}
+
+func (p prefixedProfile) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error) {
+	r, err := p.Profile.BackendV1ToAPIV3(kvp)
+	if err != nil {
+		return nil, err
+	}
+	r.(*apiv3.Profile).Name = "custom." + r.(*apiv3.Profile).Name
+	return r, nil
+}
+
+func TestRegisterConverter(t *testing.T) {
+	RegisterTestingT(t)
+
+	RegisterConverter("customProfile", func(deprecated *DeprecatedFields) (unversioned.ResourceObject, Converter) {
+		return apiv1.NewProfile(), prefixedProfile{Profile{Deprecated: deprecated}}
+	})
+
+	out, reportOut, err := ConvertV1Resources([]byte(`
+- apiVersion: v1
+  kind: CustomProfile
+  metadata:
+    name: p1
+- apiVersion: v1
+  kind: profile
+  metadata:
+    name: p2
+`))
+	Expect(err).NotTo(HaveOccurred())
+
+	var resources []struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	Expect(yaml.Unmarshal(out, &resources)).NotTo(HaveOccurred())
+	var ids []string
+	for _, r := range resources {
+		ids = append(ids, r.Kind+"/"+r.Metadata.Name)
+	}
+	Expect(ids).To(Equal([]string{"Profile/custom.p1", "Profile/p2"}))
+
+	var report OfflineReport
+	Expect(yaml.Unmarshal(reportOut, &report)).NotTo(HaveOccurred())
+	Expect(report.Converted).To(Equal(2))
+	Expect(report.Errors).To(BeEmpty())
+
+	// A kind can only be registered once.
+	Expect(func() {
+		RegisterConverter("CUSTOMPROFILE", func(_ *DeprecatedFields) (unversioned.ResourceObject, Converter) {
+			return apiv1.NewProfile(), Profile{}
+		})
+	}).To(Panic())
+}
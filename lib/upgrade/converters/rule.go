@@ -25,6 +25,7 @@ import (
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
 	"github.com/projectcalico/libcalico-go/lib/net"
 	"github.com/projectcalico/libcalico-go/lib/numorstring"
+	"github.com/projectcalico/libcalico-go/lib/selector"
 )
 
 // rulesAPIV1ToBackend converts an API Rule structure slice to a Backend Rule structure slice.
@@ -41,16 +42,77 @@ func rulesAPIV1ToBackend(ars []apiv1.Rule) []model.Rule {
 }
 
 // rulesV1BackendToV3API converts a Backend Rule structure slice to an API Rule structure slice.
-func rulesV1BackendToV3API(brs []model.Rule) []apiv3.Rule {
+// The direction ("inbound" or "outbound") is used to identify an invalid rule in the returned
+// error.
+func rulesV1BackendToV3API(brs []model.Rule, direction string) ([]apiv3.Rule, error) {
 	if brs == nil {
-		return nil
+		return nil, nil
 	}
 
 	ars := make([]apiv3.Rule, len(brs))
 	for idx, br := range brs {
-		ars[idx] = rulebackendToAPIv3(br)
+		if err := validateICMPFields(br); err != nil {
+			return nil, fmt.Errorf("invalid %s rule %d: %v", direction, idx, err)
+		}
+		ar, err := rulebackendToAPIv3(br)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s rule %d: %v", direction, idx, err)
+		}
+		if err := validateICMPVersion(ar); err != nil {
+			return nil, fmt.Errorf("invalid %s rule %d: %v", direction, idx, err)
+		}
+		ars[idx] = ar
+	}
+	return ars, nil
+}
+
+// validateICMPFields checks that the ICMP and NotICMP type and code of a Backend Rule, if
+// specified, are within the valid range 0-255.
+func validateICMPFields(br model.Rule) error {
+	for _, f := range []struct {
+		name  string
+		value *int
+	}{
+		{"ICMP type", br.ICMPType},
+		{"ICMP code", br.ICMPCode},
+		{"NotICMP type", br.NotICMPType},
+		{"NotICMP code", br.NotICMPCode},
+	} {
+		if f.value != nil && (*f.value < 0 || *f.value > 255) {
+			return fmt.Errorf("%s %d is out of range 0-255", f.name, *f.value)
+		}
+	}
+	return nil
+}
+
+// validateICMPVersion checks that the IPVersion of a converted API Rule, if specified, matches
+// the IP version of an ICMP or ICMPv6 Protocol or NotProtocol.
+func validateICMPVersion(ar apiv3.Rule) error {
+	if ar.IPVersion == nil {
+		return nil
+	}
+	for _, f := range []struct {
+		name     string
+		protocol *numorstring.Protocol
+	}{
+		{"Protocol", ar.Protocol},
+		{"NotProtocol", ar.NotProtocol},
+	} {
+		if f.protocol == nil {
+			continue
+		}
+		switch *f.protocol {
+		case protocolICMP:
+			if *ar.IPVersion != 4 {
+				return fmt.Errorf("%s %s requires IPVersion 4, not %d", f.name, *f.protocol, *ar.IPVersion)
+			}
+		case protocolICMPv6:
+			if *ar.IPVersion != 6 {
+				return fmt.Errorf("%s %s requires IPVersion 6, not %d", f.name, *f.protocol, *ar.IPVersion)
+			}
+		}
 	}
-	return ars
+	return nil
 }
 
 // ruleAPIToBackend converts an API Rule structure to a Backend Rule structure.
@@ -122,7 +184,11 @@ func normalizeIPNets(nets []*net.IPNet) []*net.IPNet {
 }
 
 // rulebackendToAPIv3 convert a Backend Rule structure to an API Rule structure.
-func rulebackendToAPIv3(br model.Rule) apiv3.Rule {
+func rulebackendToAPIv3(br model.Rule) (apiv3.Rule, error) {
+	// The ICMP type and code are copied as is.  A rule with neither matches all ICMP, so
+	// the ICMP fields are left nil, and a rule with only a type matches all codes of that
+	// type, so the code is left nil.  A code without a type is carried over unchanged (it
+	// is not valid in either v1 or v3, and is reported by the v3 validation).
 	var icmp, notICMP *apiv3.ICMPFields
 	if br.ICMPCode != nil || br.ICMPType != nil {
 		icmp = &apiv3.ICMPFields{
@@ -150,49 +216,43 @@ func rulebackendToAPIv3(br model.Rule) apiv3.Rule {
 	br.NotDstNet = normalizeIPNet(br.NotDstNet)
 	br.NotDstNets = normalizeIPNets(br.NotDstNets)
 
-	srcNets := br.AllSrcNets()
-	var srcNetsStr []string
-	for _, net := range srcNets {
-		srcNetsStr = append(srcNetsStr, net.String())
-	}
-
-	dstNets := br.AllDstNets()
-	var dstNetsStr []string
-	for _, net := range dstNets {
-		dstNetsStr = append(dstNetsStr, net.String())
-	}
-
-	notSrcNets := br.AllNotSrcNets()
-	var notSrcNetsStr []string
-	for _, net := range notSrcNets {
-		notSrcNetsStr = append(notSrcNetsStr, net.String())
-	}
-
-	notDstNets := br.AllNotDstNets()
-	var notDstNetsStr []string
-	for _, net := range notDstNets {
-		notDstNetsStr = append(notDstNetsStr, net.String())
-	}
+	srcNetsStr := netsToDedupedStrings(br.AllSrcNets())
+	dstNetsStr := netsToDedupedStrings(br.AllDstNets())
+	notSrcNetsStr := netsToDedupedStrings(br.AllNotSrcNets())
+	notDstNetsStr := netsToDedupedStrings(br.AllNotDstNets())
 
 	srcSelector := mergeTagsAndSelectors(br.SrcSelector, br.SrcTag)
 	dstSelector := mergeTagsAndSelectors(br.DstSelector, br.DstTag)
 	notSrcSelector := mergeTagsAndSelectors(br.NotSrcSelector, br.NotSrcTag)
 	notDstSelector := mergeTagsAndSelectors(br.NotDstSelector, br.NotDstTag)
 
+	action, err := ruleActionV1ToV3API(br.Action)
+	if err != nil {
+		return apiv3.Rule{}, fmt.Errorf("Action: %v", err)
+	}
+
 	var v3Protocol *numorstring.Protocol
 	if br.Protocol != nil {
-		protocol := numorstring.ProtocolV3FromProtocolV1(*br.Protocol)
+		protocol, err := numorstring.ProtocolV3FromProtocolV1(*br.Protocol)
+		if err != nil {
+			return apiv3.Rule{}, fmt.Errorf("Protocol: %v", err)
+		}
+		protocol = icmpProtocolName(protocol)
 		v3Protocol = &protocol
 	}
 
 	var v3NotProtocol *numorstring.Protocol
 	if br.NotProtocol != nil {
-		notProtocol := numorstring.ProtocolV3FromProtocolV1(*br.NotProtocol)
+		notProtocol, err := numorstring.ProtocolV3FromProtocolV1(*br.NotProtocol)
+		if err != nil {
+			return apiv3.Rule{}, fmt.Errorf("NotProtocol: %v", err)
+		}
+		notProtocol = icmpProtocolName(notProtocol)
 		v3NotProtocol = &notProtocol
 	}
 
 	return apiv3.Rule{
-		Action:      ruleActionV1ToV3API(br.Action),
+		Action:      action,
 		IPVersion:   br.IPVersion,
 		Protocol:    v3Protocol,
 		ICMP:        icmp,
@@ -215,7 +275,46 @@ func rulebackendToAPIv3(br model.Rule) apiv3.Rule {
 			NotSelector: notDstSelector,
 			NotPorts:    br.NotDstPorts,
 		},
+	}, nil
+}
+
+var (
+	protocolICMP   = numorstring.ProtocolFromString("ICMP")
+	protocolICMPv6 = numorstring.ProtocolFromString("ICMPv6")
+)
+
+// icmpProtocolName converts the ICMP (1) and ICMPv6 (58) protocol numbers to the protocol
+// names.  The v3 API only accepts ICMP type and code fields, and only checks the IPVersion,
+// for the named ICMP protocols.  Other protocols are returned unchanged.
+func icmpProtocolName(p numorstring.Protocol) numorstring.Protocol {
+	if p.Type != numorstring.NumOrStringNum {
+		return p
+	}
+	switch p.NumVal {
+	case 1:
+		return protocolICMP
+	case 58:
+		return protocolICMPv6
+	}
+	return p
+}
+
+// netsToDedupedStrings converts a slice of IPNets to a slice of CIDR strings, removing
+// any repeated entries while preserving the order of first occurrence.  A v1 rule may
+// specify the same CIDR in both the deprecated Net field and the Nets field, and these
+// are merged into a single list in the v3 API.
+func netsToDedupedStrings(nets []*net.IPNet) []string {
+	var out []string
+	seen := make(map[string]bool, len(nets))
+	for _, n := range nets {
+		s := n.String()
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
 	}
+	return out
 }
 
 // mergeTagsAndSelectors merges tags into selectors.
@@ -236,9 +335,18 @@ func rulebackendToAPIv3(br model.Rule) apiv3.Rule {
 //		Selector: "(label1 == 'value1' || make == 'cake') && tag1 == ''",
 //	},
 //}
+// Merging adds a level of parenthesis nesting to the selector.  A warning is logged if the
+// selector was already nested, since the result may exceed the selector limits configured
+// on the v3 client.
 func mergeTagsAndSelectors(sel, tag string) string {
 	if tag != "" {
 		if sel != "" {
+			if depth := selector.Depth(sel); depth > 0 {
+				log.WithFields(log.Fields{
+					"Selector": sel,
+					"Tag":      tag,
+				}).Warnf("Merging tag into a nested selector increases the selector nesting depth to %d", depth+1)
+			}
 			sel = fmt.Sprintf("(%s) && %s == ''", sel, tag)
 		} else {
 			sel = fmt.Sprintf("%s == ''", tag)
@@ -258,21 +366,20 @@ func ruleActionV1APIToV1Backend(action string) string {
 }
 
 // ruleActionV1ToV3API converts the rule action field value from the backend
-// value to the equivalent API value.
-func ruleActionV1ToV3API(inAction string) apiv3.Action {
+// value to the equivalent API value.  An error is returned if the action is not
+// one of the recognized v1 actions.
+func ruleActionV1ToV3API(inAction string) (apiv3.Action, error) {
 	if inAction == "" {
-		return apiv3.Allow
+		return apiv3.Allow, nil
 	} else if inAction == "next-tier" {
-		return apiv3.Pass
+		return apiv3.Pass, nil
 	} else {
 		for _, action := range []apiv3.Action{apiv3.Allow, apiv3.Deny, apiv3.Log, apiv3.Pass} {
 			if strings.ToLower(inAction) == strings.ToLower(string(action)) {
-				return action
+				return action, nil
 			}
 		}
 	}
 
-	log.Warnf("Unknown Rule action: '%s'", inAction)
-
-	return apiv3.Action(inAction)
+	return "", fmt.Errorf("unknown action '%s', must be one of allow, deny, log, pass or next-tier", inAction)
 }
@@ -0,0 +1,284 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	apiv1 "github.com/projectcalico/libcalico-go/lib/apis/v1"
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+	"github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/numorstring"
+)
+
+var ruleTable = []struct {
+	description string
+	v1API       apiv1.Rule
+	v1Model     model.Rule
+	v3API       apiv3.Rule
+}{
+	{
+		description: "rule with no protocol",
+		v1API: apiv1.Rule{
+			Action: "allow",
+			Source: apiv1.EntityRule{
+				Selector: "has(label1)",
+			},
+		},
+		v1Model: model.Rule{
+			Action:      "allow",
+			SrcSelector: "has(label1)",
+		},
+		v3API: apiv3.Rule{
+			Action: apiv3.Allow,
+			Source: apiv3.EntityRule{
+				Selector: "has(label1)",
+			},
+		},
+	},
+	{
+		description: "rule with Net also present in Nets",
+		v1API: apiv1.Rule{
+			Action: "allow",
+			Source: apiv1.EntityRule{
+				Net:  &cidr2,
+				Nets: []*net.IPNet{&cidr1, &cidr2, &cidr3},
+			},
+		},
+		v1Model: model.Rule{
+			Action:  "allow",
+			SrcNet:  &cidr2Net,
+			SrcNets: []*net.IPNet{&cidr1Net, &cidr2Net, &cidr3Net},
+		},
+		v3API: apiv3.Rule{
+			Action: apiv3.Allow,
+			Source: apiv3.EntityRule{
+				Nets: []string{cidr1StrictMaskStr, cidr2StrictMaskStr, cidr3StrictMaskStr},
+			},
+		},
+	},
+}
+
+func TestCanConvertRules(t *testing.T) {
+	for _, entry := range ruleTable {
+		t.Run(entry.description, func(t *testing.T) {
+			RegisterTestingT(t)
+
+			// Test and assert v1 API to v1 backend logic.
+			v1ModelResult := ruleAPIToBackend(entry.v1API)
+			Expect(v1ModelResult).To(Equal(entry.v1Model), entry.description)
+
+			// Test and assert v1 backend to v3 API logic.
+			v3APIResult, err := rulebackendToAPIv3(entry.v1Model)
+			Expect(err).NotTo(HaveOccurred(), entry.description)
+			Expect(v3APIResult).To(Equal(entry.v3API), entry.description)
+		})
+	}
+}
+
+func TestICMPRangeValidation(t *testing.T) {
+	RegisterTestingT(t)
+	intPtr := func(i int) *int { return &i }
+
+	check := func(desc string, rules []model.Rule, expectedErr string) {
+		_, err := rulesV1BackendToV3API(rules, "inbound")
+		if expectedErr == "" {
+			Expect(err).NotTo(HaveOccurred(), desc)
+		} else {
+			Expect(err).To(HaveOccurred(), desc)
+			Expect(err.Error()).To(Equal(expectedErr), desc)
+		}
+	}
+
+	check("in-range ICMP type and code", []model.Rule{
+		{Action: "allow", ICMPType: intPtr(0), ICMPCode: intPtr(255)},
+		{Action: "allow", NotICMPType: intPtr(255), NotICMPCode: intPtr(0)},
+	}, "")
+	check("out-of-range ICMP type", []model.Rule{
+		{Action: "allow"},
+		{Action: "allow", ICMPType: intPtr(300)},
+	}, "invalid inbound rule 1: ICMP type 300 is out of range 0-255")
+	check("out-of-range ICMP code", []model.Rule{
+		{Action: "allow", ICMPType: intPtr(8), ICMPCode: intPtr(-1)},
+	}, "invalid inbound rule 0: ICMP code -1 is out of range 0-255")
+	check("out-of-range NotICMP type", []model.Rule{
+		{Action: "allow", NotICMPType: intPtr(256)},
+	}, "invalid inbound rule 0: NotICMP type 256 is out of range 0-255")
+	check("out-of-range NotICMP code", []model.Rule{
+		{Action: "allow", NotICMPType: intPtr(8), NotICMPCode: intPtr(1000)},
+	}, "invalid inbound rule 0: NotICMP code 1000 is out of range 0-255")
+
+	// The error is returned from the policy conversion.
+	_, err := Policy{}.BackendV1ToAPIV3(&model.KVPair{
+		Key: model.PolicyKey{Name: "policy1"},
+		Value: &model.Policy{
+			OutboundRules: []model.Rule{{Action: "allow", ICMPType: intPtr(300)}},
+		},
+	})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("invalid outbound rule 0: ICMP type 300 is out of range 0-255"))
+}
+
+func TestRuleActionValidation(t *testing.T) {
+	RegisterTestingT(t)
+
+	for inAction, expected := range map[string]apiv3.Action{
+		"":          apiv3.Allow,
+		"allow":     apiv3.Allow,
+		"deny":      apiv3.Deny,
+		"log":       apiv3.Log,
+		"pass":      apiv3.Pass,
+		"next-tier": apiv3.Pass,
+		"Deny":      apiv3.Deny,
+	} {
+		rules, err := rulesV1BackendToV3API([]model.Rule{{Action: inAction}}, "inbound")
+		Expect(err).NotTo(HaveOccurred(), inAction)
+		Expect(rules[0].Action).To(Equal(expected), inAction)
+	}
+
+	_, err := rulesV1BackendToV3API([]model.Rule{{Action: "allow"}, {Action: "allwo"}}, "inbound")
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("invalid inbound rule 1: Action: unknown action 'allwo', must be one of allow, deny, log, pass or next-tier"))
+
+	// The error is returned from the profile conversion.
+	_, err = Profile{}.BackendV1ToAPIV3(&model.KVPair{
+		Key: model.ProfileKey{Name: "profile1"},
+		Value: &model.Profile{
+			Rules: model.ProfileRules{
+				OutboundRules: []model.Rule{{Action: "allwo"}},
+			},
+		},
+	})
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("invalid outbound rule 0: Action: unknown action 'allwo', must be one of allow, deny, log, pass or next-tier"))
+}
+
+func TestProtocolConversion(t *testing.T) {
+	RegisterTestingT(t)
+	protocolPtr := func(p numorstring.Protocol) *numorstring.Protocol { return &p }
+
+	// SCTP by name is converted to the v3 protocol name.
+	ars, err := rulesV1BackendToV3API([]model.Rule{
+		{Action: "allow", Protocol: protocolPtr(numorstring.ProtocolFromStringV1("sctp"))},
+	}, "inbound")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(*ars[0].Protocol).To(Equal(numorstring.ProtocolFromString("SCTP")))
+	Expect(ars[0].Protocol.ToV1()).To(Equal(numorstring.ProtocolFromStringV1("sctp")))
+
+	// Numeric protocols, whether stored as a number or a string, are converted to numbers.
+	ars, err = rulesV1BackendToV3API([]model.Rule{
+		{Action: "allow", Protocol: protocolPtr(numorstring.ProtocolFromInt(132))},
+		{Action: "allow", NotProtocol: protocolPtr(numorstring.ProtocolFromStringV1("132"))},
+	}, "inbound")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(*ars[0].Protocol).To(Equal(numorstring.ProtocolFromInt(132)))
+	Expect(*ars[1].NotProtocol).To(Equal(numorstring.ProtocolFromInt(132)))
+	Expect(ars[0].Protocol.ToV1()).To(Equal(numorstring.ProtocolFromInt(132)))
+
+	// Out-of-range protocol numbers are rejected.
+	_, err = rulesV1BackendToV3API([]model.Rule{
+		{Action: "allow", Protocol: protocolPtr(numorstring.ProtocolFromStringV1("256"))},
+	}, "inbound")
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("invalid inbound rule 0: Protocol: protocol 256 is out of range 0-255"))
+	_, err = rulesV1BackendToV3API([]model.Rule{
+		{Action: "allow"},
+		{Action: "allow", NotProtocol: protocolPtr(numorstring.ProtocolFromStringV1("-1"))},
+	}, "outbound")
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("invalid outbound rule 1: NotProtocol: protocol -1 is out of range 0-255"))
+}
+
+func TestICMPv6Conversion(t *testing.T) {
+	RegisterTestingT(t)
+	protocolPtr := func(p numorstring.Protocol) *numorstring.Protocol { return &p }
+	intPtr := func(i int) *int { return &i }
+	v4, v6 := 4, 6
+
+	// ICMPv6 by name or number is converted to the v3 protocol name, keeping the type and code.
+	ars, err := rulesV1BackendToV3API([]model.Rule{
+		{Action: "allow", IPVersion: &v6, Protocol: protocolPtr(numorstring.ProtocolFromStringV1("icmpv6")), ICMPType: intPtr(128), ICMPCode: intPtr(0)},
+		{Action: "allow", IPVersion: &v6, Protocol: protocolPtr(numorstring.ProtocolFromInt(58)), NotICMPType: intPtr(135)},
+		{Action: "allow", IPVersion: &v4, NotProtocol: protocolPtr(numorstring.ProtocolFromStringV1("1"))},
+	}, "inbound")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(*ars[0].Protocol).To(Equal(numorstring.ProtocolFromString("ICMPv6")))
+	Expect(*ars[0].IPVersion).To(Equal(6))
+	Expect(ars[0].ICMP).To(Equal(&apiv3.ICMPFields{Type: intPtr(128), Code: intPtr(0)}))
+	Expect(*ars[1].Protocol).To(Equal(numorstring.ProtocolFromString("ICMPv6")))
+	Expect(ars[1].NotICMP).To(Equal(&apiv3.ICMPFields{Type: intPtr(135)}))
+	Expect(*ars[2].NotProtocol).To(Equal(numorstring.ProtocolFromString("ICMP")))
+	Expect(ars[1].Protocol.ToV1()).To(Equal(numorstring.ProtocolFromStringV1("icmpv6")))
+
+	// The ICMPv6 type and code ranges are validated.
+	_, err = rulesV1BackendToV3API([]model.Rule{
+		{Action: "allow", IPVersion: &v6, Protocol: protocolPtr(numorstring.ProtocolFromInt(58)), ICMPType: intPtr(1), ICMPCode: intPtr(256)},
+	}, "inbound")
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("invalid inbound rule 0: ICMP code 256 is out of range 0-255"))
+
+	// An IPVersion that does not match the ICMP protocol is rejected.
+	_, err = rulesV1BackendToV3API([]model.Rule{
+		{Action: "allow", IPVersion: &v4, Protocol: protocolPtr(numorstring.ProtocolFromInt(58)), ICMPType: intPtr(128)},
+	}, "inbound")
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("invalid inbound rule 0: Protocol ICMPv6 requires IPVersion 6, not 4"))
+	_, err = rulesV1BackendToV3API([]model.Rule{
+		{Action: "allow"},
+		{Action: "allow", IPVersion: &v6, NotProtocol: protocolPtr(numorstring.ProtocolFromStringV1("icmp"))},
+	}, "outbound")
+	Expect(err).To(HaveOccurred())
+	Expect(err.Error()).To(Equal("invalid outbound rule 1: NotProtocol ICMP requires IPVersion 4, not 6"))
+}
+
+func TestICMPFieldsConversion(t *testing.T) {
+	RegisterTestingT(t)
+	protocolPtr := func(p numorstring.Protocol) *numorstring.Protocol { return &p }
+	intPtr := func(i int) *int { return &i }
+	icmp := protocolPtr(numorstring.ProtocolFromStringV1("icmp"))
+
+	ars, err := rulesV1BackendToV3API([]model.Rule{
+		{Action: "allow", Protocol: icmp},
+		{Action: "allow", Protocol: icmp, ICMPType: intPtr(8)},
+		{Action: "allow", Protocol: icmp, ICMPCode: intPtr(0)},
+		{Action: "allow", Protocol: icmp, ICMPType: intPtr(3), ICMPCode: intPtr(4)},
+		{Action: "allow", Protocol: icmp, NotICMPType: intPtr(5)},
+		{Action: "allow", Protocol: icmp, NotICMPType: intPtr(3), NotICMPCode: intPtr(1)},
+	}, "inbound")
+	Expect(err).NotTo(HaveOccurred())
+
+	// No type or code matches all ICMP.
+	Expect(ars[0].ICMP).To(BeNil())
+	Expect(ars[0].NotICMP).To(BeNil())
+
+	// A type without a code matches all codes of the type, so the code is not set.
+	Expect(ars[1].ICMP).To(Equal(&apiv3.ICMPFields{Type: intPtr(8)}))
+	Expect(ars[1].ICMP.Code).To(BeNil())
+
+	// A code without a type is converted as is, without setting a type.
+	Expect(ars[2].ICMP).To(Equal(&apiv3.ICMPFields{Code: intPtr(0)}))
+	Expect(ars[2].ICMP.Type).To(BeNil())
+
+	// Both type and code are converted.
+	Expect(ars[3].ICMP).To(Equal(&apiv3.ICMPFields{Type: intPtr(3), Code: intPtr(4)}))
+
+	// The same applies to NotICMP.
+	Expect(ars[4].ICMP).To(BeNil())
+	Expect(ars[4].NotICMP).To(Equal(&apiv3.ICMPFields{Type: intPtr(5)}))
+	Expect(ars[5].NotICMP).To(Equal(&apiv3.ICMPFields{Type: intPtr(3), Code: intPtr(1)}))
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+)
+
+// AnnotationV1CreationTimestamp is the annotation used to record the creation time of the v1
+// resource, in RFC 3339 format, when it is not carried in the CreationTimestamp of the converted
+// resource.
+const AnnotationV1CreationTimestamp = "projectcalico.org/v1-creation-timestamp"
+
+// CreationTimestamps carries the creation times of v1 resources into the converted v3
+// resources.  The v1 backend data does not include the creation time of a resource, so the
+// times are supplied by the caller.
+type CreationTimestamps struct {
+	// Times is the creation time of each v1 resource, keyed off the string representation
+	// of its v1 key.  Resources without an entry are not modified.
+	Times map[string]time.Time
+
+	// Annotate, if set, records the creation time in the AnnotationV1CreationTimestamp
+	// annotation rather than in the CreationTimestamp of the converted resource.
+	Annotate bool
+}
+
+// Apply sets the creation time of the v1 resource with the supplied key on the converted
+// resource, if the time is known.  It is a no-op on a nil CreationTimestamps.
+func (c *CreationTimestamps) Apply(keyV1 model.Key, r Resource) {
+	if c == nil {
+		return
+	}
+	t, ok := c.Times[keyV1.String()]
+	if !ok {
+		return
+	}
+	if !c.Annotate {
+		r.GetObjectMeta().SetCreationTimestamp(metav1.NewTime(t))
+		return
+	}
+
+	// The annotations are copied so that the v1 resource is not modified.
+	annotations := r.GetObjectMeta().GetAnnotations()
+	annotated := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		annotated[k] = v
+	}
+	annotated[AnnotationV1CreationTimestamp] = t.UTC().Format(time.RFC3339)
+	r.GetObjectMeta().SetAnnotations(annotated)
+}
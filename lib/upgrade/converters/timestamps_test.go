@@ -0,0 +1,63 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/backend/model"
+)
+
+func TestCreationTimestamps(t *testing.T) {
+	RegisterTestingT(t)
+
+	created := time.Date(2017, 6, 1, 12, 30, 0, 0, time.UTC)
+	key := model.PolicyKey{Name: "policy1"}
+	ts := &CreationTimestamps{Times: map[string]time.Time{key.String(): created}}
+
+	// The creation time is carried when present.
+	gnp := apiv3.NewGlobalNetworkPolicy()
+	ts.Apply(key, gnp)
+	Expect(gnp.CreationTimestamp.Time.Equal(created)).To(BeTrue())
+	Expect(gnp.Annotations).To(BeEmpty())
+
+	// The creation time is left at the default when absent.
+	gnp = apiv3.NewGlobalNetworkPolicy()
+	ts.Apply(model.PolicyKey{Name: "policy2"}, gnp)
+	Expect(gnp.CreationTimestamp.IsZero()).To(BeTrue())
+
+	// The creation time is left at the default when no times are supplied.
+	gnp = apiv3.NewGlobalNetworkPolicy()
+	(*CreationTimestamps)(nil).Apply(key, gnp)
+	Expect(gnp.CreationTimestamp.IsZero()).To(BeTrue())
+
+	// In annotation mode the creation time is recorded in an annotation without modifying
+	// the existing annotations.
+	annotations := map[string]string{"foo": "bar"}
+	gnp = apiv3.NewGlobalNetworkPolicy()
+	gnp.Annotations = annotations
+	ts.Annotate = true
+	ts.Apply(key, gnp)
+	Expect(gnp.CreationTimestamp.IsZero()).To(BeTrue())
+	Expect(gnp.Annotations).To(Equal(map[string]string{
+		"foo":                         "bar",
+		AnnotationV1CreationTimestamp: "2017-06-01T12:30:00Z",
+	}))
+	Expect(annotations).To(Equal(map[string]string{"foo": "bar"}))
+}
@@ -77,6 +77,7 @@ func (_ WorkloadEndpoint) APIV1ToBackendV1(rIn unversioned.Resource) (*model.KVP
 		Key: k,
 		Value: &model.WorkloadEndpoint{
 			Labels:           ah.Metadata.Labels,
+			Annotations:      ah.Metadata.Annotations,
 			ActiveInstanceID: ah.Metadata.ActiveInstanceID,
 			State:            "active",
 			Name:             ah.Spec.InterfaceName,
@@ -141,8 +142,9 @@ func (_ WorkloadEndpoint) BackendV1ToAPIV3(kvp *model.KVPair) (Resource, error)
 	wep := apiv3.NewWorkloadEndpoint()
 
 	wep.ObjectMeta = v1.ObjectMeta{
-		Namespace: namespace,
-		Labels:    labels,
+		Namespace:   namespace,
+		Labels:      labels,
+		Annotations: wepValue.Annotations,
 	}
 	wep.Spec = apiv3.WorkloadEndpointSpec{
 		Orchestrator:  convertName(wepKey.OrchestratorID),
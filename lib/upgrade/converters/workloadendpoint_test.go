@@ -47,6 +47,7 @@ var wepTable = []struct {
 				Node:             "TestNode",
 				ActiveInstanceID: "1337495556942031415926535",
 				Labels:           makeLabelsV1(),
+				Annotations:      makeAnnotations(),
 			},
 			Spec: apiv1.WorkloadEndpointSpec{
 				IPNetworks:    []net.IPNet{net.MustParseNetwork("10.0.0.1/32"), net.MustParseNetwork("2001::/128")},
@@ -68,6 +69,7 @@ var wepTable = []struct {
 			},
 			Value: &model.WorkloadEndpoint{
 				Labels:           makeLabelsV1(),
+				Annotations:      makeAnnotations(),
 				ActiveInstanceID: "1337495556942031415926535",
 				State:            "active",
 				Name:             "cali1234",
@@ -84,8 +86,9 @@ var wepTable = []struct {
 		},
 		v3API: apiv3.WorkloadEndpoint{
 			ObjectMeta: v1.ObjectMeta{
-				Name:   "testnode-k8s-frontend--5gs43-eth0",
-				Labels: makeLabelsV3(),
+				Name:        "testnode-k8s-frontend--5gs43-eth0",
+				Labels:      makeLabelsV3(),
+				Annotations: makeAnnotations(),
 			},
 			Spec: apiv3.WorkloadEndpointSpec{
 				Orchestrator:  "k8s",
@@ -340,6 +343,7 @@ func TestCanConvertV1ToV3WorkloadEndpoint(t *testing.T) {
 			Expect(err).NotTo(HaveOccurred(), entry.description)
 			Expect(v3APIResult.(*apiv3.WorkloadEndpoint).ObjectMeta.Name).To(Equal(entry.v3API.ObjectMeta.Name))
 			Expect(v3APIResult.(*apiv3.WorkloadEndpoint).ObjectMeta.Labels).To(Equal(entry.v3API.ObjectMeta.Labels))
+			Expect(v3APIResult.(*apiv3.WorkloadEndpoint).ObjectMeta.Annotations).To(Equal(entry.v3API.ObjectMeta.Annotations))
 			Expect(v3APIResult.(*apiv3.WorkloadEndpoint).Spec).To(Equal(entry.v3API.Spec))
 		})
 	}
@@ -394,6 +398,13 @@ func makeLabelsV3() map[string]string {
 	}
 }
 
+// makeAnnotations creates some dummy annotations to use in tests.
+func makeAnnotations() map[string]string {
+	return map[string]string{
+		"example.com/owner": "team-a",
+	}
+}
+
 func makeIPNATv1() []apiv1.IPNAT {
 	return []apiv1.IPNAT{
 		{
@@ -44,6 +44,7 @@ type V1ClientInterface interface {
 	Update(d *model.KVPair) (*model.KVPair, error)
 	Get(k model.Key) (*model.KVPair, error)
 	List(l model.ListInterface) ([]*model.KVPair, error)
+	Clean() error
 	IsKDD() bool
 }
 
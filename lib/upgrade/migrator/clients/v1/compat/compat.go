@@ -51,6 +51,11 @@ func (c *ModelAdaptor) Apply(d *model.KVPair) (*model.KVPair, error) {
 	return c.client.Apply(d)
 }
 
+// Clean removes all of the v1 Calico data from the datastore.
+func (c *ModelAdaptor) Clean() error {
+	return c.client.Clean()
+}
+
 // Get an entry from the datastore.  This errors if the entry does not exist.
 func (c *ModelAdaptor) Get(k model.Key) (*model.KVPair, error) {
 	switch kt := k.(type) {
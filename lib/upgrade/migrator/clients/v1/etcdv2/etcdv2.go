@@ -35,7 +35,16 @@ var (
 	etcdGetOpts          = &etcd.GetOptions{Quorum: true}
 	etcdListOpts         = &etcd.GetOptions{Quorum: true, Recursive: true, Sort: true}
 	etcdListChildrenOpts = &etcd.GetOptions{Quorum: true, Recursive: false, Sort: true}
+	etcdCleanOpts        = &etcd.DeleteOptions{Recursive: true, Dir: true}
 	clientTimeout        = 30 * time.Second
+
+	// The root directories of the v1 data.
+	v1DataDirs = []string{
+		"/calico/v1",
+		"/calico/bgp/v1",
+		"/calico/ipam/v2",
+		"/calico/felix/v1",
+	}
 )
 
 type EtcdClient struct {
@@ -112,6 +121,20 @@ func (c *EtcdClient) Apply(d *model.KVPair) (*model.KVPair, error) {
 	return c.set(d, etcdApplyOpts)
 }
 
+// Clean removes all of the v1 Calico data from the datastore.
+func (c *EtcdClient) Clean() error {
+	for _, d := range v1DataDirs {
+		log.Debugf("Deleting directory: %s", d)
+		if _, err := c.etcdKeysAPI.Delete(context.Background(), d, etcdCleanOpts); err != nil {
+			if _, ok := convertEtcdError(err, nil).(errors.ErrorResourceDoesNotExist); ok {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 // Get an entry from the datastore.  This errors if the entry does not exist.
 func (c *EtcdClient) Get(k model.Key) (*model.KVPair, error) {
 	key, err := model.KeyToDefaultPath(k)
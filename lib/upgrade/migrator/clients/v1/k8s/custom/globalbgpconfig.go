@@ -15,6 +15,11 @@
 package custom
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -35,6 +40,33 @@ type GlobalBGPConfigSpec struct {
 	Value string `json:"value"`
 }
 
+// AsBool parses the config value as a boolean.  The value is case-insensitive.
+func (s GlobalBGPConfigSpec) AsBool() (bool, error) {
+	b, err := strconv.ParseBool(strings.ToLower(s.Value))
+	if err != nil {
+		return false, fmt.Errorf("GlobalBGPConfig %s value %q is not a valid boolean", s.Name, s.Value)
+	}
+	return b, nil
+}
+
+// AsInt parses the config value as a base 10 integer.
+func (s GlobalBGPConfigSpec) AsInt() (int, error) {
+	i, err := strconv.Atoi(s.Value)
+	if err != nil {
+		return 0, fmt.Errorf("GlobalBGPConfig %s value %q is not a valid integer", s.Name, s.Value)
+	}
+	return i, nil
+}
+
+// AsDuration parses the config value as a duration, e.g. "90s" or "1m30s".
+func (s GlobalBGPConfigSpec) AsDuration() (time.Duration, error) {
+	d, err := time.ParseDuration(s.Value)
+	if err != nil {
+		return 0, fmt.Errorf("GlobalBGPConfig %s value %q is not a valid duration", s.Name, s.Value)
+	}
+	return d, nil
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 type GlobalBGPConfigList struct {
@@ -0,0 +1,59 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/projectcalico/libcalico-go/lib/upgrade/migrator/clients/v1/k8s/custom"
+)
+
+var _ = DescribeTable("GlobalBGPConfigSpec typed values",
+	func(value string, parse func(custom.GlobalBGPConfigSpec) (interface{}, error), expected interface{}, expectedErr string) {
+		v, err := parse(custom.GlobalBGPConfigSpec{Name: "TestConfig", Value: value})
+		if expectedErr != "" {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(expectedErr))
+		} else {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(expected))
+		}
+	},
+	Entry("bool true", "true", asBool, true, ""),
+	Entry("bool mixed case false", "False", asBool, false, ""),
+	Entry("bool invalid", "yes please", asBool, nil, `GlobalBGPConfig TestConfig value "yes please" is not a valid boolean`),
+	Entry("int", "64512", asInt, 64512, ""),
+	Entry("int negative", "-1", asInt, -1, ""),
+	Entry("int invalid", "12a", asInt, nil, `GlobalBGPConfig TestConfig value "12a" is not a valid integer`),
+	Entry("duration", "1m30s", asDuration, 90*time.Second, ""),
+	Entry("duration missing unit", "30", asDuration, nil, `GlobalBGPConfig TestConfig value "30" is not a valid duration`),
+	Entry("duration empty", "", asDuration, nil, `GlobalBGPConfig TestConfig value "" is not a valid duration`),
+)
+
+func asBool(s custom.GlobalBGPConfigSpec) (interface{}, error) {
+	return s.AsBool()
+}
+
+func asInt(s custom.GlobalBGPConfigSpec) (interface{}, error) {
+	return s.AsInt()
+}
+
+func asDuration(s custom.GlobalBGPConfigSpec) (interface{}, error) {
+	return s.AsDuration()
+}
@@ -223,6 +223,15 @@ func (c *KubeClient) Apply(d *model.KVPair) (*model.KVPair, error) {
 	}
 }
 
+// Clean removes all of the v1 Calico data from the datastore. (Not implemented for KDD.)
+func (c *KubeClient) Clean() error {
+	log.Warn("Attempt to 'Clean' using kubernetes backend is not supported.")
+	return errors.ErrorOperationNotSupported{
+		Identifier: "v1 data",
+		Operation:  "Clean",
+	}
+}
+
 // Get an entry from the datastore.  This errors if the entry does not exist.
 func (c *KubeClient) Get(k model.Key) (*model.KVPair, error) {
 	log.Debugf("Performing 'Get' for %+v", k)
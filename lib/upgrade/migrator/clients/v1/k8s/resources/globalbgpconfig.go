@@ -66,6 +66,13 @@ func (_ GlobalBGPConfigConverter) NameToKey(name string) (model.Key, error) {
 
 func (c GlobalBGPConfigConverter) ToKVPair(r CustomK8sResource) (*model.KVPair, error) {
 	t := r.(*custom.GlobalBGPConfig)
+
+	// The resource name is the lowercased config name, so make sure the name in the Spec
+	// has not been changed to refer to a different config value.
+	if strings.ToLower(t.Spec.Name) != t.Name {
+		return nil, fmt.Errorf("GlobalBGPConfig Spec.Name %s does not match the resource name %s", t.Spec.Name, t.Name)
+	}
+
 	return &model.KVPair{
 		Key: model.GlobalBGPConfigKey{
 			Name: t.Spec.Name,
@@ -95,4 +95,20 @@ var _ = Describe("Global BGP config conversion methods", func() {
 		Expect(kvp.Value).To(BeAssignableToTypeOf(value1))
 		Expect(kvp.Value).To(Equal(kvp1.Value))
 	})
+
+	It("should not convert a Kubernetes resource whose Spec.Name does not match the resource name", func() {
+		res := &custom.GlobalBGPConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name1,
+				ResourceVersion: "rv",
+			},
+			Spec: custom.GlobalBGPConfigSpec{
+				Name:  "AbCdE",
+				Value: value1,
+			},
+		}
+		_, err := converter.ToKVPair(res)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("GlobalBGPConfig Spec.Name AbCdE does not match the resource name abcd"))
+	})
 })
@@ -61,6 +61,7 @@ func (m *migrationHelper) queryAndConvertFelixConfigV1ToV3(
 	// flag should be true, for etcd it should be false.
 	ready := m.clientv1.IsKDD()
 	clusterInfo.Spec.DatastoreReady = &ready
+	m.recordUnknownConfig(kvps, data, globalConfig, clusterInfo)
 
 	if m.clientv1.IsKDD() {
 		m.statusBullet("skipping FelixConfiguration (per-node) resources - not supported")
@@ -94,6 +95,7 @@ func (m *migrationHelper) queryAndConvertFelixConfigV1ToV3(
 			if err := m.parseFelixConfigV1IntoResourceV3(kvps, nodeConfig, data); err != nil {
 				return fmt.Errorf("error converting FelixConfiguration: %v", err)
 			}
+			m.recordUnknownConfig(kvps, data, nodeConfig)
 		}
 	}
 
@@ -296,6 +298,48 @@ func (m *migrationHelper) parseFelixConfigV1IntoResourceV3(
 	return nil
 }
 
+// hostConfigMigratedToNode contains the names of the v1 per-node config that is migrated to
+// the Node resource rather than to the per-node FelixConfiguration.
+var hostConfigMigratedToNode = map[string]bool{
+	// Migrated to Node.Spec.BGP.IPv4IPIPTunnelAddr by queryAndConvertV1ToV3Nodes.
+	"IpInIpTunnelAddr": true,
+}
+
+// recordUnknownConfig adds the v1 config KVPairs that do not correspond to a field in any of
+// the supplied v3 resources, and are not migrated elsewhere, to the unknown config.
+func (m *migrationHelper) recordUnknownConfig(kvps []*model.KVPair, data *MigrationData, resources ...converters.Resource) {
+	known := map[string]bool{}
+	for _, res := range resources {
+		specType := reflect.ValueOf(res).Elem().FieldByName("Spec").Type()
+		for i := 0; i < specType.NumField(); i++ {
+			known[m.getConfigName(specType.Field(i))] = true
+		}
+	}
+
+	for _, kvp := range kvps {
+		var name string
+		switch key := kvp.Key.(type) {
+		case model.GlobalConfigKey:
+			name = key.Name
+		case model.HostConfigKey:
+			if hostConfigMigratedToNode[key.Name] {
+				continue
+			}
+			name = key.Name
+		default:
+			continue
+		}
+		if kvp.Value == nil || known[name] {
+			continue
+		}
+		log.WithField("Key", kvp.Key).Info("Config is not migrated")
+		data.UnknownConfig = append(data.UnknownConfig, UnknownConfig{
+			KeyV1:   kvp.Key,
+			ValueV1: kvp.Value,
+		})
+	}
+}
+
 func (m *migrationHelper) parseProtoPortFailed(msg string) error {
 	return errors.New(fmt.Sprintf("failed to parse ProtoPort-%s", msg))
 }
@@ -150,12 +150,54 @@ var _ = Describe("Test felix configuration upgrade", func() {
 		Expect(data.Resources[1]).To(Equal(globalCluster))
 		By("Checking per node felix config")
 		Expect(data.Resources[2]).To(Equal(perNodeFelix))
+		By("Checking there is no unknown config")
+		Expect(data.UnknownConfig).To(BeEmpty())
+	})
+
+	It("should convert known config keys and report unknown config keys", func() {
+		clientv1 := fakeClientV1{
+			kvps: []*model.KVPair{
+				{Key: model.GlobalConfigKey{Name: "LogSeverityScreen"}, Value: "debug"},
+				{Key: model.GlobalConfigKey{Name: "IpInIpEnabled"}, Value: "true"},
+				{Key: model.GlobalConfigKey{Name: "ClusterType"}, Value: "k8s"},
+				{Key: model.GlobalConfigKey{Name: "RemovedSetting"}, Value: "foo"},
+				{Key: model.HostConfigKey{Hostname: "mynode", Name: "InterfacePrefix"}, Value: "tap"},
+				{Key: model.HostConfigKey{Hostname: "mynode", Name: "RemovedHostSetting"}, Value: "bar"},
+				{Key: model.HostConfigKey{Hostname: "mynode", Name: "IpInIpTunnelAddr"}, Value: "10.0.0.1"},
+				{Key: model.GlobalBGPConfigKey{Name: "AsNumber"}, Value: "64512"},
+				{Key: model.GlobalBGPConfigKey{Name: "RemovedBGPSetting"}, Value: "baz"},
+			},
+		}
+
+		data := &MigrationData{}
+		mh := &migrationHelper{clientv1: clientv1}
+		err := mh.queryAndConvertFelixConfigV1ToV3(data)
+		Expect(err).NotTo(HaveOccurred())
+		err = mh.queryAndConvertGlobalBGPConfigV1ToV3(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.ConversionErrors).To(BeEmpty())
+
+		By("Checking the known config keys are converted")
+		Expect(data.Resources).To(HaveLen(4))
+		Expect(data.Resources[0].(*apiv3.FelixConfiguration).Spec.LogSeverityScreen).To(Equal("Debug"))
+		Expect(*data.Resources[0].(*apiv3.FelixConfiguration).Spec.IPIPEnabled).To(BeTrue())
+		Expect(data.Resources[1].(*apiv3.ClusterInformation).Spec.ClusterType).To(Equal("k8s"))
+		Expect(data.Resources[2].(*apiv3.FelixConfiguration).Spec.InterfacePrefix).To(Equal("tap"))
+		Expect(data.Resources[3].(*apiv3.BGPConfiguration).Spec.ASNumber.String()).To(Equal("64512"))
+
+		By("Checking the unknown config keys are reported")
+		Expect(data.UnknownConfig).To(Equal([]UnknownConfig{
+			{KeyV1: model.GlobalConfigKey{Name: "RemovedSetting"}, ValueV1: "foo"},
+			{KeyV1: model.HostConfigKey{Hostname: "mynode", Name: "RemovedHostSetting"}, ValueV1: "bar"},
+			{KeyV1: model.GlobalBGPConfigKey{Name: "RemovedBGPSetting"}, ValueV1: "baz"},
+		}))
 	})
 })
 
 type fakeClientV1 struct {
-	kdd  bool
-	kvps []*model.KVPair
+	kdd     bool
+	kvps    []*model.KVPair
+	cleaned *bool
 }
 
 func (fc fakeClientV1) Apply(d *model.KVPair) (*model.KVPair, error) {
@@ -194,6 +236,13 @@ func (fc fakeClientV1) List(l model.ListInterface) ([]*model.KVPair, error) {
 	return r, nil
 }
 
+func (fc fakeClientV1) Clean() error {
+	if fc.cleaned != nil {
+		*fc.cleaned = true
+	}
+	return nil
+}
+
 func (fc fakeClientV1) IsKDD() bool {
 	return fc.kdd
 }
@@ -16,10 +16,15 @@ package migrator
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/rand"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/coreos/go-semver/semver"
@@ -60,6 +65,7 @@ type Interface interface {
 	IsMigrationInProgress() (bool, error)
 	Abort() error
 	Complete() error
+	RemoveV1Data() error
 }
 
 // StatusWriterInterface is an optional interface supplied by the consumer of
@@ -70,13 +76,82 @@ type StatusWriterInterface interface {
 	Error(string)
 }
 
+// Option is an option that may be supplied when creating a migration helper.
+type Option func(*migrationHelper)
+
+// WithOriginalPolicyNameAnnotations records the v1 name of each policy whose name is modified
+// by the conversion in the converters.AnnotationV1Name annotation of the converted policy.
+// The mapping from the v1 name to the v3 name is also included in the NameConversions of the
+// MigrationData.
+func WithOriginalPolicyNameAnnotations() Option {
+	return func(m *migrationHelper) {
+		m.annotateOriginalPolicyNames = true
+	}
+}
+
+// WithNameClashRenaming resolves clashes between the converted names of v1 resources of the
+// same type by renaming the clashing resource, rather than failing the conversion.  The first
+// resource converted keeps its name, and subsequent resources have a suffix derived from
+// their v1 key appended to their name.  The renames are reported in the RenamedNameClashes
+// of the MigrationData.  Only policies are renamed, since they are not referenced by name
+// from other resources.  Clashes between resources of other kinds, such as Profiles and
+// Nodes, are still reported in the NameClashes.
+func WithNameClashRenaming() Option {
+	return func(m *migrationHelper) {
+		m.renameNameClashes = true
+	}
+}
+
+// WithLogActionMode configures how policy and profile rules with a Log action are converted.
+// By default they are converted to rules with a Log action, see converters.LogActionMode
+// for the alternatives.
+func WithLogActionMode(mode converters.LogActionMode) Option {
+	return func(m *migrationHelper) {
+		m.logActions = mode
+	}
+}
+
+// WithCreationTimestamps carries the creation times of the v1 resources into the converted
+// resources, either as the CreationTimestamp or as an annotation, see
+// converters.CreationTimestamps.  By default the converted resources are created with the
+// time of the migration.
+func WithCreationTimestamps(ts *converters.CreationTimestamps) Option {
+	return func(m *migrationHelper) {
+		m.creationTimestamps = ts
+	}
+}
+
+// WithConversionWorkers converts and validates the v1 resources of each kind concurrently
+// using the supplied number of workers.  The kinds are still converted in turn, and the
+// converted resources are processed in the order in which the v1 resources are listed, so the
+// migrated resources, and the order in which they are written, are the same as for a serial
+// conversion.  By default the resources are converted serially.
+func WithConversionWorkers(workers int) Option {
+	return func(m *migrationHelper) {
+		m.conversionWorkers = workers
+	}
+}
+
+// WithStrictPolicyTypes fails the conversion of a policy that has rules for a direction not
+// included in its Types, for example an Ingress-only policy with egress rules.  By default
+// such policies are converted unchanged.
+func WithStrictPolicyTypes() Option {
+	return func(m *migrationHelper) {
+		m.strictPolicyTypes = true
+	}
+}
+
 // New creates a new migration helper implementing Interface.
-func New(clientv3 clientv3.Interface, clientv1 clients.V1ClientInterface, statusWriter StatusWriterInterface) Interface {
-	return &migrationHelper{
+func New(clientv3 clientv3.Interface, clientv1 clients.V1ClientInterface, statusWriter StatusWriterInterface, opts ...Option) Interface {
+	m := &migrationHelper{
 		clientv3:     clientv3,
 		clientv1:     clientv1,
 		statusWriter: statusWriter,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
 // migrationHelper implements the migrate.Interface.
@@ -84,6 +159,24 @@ type migrationHelper struct {
 	clientv3     clientv3.Interface
 	clientv1     clients.V1ClientInterface
 	statusWriter StatusWriterInterface
+
+	// Whether to annotate converted policies with their original name.
+	annotateOriginalPolicyNames bool
+
+	// Whether to resolve name clashes by renaming the clashing resource.
+	renameNameClashes bool
+
+	// How to convert rules with a Log action.
+	logActions converters.LogActionMode
+
+	// The creation times of the v1 resources, if supplied.
+	creationTimestamps *converters.CreationTimestamps
+
+	// The number of workers converting the resources of each kind.
+	conversionWorkers int
+
+	// Whether to reject policies with rules for directions not included in their Types.
+	strictPolicyTypes bool
 }
 
 // Error types encountered during validation and migration.
@@ -125,9 +218,66 @@ type MigrationData struct {
 	// reconfiguration before attempting the upgrade.
 	NameClashes []NameClash
 
+	// Name clashes in the converted resources that were resolved by renaming the clashing
+	// resource.  The KeyV3 is the key of the renamed resource.  This is only used if
+	// name clash renaming is enabled, in which case NameClashes is only used for clashes
+	// that could not be resolved.
+	RenamedNameClashes []NameClash
+
 	// Entries that were skipped because they will be handled by the Kubernetes
 	// Policy controller.
 	HandledByPolicyCtrl []model.Key
+
+	// The GlobalNetworkSets synthesized from the tags referenced in the v1 data.
+	// These are stored along with the converted Resources.
+	TagNetworkSets []TagNetworkSet
+
+	// Rules in the v1 data that use deprecated fields.  These rules are converted, but the
+	// user may wish to update the rules to remove the deprecated fields.
+	DeprecatedFields []converters.DeprecatedFieldUsage
+
+	// Configuration in the v1 data that does not correspond to a field in the v3
+	// configuration resources, and so is not migrated.
+	UnknownConfig []UnknownConfig
+
+	// Differences between the converted resources and the resources read back from the v3
+	// datastore after they were stored, for example due to defaulting by the datastore.
+	VerificationDiscrepancies []VerificationDiscrepancy
+
+	// A summary of the conversion and storage of the v3 resources, keyed off the v3 kind.
+	Summary map[string]*KindSummary
+
+	// Accumulates the tags and tag members found in the v1 data.
+	tags *converters.TagNetworkSets
+}
+
+// KindSummary contains a summary of the conversion and storage of the resources of a
+// single v3 kind.
+type KindSummary struct {
+	// The number of v3 resources converted successfully.
+	Converted int
+
+	// The number of v1 resources that could not be converted, or whose converted v3
+	// resource failed validation or clashed with another converted resource.
+	Failed int
+
+	// The number of v3 resources written to the v3 datastore.
+	Written int
+
+	// The number of v3 resources that were already stored in the v3 datastore with the
+	// same contents, and so were not rewritten.
+	Unchanged int
+}
+
+// summary returns the summary for the v3 kind, creating it if necessary.
+func (c *MigrationData) summary(kind string) *KindSummary {
+	if c.Summary == nil {
+		c.Summary = map[string]*KindSummary{}
+	}
+	if c.Summary[kind] == nil {
+		c.Summary[kind] = &KindSummary{}
+	}
+	return c.Summary[kind]
 }
 
 // HasErrors returns whether there are any errors contained in the MigrationData.
@@ -147,6 +297,26 @@ type ConversionError struct {
 	ValueV3 converters.Resource
 }
 
+// VerificationDiscrepancy contains details about a field of a converted v3 resource whose
+// value differs from that of the resource read back from the v3 datastore.
+type VerificationDiscrepancy struct {
+	KeyV3 model.Key
+
+	// The differing field, for example "Spec.Selector" or "Metadata.Labels".
+	Field string
+
+	// The converted value and the value read back from the datastore.
+	Expected interface{}
+	Stored   interface{}
+}
+
+// TagNetworkSet contains details about a GlobalNetworkSet synthesized from a v1 tag.
+type TagNetworkSet struct {
+	Tag     string
+	KeyV3   model.Key
+	ValueV3 *apiv3.GlobalNetworkSet
+}
+
 // NameConversion contains details about name/id conversions.
 type NameConversion struct {
 	KeyV1 model.Key
@@ -161,6 +331,13 @@ type NameClash struct {
 	OtherKeyV1 model.Key
 }
 
+// UnknownConfig contains details about a v1 configuration key that does not correspond
+// to a field in the v3 configuration resources.
+type UnknownConfig struct {
+	KeyV1   model.Key
+	ValueV1 interface{}
+}
+
 // Validate validates that the v1 data can be correctly converted to v3.
 // If an error is returned it will be of type MigrationError.
 func (m *migrationHelper) ValidateConversion() (*MigrationData, error) {
@@ -220,6 +397,15 @@ func (m *migrationHelper) IsDestinationEmpty() (bool, error) {
 // Migrate migrates the data from v1 format to v3. Both a v1 and v3 client are required.
 // It returns the converted set of data, a bool indicating whether the migration succeeded.
 // If an error is returned it will be of type MigrationError.
+//
+// The v1 data is preserved so that it remains readable for a rollback - the v3 resources are
+// written under their new keys and the v1 data is only removed by an explicit call to
+// RemoveV1Data once the upgrade is complete.  While the v1 and v3 data coexist:
+//   - The v1 data is a snapshot taken at the time of the migration.  Changes made through
+//     the v3 API (including IPAM allocations) are not reflected in the v1 data, so rolling
+//     back to v1 loses those changes.
+//   - Re-running the migration converts the v1 snapshot again and overwrites any v3
+//     resources that have since been modified.
 func (m *migrationHelper) Migrate() (*MigrationData, error) {
 	// Now set the Ready flag to False. This will stop Felix from making any data plane updates
 	// and will prevent the orchestrator plugins from adding any new workloads or IP allocations
@@ -264,6 +450,16 @@ func (m *migrationHelper) Migrate() (*MigrationData, error) {
 			fmt.Errorf("error storing converted data: %v", err), ErrorMigratingData,
 		)
 	}
+	m.reportSummary(data)
+
+	m.status("Verifying stored v3 data")
+	if err = m.verifyV3Resources(data); err != nil {
+		m.statusError("Unable to verify the stored v3 resources")
+		m.statusBullet("cause: %v", err)
+		return nil, m.abortAfterError(
+			fmt.Errorf("error verifying stored data: %v", err), ErrorMigratingData,
+		)
+	}
 
 	// And we also need to migrate the IPAM data.
 	m.status("Migrating IPAM data")
@@ -364,6 +560,49 @@ func (m *migrationHelper) Complete() error {
 	return nil
 }
 
+// RemoveV1Data removes the v1 data from the datastore.  This is a separate step from the
+// migration so that the v1 data remains available for a rollback until the operator has
+// confirmed that the upgrade is successful.  This is only permitted once the upgrade has been
+// completed, and is not supported for KDD.
+// If an error is returned it will be of type MigrationError.
+func (m *migrationHelper) RemoveV1Data() error {
+	m.status("Removing v1 data")
+	if m.clientv1.IsKDD() {
+		m.statusError("Removing the v1 data is not supported for the Kubernetes datastore")
+		return MigrationError{
+			Type: ErrorGeneric,
+			Err:  errors.New("removing the v1 data is not supported for the Kubernetes datastore"),
+		}
+	}
+
+	// Only remove the v1 data once the v3 datastore has been marked as ready, which indicates
+	// the upgrade has been completed.
+	ci, err := m.clientv3.ClusterInformation().Get(context.Background(), "default", options.GetOptions{})
+	if err != nil {
+		m.statusError("Unable to query the v3 ClusterInformation")
+		m.statusBullet("cause: %v", err)
+		return MigrationError{
+			Type: ErrorGeneric,
+			Err:  fmt.Errorf("unable to determine if the upgrade is complete: %v", err),
+		}
+	}
+	if ci.Spec.DatastoreReady == nil || !*ci.Spec.DatastoreReady {
+		m.statusError("The upgrade has not been completed - not removing v1 data")
+		return MigrationError{
+			Type: ErrorGeneric,
+			Err:  errors.New("the v1 data can only be removed once the upgrade has been completed"),
+		}
+	}
+
+	if err := m.clientv1.Clean(); err != nil {
+		m.statusError("Failed to remove v1 data. Retry command.")
+		m.statusBullet("cause: %v", err)
+		return MigrationError{Type: ErrorGeneric, Err: err}
+	}
+	m.status("Removed v1 data successfully")
+	return nil
+}
+
 type policyCtrlFilterOut func(model.Key) bool
 
 var noFilter = func(_ model.Key) bool { return false }
@@ -406,7 +645,10 @@ func (m *migrationHelper) v3DatastoreIsClean() (bool, error) {
 // conversion errors - this allows a full pre-migration report to be generated in a single
 // shot.
 func (m *migrationHelper) queryAndConvertResources() (*MigrationData, error) {
-	data := &MigrationData{}
+	data := &MigrationData{
+		tags: converters.NewTagNetworkSets(),
+	}
+	deprecated := &converters.DeprecatedFields{}
 
 	// Query and convert global felix configuration and cluster info.
 	if err := m.queryAndConvertFelixConfigV1ToV3(data); err != nil {
@@ -478,7 +720,12 @@ func (m *migrationHelper) queryAndConvertResources() (*MigrationData, error) {
 		m.statusBullet("handling GlobalNetworkPolicy resources")
 		// Query and convert the Policies
 		if err := m.queryAndConvertV1ToV3Resources(
-			data, model.PolicyListOptions{}, converters.Policy{}, filterGNP,
+			data, model.PolicyListOptions{}, converters.Policy{
+				Deprecated:           deprecated,
+				AnnotateOriginalName: m.annotateOriginalPolicyNames,
+				LogActions:           m.logActions,
+				StrictTypes:          m.strictPolicyTypes,
+			}, filterGNP,
 		); err != nil {
 			return nil, err
 		}
@@ -490,7 +737,7 @@ func (m *migrationHelper) queryAndConvertResources() (*MigrationData, error) {
 		m.statusBullet("handling Profile resources")
 		// Query and convert the Profiles
 		if err := m.queryAndConvertV1ToV3Resources(
-			data, model.ProfileListOptions{}, converters.Profile{}, filterProfile,
+			data, model.ProfileListOptions{}, converters.Profile{Deprecated: deprecated, LogActions: m.logActions}, filterProfile,
 		); err != nil {
 			return nil, err
 		}
@@ -508,9 +755,79 @@ func (m *migrationHelper) queryAndConvertResources() (*MigrationData, error) {
 		}
 	}
 
+	// Query and convert the resources of the kinds with registered converters.
+	for _, dc := range converters.DatastoreConverters() {
+		m.statusBullet("handling %s resources", dc.Kind)
+		if err := m.queryAndConvertV1ToV3Resources(data, dc.List, dc.Converter, noFilter); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.clientv1.IsKDD() {
+		m.statusBullet("skipping GlobalNetworkSet resources for tags - tags are not supported")
+	} else {
+		m.statusBullet("handling GlobalNetworkSet resources for tags")
+		m.convertTagsToGlobalNetworkSets(data)
+	}
+
+	data.DeprecatedFields = deprecated.Usages
+	if m.conversionWorkers > 1 {
+		// The rules are recorded in the order in which the workers convert the resources,
+		// so sort them to make the report independent of the scheduling of the workers.
+		sort.SliceStable(data.DeprecatedFields, func(i, j int) bool {
+			ui, uj := data.DeprecatedFields[i], data.DeprecatedFields[j]
+			if ki, kj := ui.Key.String(), uj.Key.String(); ki != kj {
+				return ki < kj
+			}
+			if ui.Direction != uj.Direction {
+				return ui.Direction < uj.Direction
+			}
+			return ui.Index < uj.Index
+		})
+	}
+	if len(data.DeprecatedFields) > 0 {
+		m.statusBullet("%d rules use deprecated fields", len(data.DeprecatedFields))
+	}
+
+	// Summarize the converted resources by kind.
+	for _, r := range data.Resources {
+		data.summary(r.GetObjectKind().GroupVersionKind().Kind).Converted++
+	}
+	if len(data.TagNetworkSets) > 0 {
+		data.summary(apiv3.KindGlobalNetworkSet).Converted += len(data.TagNetworkSets)
+	}
+
 	return data, nil
 }
 
+// convertTagsToGlobalNetworkSets synthesizes a GlobalNetworkSet for each tag referenced in
+// the converted v1 policies and profiles.  The network sets are recorded in the
+// TagNetworkSets report.
+func (m *migrationHelper) convertTagsToGlobalNetworkSets(data *MigrationData) {
+	for _, tgns := range data.tags.GlobalNetworkSets() {
+		gns := tgns.GlobalNetworkSet
+		key := resourceToKey(gns)
+
+		// Check the synthesized resource validates correctly.
+		if err := validatorv3.Validate(gns); err != nil {
+			data.summary(apiv3.KindGlobalNetworkSet).Failed++
+			data.ConvertedResourceValidationErrors = append(data.ConvertedResourceValidationErrors, ConversionError{
+				KeyV3:   key,
+				ValueV3: gns,
+				Cause:   err,
+			})
+			continue
+		}
+
+		m.statusBullet("creating GlobalNetworkSet %s for tag %s", gns.Name, tgns.Tag)
+		data.TagNetworkSets = append(data.TagNetworkSets, TagNetworkSet{
+			Tag:     tgns.Tag,
+			KeyV3:   key,
+			ValueV3: gns,
+		})
+	}
+}
+
 // Query the v1 format resources and convert to the v3 format. Successfully
 // migrated resources are appended to res, and conversion errors to convErr.
 func (m *migrationHelper) queryAndConvertV1ToV3Resources(
@@ -535,8 +852,8 @@ func (m *migrationHelper) queryAndConvertV1ToV3Resources(
 	// it just in case.
 	convertedNames := make(map[string]model.Key, len(kvps))
 
-	// Pass the results through the supplied converter and check that each result
-	// validates.
+	// Filter out the resources handled by the policy controller.
+	toConvert := make([]*model.KVPair, 0, len(kvps))
 	for _, kvp := range kvps {
 		if filterOut(kvp.Key) {
 			log.Infof("Filter out Policy Controller created resource: %s", kvp.Key)
@@ -544,8 +861,20 @@ func (m *migrationHelper) queryAndConvertV1ToV3Resources(
 			continue
 		}
 
-		r, err := converter.BackendV1ToAPIV3(kvp)
+		// Track the tags and tag members for the GlobalNetworkSet conversion.
+		if data.tags != nil {
+			data.tags.Add(kvp)
+		}
+		toConvert = append(toConvert, kvp)
+	}
+
+	// Pass the results through the supplied converter and check that each result
+	// validates.
+	results := m.convertAndValidate(toConvert, converter)
+	for i, kvp := range toConvert {
+		r, err := results[i].resource, results[i].err
 		if err != nil {
+			data.summary(v3KindForConverter(converter)).Failed++
 			data.ConversionErrors = append(data.ConversionErrors, ConversionError{
 				KeyV1:   kvp.Key,
 				ValueV1: kvp.Value,
@@ -558,6 +887,25 @@ func (m *migrationHelper) queryAndConvertV1ToV3Resources(
 		// continue with additional checks so that we output as much information as possible.
 		valid := true
 		convertedName := r.GetObjectMeta().GetNamespace() + "/" + r.GetObjectMeta().GetName()
+		if k, ok := convertedNames[convertedName]; ok && m.renameNameClashes && isPolicy(r) {
+			renamed := clashSuffixedName(r.GetObjectMeta().GetName(), kvp.Key)
+			renamedName := r.GetObjectMeta().GetNamespace() + "/" + renamed
+			if _, ok := convertedNames[renamedName]; !ok {
+				log.WithFields(log.Fields{
+					"KeyV1":      kvp.Key,
+					"OtherKeyV1": k,
+					"Name":       renamed,
+				}).Info("Renaming resource to resolve name clash")
+				r.GetObjectMeta().SetName(renamed)
+				results[i].validationErr = validatorv3.Validate(r)
+				convertedName = renamedName
+				data.RenamedNameClashes = append(data.RenamedNameClashes, NameClash{
+					KeyV1:      kvp.Key,
+					KeyV3:      resourceToKey(r),
+					OtherKeyV1: k,
+				})
+			}
+		}
 		if k, ok := convertedNames[convertedName]; ok {
 			data.NameClashes = append(data.NameClashes, NameClash{
 				KeyV1:      kvp.Key,
@@ -569,7 +917,7 @@ func (m *migrationHelper) queryAndConvertV1ToV3Resources(
 		convertedNames[convertedName] = kvp.Key
 
 		// Check the converted resource validates correctly.
-		if err := validatorv3.Validate(r); err != nil {
+		if err := results[i].validationErr; err != nil {
 			data.ConvertedResourceValidationErrors = append(data.ConvertedResourceValidationErrors, ConversionError{
 				KeyV1:   kvp.Key,
 				ValueV1: kvp.Value,
@@ -581,7 +929,9 @@ func (m *migrationHelper) queryAndConvertV1ToV3Resources(
 		}
 
 		// Only store the resource and the converted name if it's valid.
-		if valid {
+		if !valid {
+			data.summary(r.GetObjectKind().GroupVersionKind().Kind).Failed++
+		} else {
 			data.Resources = append(data.Resources, r)
 			data.NameConversions = append(data.NameConversions, NameConversion{
 				KeyV1: kvp.Key,
@@ -593,6 +943,92 @@ func (m *migrationHelper) queryAndConvertV1ToV3Resources(
 	return nil
 }
 
+// conversionResult is the result of converting and validating a single v1 KVPair.
+type conversionResult struct {
+	resource      converters.Resource
+	err           error
+	validationErr error
+}
+
+// convertAndValidate converts the v1 KVPairs using the converter and validates the converted
+// resources, returning the results in the same order as the KVPairs.  The KVPairs are
+// converted concurrently if more than one conversion worker is configured.
+func (m *migrationHelper) convertAndValidate(kvps []*model.KVPair, converter converters.Converter) []conversionResult {
+	results := make([]conversionResult, len(kvps))
+	convert := func(i int) {
+		res := &results[i]
+		if res.resource, res.err = converter.BackendV1ToAPIV3(kvps[i]); res.err == nil {
+			m.creationTimestamps.Apply(kvps[i].Key, res.resource)
+			res.validationErr = validatorv3.Validate(res.resource)
+		}
+	}
+
+	if m.conversionWorkers <= 1 {
+		for i := range kvps {
+			convert(i)
+		}
+		return results
+	}
+
+	// Each KVPair is converted by a single worker which writes only its own result, so the
+	// results do not need to be locked.
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < m.conversionWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				convert(i)
+			}
+		}()
+	}
+	for i := range kvps {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return results
+}
+
+// isPolicy returns true if the resource is a GlobalNetworkPolicy or NetworkPolicy.
+func isPolicy(r converters.Resource) bool {
+	switch r.GetObjectKind().GroupVersionKind().Kind {
+	case apiv3.KindGlobalNetworkPolicy, apiv3.KindNetworkPolicy:
+		return true
+	}
+	return false
+}
+
+// clashSuffixedName returns the name with a suffix calculated from the v1 key appended, used
+// to rename a resource whose converted name clashes with that of another resource.
+func clashSuffixedName(name string, keyV1 model.Key) string {
+	h := sha1.Sum([]byte(keyV1.String()))
+	return fmt.Sprintf("%s-%s", name, hex.EncodeToString(h[:])[:8])
+}
+
+// v3KindForConverter returns the v3 kind of the resources produced by the converter.  This
+// is used to summarize the v1 resources that could not be converted.
+func v3KindForConverter(converter converters.Converter) string {
+	switch converter.(type) {
+	case converters.BGPPeer:
+		return apiv3.KindBGPPeer
+	case converters.HostEndpoint:
+		return apiv3.KindHostEndpoint
+	case converters.IPPool:
+		return apiv3.KindIPPool
+	case converters.Node:
+		return apiv3.KindNode
+	case converters.Policy:
+		return apiv3.KindGlobalNetworkPolicy
+	case converters.Profile:
+		return apiv3.KindProfile
+	case converters.WorkloadEndpoint:
+		return apiv3.KindWorkloadEndpoint
+	}
+	return reflect.TypeOf(converter).Name()
+}
+
 func (m *migrationHelper) queryAndConvertGlobalBGPConfigV1ToV3(data *MigrationData) error {
 	globalBGPConfig := apiv3.NewBGPConfiguration()
 	globalBGPConfig.Name = "default"
@@ -641,6 +1077,27 @@ func (m *migrationHelper) queryAndConvertGlobalBGPConfigV1ToV3(data *MigrationDa
 	if setValue {
 		data.Resources = append(data.Resources, globalBGPConfig)
 	}
+	return m.recordUnknownGlobalBGPConfig(data)
+}
+
+// recordUnknownGlobalBGPConfig adds the global BGP configuration that is not converted to the
+// v3 BGPConfiguration to the unknown config.
+func (m *migrationHelper) recordUnknownGlobalBGPConfig(data *MigrationData) error {
+	kvps, err := m.clientv1.List(model.GlobalBGPConfigListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, kvp := range kvps {
+		switch kvp.Key.(model.GlobalBGPConfigKey).Name {
+		case "AsNumber", "LogLevel", "NodeMeshEnabled":
+		default:
+			log.WithField("Key", kvp.Key).Info("Global BGP config is not migrated")
+			data.UnknownConfig = append(data.UnknownConfig, UnknownConfig{
+				KeyV1:   kvp.Key,
+				ValueV1: kvp.Value,
+			})
+		}
+	}
 	return nil
 }
 
@@ -1007,7 +1464,10 @@ func resourceToKey(r converters.Resource) model.Key {
 	}
 }
 
-// storeV3Resources stores the converted resources in the v3 datastore.
+// storeV3Resources stores the converted resources in the v3 datastore.  Resources that are
+// already stored with the same contents are not rewritten, so this is safe to re-run after
+// a partial failure.  The number of resources written and unchanged are recorded in the
+// summary.
 func (m *migrationHelper) storeV3Resources(data *MigrationData) error {
 	m.statusBullet("Storing resources in v3 format")
 	for n, r := range data.Resources {
@@ -1016,24 +1476,147 @@ func (m *migrationHelper) storeV3Resources(data *MigrationData) error {
 		// processing. Since we are applying directly to the backend we need to set the UUID
 		// and creation timestamp which is normally handled by clientv3.
 		r = toStorage(r)
-		if err := m.applyToBackend(&model.KVPair{
+		written, err := m.applyToBackend(&model.KVPair{
 			Key:   resourceToKey(r),
 			Value: r,
-		}); err != nil {
+		})
+		if err != nil {
 			return err
 		}
+		data.recordStored(r.GetObjectKind().GroupVersionKind().Kind, written)
 
 		if (n+1)%numAppliesPerUpdate == 0 {
 			m.statusBullet("applied %d resources", (n + 1))
 		}
 	}
+	for _, tns := range data.TagNetworkSets {
+		r := toStorage(tns.ValueV3)
+		written, err := m.applyToBackend(&model.KVPair{
+			Key:   tns.KeyV3,
+			Value: r,
+		})
+		if err != nil {
+			return err
+		}
+		data.recordStored(apiv3.KindGlobalNetworkSet, written)
+	}
 	m.statusBullet("success: resources stored in v3 datastore")
 	return nil
 }
 
+// verifyV3Resources reads back each of the stored resources from the v3 datastore and compares
+// the labels, annotations and each field of the spec with the converted resource, recording
+// any differences in the VerificationDiscrepancies.  The differences are reported but do not
+// fail the migration, since they may be due to defaulting by the datastore.
+func (m *migrationHelper) verifyV3Resources(data *MigrationData) error {
+	bc := m.clientv3.(backendClientAccessor).Backend()
+	verify := func(key model.Key, expected converters.Resource) error {
+		kvp, err := bc.Get(context.Background(), key, "")
+		if err != nil {
+			return err
+		}
+		stored, ok := kvp.Value.(converters.Resource)
+		if !ok {
+			return fmt.Errorf("stored value for %s is not a valid resource", key)
+		}
+		data.VerificationDiscrepancies = append(data.VerificationDiscrepancies,
+			resourceDiscrepancies(key, expected, stored)...)
+		return nil
+	}
+
+	for _, r := range data.Resources {
+		if err := verify(resourceToKey(r), r); err != nil {
+			return err
+		}
+	}
+	for _, tns := range data.TagNetworkSets {
+		if err := verify(tns.KeyV3, tns.ValueV3); err != nil {
+			return err
+		}
+	}
+
+	if len(data.VerificationDiscrepancies) == 0 {
+		m.statusBullet("success: stored resources match the converted resources")
+		return nil
+	}
+	m.statusError("%d fields of the stored resources differ from the converted resources", len(data.VerificationDiscrepancies))
+	for _, d := range data.VerificationDiscrepancies {
+		m.statusBullet("%s %s: converted %v, stored %v", d.KeyV3, d.Field, d.Expected, d.Stored)
+	}
+	return nil
+}
+
+// resourceDiscrepancies compares the labels, annotations and each field of the spec of the
+// expected and stored resources, returning the fields that differ.  The remaining metadata is
+// filled in by the datastore and so is not compared.
+func resourceDiscrepancies(key model.Key, expected, stored converters.Resource) []VerificationDiscrepancy {
+	var ds []VerificationDiscrepancy
+	compare := func(field string, e, s interface{}) {
+		if !reflect.DeepEqual(e, s) && !(isEmpty(e) && isEmpty(s)) {
+			ds = append(ds, VerificationDiscrepancy{KeyV3: key, Field: field, Expected: e, Stored: s})
+		}
+	}
+	compare("Metadata.Labels", expected.GetObjectMeta().GetLabels(), stored.GetObjectMeta().GetLabels())
+	compare("Metadata.Annotations", expected.GetObjectMeta().GetAnnotations(), stored.GetObjectMeta().GetAnnotations())
+
+	es := reflect.Indirect(reflect.ValueOf(expected)).FieldByName("Spec")
+	ss := reflect.Indirect(reflect.ValueOf(stored)).FieldByName("Spec")
+	if !es.IsValid() || !ss.IsValid() || es.Type() != ss.Type() || es.Kind() != reflect.Struct {
+		return ds
+	}
+	for i := 0; i < es.NumField(); i++ {
+		if f := es.Type().Field(i); f.PkgPath == "" {
+			compare("Spec."+f.Name, es.Field(i).Interface(), ss.Field(i).Interface())
+		}
+	}
+	return ds
+}
+
+// isEmpty returns true if the value is nil, or is an empty slice or map.  A nil and an empty
+// slice or map are not distinguished once stored, so they are treated as equal.
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// reportSummary outputs the per-kind summary of the converted and stored resources.
+func (m *migrationHelper) reportSummary(data *MigrationData) {
+	kinds := []string{}
+	for kind := range data.Summary {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		s := data.Summary[kind]
+		m.statusBullet("%s: %d converted, %d failed, %d written, %d unchanged",
+			kind, s.Converted, s.Failed, s.Written, s.Unchanged)
+	}
+}
+
+// recordStored records in the summary whether a resource of the v3 kind was written or was
+// unchanged.
+func (c *MigrationData) recordStored(kind string, written bool) {
+	if written {
+		c.summary(kind).Written++
+	} else {
+		c.summary(kind).Unchanged++
+	}
+}
+
 func toStorage(r converters.Resource) converters.Resource {
-	// Set timestamp and UID.
-	r.GetObjectMeta().SetCreationTimestamp(metav1.Now())
+	// Set timestamp, unless carried from the v1 resource, and UID.
+	if ts := r.GetObjectMeta().GetCreationTimestamp(); ts.IsZero() {
+		r.GetObjectMeta().SetCreationTimestamp(metav1.Now())
+	}
 	r.GetObjectMeta().SetUID(uuid.NewUUID())
 
 	// Some types require additional processing when converting to storage.
@@ -1117,7 +1700,7 @@ func (m *migrationHelper) migrateIPAMData() error {
 	// Create/Apply the converted entries into the v3 datastore.
 	m.statusBullet("storing IPAM data in v3 format")
 	for _, kvp := range kvpsv3 {
-		if err := m.applyToBackend(kvp); err != nil {
+		if _, err := m.applyToBackend(kvp); err != nil {
 			m.statusError("Error writing IPAM data to v3 datastore")
 			return fmt.Errorf("error storing converted IPAM data: %v", err)
 		}
@@ -1133,8 +1716,9 @@ type backendClientAccessor interface {
 	Backend() bapi.Client
 }
 
-// applyToBackend applies the supplied KVPair directly to the backend datastore.
-func (m *migrationHelper) applyToBackend(kvp *model.KVPair) error {
+// applyToBackend applies the supplied KVPair directly to the backend datastore.  Returns
+// false if the resource was already stored with the same contents and so was not written.
+func (m *migrationHelper) applyToBackend(kvp *model.KVPair) (bool, error) {
 	// Extract the backend client API from the v3 client.
 	bc := m.clientv3.(backendClientAccessor).Backend()
 
@@ -1145,11 +1729,11 @@ func (m *migrationHelper) applyToBackend(kvp *model.KVPair) error {
 	_, err := bc.Create(context.Background(), kvp)
 	if err == nil {
 		logCxt.Debug("Resource created")
-		return nil
+		return true, nil
 	}
 	if _, ok := err.(cerrors.ErrorResourceAlreadyExists); !ok {
 		logCxt.WithError(err).Info("Failed to create resource")
-		return err
+		return false, err
 	}
 
 	logCxt.Debug("Resource already exists, try update")
@@ -1159,14 +1743,18 @@ func (m *migrationHelper) applyToBackend(kvp *model.KVPair) error {
 		logCxt.Debug("Attempting to update resource")
 		current, err := bc.Get(context.Background(), kvp.Key, "")
 		if err != nil {
-			return err
+			return false, err
+		}
+		if isUnchanged(current.Value, kvp.Value) {
+			logCxt.Debug("Resource is unchanged, skipping update")
+			return false, nil
 		}
 		kvp.Revision = current.Revision
 
 		_, err = bc.Update(context.Background(), kvp)
 		if err == nil {
 			logCxt.Debug("Resource updated")
-			return nil
+			return true, nil
 		}
 		if _, ok := err.(cerrors.ErrorResourceUpdateConflict); !ok {
 			break
@@ -1178,5 +1766,26 @@ func (m *migrationHelper) applyToBackend(kvp *model.KVPair) error {
 	}
 
 	logCxt.WithError(err).Info("Failed to update resource")
-	return err
+	return false, err
+}
+
+// isUnchanged returns true if the stored value has the same contents as the value being
+// applied.  For resources, only the labels, annotations and spec are compared since the
+// remaining metadata is filled in by the datastore.
+func isUnchanged(current, applying interface{}) bool {
+	cr, ok1 := current.(converters.Resource)
+	ar, ok2 := applying.(converters.Resource)
+	if !ok1 || !ok2 {
+		return reflect.DeepEqual(current, applying)
+	}
+	if !reflect.DeepEqual(cr.GetObjectMeta().GetLabels(), ar.GetObjectMeta().GetLabels()) ||
+		!reflect.DeepEqual(cr.GetObjectMeta().GetAnnotations(), ar.GetObjectMeta().GetAnnotations()) {
+		return false
+	}
+	cs := reflect.Indirect(reflect.ValueOf(cr)).FieldByName("Spec")
+	as := reflect.Indirect(reflect.ValueOf(ar)).FieldByName("Spec")
+	if !cs.IsValid() || !as.IsValid() {
+		return false
+	}
+	return reflect.DeepEqual(cs.Interface(), as.Interface())
 }
@@ -17,13 +17,16 @@ package migrator
 import (
 	"context"
 	"errors"
+	"fmt"
 	gnet "net"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	"github.com/projectcalico/libcalico-go/lib/apiconfig"
+	"github.com/projectcalico/libcalico-go/lib/apis/v1/unversioned"
 	"github.com/projectcalico/libcalico-go/lib/apis/v3"
 	"github.com/projectcalico/libcalico-go/lib/backend"
 	"github.com/projectcalico/libcalico-go/lib/backend/model"
@@ -173,6 +176,351 @@ var _ = Describe("Test OpenStack migration filters", func() {
 	})
 })
 
+var _ = Describe("Test tag GlobalNetworkSet conversion", func() {
+	It("should create a single GlobalNetworkSet for a tag shared by two policies", func() {
+		clientv1 := fakeClientV1{
+			kvps: []*model.KVPair{
+				{
+					Key: model.PolicyKey{Name: "policy1"},
+					Value: &model.Policy{
+						InboundRules: []model.Rule{{Action: "allow", SrcTag: "tag1"}},
+						Selector:     "all()",
+					},
+				},
+				{
+					Key: model.PolicyKey{Name: "policy2"},
+					Value: &model.Policy{
+						InboundRules: []model.Rule{{Action: "allow", SrcTag: "tag1"}},
+						Selector:     "all()",
+					},
+				},
+			},
+		}
+
+		mh := &migrationHelper{clientv1: clientv1}
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.HasErrors()).To(BeFalse())
+		Expect(data.Resources).To(HaveLen(2))
+		Expect(data.TagNetworkSets).To(HaveLen(1))
+		Expect(data.TagNetworkSets[0].Tag).To(Equal("tag1"))
+		Expect(data.TagNetworkSets[0].KeyV3).To(Equal(model.ResourceKey{
+			Kind: v3.KindGlobalNetworkSet,
+			Name: "tag-tag1",
+		}))
+		Expect(data.TagNetworkSets[0].ValueV3.Labels).To(Equal(map[string]string{"tag1": ""}))
+	})
+})
+
+// networkSetConverter converts v1 NetworkSets to v3 GlobalNetworkSets.
+type networkSetConverter struct{}
+
+func (_ networkSetConverter) APIV1ToBackendV1(r unversioned.Resource) (*model.KVPair, error) {
+	return nil, errors.New("NetworkSets have no v1 API")
+}
+
+func (_ networkSetConverter) BackendV1ToAPIV3(kvp *model.KVPair) (converters.Resource, error) {
+	gns := v3.NewGlobalNetworkSet()
+	gns.Name = kvp.Key.(model.NetworkSetKey).Name
+	gns.Labels = kvp.Value.(*model.NetworkSet).Labels
+	for _, n := range kvp.Value.(*model.NetworkSet).Nets {
+		gns.Spec.Nets = append(gns.Spec.Nets, n.String())
+	}
+	return gns, nil
+}
+
+var _ = Describe("Test registered datastore converters", func() {
+	It("should convert the resources listed for a registered converter", func() {
+		converters.RegisterDatastoreConverter("NetworkSet", model.NetworkSetListOptions{}, networkSetConverter{})
+
+		clientv1 := fakeClientV1{
+			kvps: []*model.KVPair{
+				{
+					Key: model.NetworkSetKey{Name: "netset1"},
+					Value: &model.NetworkSet{
+						Nets:   []net.IPNet{net.MustParseCIDR("10.0.0.0/24")},
+						Labels: map[string]string{"label1": "value1"},
+					},
+				},
+			},
+		}
+
+		mh := &migrationHelper{clientv1: clientv1}
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.HasErrors()).To(BeFalse())
+		Expect(data.Resources).To(HaveLen(1))
+		gns := data.Resources[0].(*v3.GlobalNetworkSet)
+		Expect(gns.Name).To(Equal("netset1"))
+		Expect(gns.Spec.Nets).To(Equal([]string{"10.0.0.0/24"}))
+		Expect(data.NameConversions).To(Equal([]NameConversion{{
+			KeyV1: model.NetworkSetKey{Name: "netset1"},
+			KeyV3: model.ResourceKey{Kind: v3.KindGlobalNetworkSet, Name: "netset1"},
+		}}))
+	})
+})
+
+var _ = Describe("Test original policy name annotations", func() {
+	clientv1 := fakeClientV1{
+		kvps: []*model.KVPair{
+			{
+				Key:   model.PolicyKey{Name: "MaKe.-.MaKe"},
+				Value: &model.Policy{Selector: "all()"},
+			},
+		},
+	}
+	v3Key := model.ResourceKey{Kind: v3.KindGlobalNetworkPolicy, Name: "make-make-1b6971c8"}
+
+	It("should annotate the converted policy and report the name mapping", func() {
+		mh := New(nil, clientv1, nil, WithOriginalPolicyNameAnnotations()).(*migrationHelper)
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.HasErrors()).To(BeFalse())
+		Expect(data.Resources).To(HaveLen(1))
+		Expect(data.Resources[0].GetObjectMeta().GetAnnotations()).To(Equal(map[string]string{
+			converters.AnnotationV1Name: "MaKe.-.MaKe",
+		}))
+		Expect(data.NameConversions).To(ContainElement(NameConversion{
+			KeyV1: model.PolicyKey{Name: "MaKe.-.MaKe"},
+			KeyV3: v3Key,
+		}))
+	})
+
+	It("should not annotate the converted policy by default", func() {
+		mh := &migrationHelper{clientv1: clientv1}
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Resources).To(HaveLen(1))
+		Expect(data.Resources[0].GetObjectMeta().GetAnnotations()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Test original creation timestamps", func() {
+	clientv1 := fakeClientV1{
+		kvps: []*model.KVPair{
+			{
+				Key:   model.PolicyKey{Name: "policy1"},
+				Value: &model.Policy{Selector: "all()"},
+			},
+		},
+	}
+	created := time.Date(2017, 6, 1, 12, 30, 0, 0, time.UTC)
+
+	It("should carry the v1 creation time into the stored resource", func() {
+		mh := New(nil, clientv1, nil, WithCreationTimestamps(&converters.CreationTimestamps{
+			Times: map[string]time.Time{model.PolicyKey{Name: "policy1"}.String(): created},
+		})).(*migrationHelper)
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Resources).To(HaveLen(1))
+		r := toStorage(data.Resources[0])
+		Expect(r.GetObjectMeta().GetCreationTimestamp().Time.Equal(created)).To(BeTrue())
+	})
+
+	It("should use the time of the migration by default", func() {
+		mh := &migrationHelper{clientv1: clientv1}
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Resources).To(HaveLen(1))
+		r := toStorage(data.Resources[0])
+		Expect(r.GetObjectMeta().GetCreationTimestamp().Time.After(created)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Test concurrent conversion", func() {
+	srcNet := net.MustParseCIDR("10.0.0.0/24")
+	kvps := []*model.KVPair{
+		// Two policies whose converted names clash.
+		{
+			Key:   model.PolicyKey{Name: "make-make-1b6971c8"},
+			Value: &model.Policy{Selector: "all()"},
+		},
+		{
+			Key:   model.PolicyKey{Name: "MaKe.-.MaKe"},
+			Value: &model.Policy{Selector: "all()"},
+		},
+		// A policy that fails to convert.
+		{
+			Key: model.PolicyKey{Name: "bad-action"},
+			Value: &model.Policy{
+				Selector:     "all()",
+				InboundRules: []model.Rule{{Action: "foo"}},
+			},
+		},
+	}
+	for i := 0; i < 20; i++ {
+		kvps = append(kvps,
+			&model.KVPair{
+				Key: model.PolicyKey{Name: fmt.Sprintf("policy-%d", i)},
+				Value: &model.Policy{
+					Selector:     "all()",
+					InboundRules: []model.Rule{{Action: "allow", SrcTag: "tag1", SrcNet: &srcNet}},
+				},
+			},
+			&model.KVPair{
+				Key: model.ProfileKey{Name: fmt.Sprintf("profile-%d", i)},
+				Value: &model.Profile{
+					Rules: model.ProfileRules{
+						OutboundRules: []model.Rule{{Action: "deny", SrcNet: &srcNet}},
+					},
+				},
+			},
+		)
+	}
+	clientv1 := fakeClientV1{kvps: kvps}
+
+	It("should convert the same resources as a serial conversion", func() {
+		serial, err := (&migrationHelper{clientv1: clientv1}).queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serial.Resources).To(HaveLen(41))
+		Expect(serial.ConversionErrors).To(HaveLen(1))
+		Expect(serial.NameClashes).To(HaveLen(1))
+		Expect(serial.DeprecatedFields).To(HaveLen(40))
+
+		mh := New(nil, clientv1, nil, WithConversionWorkers(4)).(*migrationHelper)
+		concurrent, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(concurrent.Resources).To(Equal(serial.Resources))
+		Expect(concurrent.NameConversions).To(Equal(serial.NameConversions))
+		Expect(concurrent.ConversionErrors).To(Equal(serial.ConversionErrors))
+		Expect(concurrent.ConvertedResourceValidationErrors).To(Equal(serial.ConvertedResourceValidationErrors))
+		Expect(concurrent.NameClashes).To(Equal(serial.NameClashes))
+		Expect(concurrent.TagNetworkSets).To(Equal(serial.TagNetworkSets))
+		Expect(concurrent.Summary).To(Equal(serial.Summary))
+		Expect(concurrent.DeprecatedFields).To(ConsistOf(serial.DeprecatedFields))
+	})
+})
+
+var _ = Describe("Test converted name clashes", func() {
+	// The second policy name is normalized and qualified to the name of the first.
+	clientv1 := fakeClientV1{
+		kvps: []*model.KVPair{
+			{
+				Key:   model.PolicyKey{Name: "make-make-1b6971c8"},
+				Value: &model.Policy{Selector: "all()"},
+			},
+			{
+				Key:   model.PolicyKey{Name: "MaKe.-.MaKe"},
+				Value: &model.Policy{Selector: "all()"},
+			},
+		},
+	}
+
+	It("should report the clashing resources", func() {
+		mh := &migrationHelper{clientv1: clientv1}
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.HasErrors()).To(BeTrue())
+		Expect(data.NameClashes).To(Equal([]NameClash{{
+			KeyV1:      model.PolicyKey{Name: "MaKe.-.MaKe"},
+			KeyV3:      model.ResourceKey{Kind: v3.KindGlobalNetworkPolicy, Name: "make-make-1b6971c8"},
+			OtherKeyV1: model.PolicyKey{Name: "make-make-1b6971c8"},
+		}}))
+		Expect(data.Resources).To(HaveLen(1))
+	})
+
+	It("should rename the clashing resource when renaming is enabled", func() {
+		mh := New(nil, clientv1, nil, WithNameClashRenaming()).(*migrationHelper)
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.HasErrors()).To(BeFalse())
+		Expect(data.NameClashes).To(BeEmpty())
+
+		renamed := clashSuffixedName("make-make-1b6971c8", model.PolicyKey{Name: "MaKe.-.MaKe"})
+		Expect(data.RenamedNameClashes).To(Equal([]NameClash{{
+			KeyV1:      model.PolicyKey{Name: "MaKe.-.MaKe"},
+			KeyV3:      model.ResourceKey{Kind: v3.KindGlobalNetworkPolicy, Name: renamed},
+			OtherKeyV1: model.PolicyKey{Name: "make-make-1b6971c8"},
+		}}))
+		Expect(data.Resources).To(HaveLen(2))
+		Expect(data.Resources[0].GetObjectMeta().GetName()).To(Equal("make-make-1b6971c8"))
+		Expect(data.Resources[1].GetObjectMeta().GetName()).To(Equal(renamed))
+
+		By("Checking the rename is deterministic")
+		Expect(renamed).To(Equal(clashSuffixedName("make-make-1b6971c8", model.PolicyKey{Name: "MaKe.-.MaKe"})))
+		Expect(renamed).To(MatchRegexp("^make-make-1b6971c8-[0-9a-f]{8}$"))
+	})
+
+	It("should not rename clashing resources that are referenced by name", func() {
+		// The name of the first profile is the converted name of the second.
+		clashing := &model.KVPair{Key: model.ProfileKey{Name: "MaKe.-.MaKe"}, Value: &model.Profile{}}
+		r, err := converters.Profile{}.BackendV1ToAPIV3(clashing)
+		Expect(err).NotTo(HaveOccurred())
+		name := r.GetObjectMeta().GetName()
+		profiles := fakeClientV1{
+			kvps: []*model.KVPair{
+				{Key: model.ProfileKey{Name: name}, Value: &model.Profile{}},
+				clashing,
+			},
+		}
+
+		mh := New(nil, profiles, nil, WithNameClashRenaming()).(*migrationHelper)
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.RenamedNameClashes).To(BeEmpty())
+		Expect(data.NameClashes).To(Equal([]NameClash{{
+			KeyV1:      model.ProfileKey{Name: "MaKe.-.MaKe"},
+			KeyV3:      model.ResourceKey{Kind: v3.KindProfile, Name: name},
+			OtherKeyV1: model.ProfileKey{Name: name},
+		}}))
+	})
+})
+
+var summaryKVPs = []*model.KVPair{
+	{
+		Key: model.IPPoolKey{CIDR: net.MustParseCIDR("10.0.0.0/16")},
+		Value: &model.IPPool{
+			CIDR: net.MustParseCIDR("10.0.0.0/16"),
+		},
+	},
+	{
+		Key:   model.IPPoolKey{CIDR: net.MustParseCIDR("10.1.0.0/16")},
+		Value: "not an IPPool",
+	},
+	{
+		Key: model.GlobalBGPPeerKey{PeerIP: net.MustParseIP("192.168.0.1")},
+		Value: &model.BGPPeer{
+			PeerIP: net.MustParseIP("192.168.0.1"),
+			ASNum:  64512,
+		},
+	},
+}
+
+var _ = Describe("Test conversion summary", func() {
+	It("should count the converted and failed resources by kind", func() {
+		mh := &migrationHelper{clientv1: fakeClientV1{kvps: summaryKVPs}}
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.ConversionErrors).To(HaveLen(1))
+		Expect(data.Summary).To(HaveKeyWithValue(v3.KindIPPool, &KindSummary{Converted: 1, Failed: 1}))
+		Expect(data.Summary).To(HaveKeyWithValue(v3.KindBGPPeer, &KindSummary{Converted: 1}))
+	})
+})
+
+var _ = Describe("Test stored resource verification", func() {
+	It("should report the fields that differ from the converted resource", func() {
+		key := model.ResourceKey{Kind: v3.KindIPPool, Name: "10-0-0-0-16"}
+		expected := v3.NewIPPool()
+		expected.Name = "10-0-0-0-16"
+		expected.Labels = map[string]string{}
+		expected.Spec = v3.IPPoolSpec{CIDR: "10.0.0.0/16", IPIPMode: v3.IPIPModeNever, NATOutgoing: true}
+
+		By("Comparing with an identical resource whose empty labels were not stored")
+		stored := expected.DeepCopy()
+		stored.Labels = nil
+		Expect(resourceDiscrepancies(key, expected, stored)).To(BeEmpty())
+
+		By("Comparing with a resource with a different spec field")
+		stored.Spec.NATOutgoing = false
+		Expect(resourceDiscrepancies(key, expected, stored)).To(Equal([]VerificationDiscrepancy{{
+			KeyV3:    key,
+			Field:    "Spec.NATOutgoing",
+			Expected: true,
+			Stored:   false,
+		}}))
+	})
+})
+
 var _ = testutils.E2eDatastoreDescribe("Migration tests", testutils.DatastoreEtcdV3, func(config apiconfig.CalicoAPIConfig) {
 
 	ctx := context.Background()
@@ -255,4 +603,115 @@ var _ = testutils.E2eDatastoreDescribe("Migration tests", testutils.DatastoreEtc
 			errors.New("unable to migrate data from version '': unable to parse the version")),
 		Entry("v3 only calico version (blank)", nil, &blank, false),
 	)
+
+	It("should not rewrite unchanged resources when storing the converted data again", func() {
+		v3Client, err := clientv3.New(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		be, err := backend.NewClient(config)
+		Expect(err).NotTo(HaveOccurred())
+		be.Clean()
+
+		mh := &migrationHelper{clientv1: fakeClientV1{kvps: summaryKVPs}, clientv3: v3Client}
+
+		By("Converting and storing the v1 data")
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		err = mh.storeV3Resources(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Summary).To(HaveKeyWithValue(v3.KindIPPool, &KindSummary{Converted: 1, Failed: 1, Written: 1}))
+		Expect(data.Summary).To(HaveKeyWithValue(v3.KindBGPPeer, &KindSummary{Converted: 1, Written: 1}))
+
+		By("Converting and storing the v1 data a second time")
+		data, err = mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		err = mh.storeV3Resources(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.Summary).To(HaveKeyWithValue(v3.KindIPPool, &KindSummary{Converted: 1, Failed: 1, Unchanged: 1}))
+		Expect(data.Summary).To(HaveKeyWithValue(v3.KindBGPPeer, &KindSummary{Converted: 1, Unchanged: 1}))
+
+		pools, err := v3Client.IPPools().List(ctx, options.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pools.Items).To(HaveLen(1))
+	})
+
+	It("should report a stored resource that differs from the converted resource", func() {
+		v3Client, err := clientv3.New(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		be, err := backend.NewClient(config)
+		Expect(err).NotTo(HaveOccurred())
+		be.Clean()
+
+		mh := &migrationHelper{clientv1: fakeClientV1{kvps: summaryKVPs}, clientv3: v3Client}
+
+		By("Converting, storing and verifying the v1 data")
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mh.storeV3Resources(data)).To(Succeed())
+		Expect(mh.verifyV3Resources(data)).To(Succeed())
+		Expect(data.VerificationDiscrepancies).To(BeEmpty())
+
+		By("Modifying the converted IPPool after it was stored and verifying again")
+		var pool *v3.IPPool
+		for _, r := range data.Resources {
+			if p, ok := r.(*v3.IPPool); ok {
+				pool = p
+			}
+		}
+		Expect(pool).NotTo(BeNil())
+		pool.Spec.NATOutgoing = true
+		Expect(mh.verifyV3Resources(data)).To(Succeed())
+		Expect(data.VerificationDiscrepancies).To(Equal([]VerificationDiscrepancy{{
+			KeyV3:    model.ResourceKey{Kind: v3.KindIPPool, Name: pool.Name},
+			Field:    "Spec.NATOutgoing",
+			Expected: true,
+			Stored:   false,
+		}}))
+	})
+
+	It("should preserve the v1 data after migration and only remove it once the upgrade is complete", func() {
+		v3Client, err := clientv3.New(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		be, err := backend.NewClient(config)
+		Expect(err).NotTo(HaveOccurred())
+		be.Clean()
+
+		cleaned := false
+		v1Client := fakeClientV1{kvps: summaryKVPs, cleaned: &cleaned}
+		mh := &migrationHelper{clientv1: v1Client, clientv3: v3Client}
+
+		By("Converting and storing the v1 data")
+		data, err := mh.queryAndConvertResources()
+		Expect(err).NotTo(HaveOccurred())
+		err = mh.storeV3Resources(data)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Checking the v1 keys still resolve")
+		for _, kvp := range summaryKVPs {
+			_, err := v1Client.Get(kvp.Key)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		By("Attempting to remove the v1 data before the upgrade is complete")
+		err = mh.RemoveV1Data()
+		Expect(err).To(HaveOccurred())
+		Expect(cleaned).To(BeFalse())
+
+		By("Completing the upgrade and removing the v1 data")
+		err = mh.setReadyV3(true)
+		Expect(err).NotTo(HaveOccurred())
+		err = mh.RemoveV1Data()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cleaned).To(BeTrue())
+	})
+
+	It("should not remove the v1 data for KDD", func() {
+		cleaned := false
+		mh := &migrationHelper{clientv1: fakeClientV1{kdd: true, cleaned: &cleaned}}
+		err := mh.RemoveV1Data()
+		Expect(err).To(HaveOccurred())
+		Expect(cleaned).To(BeFalse())
+	})
 })
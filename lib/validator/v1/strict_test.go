@@ -0,0 +1,68 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1_test
+
+import (
+	validator "github.com/projectcalico/libcalico-go/lib/validator/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	api "github.com/projectcalico/libcalico-go/lib/apis/v1"
+	"github.com/projectcalico/libcalico-go/lib/errors"
+	"github.com/projectcalico/libcalico-go/lib/net"
+	"github.com/projectcalico/libcalico-go/lib/numorstring"
+)
+
+var _ = Describe("Test ValidateStrict function", func() {
+	netv4 := net.MustParseNetwork("10.0.0.0/24")
+
+	It("should warn but not fail for a rule using Net in non-strict mode", func() {
+		rule := api.Rule{Action: "allow", Source: api.EntityRule{Net: &netv4}}
+		Expect(validator.Validate(rule)).NotTo(HaveOccurred())
+	})
+
+	It("should reject a rule using Net in strict mode", func() {
+		rule := api.Rule{Action: "allow", Source: api.EntityRule{Net: &netv4}}
+		err := validator.ValidateStrict(rule)
+		Expect(err).To(HaveOccurred())
+		verr, ok := err.(errors.ErrorValidation)
+		Expect(ok).To(BeTrue())
+		Expect(verr.ErroredFields).To(HaveLen(1))
+		Expect(verr.ErroredFields[0].Reason).To(Equal("Net and NotNet are deprecated, use Nets and NotNets instead"))
+	})
+
+	It("should reject a policy with a rule using NotNet in strict mode", func() {
+		policy := api.NewPolicy()
+		policy.Metadata.Name = "policy1"
+		policy.Spec.EgressRules = []api.Rule{
+			{Action: "allow", Destination: api.EntityRule{NotNet: &netv4}},
+		}
+		Expect(validator.Validate(policy)).NotTo(HaveOccurred())
+		Expect(validator.ValidateStrict(policy)).To(HaveOccurred())
+	})
+
+	It("should accept a rule using Nets in strict mode", func() {
+		rule := api.Rule{Action: "allow", Source: api.EntityRule{Nets: []*net.IPNet{&netv4}}}
+		Expect(validator.ValidateStrict(rule)).NotTo(HaveOccurred())
+	})
+
+	It("should still apply the standard rule validation in strict mode", func() {
+		rule := api.Rule{
+			Action:      "allow",
+			Destination: api.EntityRule{Ports: []numorstring.Port{numorstring.SinglePort(80)}},
+		}
+		Expect(validator.ValidateStrict(rule)).To(HaveOccurred())
+	})
+})
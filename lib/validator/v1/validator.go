@@ -37,6 +37,10 @@ import (
 
 var validate *validator.Validate
 
+// validateStrict performs the same validation as validate, but additionally rejects the use
+// of deprecated fields.
+var validateStrict *validator.Validate
+
 var (
 	labelRegex          = regexp.MustCompile(`^` + tokenizer.LabelKeyMatcher + `$`)
 	labelValueRegex     = regexp.MustCompile("^[a-zA-Z0-9]?([a-zA-Z0-9_.-]{0,61}[a-zA-Z0-9])?$")
@@ -53,7 +57,12 @@ var (
 	poolUnstictCIDR     = "IP pool CIDR is not strictly masked"
 	overlapsV4LinkLocal = "IP pool range overlaps with IPv4 Link Local range 169.254.0.0/16"
 	overlapsV6LinkLocal = "IP pool range overlaps with IPv6 Link Local range fe80::/10"
+	reservedNotInPool   = "IP pool reserved CIDR is not within the pool CIDR"
 	protocolPortsMsg    = "rules that specify ports must set protocol to TCP or UDP"
+	deprecatedNetMsg    = "Net and NotNet are deprecated, use Nets and NotNets instead"
+
+	// Annotations with this prefix are reserved for use by Calico.
+	reservedAnnotationPrefix = "projectcalico.org/"
 
 	ipv4LinkLocalNet = net.IPNet{
 		IP:   net.ParseIP("169.254.0.0"),
@@ -69,7 +78,19 @@ var (
 // Validate is used to validate the supplied structure according to the
 // registered field and structure validators.
 func Validate(current interface{}) error {
-	err := validate.Struct(current)
+	return validateWith(validate, current)
+}
+
+// ValidateStrict is used to validate the supplied structure in the same way as Validate, but
+// additionally returns an error if any deprecated fields are used, rather than just logging a
+// warning.  Currently this rejects rules that specify the Source or Destination Net or NotNet
+// fields.
+func ValidateStrict(current interface{}) error {
+	return validateWith(validateStrict, current)
+}
+
+func validateWith(v *validator.Validate, current interface{}) error {
+	err := v.Struct(current)
 	if err == nil {
 		return nil
 	}
@@ -90,6 +111,7 @@ func init() {
 	// Initialise static data.
 	config := &validator.Config{TagName: "validate", FieldNameTag: "json"}
 	validate = validator.New(config)
+	validateStrict = validator.New(config)
 
 	// Register field validators.
 	registerFieldValidator("action", validateAction)
@@ -100,6 +122,7 @@ func init() {
 	registerFieldValidator("selector", validateSelector)
 	registerFieldValidator("tag", validateTag)
 	registerFieldValidator("labels", validateLabels)
+	registerFieldValidator("annotations", validateAnnotations)
 	registerFieldValidator("scopeglobalornode", validateScopeGlobalOrNode)
 	registerFieldValidator("ipVersion", validateIPVersion)
 	registerFieldValidator("ipIpMode", validateIPIPMode)
@@ -126,6 +149,9 @@ func init() {
 	// Backend model types.
 	registerStructValidator(validateBackendRule, model.Rule{})
 	registerStructValidator(validateBackendEndpointPort, model.EndpointPort{})
+
+	// Strict mode replaces the rule validation to also reject deprecated fields.
+	validateStrict.RegisterStructValidation(validateRuleStrict, api.Rule{})
 }
 
 // reason returns the provided error reason prefixed with an identifier that
@@ -146,10 +172,12 @@ func extractReason(tag string) string {
 
 func registerFieldValidator(key string, fn validator.Func) {
 	validate.RegisterValidation(key, fn)
+	validateStrict.RegisterValidation(key, fn)
 }
 
 func registerStructValidator(fn validator.StructLevelFunc, t ...interface{}) {
 	validate.RegisterStructValidation(fn, t...)
+	validateStrict.RegisterStructValidation(fn, t...)
 }
 
 func validateAction(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
@@ -230,6 +258,19 @@ func validateLabels(v *validator.Validate, topStruct reflect.Value, currentStruc
 	return true
 }
 
+// validateAnnotations rejects annotations with the reserved projectcalico.org/ prefix, which
+// are used by Calico, for example to record details of the upgrade of a v1 resource.
+func validateAnnotations(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
+	a := field.Interface().(map[string]string)
+	log.Debugf("Validate annotations: %s", a)
+	for k := range a {
+		if strings.HasPrefix(k, reservedAnnotationPrefix) {
+			return false
+		}
+	}
+	return true
+}
+
 func validateScopeGlobalOrNode(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
 	f := field.Interface().(scope.Scope)
 	log.Debugf("Validate scope: %v", f)
@@ -404,6 +445,16 @@ func validateIPPool(v *validator.Validate, structLevel *validator.StructLevel) {
 			structLevel.ReportError(reflect.ValueOf(pool.Metadata.CIDR),
 				"CIDR", "", reason(overlapsV6LinkLocal))
 		}
+
+		// Each reserved CIDR must be contained within the pool CIDR.
+		poolOnes, _ := pool.Metadata.CIDR.Mask.Size()
+		for _, r := range pool.Spec.ReservedCIDRs {
+			ones, _ := r.Mask.Size()
+			if r.Version() != pool.Metadata.CIDR.Version() || ones < poolOnes || !pool.Metadata.CIDR.Contains(r.IP) {
+				structLevel.ReportError(reflect.ValueOf(r),
+					"ReservedCIDRs", "", reason(reservedNotInPool))
+			}
+		}
 	}
 }
 
@@ -419,6 +470,36 @@ func validateICMPFields(v *validator.Validate, structLevel *validator.StructLeve
 
 func validateRule(v *validator.Validate, structLevel *validator.StructLevel) {
 	rule := structLevel.CurrentStruct.Interface().(api.Rule)
+	validateRuleFields(structLevel, rule)
+
+	if rule.Source.Net != nil || rule.Source.NotNet != nil ||
+		rule.Destination.Net != nil || rule.Destination.NotNet != nil {
+		log.WithField("rule", rule).Warn("Rule uses deprecated fields: " + deprecatedNetMsg)
+	}
+}
+
+// validateRuleStrict validates a rule in the same way as validateRule, but reports an error
+// rather than a warning if the rule uses the deprecated Net or NotNet fields.
+func validateRuleStrict(v *validator.Validate, structLevel *validator.StructLevel) {
+	rule := structLevel.CurrentStruct.Interface().(api.Rule)
+	validateRuleFields(structLevel, rule)
+
+	for _, f := range []struct {
+		name  string
+		value *calinet.IPNet
+	}{
+		{"Source.Net", rule.Source.Net},
+		{"Source.NotNet", rule.Source.NotNet},
+		{"Destination.Net", rule.Destination.Net},
+		{"Destination.NotNet", rule.Destination.NotNet},
+	} {
+		if f.value != nil {
+			structLevel.ReportError(reflect.ValueOf(f.value), f.name, "", reason(deprecatedNetMsg))
+		}
+	}
+}
+
+func validateRuleFields(structLevel *validator.StructLevel, rule api.Rule) {
 
 	// If the protocol is neither tcp (6) nor udp (17) check that the port values have not
 	// been specified.
@@ -488,6 +488,10 @@ func init() {
 		Entry("should reject label value starting with ~", api.HostEndpointMetadata{Labels: map[string]string{"rank_.0-9": "~gold"}}, false),
 		Entry("should reject label value ending with ~", api.HostEndpointMetadata{Labels: map[string]string{"rank_.0-9": "gold~"}}, false),
 
+		// (API) Annotations.
+		Entry("should accept workload endpoint annotations", api.WorkloadEndpointMetadata{Annotations: map[string]string{"example.com/owner": "team-a", "note": "any value!"}}, true),
+		Entry("should reject workload endpoint annotations with the reserved prefix", api.WorkloadEndpointMetadata{Annotations: map[string]string{"projectcalico.org/owner": "team-a"}}, false),
+
 		// (API) Interface.
 		Entry("should accept a valid interface", api.WorkloadEndpointSpec{InterfaceName: "Valid_Iface.0-9"}, true),
 		Entry("should reject an interface that is too long", api.WorkloadEndpointSpec{InterfaceName: "interfaceTooLong"}, false),
@@ -650,6 +654,31 @@ func init() {
 			api.IPPool{Metadata: api.IPPoolMetadata{CIDR: net.MustParseCIDR("169.254.5.0/24")}}, false),
 		Entry("should reject IPv6 pool with a CIDR range overlapping with Link Local range",
 			api.IPPool{Metadata: api.IPPoolMetadata{CIDR: net.MustParseCIDR("fe80::/120")}}, false),
+		Entry("should accept IP pool with a reserved CIDR within the pool",
+			api.IPPool{
+				Metadata: api.IPPoolMetadata{CIDR: netv4_3},
+				Spec:     api.IPPoolSpec{ReservedCIDRs: []net.IPNet{netv4_1}},
+			}, true),
+		Entry("should accept IPv6 pool with a reserved CIDR within the pool",
+			api.IPPool{
+				Metadata: api.IPPoolMetadata{CIDR: netv6_3},
+				Spec:     api.IPPoolSpec{ReservedCIDRs: []net.IPNet{netv6_1}},
+			}, true),
+		Entry("should reject IP pool with a reserved CIDR outside the pool",
+			api.IPPool{
+				Metadata: api.IPPoolMetadata{CIDR: netv4_3},
+				Spec:     api.IPPoolSpec{ReservedCIDRs: []net.IPNet{netv4_2}},
+			}, false),
+		Entry("should reject IP pool with a reserved CIDR larger than the pool",
+			api.IPPool{
+				Metadata: api.IPPoolMetadata{CIDR: netv4_3},
+				Spec:     api.IPPoolSpec{ReservedCIDRs: []net.IPNet{net.MustParseCIDR("1.2.0.0/16")}},
+			}, false),
+		Entry("should reject IPv4 pool with an IPv6 reserved CIDR",
+			api.IPPool{
+				Metadata: api.IPPoolMetadata{CIDR: netv4_3},
+				Spec:     api.IPPoolSpec{ReservedCIDRs: []net.IPNet{netv6_1}},
+			}, false),
 
 		// (API) IPIPConfiguration
 		Entry("should accept IPIP disabled", api.IPIPConfiguration{Enabled: false}, true),
@@ -19,6 +19,7 @@ import (
 	"net"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 
 	validator "gopkg.in/go-playground/validator.v8"
@@ -112,6 +113,45 @@ func Validate(current interface{}) error {
 	return nil
 }
 
+// ValidatePolicyTypes checks that a policy only has rules for the directions listed in its
+// Types, returning an ErrorValidation for each direction that has rules but is not listed.
+// The errors are reported against the fields of the policy of the supplied kind.  A policy
+// with no Types is not checked, since its Types are defaulted from its rules.
+//
+// This is not checked by Validate, since policies that specify Types and also carry rules
+// for other directions are permitted to support upgrading from policies without Types.
+func ValidatePolicyTypes(kind string, types []api.PolicyType, ingress, egress []api.Rule) error {
+	if len(types) == 0 {
+		return nil
+	}
+	listed := map[api.PolicyType]bool{}
+	for _, t := range types {
+		listed[t] = true
+	}
+
+	verr := errors.ErrorValidation{}
+	for _, d := range []struct {
+		field      string
+		policyType api.PolicyType
+		rules      []api.Rule
+	}{
+		{kind + ".Spec.Ingress", api.PolicyTypeIngress, ingress},
+		{kind + ".Spec.Egress", api.PolicyTypeEgress, egress},
+	} {
+		if len(d.rules) > 0 && !listed[d.policyType] {
+			verr.ErroredFields = append(verr.ErroredFields, errors.ErroredField{
+				Name:   d.field,
+				Value:  types,
+				Reason: fmt.Sprintf("policy has %s rules but Types does not include %s", strings.ToLower(string(d.policyType)), d.policyType),
+			})
+		}
+	}
+	if len(verr.ErroredFields) > 0 {
+		return verr
+	}
+	return nil
+}
+
 func convertError(err error) errors.ErrorValidation {
 	verr := errors.ErrorValidation{}
 	for _, f := range err.(validator.ValidationErrors) {
@@ -122,6 +162,12 @@ func convertError(err error) errors.ErrorValidation {
 				Reason: extractReason(f.Tag),
 			})
 	}
+
+	// The validator returns the errors as a map, so sort the errored fields to give a
+	// deterministic ordering.
+	sort.SliceStable(verr.ErroredFields, func(i, j int) bool {
+		return verr.ErroredFields[i].Name < verr.ErroredFields[j].Name
+	})
 	return verr
 }
 
@@ -139,7 +185,6 @@ func init() {
 	registerFieldValidator("name", validateName)
 	registerFieldValidator("containerID", validateContainerID)
 	registerFieldValidator("selector", validateSelector)
-	registerFieldValidator("labels", validateLabels)
 	registerFieldValidator("ipVersion", validateIPVersion)
 	registerFieldValidator("ipIpMode", validateIPIPMode)
 	registerFieldValidator("policyType", validatePolicyType)
@@ -173,6 +218,8 @@ func init() {
 	registerStructValidator(validatorPrimary, validateNodeSpec, api.NodeSpec{})
 	registerStructValidator(validatorPrimary, validateObjectMeta, metav1.ObjectMeta{})
 	registerStructValidator(validatorPrimary, validateHTTPRule, api.HTTPMatch{})
+	registerStructValidator(validatorPrimary, validateRuleMetadata, api.RuleMetadata{})
+	registerStructValidator(validatorPrimary, validateProfileSpec, api.ProfileSpec{})
 
 	// Register structs that have one level of additional structs to validate.
 	registerStructValidator(validatorSecondary, validateFelixConfigSpec, api.FelixConfigurationSpec{})
@@ -310,19 +357,6 @@ func validateTag(v *validator.Validate, topStruct reflect.Value, currentStructOr
 	return nameRegex.MatchString(s)
 }
 
-func validateLabels(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
-	labels := field.Interface().(map[string]string)
-	for k, v := range labels {
-		if len(k8svalidation.IsQualifiedName(k)) != 0 {
-			return false
-		}
-		if len(k8svalidation.IsValidLabelValue(v)) != 0 {
-			return false
-		}
-	}
-	return true
-}
-
 func validatePolicyType(v *validator.Validate, topStruct reflect.Value, currentStructOrField reflect.Value, field reflect.Value, fieldType reflect.Type, fieldKind reflect.Kind, param string) bool {
 	s := field.String()
 	log.Debugf("Validate policy type: %s", s)
@@ -546,80 +580,118 @@ func validateFelixConfigSpec(v *validator.Validate, structLevel *validator.Struc
 func validateWorkloadEndpointSpec(v *validator.Validate, structLevel *validator.StructLevel) {
 	w := structLevel.CurrentStruct.Interface().(api.WorkloadEndpointSpec)
 
+	// Each problem is reported against the path of the offending field, for example
+	// WorkloadEndpoint.Spec.IPNATs[0].InternalIP, so that all of the problems with the
+	// endpoint are returned together.
+
 	// The configured networks only support /32 (for IPv4) and /128 (for IPv6) at present.
-	for _, netw := range w.IPNetworks {
+	networks := []*cnet.IPNet{}
+	for i, netw := range w.IPNetworks {
 		_, nw, err := cnet.ParseCIDROrIP(netw)
 		if err != nil {
 			structLevel.ReportError(reflect.ValueOf(netw),
-				"IPNetworks", "", reason("invalid CIDR"))
+				fmt.Sprintf("WorkloadEndpoint.Spec.IPNetworks[%d]", i), "", reason("invalid CIDR"))
+			continue
 		}
+		networks = append(networks, nw)
 
 		ones, bits := nw.Mask.Size()
 		if bits != ones {
-			structLevel.ReportError(reflect.ValueOf(w.IPNetworks),
-				"IPNetworks", "", reason("IP network contains multiple addresses"))
+			structLevel.ReportError(reflect.ValueOf(netw),
+				fmt.Sprintf("WorkloadEndpoint.Spec.IPNetworks[%d]", i), "", reason("IP network contains multiple addresses"))
 		}
 	}
 
-	_, v4gw, err := cnet.ParseCIDROrIP(w.IPv4Gateway)
-	if err != nil {
-		structLevel.ReportError(reflect.ValueOf(w.IPv4Gateway),
-			"IPv4Gateway", "", reason("invalid CIDR"))
-	}
-
-	_, v6gw, err := cnet.ParseCIDROrIP(w.IPv6Gateway)
-	if err != nil {
-		structLevel.ReportError(reflect.ValueOf(w.IPv6Gateway),
-			"IPv6Gateway", "", reason("invalid CIDR"))
+	if w.IPv4Gateway != "" {
+		if gw := cnet.ParseIP(w.IPv4Gateway); gw == nil || gw.Version() != 4 {
+			structLevel.ReportError(reflect.ValueOf(w.IPv4Gateway),
+				"WorkloadEndpoint.Spec.IPv4Gateway", "", reason("invalid IPv4 gateway address specified"))
+		}
 	}
 
-	if v4gw.IP != nil && v4gw.Version() != 4 {
-		structLevel.ReportError(reflect.ValueOf(w.IPv4Gateway),
-			"IPv4Gateway", "", reason("invalid IPv4 gateway address specified"))
+	if w.IPv6Gateway != "" {
+		if gw := cnet.ParseIP(w.IPv6Gateway); gw == nil || gw.Version() != 6 {
+			structLevel.ReportError(reflect.ValueOf(w.IPv6Gateway),
+				"WorkloadEndpoint.Spec.IPv6Gateway", "", reason("invalid IPv6 gateway address specified"))
+		}
 	}
 
-	if v6gw.IP != nil && v6gw.Version() != 6 {
-		structLevel.ReportError(reflect.ValueOf(w.IPv6Gateway),
-			"IPv6Gateway", "", reason("invalid IPv6 gateway address specified"))
+	if w.MAC != "" {
+		if _, err := net.ParseMAC(w.MAC); err != nil {
+			structLevel.ReportError(reflect.ValueOf(w.MAC),
+				"WorkloadEndpoint.Spec.MAC", "", reason("invalid MAC address"))
+		}
 	}
 
 	// If NATs have been specified, then they should each be within the configured networks of
 	// the endpoint.
-	if len(w.IPNATs) > 0 {
+	for i, nat := range w.IPNATs {
+		field := fmt.Sprintf("WorkloadEndpoint.Spec.IPNATs[%d].InternalIP", i)
+		natIP, _, err := cnet.ParseCIDROrIP(nat.InternalIP)
+		if err != nil {
+			structLevel.ReportError(reflect.ValueOf(nat.InternalIP),
+				field, "", reason("invalid InternalIP CIDR"))
+			continue
+		}
+
 		valid := false
-		for _, nat := range w.IPNATs {
-			_, natCidr, err := cnet.ParseCIDROrIP(nat.InternalIP)
-			if err != nil {
-				structLevel.ReportError(reflect.ValueOf(nat.InternalIP),
-					"IPNATs", "", reason("invalid InternalIP CIDR"))
+		for _, nw := range networks {
+			if nw.Contains(natIP.IP) {
+				valid = true
+				break
 			}
-			// Check each NAT to ensure it is within the configured networks.  If any
-			// are not then exit without further checks.
-			valid = false
-			for _, cidr := range w.IPNetworks {
-				_, nw, err := cnet.ParseCIDROrIP(cidr)
-				if err != nil {
-					structLevel.ReportError(reflect.ValueOf(cidr),
-						"IPNetworks", "", reason("invalid CIDR"))
-				}
+		}
+		if !valid {
+			structLevel.ReportError(reflect.ValueOf(nat.InternalIP),
+				field, "", reason("NAT is not in the endpoint networks"))
+		}
+	}
 
-				if nw.Contains(natCidr.IP) {
-					valid = true
-					break
+	// The internal IPs and the external IPs should each be unique across the NATs.  Internal
+	// IPs are compared after masking, so that an internal IP specified as a CIDR collides with
+	// the IPs it contains, and an internal IP that maps to more than one external IP is
+	// reported as a contradictory mapping rather than a duplicate.
+	internalNets := make([]*cnet.IPNet, len(w.IPNATs))
+	externalIPs := map[string]int{}
+	for i, nat := range w.IPNATs {
+		if _, nw, err := cnet.ParseCIDROrIP(nat.InternalIP); err == nil {
+			for j, other := range internalNets[:i] {
+				if other == nil || !(other.Contains(nw.IP) || nw.Contains(other.IP)) {
+					continue
 				}
-			}
-			if !valid {
+				r := fmt.Sprintf("duplicate InternalIP, also used by IPNATs[%d]", j)
+				if !sameIP(nat.ExternalIP, w.IPNATs[j].ExternalIP) {
+					r = fmt.Sprintf("InternalIP is mapped to ExternalIP %s and also to ExternalIP %s by IPNATs[%d]",
+						nat.ExternalIP, w.IPNATs[j].ExternalIP, j)
+				}
+				structLevel.ReportError(reflect.ValueOf(nat.InternalIP),
+					fmt.Sprintf("WorkloadEndpoint.Spec.IPNATs[%d].InternalIP", i), "", reason(r))
 				break
 			}
+			internalNets[i] = nw
 		}
-
-		if !valid {
-			structLevel.ReportError(reflect.ValueOf(w.IPNATs),
-				"IPNATs", "", reason("NAT is not in the endpoint networks"))
+		if ip := cnet.ParseIP(nat.ExternalIP); ip != nil {
+			if j, ok := externalIPs[ip.String()]; ok {
+				structLevel.ReportError(reflect.ValueOf(nat.ExternalIP),
+					fmt.Sprintf("WorkloadEndpoint.Spec.IPNATs[%d].ExternalIP", i), "",
+					reason(fmt.Sprintf("duplicate ExternalIP, also used by IPNATs[%d]", j)))
+			} else {
+				externalIPs[ip.String()] = i
+			}
 		}
 	}
 }
 
+// sameIP returns whether the two strings are the same IP address, comparing the strings
+// directly if either is not a valid IP address.
+func sameIP(a, b string) bool {
+	ipa, ipb := cnet.ParseIP(a), cnet.ParseIP(b)
+	if ipa == nil || ipb == nil {
+		return a == b
+	}
+	return ipa.Equal(ipb.IP)
+}
+
 func validateHostEndpointSpec(v *validator.Validate, structLevel *validator.StructLevel) {
 	h := structLevel.CurrentStruct.Interface().(api.HostEndpointSpec)
 
@@ -914,7 +986,7 @@ func validateGlobalNetworkSet(v *validator.Validate, structLevel *validator.Stru
 			// to the v1 datamodel.  It shouldn't appear in the v3 datamodel.
 			structLevel.ReportError(
 				reflect.ValueOf(k),
-				"Metadata.Labels (label)",
+				labelFieldName("Metadata.Labels", k, "label"),
 				"",
 				reason("projectcalico.org/namespace is not a valid label name"),
 			)
@@ -996,14 +1068,26 @@ func validateGlobalNetworkPolicy(v *validator.Validate, structLevel *validator.S
 }
 
 func validateObjectMetaAnnotations(v *validator.Validate, structLevel *validator.StructLevel, annotations map[string]string) {
+	validateAnnotations(structLevel, "Metadata.Annotations", annotations)
+}
+
+func validateRuleMetadata(v *validator.Validate, structLevel *validator.StructLevel) {
+	m := structLevel.CurrentStruct.Interface().(api.RuleMetadata)
+	validateAnnotations(structLevel, "Rule.Metadata.Annotations", m.Annotations)
+}
+
+// validateAnnotations checks the format of the annotation keys and the total size of the
+// annotations, reporting any errors against the named field.  An invalid key is reported
+// against the field for that key.
+func validateAnnotations(structLevel *validator.StructLevel, field string, annotations map[string]string) {
 	var totalSize int64
 	for k, v := range annotations {
-		for _, errStr := range k8svalidation.IsQualifiedName(strings.ToLower(k)) {
+		if errs := k8svalidation.IsQualifiedName(strings.ToLower(k)); len(errs) != 0 {
 			structLevel.ReportError(
 				reflect.ValueOf(k),
-				"Metadata.Annotations (key)",
+				labelFieldName(field, k, "key"),
 				"",
-				reason(errStr),
+				reason(strings.Join(errs, "; ")),
 			)
 		}
 		totalSize += (int64)(len(k)) + (int64)(len(v))
@@ -1012,7 +1096,7 @@ func validateObjectMetaAnnotations(v *validator.Validate, structLevel *validator
 	if totalSize > (int64)(totalAnnotationSizeLimitB) {
 		structLevel.ReportError(
 			reflect.ValueOf(annotations),
-			"Metadata.Annotations (key)",
+			field+" (key)",
 			"",
 			reason(fmt.Sprintf("total size of annotations is too large by %d bytes", totalSize-totalAnnotationSizeLimitB)),
 		)
@@ -1020,22 +1104,42 @@ func validateObjectMetaAnnotations(v *validator.Validate, structLevel *validator
 }
 
 func validateObjectMetaLabels(v *validator.Validate, structLevel *validator.StructLevel, labels map[string]string) {
+	validateLabels(structLevel, "Metadata.Labels", labels)
+}
+
+func validateProfileSpec(v *validator.Validate, structLevel *validator.StructLevel) {
+	spec := structLevel.CurrentStruct.Interface().(api.ProfileSpec)
+	validateLabels(structLevel, "Profile.Spec.LabelsToApply", spec.LabelsToApply)
+}
+
+// validateLabels checks the format of the label keys and values, reporting any errors against
+// the field for the label so that every invalid label is reported.  The key must be a
+// qualified name (an optional DNS subdomain prefix and a name of at most 63 characters) and
+// the value must be at most 63 characters of the allowed label value characters.
+func validateLabels(structLevel *validator.StructLevel, field string, labels map[string]string) {
 	for k, v := range labels {
-		for _, errStr := range k8svalidation.IsQualifiedName(k) {
+		if errs := k8svalidation.IsQualifiedName(k); len(errs) != 0 {
 			structLevel.ReportError(
 				reflect.ValueOf(k),
-				"Metadata.Labels (label)",
+				labelFieldName(field, k, "label"),
 				"",
-				reason(errStr),
+				reason(strings.Join(errs, "; ")),
 			)
 		}
-		for _, errStr := range k8svalidation.IsValidLabelValue(v) {
+		if errs := k8svalidation.IsValidLabelValue(v); len(errs) != 0 {
 			structLevel.ReportError(
 				reflect.ValueOf(v),
-				"Metadata.Labels (value)",
+				labelFieldName(field, k, "value"),
 				"",
-				reason(errStr),
+				reason(strings.Join(errs, "; ")),
 			)
 		}
 	}
 }
+
+// labelFieldName returns the name of the field for a single label or annotation, identifying
+// whether the error is for the key or the value.  The validator records one error per field
+// name, so each key must have its own field name for all of the errors to be reported.
+func labelFieldName(field, key, part string) string {
+	return fmt.Sprintf("%s[%s] (%s)", field, key, part)
+}
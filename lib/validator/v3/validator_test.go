@@ -15,12 +15,15 @@
 package v3_test
 
 import (
+	"strings"
+
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/projectcalico/libcalico-go/lib/apis/v1"
 	api "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/errors"
 	"github.com/projectcalico/libcalico-go/lib/ipip"
 	"github.com/projectcalico/libcalico-go/lib/numorstring"
 	"github.com/projectcalico/libcalico-go/lib/validator/v3"
@@ -88,6 +91,64 @@ func init() {
 					}},
 				},
 			}, "error with field Port = '0' (port range invalid, port number must be between 1 and 65535)"),
+		Entry("should reject WorkloadEndpointSpec with duplicate NAT ExternalIPs",
+			api.WorkloadEndpointSpec{
+				InterfaceName: "cali012371237",
+				IPNetworks:    []string{netv4_1, netv4_2},
+				IPNATs: []api.IPNAT{
+					{InternalIP: ipv4_1, ExternalIP: ipv4_2},
+					{InternalIP: "1.2.0.0", ExternalIP: ipv4_2},
+				},
+			}, "error with field WorkloadEndpoint.Spec.IPNATs[1].ExternalIP = '100.200.0.0' (duplicate ExternalIP, also used by IPNATs[0])"),
+		Entry("should reject WorkloadEndpointSpec with an InternalIP mapped to two ExternalIPs",
+			api.WorkloadEndpointSpec{
+				InterfaceName: "cali012371237",
+				IPNetworks:    []string{netv4_1},
+				IPNATs: []api.IPNAT{
+					{InternalIP: ipv4_1, ExternalIP: ipv4_2},
+					{InternalIP: ipv4_1, ExternalIP: "100.200.0.1"},
+				},
+			}, "error with field WorkloadEndpoint.Spec.IPNATs[1].InternalIP = '1.2.3.4' (InternalIP is mapped to ExternalIP 100.200.0.1 and also to ExternalIP 100.200.0.0 by IPNATs[0])"),
+		Entry("should reject WorkloadEndpointSpec with a CIDR InternalIP mapped to two ExternalIPs",
+			api.WorkloadEndpointSpec{
+				InterfaceName: "cali012371237",
+				IPNetworks:    []string{netv4_1},
+				IPNATs: []api.IPNAT{
+					{InternalIP: ipv4_1, ExternalIP: ipv4_2},
+					{InternalIP: netv4_1, ExternalIP: "100.200.0.1"},
+				},
+			}, "error with field WorkloadEndpoint.Spec.IPNATs[1].InternalIP = '1.2.3.4/32' (InternalIP is mapped to ExternalIP 100.200.0.1 and also to ExternalIP 100.200.0.0 by IPNATs[0])"),
+	)
+
+	// Perform validation that checks the full set of errored fields is reported.
+	DescribeTable("Validator errored fields",
+		func(input interface{}, fields []string) {
+			err := v3.Validate(input)
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+			names := []string{}
+			for _, f := range err.(errors.ErrorValidation).ErroredFields {
+				names = append(names, f.Name)
+			}
+			Expect(names).To(Equal(fields))
+		},
+		Entry("should report all WorkloadEndpointSpec problems together",
+			api.WorkloadEndpointSpec{
+				InterfaceName: "cali012371237",
+				IPNetworks:    []string{netv4_1, netv4_3},
+				IPNATs: []api.IPNAT{
+					{InternalIP: ipv4_1, ExternalIP: ipv4_2},
+					{InternalIP: ipv4_2, ExternalIP: ipv4_1},
+				},
+				IPv4Gateway: ipv6_1,
+				MAC:         "01:23:45:67:89",
+			},
+			[]string{
+				"WorkloadEndpoint.Spec.IPNATs[1].InternalIP",
+				"WorkloadEndpoint.Spec.IPNetworks[1]",
+				"WorkloadEndpoint.Spec.IPv4Gateway",
+				"WorkloadEndpoint.Spec.MAC",
+			},
+		),
 	)
 
 	// Perform basic validation of different fields and structures to test simple valid/invalid
@@ -562,6 +623,33 @@ func init() {
 					{InternalIP: ipv6_1, ExternalIP: ipv6_2},
 				},
 			}, true),
+		Entry("should accept workload endpoint with distinct NATs",
+			api.WorkloadEndpointSpec{
+				InterfaceName: "cali012371237",
+				IPNetworks:    []string{netv4_1, netv4_2},
+				IPNATs: []api.IPNAT{
+					{InternalIP: ipv4_1, ExternalIP: ipv4_2},
+					{InternalIP: "1.2.0.0", ExternalIP: "100.200.0.1"},
+				},
+			}, true),
+		Entry("should reject workload endpoint with duplicate NAT ExternalIPs",
+			api.WorkloadEndpointSpec{
+				InterfaceName: "cali012371237",
+				IPNetworks:    []string{netv4_1, netv4_2},
+				IPNATs: []api.IPNAT{
+					{InternalIP: ipv4_1, ExternalIP: ipv4_2},
+					{InternalIP: "1.2.0.0", ExternalIP: ipv4_2},
+				},
+			}, false),
+		Entry("should reject workload endpoint with duplicate NAT InternalIPs",
+			api.WorkloadEndpointSpec{
+				InterfaceName: "cali012371237",
+				IPNetworks:    []string{netv4_1},
+				IPNATs: []api.IPNAT{
+					{InternalIP: ipv4_1, ExternalIP: ipv4_2},
+					{InternalIP: ipv4_1, ExternalIP: "100.200.0.1"},
+				},
+			}, false),
 		Entry("should accept workload endpoint with mixed-case ContainerID",
 			api.WorkloadEndpointSpec{
 				InterfaceName: "cali012371237",
@@ -595,6 +683,28 @@ func init() {
 				IPNetworks:    []string{netv6_1},
 				IPNATs:        []api.IPNAT{{InternalIP: ipv6_2, ExternalIP: ipv6_1}},
 			}, false),
+		Entry("should accept workload endpoint with valid gateways and MAC",
+			api.WorkloadEndpointSpec{
+				InterfaceName: "cali012371237",
+				IPv4Gateway:   ipv4_1,
+				IPv6Gateway:   ipv6_1,
+				MAC:           "01:23:45:67:89:ab",
+			}, true),
+		Entry("should reject workload endpoint with IPv6 address as IPv4 gateway",
+			api.WorkloadEndpointSpec{
+				InterfaceName: "cali012371237",
+				IPv4Gateway:   ipv6_1,
+			}, false),
+		Entry("should reject workload endpoint with IPv4 address as IPv6 gateway",
+			api.WorkloadEndpointSpec{
+				InterfaceName: "cali012371237",
+				IPv6Gateway:   ipv4_1,
+			}, false),
+		Entry("should reject workload endpoint with invalid MAC",
+			api.WorkloadEndpointSpec{
+				InterfaceName: "cali012371237",
+				MAC:           "01:23:45:67:89",
+			}, false),
 		Entry("should reject workload endpoint containerID that starts with a dash",
 			api.WorkloadEndpointSpec{
 				InterfaceName: "cali0134",
@@ -1535,6 +1645,16 @@ func init() {
 			&api.HTTPMatch{Paths: []api.HTTPPath{{Exact: "/foo", Prefix: "/bar"}, {Prefix: "/bar"}}},
 			false,
 		),
+
+		// Rule metadata.
+		Entry("allow a rule with metadata annotations",
+			&api.Rule{Action: "Allow", Metadata: &api.RuleMetadata{Annotations: map[string]string{"note": "allow dns", "example.com/ticket": "1234"}}},
+			true,
+		),
+		Entry("disallow a rule with an invalid metadata annotation key",
+			&api.Rule{Action: "Allow", Metadata: &api.RuleMetadata{Annotations: map[string]string{"not a key": "value"}}},
+			false,
+		),
 		Entry("disallow HTTP Path with invalid match clauses",
 			&api.HTTPMatch{Paths: []api.HTTPPath{{Exact: "/fo?o"}}},
 			false,
@@ -1579,3 +1699,114 @@ func mustParsePortRange(min, max uint16) numorstring.Port {
 	}
 	return p
 }
+
+var _ = DescribeTable("ValidatePolicyTypes",
+	func(types []api.PolicyType, ingress, egress []api.Rule, expected string) {
+		err := v3.ValidatePolicyTypes(api.KindGlobalNetworkPolicy, types, ingress, egress)
+		if expected == "" {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+			Expect(err.Error()).To(ContainSubstring(expected))
+		}
+	},
+	Entry("allow missing Types with rules",
+		nil, []api.Rule{{Action: "Allow"}}, []api.Rule{{Action: "Allow"}}, ""),
+	Entry("allow ingress Types with ingress rules",
+		[]api.PolicyType{api.PolicyTypeIngress}, []api.Rule{{Action: "Allow"}}, nil, ""),
+	Entry("allow ingress+egress Types with ingress and egress rules",
+		[]api.PolicyType{api.PolicyTypeIngress, api.PolicyTypeEgress}, []api.Rule{{Action: "Allow"}}, []api.Rule{{Action: "Deny"}}, ""),
+	Entry("allow egress Types with no rules",
+		[]api.PolicyType{api.PolicyTypeEgress}, nil, nil, ""),
+	Entry("disallow ingress Types with egress rules",
+		[]api.PolicyType{api.PolicyTypeIngress}, []api.Rule{{Action: "Allow"}}, []api.Rule{{Action: "Allow"}},
+		"error with field GlobalNetworkPolicy.Spec.Egress = '[Ingress]' (policy has egress rules but Types does not include Egress)"),
+	Entry("disallow egress Types with ingress rules",
+		[]api.PolicyType{api.PolicyTypeEgress}, []api.Rule{{Action: "Allow"}}, nil,
+		"error with field GlobalNetworkPolicy.Spec.Ingress = '[Egress]' (policy has ingress rules but Types does not include Ingress)"),
+)
+
+var _ = DescribeTable("Label and annotation errors",
+	func(res interface{}, expected []errors.ErroredField, reason string) {
+		err := v3.Validate(res)
+		if expected == nil {
+			Expect(err).NotTo(HaveOccurred())
+			return
+		}
+		Expect(err).To(BeAssignableToTypeOf(errors.ErrorValidation{}))
+
+		// The reasons are the messages of the Kubernetes validation, so just check that
+		// each field has one, containing the expected text if specified.
+		actual := []errors.ErroredField{}
+		for _, f := range err.(errors.ErrorValidation).ErroredFields {
+			Expect(f.Reason).NotTo(BeEmpty())
+			Expect(f.Reason).To(ContainSubstring(reason))
+			actual = append(actual, errors.ErroredField{Name: f.Name, Value: f.Value})
+		}
+		Expect(actual).To(Equal(expected))
+	},
+	Entry("accept valid labels and annotations",
+		&api.GlobalNetworkSet{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "networkset",
+				Labels:      map[string]string{"app": "frontend", "projectcalico.org/tier": "web", "empty": ""},
+				Annotations: map[string]string{"projectcalico.org/note": "any value, including spaces"},
+			},
+		},
+		nil, "",
+	),
+	Entry("report each invalid label key and value",
+		&api.GlobalNetworkSet{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "networkset",
+				Labels: map[string]string{
+					"app":         "frontend",
+					"has space":   "value",
+					"Bad.Domain/": "value",
+					"tier":        "not valid!",
+				},
+			},
+		},
+		[]errors.ErroredField{
+			{Name: "Metadata.Labels[Bad.Domain/] (label)", Value: "Bad.Domain/"},
+			{Name: "Metadata.Labels[has space] (label)", Value: "has space"},
+			{Name: "Metadata.Labels[tier] (value)", Value: "not valid!"},
+		}, "",
+	),
+	Entry("report a label key and value that are too long",
+		&api.GlobalNetworkSet{
+			ObjectMeta: v1.ObjectMeta{
+				Name:   "networkset",
+				Labels: map[string]string{strings.Repeat("k", 64): strings.Repeat("v", 64)},
+			},
+		},
+		[]errors.ErroredField{
+			{Name: "Metadata.Labels[" + strings.Repeat("k", 64) + "] (label)", Value: strings.Repeat("k", 64)},
+			{Name: "Metadata.Labels[" + strings.Repeat("k", 64) + "] (value)", Value: strings.Repeat("v", 64)},
+		}, "63 characters",
+	),
+	Entry("report each invalid profile label to apply",
+		&api.Profile{
+			ObjectMeta: v1.ObjectMeta{Name: "profile"},
+			Spec: api.ProfileSpec{
+				LabelsToApply: map[string]string{"valid": "value", "in valid": "value", "key": "in valid"},
+			},
+		},
+		[]errors.ErroredField{
+			{Name: "Profile.Spec.LabelsToApply[in valid] (label)", Value: "in valid"},
+			{Name: "Profile.Spec.LabelsToApply[key] (value)", Value: "in valid"},
+		}, "",
+	),
+	Entry("report each invalid annotation key",
+		&api.GlobalNetworkSet{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "networkset",
+				Annotations: map[string]string{"has space": "value", "ok": "value", "bad$key": "value"},
+			},
+		},
+		[]errors.ErroredField{
+			{Name: "Metadata.Annotations[bad$key] (key)", Value: "bad$key"},
+			{Name: "Metadata.Annotations[has space] (key)", Value: "has space"},
+		}, "",
+	),
+)
@@ -0,0 +1,181 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NewCoalescingWatcher wraps a watcher, collapsing the events received for the same resource
+// within the window into a single event with the latest state of the resource.  This is useful
+// for consumers that only need the latest state of each resource, and would otherwise process
+// many events for a resource that is updated rapidly.
+//
+// The window starts when an event is received, and all of the events received within the window
+// are emitted when it ends.  The events for each resource are combined as follows: an Added
+// followed by Modified events is emitted as an Added event with the latest state, Modified
+// events are emitted as a single Modified event with the earliest previous state and the latest
+// state, and a Deleted event replaces the pending event for the resource.  If the pending event
+// is an Added event the resource was created and deleted within the window, so neither event is
+// emitted.  Events received after a Deleted event for the same resource are not combined with
+// it, so that a delete is always emitted before a subsequent add.  The coalesced events are
+// emitted in the order in which their latest event was received, so the relative order of the
+// events for different resources is preserved.  Error events, and events whose resource cannot
+// be identified, are not coalesced.
+func NewCoalescingWatcher(w Interface, window time.Duration) Interface {
+	cw := &coalescingWatcher{
+		source:  w,
+		window:  window,
+		results: make(chan Event, DefaultChanSize),
+		done:    make(chan struct{}),
+		pending: map[string]int{},
+	}
+	go cw.run()
+	return cw
+}
+
+// coalescingWatcher implements the watch.Interface, coalescing the events of the source
+// watcher.
+type coalescingWatcher struct {
+	source   Interface
+	window   time.Duration
+	results  chan Event
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// The events received in the current window, in the order in which they are emitted.
+	// Entries for events that have been combined with a later event are nil.
+	events []*Event
+
+	// The index in events of the pending event for each resource key.
+	pending map[string]int
+}
+
+func (w *coalescingWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+	})
+	w.source.Stop()
+}
+
+func (w *coalescingWatcher) ResultChan() <-chan Event {
+	return w.results
+}
+
+// run is the main loop, coalescing the events from the source watcher and sending the events
+// at the end of each window.
+func (w *coalescingWatcher) run() {
+	defer close(w.results)
+	var timer <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.source.ResultChan():
+			if !ok {
+				// The source watcher has terminated, send the pending events.
+				w.flush()
+				return
+			}
+			if len(w.events) == 0 {
+				timer = time.After(w.window)
+			}
+			w.add(event)
+		case <-timer:
+			timer = nil
+			if !w.flush() {
+				return
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// add adds the event to the current window, combining it with the pending event for the same
+// resource if there is one.
+func (w *coalescingWatcher) add(event Event) {
+	key, ok := eventKey(event)
+	if !ok {
+		w.events = append(w.events, &event)
+		return
+	}
+
+	if idx, ok := w.pending[key]; ok {
+		prev := w.events[idx]
+		if prev.Type != Deleted {
+			w.events[idx] = nil
+			switch {
+			case event.Type == Deleted && prev.Type == Added:
+				// The resource was never emitted, so drop both events.
+				delete(w.pending, key)
+				return
+			case event.Type == Deleted:
+				// The Deleted event replaces the pending event.
+			case prev.Type == Added:
+				event = Event{Type: Added, Object: event.Object}
+			default:
+				event = Event{Type: Modified, Previous: prev.Previous, Object: event.Object}
+			}
+		}
+	}
+	w.pending[key] = len(w.events)
+	w.events = append(w.events, &event)
+}
+
+// flush sends the events of the current window and starts a new window.  Returns false if the
+// watcher was stopped while sending.
+func (w *coalescingWatcher) flush() bool {
+	events := w.events
+	w.events = nil
+	w.pending = map[string]int{}
+	for _, e := range events {
+		if e == nil {
+			continue
+		}
+		select {
+		case w.results <- *e:
+		case <-w.done:
+			return false
+		}
+	}
+	return true
+}
+
+// eventKey returns the key identifying the resource of the event, or false if the event is not
+// for an identifiable resource.
+func eventKey(event Event) (string, bool) {
+	if event.Type == Error {
+		return "", false
+	}
+	obj := event.Object
+	if obj == nil {
+		obj = event.Previous
+	}
+	if obj == nil {
+		return "", false
+	}
+	return objectKey(obj)
+}
+
+func objectKey(obj runtime.Object) (string, bool) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return "", false
+	}
+	return obj.GetObjectKind().GroupVersionKind().Kind + "/" + m.GetNamespace() + "/" + m.GetName(), true
+}
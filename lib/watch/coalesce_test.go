@@ -0,0 +1,155 @@
+// Copyright (c) 2018 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch_test
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apiv3 "github.com/projectcalico/libcalico-go/lib/apis/v3"
+	"github.com/projectcalico/libcalico-go/lib/watch"
+)
+
+// fakeWatcher is a source watcher whose events are sent by the test.
+type fakeWatcher struct {
+	events chan watch.Event
+}
+
+func (w *fakeWatcher) Stop() {}
+
+func (w *fakeWatcher) ResultChan() <-chan watch.Event {
+	return w.events
+}
+
+var _ = Describe("Coalescing watcher", func() {
+	window := 100 * time.Millisecond
+	var source *fakeWatcher
+	var w watch.Interface
+
+	wep := func(name, revision string) *apiv3.WorkloadEndpoint {
+		res := apiv3.NewWorkloadEndpoint()
+		res.Namespace = "namespace1"
+		res.Name = name
+		res.ResourceVersion = revision
+		return res
+	}
+
+	BeforeEach(func() {
+		source = &fakeWatcher{events: make(chan watch.Event, 100)}
+		w = watch.NewCoalescingWatcher(source, window)
+	})
+
+	AfterEach(func() {
+		w.Stop()
+	})
+
+	It("should collapse rapid modifications of a resource into one event", func() {
+		for i := 1; i <= 5; i++ {
+			source.events <- watch.Event{
+				Type:     watch.Modified,
+				Previous: wep("wep1", strconv.Itoa(i-1)),
+				Object:   wep("wep1", strconv.Itoa(i)),
+			}
+		}
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Modified))
+		Expect(event.Previous.(*apiv3.WorkloadEndpoint).ResourceVersion).To(Equal("0"))
+		Expect(event.Object.(*apiv3.WorkloadEndpoint).ResourceVersion).To(Equal("5"))
+		Consistently(w.ResultChan(), 2*window).ShouldNot(Receive())
+	})
+
+	It("should collapse an add and modifications into an add with the latest state", func() {
+		source.events <- watch.Event{Type: watch.Added, Object: wep("wep1", "1")}
+		source.events <- watch.Event{Type: watch.Modified, Previous: wep("wep1", "1"), Object: wep("wep1", "2")}
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Added))
+		Expect(event.Previous).To(BeNil())
+		Expect(event.Object.(*apiv3.WorkloadEndpoint).ResourceVersion).To(Equal("2"))
+		Consistently(w.ResultChan(), 2*window).ShouldNot(Receive())
+	})
+
+	It("should emit the events for different resources in the order of their latest event", func() {
+		source.events <- watch.Event{Type: watch.Modified, Previous: wep("wep1", "1"), Object: wep("wep1", "2")}
+		source.events <- watch.Event{Type: watch.Deleted, Previous: wep("wep2", "3")}
+		source.events <- watch.Event{Type: watch.Added, Object: wep("wep3", "4")}
+		source.events <- watch.Event{Type: watch.Modified, Previous: wep("wep1", "2"), Object: wep("wep1", "5")}
+
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Deleted))
+		Expect(event.Previous.(*apiv3.WorkloadEndpoint).Name).To(Equal("wep2"))
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Added))
+		Expect(event.Object.(*apiv3.WorkloadEndpoint).Name).To(Equal("wep3"))
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Modified))
+		Expect(event.Previous.(*apiv3.WorkloadEndpoint).ResourceVersion).To(Equal("1"))
+		Expect(event.Object.(*apiv3.WorkloadEndpoint).ResourceVersion).To(Equal("5"))
+	})
+
+	It("should not combine an add with an earlier delete of the same resource", func() {
+		source.events <- watch.Event{Type: watch.Modified, Previous: wep("wep1", "1"), Object: wep("wep1", "2")}
+		source.events <- watch.Event{Type: watch.Deleted, Previous: wep("wep1", "2")}
+		source.events <- watch.Event{Type: watch.Added, Object: wep("wep1", "3")}
+
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Deleted))
+		Expect(event.Previous.(*apiv3.WorkloadEndpoint).ResourceVersion).To(Equal("2"))
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Added))
+		Expect(event.Object.(*apiv3.WorkloadEndpoint).ResourceVersion).To(Equal("3"))
+	})
+
+	It("should drop an add and a delete of the same resource", func() {
+		source.events <- watch.Event{Type: watch.Added, Object: wep("wep1", "1")}
+		source.events <- watch.Event{Type: watch.Modified, Previous: wep("wep1", "1"), Object: wep("wep1", "2")}
+		source.events <- watch.Event{Type: watch.Deleted, Previous: wep("wep1", "2")}
+		source.events <- watch.Event{Type: watch.Added, Object: wep("wep2", "3")}
+
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Added))
+		Expect(event.Object.(*apiv3.WorkloadEndpoint).Name).To(Equal("wep2"))
+		Consistently(w.ResultChan(), 2*window).ShouldNot(Receive())
+	})
+
+	It("should not coalesce error events", func() {
+		source.events <- watch.Event{Type: watch.Error, Error: errors.New("error 1")}
+		source.events <- watch.Event{Type: watch.Error, Error: errors.New("error 2")}
+
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Error).To(MatchError("error 1"))
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Error).To(MatchError("error 2"))
+	})
+
+	It("should send the pending events and close the results when the source terminates", func() {
+		source.events <- watch.Event{Type: watch.Added, Object: wep("wep1", "1")}
+		close(source.events)
+
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Added))
+		Eventually(w.ResultChan()).Should(BeClosed())
+	})
+})